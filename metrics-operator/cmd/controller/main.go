@@ -1,50 +1,445 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
 
+	"github.com/tektoncd/experimental/metrics-operator/pkg/informers"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/customrun"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/taskmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/clustertaskmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/metricsexporter"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/pipelinemonitor"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/pipelinerunmonitor"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/taskrun"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/pipelinerun"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/taskrunmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/server"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/simulate"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/sink"
+	_ "github.com/lib/pq"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	cminformer "knative.dev/pkg/configmap/informer"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
 	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/pkg/logging"
+	knativemetrics "knative.dev/pkg/metrics"
 	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
 )
 
 func main() {
-	fmt.Printf("Starting metric-operator...\n")
-	exporter, err := server.NewPrometheusExporter(&server.MetricConfig{
-		PrometheusHost: "0.0.0.0",
-		PrometheusPort: 2112,
-	})
+	evaluationInterval := flag.Duration("evaluation-interval", 30*time.Second, "Interval to re-evaluate gauges and the elapsed time of in-flight runs, 0 disables it.")
+	rateInterval := flag.Duration("rate-interval", 15*time.Second, "Interval to recompute the rate metrics of monitors over their window, 0 only updates them when runs complete.")
+	activeSeriesInterval := flag.Duration("active-series-interval", time.Minute, "Interval to record the number of series of every monitor metric, 0 disables it.")
+	operatorMetricsInterval := flag.Duration("operator-metrics-interval", 30*time.Second, "Interval to record the work queue depths of the controllers and the number of views registered for monitors, 0 disables it.")
+	resyncPeriod := flag.Duration("resync-period", controller.DefaultResyncPeriod, "Period between full resyncs of the informer caches.")
+	listPageSize := flag.Int64("list-page-size", 0, "Number of TaskRuns and PipelineRuns fetched per list call, 0 uses the client default.")
+	watchTimeout := flag.Duration("watch-timeout", 0, "Server side timeout of TaskRun and PipelineRun watch calls, 0 uses the client default.")
+	runLabelSelector := flag.String("run-label-selector", "", "Static label selector of the cached TaskRuns and PipelineRuns, runs not matching it are never recorded by any monitor.")
+	excludeNamespaces := flag.String("exclude-namespaces", "", "Comma separated namespaces never recorded, entries can be glob patterns like test-*.")
+	retryQueueSize := flag.Int("retry-queue-size", 1000, "Maximum number of failed recordings waiting to be retried.")
+	retryInitialBackoff := flag.Duration("retry-initial-backoff", time.Second, "Backoff before retrying a failed recording, doubled on every attempt.")
+	retryMaxBackoff := flag.Duration("retry-max-backoff", 5*time.Minute, "Maximum backoff between two attempts of a failed recording.")
+	retryMaxAttempts := flag.Int("retry-max-attempts", 5, "Attempts before a failed recording is dropped.")
+	breakerThreshold := flag.Int("breaker-threshold", 10, "Consecutive failures of a metric before it's suspended, 0 disables suspensions.")
+	breakerInitialBackoff := flag.Duration("breaker-initial-backoff", time.Minute, "Backoff before a suspended metric is attempted again, doubled every time the attempt fails.")
+	breakerMaxBackoff := flag.Duration("breaker-max-backoff", 30*time.Minute, "Maximum backoff between two attempts of a suspended metric.")
+	recordBatchInterval := flag.Duration("record-batch-interval", 0, "Interval between two flushes of the batched samples of monitor metrics, 0 records every sample right away.")
+	recordBatchSize := flag.Int("record-batch-size", 1000, "Batched samples flushing the batch before the interval.")
+	dedupCacheSize := flag.Int("dedup-cache-size", 50000, "Recordings of done runs remembered so repeated updates of a run are recorded once per metric, 0 disables deduplication.")
+	replayWindow := flag.Duration("replay-window", 0, "Window before startup in which completed TaskRuns and PipelineRuns are replayed through monitor metrics that didn't record them, 0 disables replays.")
+	replayDelay := flag.Duration("replay-delay", time.Minute, "Delay after the run caches synced before replaying, so monitors have registered their metrics.")
+	runFinalizers := flag.Bool("run-finalizers", true, "Add a finalizer to TaskRuns and PipelineRuns so their gauge series are cleaned even if deleted while the operator is down.")
+	defaultMetrics := flag.Bool("default-metrics", false, "Record a duration histogram and a completion counter by task or pipeline and namespace for runs not covered by any monitor.")
+	stepMetrics := flag.Bool("step-metrics", false, "Record a histogram of the steps executed per TaskRun by task and namespace.")
+	stepMetricsSkipped := flag.Bool("step-metrics-skipped", false, "Also record a histogram of the steps skipped per TaskRun after a failed step, requires --step-metrics.")
+	skippedTaskMetrics := flag.Bool("skipped-task-metrics", false, "Record a counter of the tasks skipped by PipelineRuns by pipeline, namespace and skipping reason.")
+	activePipelinesWindow := flag.Duration("active-pipelines-window", 0, "Window of the gauge of distinct pipelines with a run created within it by namespace, 0 disables it.")
+	podRestartMetrics := flag.Bool("pod-restart-metrics", false, "Record a counter of the restarts of the step containers of TaskRun pods by task, step and namespace, the pods of TaskRuns are cached.")
+	podMetrics := flag.Bool("pod-metrics", false, "Cache the pods of TaskRuns for the oomKilled and containerRestarts value presets of monitor counters and the durations ending on pods, like the schedulingLatency preset.")
+	gateKinds := flag.String("gate-kinds", "", "Comma separated kinds of the custom tasks implementing wait and approval gates, e.g. ApprovalTask, empty disables gate metrics.")
+	customRunMonitors := flag.Bool("custom-run-monitors", false, "Record CustomRuns in the metrics of TaskMonitors of kind CustomRun, requires the CustomRun API of Tekton.")
+	monitorStatusInterval := flag.Duration("monitor-status-interval", time.Minute, "Interval to refresh the metric stats in the status of monitors, 0 refreshes them only when monitors change.")
+	runEvents := flag.Bool("run-events", false, "Post a warning Event on TaskRuns and PipelineRuns failing to be recorded by a metric.")
+	monitorEventInterval := flag.Duration("monitor-event-interval", 5*time.Minute, "Minimum interval between two warning Events posted on a monitor for a metric failing to record runs, 0 disables them.")
+	staticTags := flag.String("static-tags", "", "Comma separated key=value tags added to every sample of monitor metrics, e.g. environment=prod,region=eu.")
+	staticTagsConfigMap := flag.String("static-tags-configmap", "", "ConfigMap in the system namespace whose data is merged over --static-tags, empty disables it.")
+	debugAddress := flag.String("debug-address", "", "Address serving the in-memory aggregation state as JSON on /debug/snapshot and the series per metric on /debug/cardinality, and evaluating posted monitors against a run on /debug/evaluate, empty disables it.")
+	postgresSinkDSN := flag.String("postgres-sink-dsn", "", "Connection string of a PostgreSQL database receiving one row per recorded sample, empty disables it.")
+	clickHouseSinkURL := flag.String("clickhouse-sink-url", "", "URL of the HTTP interface of a ClickHouse server receiving one row per recorded sample, empty disables it.")
+	bigQuerySinkSecret := flag.String("bigquery-sink-secret", "", "Secret in the system namespace with the project, dataset, table and optional credentials.json of a BigQuery table receiving one row per recorded sample, empty disables it.")
+	kafkaSinkBrokers := flag.String("kafka-sink-brokers", "", "Comma separated Kafka brokers receiving one message per recorded sample, empty disables it.")
+	kafkaSinkTopic := flag.String("kafka-sink-topic", "tekton-metric-samples", "Topic of the Kafka sink.")
+	kafkaSinkKeyTags := flag.String("kafka-sink-key-tags", "", "Comma separated tags whose values key the messages of the Kafka sink, the run uid when empty.")
+	cloudWatchSinkLogGroup := flag.String("cloudwatch-sink-log-group", "", "CloudWatch log group receiving one Embedded Metric Format event per recorded sample, empty disables it.")
+	cloudWatchSinkNamespace := flag.String("cloudwatch-sink-namespace", "Tekton", "Namespace of the metrics extracted by CloudWatch.")
+	cloudWatchSinkDimensions := flag.String("cloudwatch-sink-dimensions", "", "Comma separated tags reported as CloudWatch dimensions, renamed with tag=Dimension.")
+	azureMonitorSinkSecret := flag.String("azure-monitor-sink-secret", "", "Secret in the system namespace with the connectionString of an Application Insights resource receiving one custom metric per recorded sample, empty disables it.")
+	sinkTable := flag.String("sink-table", "metric_samples", "Table the sample sinks write to.")
+	sinkBufferSize := flag.Int("sink-buffer-size", 10000, "Samples waiting to be written to a sink before new ones are dropped.")
+	sinkFlushInterval := flag.Duration("sink-flush-interval", 10*time.Second, "Interval between two writes of the pending samples to a sink.")
+	metricsBackend := flag.String("metrics-backend", server.BackendPrometheus, "Backend of monitor metrics, prometheus, opencensus, otlp, statsd, stackdriver or none, overridden by the config-observability ConfigMap.")
+	prometheusHost := flag.String("prometheus-host", "0.0.0.0", "Host the Prometheus endpoint of monitor metrics listens on, overridden by the config-observability ConfigMap.")
+	prometheusPort := flag.Int("prometheus-port", 2112, "Port serving monitor metrics on /metrics, overridden by the config-observability ConfigMap.")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "host:port of the OpenTelemetry collector receiving monitor metrics with the otlp backend, the OTLP default when empty.")
+	otlpProtocol := flag.String("otlp-protocol", server.OTLPProtocolGRPC, "Protocol of the otlp backend, grpc or http.")
+	otlpInsecure := flag.Bool("otlp-insecure", false, "Push monitor metrics to the OpenTelemetry collector without TLS.")
+	statsDHost := flag.String("statsd-host", "localhost", "Host of the StatsD endpoint receiving monitor metrics with the statsd backend.")
+	statsDPort := flag.Int("statsd-port", 8125, "UDP port of the StatsD endpoint.")
+	statsDPrefix := flag.String("statsd-prefix", "", "Prefix of the names of the metrics pushed to StatsD, separated with a dot.")
+	statsDFlavor := flag.String("statsd-flavor", server.StatsDFlavorDogStatsD, "dogstatsd pushes tags as DogStatsD tags, statsd appends their values to metric names.")
+	stackdriverProject := flag.String("stackdriver-project", "", "Project receiving monitor metrics with the stackdriver backend, the project of the GKE metadata server when empty.")
+	stackdriverLocation := flag.String("stackdriver-location", "", "Location of the monitored resource of monitor metrics, the cluster location of the GKE metadata server when empty.")
+	stackdriverCluster := flag.String("stackdriver-cluster", "", "Cluster name of the monitored resource of monitor metrics, the cluster name of the GKE metadata server when empty.")
+	stackdriverResourceType := flag.String("stackdriver-resource-type", server.StackdriverResourceCluster, "Monitored resource of monitor metrics, k8s_cluster or generic_task with the namespace of the run.")
+	exemplars := flag.Bool("exemplars", false, "Attach the name, uid and trace of runs to histogram samples, served as exemplars in the OpenMetrics format by the Prometheus endpoint.")
+	disableHighAvailability := flag.Bool("disable-ha", false, "Disable leader election for single-replica installs, the replica records every run without waiting for a lease.")
+
+	// This parses flags.
+	cfg := injection.ParseAndGetRESTConfigOrDie()
+
+	if _, err := labels.Parse(*runLabelSelector); err != nil {
+		log.Fatalf("invalid run label selector %q: %v", *runLabelSelector, err)
+	}
+
+	excludedNamespaces := []string{}
+	for _, namespace := range strings.Split(*excludeNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace == "" {
+			continue
+		}
+		if _, err := path.Match(namespace, ""); err != nil {
+			log.Fatalf("invalid excluded namespace %q: %v", namespace, err)
+		}
+		excludedNamespaces = append(excludedNamespaces, namespace)
+	}
+
+	flagTags, err := parseStaticTags(*staticTags)
 	if err != nil {
-		panic("failed to start external prometheus exporter")
+		log.Fatalf("invalid static tags %q: %v", *staticTags, err)
+	}
+	// static keys must be known before the views of monitors are created
+	if err := recorder.SetStaticTags(flagTags); err != nil {
+		log.Fatalf("invalid static tags %q: %v", *staticTags, err)
 	}
-	fmt.Printf("Starting external prometheus exporter...\n")
-	go func() {
-		exporter.Start()
-	}()
 
+	fmt.Printf("Starting metric-operator...\n")
 	fmt.Printf("Starting meter...\n")
 	external := view.NewMeter()
 	external.Start()
-	fmt.Printf("Starting registering external exporter...\n")
-	external.RegisterExporter(exporter.GetExporter())
+	observability := server.DefaultObservabilityConfig()
+	observability.Backend = *metricsBackend
+	observability.PrometheusHost = *prometheusHost
+	observability.PrometheusPort = *prometheusPort
+	observability.OTLPEndpoint = *otlpEndpoint
+	observability.OTLPProtocol = *otlpProtocol
+	observability.OTLPInsecure = *otlpInsecure
+	observability.StatsDHost = *statsDHost
+	observability.StatsDPort = *statsDPort
+	observability.StatsDPrefix = *statsDPrefix
+	observability.StatsDFlavor = *statsDFlavor
+	observability.StackdriverProject = *stackdriverProject
+	observability.StackdriverLocation = *stackdriverLocation
+	observability.StackdriverCluster = *stackdriverCluster
+	observability.StackdriverResourceType = *stackdriverResourceType
+	observability.PrometheusExemplars = *exemplars
+	if err := observability.Validate(); err != nil {
+		log.Fatalf("invalid observability flags: %v", err)
+	}
+	exporter := server.NewObservedExporter(external, observability)
+	if err := exporter.Apply(observability); err != nil {
+		log.Fatalf("failed to start external exporter: %v", err)
+	}
+	if err := selfmetrics.Register(external); err != nil {
+		log.Fatalf("failed to register operator metrics: %v", err)
+	}
 
-	manager := metrics.NewManager(external)
+	retries := metrics.NewRetryQueue(external, metrics.RetryOptions{
+		MaxQueueSize:   *retryQueueSize,
+		InitialBackoff: *retryInitialBackoff,
+		MaxBackoff:     *retryMaxBackoff,
+		MaxAttempts:    *retryMaxAttempts,
+	})
+	manager := metrics.NewManager(external, retries)
+	manager.PurgeSeriesWith(exporter)
+	if *exemplars {
+		manager.EnableExemplars()
+	}
+	if *runEvents {
+		manager.EnableRunEvents()
+	}
+	if *monitorEventInterval > 0 {
+		manager.EnableMonitorEvents(*monitorEventInterval)
+	}
+	if *breakerThreshold > 0 {
+		manager.EnableCircuitBreaker(metrics.BreakerOptions{
+			Threshold:      *breakerThreshold,
+			InitialBackoff: *breakerInitialBackoff,
+			MaxBackoff:     *breakerMaxBackoff,
+		})
+	}
+	if *dedupCacheSize > 0 {
+		manager.EnableDeduplication(*dedupCacheSize)
+	}
+	if *replayWindow > 0 {
+		if *dedupCacheSize <= 0 {
+			log.Fatalf("--replay-window requires --dedup-cache-size, replays would record runs twice")
+		}
+		manager.EnableReplay(metrics.ReplayOptions{
+			Window: *replayWindow,
+			Delay:  *replayDelay,
+		})
+	}
+	if *recordBatchInterval > 0 {
+		manager.EnableBatchedRecording(metrics.BatchOptions{
+			Size:          *recordBatchSize,
+			FlushInterval: *recordBatchInterval,
+		})
+	}
+	if *defaultMetrics {
+		if err := manager.EnableDefaultMetrics(); err != nil {
+			log.Fatalf("failed to register default metrics: %v", err)
+		}
+	}
+	if *stepMetrics {
+		if err := manager.EnableStepMetrics(*stepMetricsSkipped); err != nil {
+			log.Fatalf("failed to register step metrics: %v", err)
+		}
+	}
+	if *skippedTaskMetrics {
+		if err := manager.EnableSkippedTaskMetrics(); err != nil {
+			log.Fatalf("failed to register skipped task metrics: %v", err)
+		}
+	}
+	if *podRestartMetrics {
+		if err := manager.EnablePodRestartMetrics(); err != nil {
+			log.Fatalf("failed to register pod restart metrics: %v", err)
+		}
+	}
+	if *activePipelinesWindow > 0 {
+		if err := manager.EnableActivePipelines(*activePipelinesWindow); err != nil {
+			log.Fatalf("failed to register active pipelines gauge: %v", err)
+		}
+	}
+	controllers := []injection.ControllerConstructor{
+		taskrun.NewController(manager, *runFinalizers, *podMetrics || *podRestartMetrics),
+		taskrunmonitor.NewController(manager, *monitorStatusInterval),
+		taskmonitor.NewController(manager, *monitorStatusInterval),
+		clustertaskmonitor.NewController(manager, *monitorStatusInterval),
+		pipelinerun.NewController(manager, *runFinalizers),
+		pipelinerunmonitor.NewController(manager, *monitorStatusInterval),
+		pipelinemonitor.NewController(manager, *monitorStatusInterval),
+		metricsexporter.NewController(exporter),
+	}
+	if *gateKinds != "" {
+		if err := manager.EnableGateMetrics(strings.Split(*gateKinds, ",")); err != nil {
+			log.Fatalf("failed to register gate metrics: %v", err)
+		}
+	}
+	if *gateKinds != "" || *customRunMonitors {
+		controllers = append(controllers, customrun.NewController(manager, *customRunMonitors))
+	}
 
 	ctx := signals.NewContext()
-	sharedmain.MainWithContext(ctx, "metrics-operator-controller",
-		taskrun.NewController(manager),
-		taskrunmonitor.NewController(manager),
-		taskmonitor.NewController(manager),
-		pipelinerun.NewController(manager),
-		pipelinerunmonitor.NewController(manager),
-		pipelinemonitor.NewController(manager),
-	)
+	if *disableHighAvailability {
+		ctx = sharedmain.WithHADisabled(ctx)
+	}
+	ctx = informers.WithTuning(ctx, &informers.Tuning{
+		ResyncPeriod:       *resyncPeriod,
+		ListPageSize:       *listPageSize,
+		WatchTimeout:       *watchTimeout,
+		LabelSelector:      *runLabelSelector,
+		ExcludedNamespaces: excludedNamespaces,
+	})
+	// the exporter of monitor metrics follows the config-observability
+	// ConfigMap, like the metrics of the controller itself
+	cmw := cminformer.NewInformedWatcher(kubernetes.NewForConfigOrDie(cfg), system.Namespace())
+	cmw.Watch(knativemetrics.ConfigMapName(), exporter.Watch(logging.FromContext(ctx)))
+	go exporter.Run(ctx)
+	if *staticTagsConfigMap != "" {
+		cmw.WatchWithDefault(corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: *staticTagsConfigMap}}, func(configMap *corev1.ConfigMap) {
+			merged := map[string]string{}
+			for key, value := range flagTags {
+				merged[key] = value
+			}
+			for key, value := range configMap.Data {
+				merged[key] = value
+			}
+			if err := recorder.SetStaticTags(merged); err != nil {
+				logging.FromContext(ctx).Errorw("invalid static tags, keeping the current ones", zap.Error(err))
+			}
+		})
+	}
+	if err := cmw.Start(ctx.Done()); err != nil {
+		log.Fatalf("failed to watch observability config: %v", err)
+	}
+	if *debugAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/snapshot", manager.SnapshotHandler())
+		mux.Handle("/debug/cardinality", manager.CardinalityHandler())
+		tekton := pipelineclient.NewForConfigOrDie(cfg).TektonV1beta1()
+		mux.Handle("/debug/evaluate", simulate.EvaluateHandler(func(ctx context.Context, resource, namespace, name string) (runtime.Object, error) {
+			switch resource {
+			case "taskrun":
+				return tekton.TaskRuns(namespace).Get(ctx, name, metav1.GetOptions{})
+			case "pipelinerun":
+				return tekton.PipelineRuns(namespace).Get(ctx, name, metav1.GetOptions{})
+			case "customrun":
+				return tekton.CustomRuns(namespace).Get(ctx, name, metav1.GetOptions{})
+			default:
+				return nil, fmt.Errorf("%w %q", simulate.ErrUnsupportedResource, resource)
+			}
+		}))
+		go func() {
+			if err := http.ListenAndServe(*debugAddress, mux); err != nil {
+				log.Fatalf("failed to serve debug endpoints: %v", err)
+			}
+		}()
+	}
+	sinkOptions := sink.BufferOptions{Size: *sinkBufferSize, FlushInterval: *sinkFlushInterval, Retry: retries.Add}
+	if *postgresSinkDSN != "" {
+		postgres, err := sink.NewSQL("postgres", *postgresSinkDSN, *sinkTable)
+		if err != nil {
+			log.Fatalf("failed to open postgres sink: %v", err)
+		}
+		buffered := sink.NewBuffered("postgres", postgres, sinkOptions, logging.FromContext(ctx))
+		manager.AddSink(buffered)
+		go buffered.Run(ctx)
+	}
+	if *clickHouseSinkURL != "" {
+		clickHouse, err := sink.NewClickHouse(*clickHouseSinkURL, *sinkTable)
+		if err != nil {
+			log.Fatalf("failed to open clickhouse sink: %v", err)
+		}
+		buffered := sink.NewBuffered("clickhouse", clickHouse, sinkOptions, logging.FromContext(ctx))
+		manager.AddSink(buffered)
+		go buffered.Run(ctx)
+	}
+	if *bigQuerySinkSecret != "" {
+		secret, err := kubernetes.NewForConfigOrDie(cfg).CoreV1().Secrets(system.Namespace()).Get(ctx, *bigQuerySinkSecret, metav1.GetOptions{})
+		if err != nil {
+			log.Fatalf("failed to read bigquery sink secret: %v", err)
+		}
+		bigQuery, err := sink.NewBigQuery(ctx, sink.BigQueryConfig{
+			Project:     string(secret.Data["project"]),
+			Dataset:     string(secret.Data["dataset"]),
+			Table:       string(secret.Data["table"]),
+			Credentials: secret.Data["credentials.json"],
+		})
+		if err != nil {
+			log.Fatalf("failed to open bigquery sink: %v", err)
+		}
+		buffered := sink.NewBuffered("bigquery", bigQuery, sinkOptions, logging.FromContext(ctx))
+		manager.AddSink(buffered)
+		go buffered.Run(ctx)
+	}
+	if *kafkaSinkBrokers != "" {
+		keyTags := []string{}
+		if *kafkaSinkKeyTags != "" {
+			keyTags = strings.Split(*kafkaSinkKeyTags, ",")
+		}
+		kafka := sink.NewKafka(strings.Split(*kafkaSinkBrokers, ","), *kafkaSinkTopic, keyTags)
+		buffered := sink.NewBuffered("kafka", kafka, sinkOptions, logging.FromContext(ctx))
+		manager.AddSink(buffered)
+		go buffered.Run(ctx)
+	}
+	if *cloudWatchSinkLogGroup != "" {
+		dimensions, err := parseDimensions(*cloudWatchSinkDimensions)
+		if err != nil {
+			log.Fatalf("invalid cloudwatch dimensions %q: %v", *cloudWatchSinkDimensions, err)
+		}
+		// one stream per replica, the pod name is the hostname
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Fatalf("failed to name cloudwatch log stream: %v", err)
+		}
+		cloudWatch, err := sink.NewCloudWatch(ctx, sink.CloudWatchConfig{
+			LogGroup:   *cloudWatchSinkLogGroup,
+			LogStream:  hostname,
+			Namespace:  *cloudWatchSinkNamespace,
+			Dimensions: dimensions,
+		})
+		if err != nil {
+			log.Fatalf("failed to open cloudwatch sink: %v", err)
+		}
+		buffered := sink.NewBuffered("cloudwatch", cloudWatch, sinkOptions, logging.FromContext(ctx))
+		manager.AddSink(buffered)
+		go buffered.Run(ctx)
+	}
+	if *azureMonitorSinkSecret != "" {
+		secret, err := kubernetes.NewForConfigOrDie(cfg).CoreV1().Secrets(system.Namespace()).Get(ctx, *azureMonitorSinkSecret, metav1.GetOptions{})
+		if err != nil {
+			log.Fatalf("failed to read azure monitor sink secret: %v", err)
+		}
+		azureMonitor, err := sink.NewAzureMonitor(string(secret.Data["connectionString"]))
+		if err != nil {
+			log.Fatalf("failed to open azure monitor sink: %v", err)
+		}
+		buffered := sink.NewBuffered("azure-monitor", azureMonitor, sinkOptions, logging.FromContext(ctx))
+		manager.AddSink(buffered)
+		go buffered.Run(ctx)
+	}
+	go retries.Run(ctx)
+	go manager.RunBatchLoop(ctx)
+	go manager.RunEvaluationLoop(ctx, *evaluationInterval)
+	go manager.RunRateLoop(ctx, *rateInterval)
+	go manager.RunSeriesLoop(ctx, *activeSeriesInterval)
+	go manager.RunOperatorLoop(ctx, *operatorMetricsInterval)
+	go manager.RunActivePipelinesLoop(ctx, time.Minute)
+
+	sharedmain.MainWithConfig(ctx, "metrics-operator-controller", cfg, controllers...)
+}
+
+// parseDimensions parses comma separated tags, optionally renamed with
+// tag=Dimension
+func parseDimensions(value string) (map[string]string, error) {
+	dimensions := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		tag, dimension, found := strings.Cut(entry, "=")
+		if !found {
+			dimension = tag
+		}
+		if tag == "" || dimension == "" {
+			return nil, fmt.Errorf("expected tag or tag=Dimension, got %q", entry)
+		}
+		dimensions[tag] = dimension
+	}
+	return dimensions, nil
+}
+
+// parseStaticTags parses comma separated key=value pairs
+func parseStaticTags(value string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, tagValue, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		tags[key] = tagValue
+	}
+	return tags, nil
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/lint"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/simulate"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+)
+
+const usage = `Usage: metrics-cli <command> [flags]
+
+Commands:
+  lint     validate monitor files offline
+  preview  print the samples a monitor would record for a run
+
+Installed as tkn-metrics in the PATH, the commands are available as
+tkn metrics <command>.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "lint":
+		os.Exit(lint.Run(os.Args[2:], os.Stdout))
+	case "preview":
+		os.Exit(runPreview(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runPreview(args []string) int {
+	flags := flag.NewFlagSet("preview", flag.ExitOnError)
+	monitorFile := flags.String("monitor", "", "File of the monitor, YAML or JSON.")
+	runFile := flags.String("run", "", "File of the TaskRun, PipelineRun or CustomRun, e.g. from kubectl get -o yaml.")
+	strict := flags.Bool("strict", false, "Fail when a metric doesn't match the run or skips it.")
+	flags.Parse(args)
+	if *monitorFile == "" || *runFile == "" {
+		fmt.Fprintln(os.Stderr, "preview requires --monitor and --run")
+		return 2
+	}
+
+	monitor, err := decodeFile(*monitorFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	run, err := decodeFile(*runFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// the monitor is checked as the admission webhook would before applying it
+	ctx := context.Background()
+	if defaultable, ok := monitor.(apis.Defaultable); ok {
+		defaultable.SetDefaults(ctx)
+	}
+	if validatable, ok := monitor.(apis.Validatable); ok {
+		if errs := validatable.Validate(ctx); errs != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *monitorFile, errs)
+			return 1
+		}
+	}
+	evaluations, err := simulate.Evaluate(ctx, monitor, run)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if printEvaluations(os.Stdout, evaluations, *strict) {
+		return 1
+	}
+	return 0
+}
+
+// printEvaluations prints the samples of the evaluations as a table, returns
+// true when an evaluation failed, or didn't match or skipped the run in strict
+// mode
+func printEvaluations(out io.Writer, evaluations []metrics.Evaluation, strict bool) bool {
+	failed := false
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MONITOR\tMETRIC\tSERIES\tTAGS\tVALUE")
+	for _, evaluation := range evaluations {
+		switch {
+		case evaluation.Error != "":
+			fmt.Fprintf(w, "%s\t%s\t(error: %s)\t\t\n", evaluation.Monitor, evaluation.Metric, evaluation.Error)
+			failed = true
+		case !evaluation.Matched:
+			fmt.Fprintf(w, "%s\t%s\t(not matched)\t\t\n", evaluation.Monitor, evaluation.Metric)
+			failed = failed || strict
+		case evaluation.Skipped != "":
+			fmt.Fprintf(w, "%s\t%s\t(skipped: %s)\t\t\n", evaluation.Monitor, evaluation.Metric, evaluation.Skipped)
+			failed = failed || strict
+		}
+		for _, sample := range evaluation.Samples {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", evaluation.Monitor, evaluation.Metric, sample.Name, formatTags(sample), strconv.FormatFloat(sample.Value, 'g', -1, 64))
+		}
+	}
+	w.Flush()
+	return failed
+}
+
+func decodeFile(file string) (runtime.Object, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	obj, err := simulate.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return obj, nil
+}
+
+func formatTags(sample metrics.EvaluatedSample) string {
+	tags := []string{}
+	for key, value := range sample.Tags {
+		tags = append(tags, key+"="+value)
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ",")
+}
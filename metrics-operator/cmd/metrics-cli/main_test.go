@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+)
+
+func TestPrintEvaluations(t *testing.T) {
+	evaluations := []metrics.Evaluation{{
+		Monitor: "task/build",
+		Metric:  "runs",
+		Matched: true,
+		Samples: []metrics.EvaluatedSample{{
+			Name:  "task_build_runs_total",
+			Tags:  map[string]string{"status": "success", "namespace": "dev"},
+			Value: 1,
+		}},
+	}, {
+		Monitor: "task/build",
+		Metric:  "duration",
+		Matched: true,
+		Skipped: "missing duration timestamp",
+	}, {
+		Monitor: "task/test",
+		Metric:  "runs",
+	}}
+
+	out := &bytes.Buffer{}
+	if printEvaluations(out, evaluations, false) {
+		t.Error("want no failure for runs not matched or skipped")
+	}
+	// columns are aligned with spaces, fields are compared
+	got := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		got = append(got, strings.Join(strings.Fields(line), " "))
+	}
+	want := []string{
+		"MONITOR METRIC SERIES TAGS VALUE",
+		"task/build runs task_build_runs_total namespace=dev,status=success 1",
+		"task/build duration (skipped: missing duration timestamp)",
+		"task/test runs (not matched)",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("output (-want, +got):\n%s", diff)
+	}
+
+	if !printEvaluations(&bytes.Buffer{}, evaluations, true) {
+		t.Error("want a failure for runs not matched or skipped in strict mode")
+	}
+	failed := []metrics.Evaluation{{Monitor: "task/build", Metric: "duration", Matched: true, Error: "error parsing duration"}}
+	if !printEvaluations(&bytes.Buffer{}, failed, false) {
+		t.Error("want a failure for an evaluation error")
+	}
+}
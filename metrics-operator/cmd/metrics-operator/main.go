@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/lint"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/simulate"
+)
+
+const usage = `Usage: metrics-operator <command> [flags] <paths>
+
+Commands:
+  lint         validate monitor files offline
+  simulate     replay past runs through monitors and print the resulting series
+  cardinality  report the active series per metric of a running operator
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "lint":
+		os.Exit(lint.Run(os.Args[2:], os.Stdout))
+	case "simulate":
+		os.Exit(runSimulate(os.Args[2:]))
+	case "cardinality":
+		os.Exit(runCardinality(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runSimulate(args []string) int {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "simulate requires at least one file or directory")
+		return 2
+	}
+
+	input := &simulate.Input{}
+	if err := input.Load(flags.Args()...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := simulate.Run(context.Background(), input, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func runCardinality(args []string) int {
+	flags := flag.NewFlagSet("cardinality", flag.ExitOnError)
+	address := flags.String("address", "http://localhost:8008", "URL of the debug address of the operator, e.g. port-forwarded.")
+	metric := flags.String("metric", "", "Only report this metric.")
+	top := flags.Int("top", 5, "Values listed per tag.")
+	flags.Parse(args)
+
+	query := url.Values{"top": {strconv.Itoa(*top)}}
+	if *metric != "" {
+		query.Set("metric", *metric)
+	}
+	response, err := http.Get(strings.TrimSuffix(*address, "/") + "/debug/cardinality?" + query.Encode())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "operator returned %s\n", response.Status)
+		return 1
+	}
+	reports := []metrics.CardinalityReport{}
+	if err := json.NewDecoder(response.Body).Decode(&reports); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tSERIES\tTAG\tVALUES\tTOP")
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s\t%d\t\t\t\n", report.Metric, report.Series)
+		for _, tag := range report.Tags {
+			values := []string{}
+			for _, value := range tag.Top {
+				values = append(values, fmt.Sprintf("%s (%d)", value.Value, value.Series))
+			}
+			fmt.Fprintf(w, "\t\t%s\t%d\t%s\n", tag.Key, tag.Values, strings.Join(values, ", "))
+		}
+	}
+	w.Flush()
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/pkg/signals"
+	"knative.dev/pkg/webhook"
+	"knative.dev/pkg/webhook/certificates"
+	"knative.dev/pkg/webhook/resourcesemantics"
+	"knative.dev/pkg/webhook/resourcesemantics/defaulting"
+	"knative.dev/pkg/webhook/resourcesemantics/validation"
+)
+
+// types are the monitors defaulted and validated by the webhook
+var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
+	monitoringv1alpha1.SchemeGroupVersion.WithKind("TaskMonitor"):        &monitoringv1alpha1.TaskMonitor{},
+	monitoringv1alpha1.SchemeGroupVersion.WithKind("ClusterTaskMonitor"): &monitoringv1alpha1.ClusterTaskMonitor{},
+	monitoringv1alpha1.SchemeGroupVersion.WithKind("TaskRunMonitor"):     &monitoringv1alpha1.TaskRunMonitor{},
+	monitoringv1alpha1.SchemeGroupVersion.WithKind("PipelineMonitor"):    &monitoringv1alpha1.PipelineMonitor{},
+	monitoringv1alpha1.SchemeGroupVersion.WithKind("PipelineRunMonitor"): &monitoringv1alpha1.PipelineRunMonitor{},
+}
+
+func newDefaultingAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	return defaulting.NewAdmissionController(ctx,
+		// name of the MutatingWebhookConfiguration
+		"defaulting.webhook.metrics.tekton.dev",
+		// path of the admission requests
+		"/defaulting",
+		types,
+		func(ctx context.Context) context.Context {
+			return ctx
+		},
+		// a misspelled field would otherwise be dropped silently
+		true,
+	)
+}
+
+func newValidationAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	return validation.NewAdmissionController(ctx,
+		// name of the ValidatingWebhookConfiguration
+		"validation.webhook.metrics.tekton.dev",
+		// path of the admission requests
+		"/resource-validation",
+		types,
+		func(ctx context.Context) context.Context {
+			return ctx
+		},
+		// a misspelled field would otherwise be dropped silently
+		true,
+	)
+}
+
+func main() {
+	serviceName := os.Getenv("WEBHOOK_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "webhook"
+	}
+	secretName := os.Getenv("WEBHOOK_SECRET_NAME")
+	if secretName == "" {
+		secretName = "webhook-certs"
+	}
+
+	ctx := webhook.WithOptions(signals.NewContext(), webhook.Options{
+		ServiceName: serviceName,
+		Port:        8443,
+		SecretName:  secretName,
+	})
+	sharedmain.MainWithContext(ctx, "metrics-operator-webhook",
+		certificates.NewController,
+		newDefaultingAdmissionController,
+		newValidationAdmissionController,
+	)
+}
@@ -0,0 +1,57 @@
+#!/usr/bin/env bash
+
+# Copyright 2023 The Tekton Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Builds and vets the metrics-operator module at every commit of a range, not
+# only at its tip, so a series of commits stays bisectable.
+#
+# Usage: hack/verify-commits.sh [base] [head]
+# The range defaults to origin/main..HEAD, commits not touching the module are
+# skipped.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+readonly BASE=${1:-origin/main}
+readonly HEAD=${2:-HEAD}
+readonly REPO_ROOT_DIR=$(git rev-parse --show-toplevel)
+readonly MODULE_DIR=metrics-operator
+readonly WORKTREE="$(mktemp -d)"
+
+cleanup() {
+  git -C "${REPO_ROOT_DIR}" worktree remove --force "${WORKTREE}" >/dev/null 2>&1 || rm -rf "${WORKTREE}"
+}
+
+trap "cleanup" EXIT SIGINT
+
+git -C "${REPO_ROOT_DIR}" worktree add --detach "${WORKTREE}" "${HEAD}" >/dev/null
+
+failed=()
+for commit in $(git -C "${REPO_ROOT_DIR}" rev-list --reverse "${BASE}..${HEAD}" -- "${MODULE_DIR}"); do
+  subject=$(git -C "${REPO_ROOT_DIR}" log -1 --format='%h %s' "${commit}")
+  echo "=== ${subject}"
+  git -C "${WORKTREE}" checkout --quiet --detach "${commit}"
+  if ! (cd "${WORKTREE}/${MODULE_DIR}" && go build ./... && go vet ./...); then
+    failed+=("${subject}")
+  fi
+done
+
+if (( ${#failed[@]} )); then
+  echo "go build or go vet failed at:"
+  printf '  %s\n' "${failed[@]}"
+  exit 1
+fi
+echo "every commit of ${BASE}..${HEAD} builds and vets"
@@ -0,0 +1,107 @@
+package v1alpha1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +genreconciler:krshapedlogic=false
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MetricsExporter exports the metrics of every monitor to a destination, in
+// addition to the backend of the config-observability ConfigMap
+// +k8s:openapi-gen=true
+type MetricsExporter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MetricsExporterSpec   `json:"spec"`
+	Status            MetricsExporterStatus `json:"status"`
+}
+
+const (
+	MetricsExporterTypePrometheus = "prometheus"
+	MetricsExporterTypeOTLP       = "otlp"
+	MetricsExporterTypeStatsD     = "statsd"
+)
+
+// MetricsExporterSpec ...
+type MetricsExporterSpec struct {
+	// Type is prometheus, otlp or statsd
+	Type string `json:"type"`
+	// Endpoint is the host:port the Prometheus endpoint listens on, or the
+	// host:port metrics are pushed to
+	Endpoint string `json:"endpoint"`
+	// Interval between two pushes, the reporting period of the operator when
+	// unset or shorter
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Protocol of otlp exporters, grpc or http
+	Protocol string `json:"protocol,omitempty"`
+	// Prefix of the metric names pushed by statsd exporters
+	Prefix string `json:"prefix,omitempty"`
+	// Flavor of statsd exporters, dogstatsd or statsd
+	Flavor string `json:"flavor,omitempty"`
+	// TLS of the connection of otlp exporters
+	TLS *MetricsExporterTLS `json:"tls,omitempty"`
+}
+
+// MetricsExporterTLS ...
+type MetricsExporterTLS struct {
+	// Insecure pushes without TLS
+	Insecure bool `json:"insecure,omitempty"`
+	// InsecureSkipVerify accepts any certificate of the endpoint
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// CABundle is the PEM encoded CA verifying the endpoint, the system
+	// roots when empty
+	CABundle string `json:"caBundle,omitempty"`
+	// ServerName overrides the host name verified in the certificate
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// MetricsExporterStatus ...
+type MetricsExporterStatus struct {
+	duckv1.Status `json:",inline"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MetricsExporterList ...
+type MetricsExporterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricsExporter `json:"items"`
+}
+
+// SetReadyCondition sets the Ready condition of an exporter from the error of
+// its last attachment, or of a monitor from the validation of its spec. The
+// transition time only changes with the status.
+func SetReadyCondition(status *duckv1.Status, reason string, err error) {
+	condition := apis.Condition{
+		Type:   apis.ConditionReady,
+		Status: corev1.ConditionTrue,
+	}
+	if err != nil {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = reason
+		condition.Message = err.Error()
+	}
+	conditions := duckv1.Conditions{}
+	for _, existing := range status.Conditions {
+		if existing.Type != apis.ConditionReady {
+			conditions = append(conditions, existing)
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+	if condition.LastTransitionTime.Inner.IsZero() {
+		condition.LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(time.Now())}
+	}
+	status.Conditions = append(conditions, condition)
+}
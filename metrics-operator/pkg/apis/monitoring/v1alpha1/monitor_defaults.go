@@ -0,0 +1,152 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+var (
+	_ apis.Defaultable = (*TaskMonitor)(nil)
+	_ apis.Defaultable = (*ClusterTaskMonitor)(nil)
+	_ apis.Defaultable = (*TaskRunMonitor)(nil)
+	_ apis.Defaultable = (*PipelineMonitor)(nil)
+	_ apis.Defaultable = (*PipelineRunMonitor)(nil)
+)
+
+// DefaultBuckets are the bucket boundaries of distributions without buckets
+// or bucket strategy, from 0.25 to 10000
+var DefaultBuckets = []float64{.25, .5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// DefaultRateWindow is the window of rate metrics without window
+const DefaultRateWindow = 5 * time.Minute
+
+// StepDuration is the duration of histograms recording a sample per step
+// without a duration, measured against each step state
+var StepDuration = MetricHistogramDuration{
+	From: ".terminated.startedAt",
+	To:   ".terminated.finishedAt",
+}
+
+func (m *TaskMonitor) SetDefaults(ctx context.Context) {
+	m.Spec.SetDefaults(ctx)
+}
+
+func (m *ClusterTaskMonitor) SetDefaults(ctx context.Context) {
+	m.Spec.SetDefaults(ctx)
+}
+
+func (m *TaskRunMonitor) SetDefaults(ctx context.Context) {
+	setMetricDefaults(ctx, m.Spec.Metrics)
+}
+
+func (m *PipelineMonitor) SetDefaults(ctx context.Context) {
+	setMetricDefaults(ctx, m.Spec.Metrics)
+}
+
+func (m *PipelineRunMonitor) SetDefaults(ctx context.Context) {
+	setMetricDefaults(ctx, m.Spec.Metrics)
+}
+
+// SetDefaults monitors TaskRuns unless another kind is set
+func (s *TaskMonitorSpec) SetDefaults(ctx context.Context) {
+	if s.Kind == "" {
+		s.Kind = TaskMonitorKindTaskRun
+	}
+	setMetricDefaults(ctx, s.Metrics)
+}
+
+func setMetricDefaults(ctx context.Context, metrics []Metric) {
+	for i := range metrics {
+		metrics[i].SetDefaults(ctx)
+	}
+}
+
+// SetDefaults makes the metric canonical: the name is normalized, the
+// duration preset of histograms and slos is replaced with its duration,
+// executionTime by default or the step duration when histograms record steps,
+// distributions get the default buckets, converted to the unit of durations,
+// and rates the default window. Metrics stored before the defaulting webhook
+// are defaulted by the reconcilers, so the recorders don't need fallbacks.
+func (m *Metric) SetDefaults(context.Context) {
+	m.Name = NormalizeMetricName(m.Name)
+	if (m.Type == "histogram" || m.Type == "slo") && m.Duration == nil && m.Value == nil {
+		switch {
+		case m.DurationPreset != "":
+			// an unknown preset is kept, validation reports it
+			if duration, err := m.DurationPreset.Duration(); err == nil {
+				m.Duration = duration
+				m.DurationPreset = ""
+			}
+		case m.Type == "histogram" && (m.Granularity == GranularityStep || hasStepDimension(m.By)):
+			duration := StepDuration
+			m.Duration = &duration
+		default:
+			m.Duration, _ = DurationPresetExecutionTime.Duration()
+		}
+	}
+	if len(m.Buckets) == 0 && m.BucketStrategy == nil && m.recordsDistribution() {
+		m.Buckets = append([]float64{}, DefaultBuckets...)
+		// the default boundaries are seconds, durations are recorded in the
+		// unit of the metric
+		if m.Value == nil {
+			for i := range m.Buckets {
+				m.Buckets[i] = m.Unit.FromSeconds(m.Buckets[i])
+			}
+		}
+	}
+	if m.Type == "rate" && m.Window == nil {
+		m.Window = &metav1.Duration{Duration: DefaultRateWindow}
+	}
+}
+
+// recordsDistribution returns true when the metric is exported as a
+// distribution with bucket boundaries
+func (m *Metric) recordsDistribution() bool {
+	switch m.Aggregation {
+	case AggregationDistribution:
+		return true
+	case "":
+		return m.Type == "histogram" || m.Type == "durationBreakdown"
+	default:
+		return false
+	}
+}
+
+func hasStepDimension(by []ByStatement) bool {
+	for _, statement := range by {
+		if statement.Step != nil && *statement.Step {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeMetricName returns the name in lower snake case, e.g. queueTime
+// and queue-time become queue_time. Characters Prometheus doesn't accept in
+// names are replaced with underscores.
+func NormalizeMetricName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case r < unicode.MaxASCII && unicode.IsUpper(r):
+			// a new word starts after a lower case letter or a digit, or at
+			// the last upper case letter of an acronym, e.g. HTTPRequests
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case r < unicode.MaxASCII && (unicode.IsLower(r) || unicode.IsDigit(r) || r == '_'):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizeMetricName(t *testing.T) {
+	for name, want := range map[string]string{
+		"duration":      "duration",
+		"queueTime":     "queue_time",
+		"queue-time":    "queue_time",
+		"HTTPRequests":  "http_requests",
+		"build.retries": "build_retries",
+		"step2Duration": "step2_duration",
+	} {
+		if got := NormalizeMetricName(name); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestMetricSetDefaults(t *testing.T) {
+	enabled := true
+	for name, tc := range map[string]struct {
+		metric Metric
+		want   Metric
+	}{
+		"histogram without duration": {
+			metric: Metric{Type: "histogram", Name: "duration"},
+			want: Metric{Type: "histogram", Name: "duration", Buckets: DefaultBuckets,
+				Duration: &MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"}},
+		},
+		"duration preset": {
+			metric: Metric{Type: "histogram", Name: "queueTime", DurationPreset: DurationPresetQueueTime},
+			want: Metric{Type: "histogram", Name: "queue_time", Buckets: DefaultBuckets,
+				Duration: &MetricHistogramDuration{From: ".metadata.creationTimestamp", To: ".status.startTime"}},
+		},
+		"step dimension": {
+			metric: Metric{Type: "histogram", Name: "steps", By: []ByStatement{{MetricDimensionRef: MetricDimensionRef{Step: &enabled}}}},
+			want: Metric{Type: "histogram", Name: "steps", By: []ByStatement{{MetricDimensionRef: MetricDimensionRef{Step: &enabled}}},
+				Buckets: DefaultBuckets, Duration: &StepDuration},
+		},
+		"bucket strategy": {
+			metric: Metric{Type: "histogram", Name: "values", Value: &MetricValue{Path: ".metadata.annotations.size"}, BucketStrategy: &MetricBucketStrategy{Type: BucketStrategyLinear, Width: 1, Count: 3}},
+			want:   Metric{Type: "histogram", Name: "values", Value: &MetricValue{Path: ".metadata.annotations.size"}, BucketStrategy: &MetricBucketStrategy{Type: BucketStrategyLinear, Width: 1, Count: 3}},
+		},
+		"counter": {
+			metric: Metric{Type: "counter", Name: "runs"},
+			want:   Metric{Type: "counter", Name: "runs"},
+		},
+	} {
+		tc.metric.SetDefaults(context.Background())
+		if diff := cmp.Diff(tc.want, tc.metric); diff != "" {
+			t.Errorf("%s: unexpected defaults (-want +got):\n%s", name, diff)
+		}
+	}
+}
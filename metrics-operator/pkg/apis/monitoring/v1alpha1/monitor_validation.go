@@ -0,0 +1,233 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/jsonpath"
+	"knative.dev/pkg/apis"
+)
+
+var (
+	_ apis.Validatable = (*TaskMonitor)(nil)
+	_ apis.Validatable = (*ClusterTaskMonitor)(nil)
+	_ apis.Validatable = (*TaskRunMonitor)(nil)
+	_ apis.Validatable = (*PipelineMonitor)(nil)
+	_ apis.Validatable = (*PipelineRunMonitor)(nil)
+)
+
+// labelNamePattern matches the label names accepted by Prometheus, names
+// starting with __ are reserved
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Validate rejects TaskMonitors whose metrics would fail at record time
+func (m *TaskMonitor) Validate(ctx context.Context) *apis.FieldError {
+	errs := apis.ValidateObjectMetadata(m.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(m.Spec.Validate(ctx).ViaField("spec"))
+}
+
+// Validate rejects ClusterTaskMonitors whose metrics would fail at record
+// time
+func (m *ClusterTaskMonitor) Validate(ctx context.Context) *apis.FieldError {
+	errs := apis.ValidateObjectMetadata(m.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(m.Spec.Validate(ctx).ViaField("spec"))
+}
+
+// Validate rejects TaskRunMonitors whose metrics would fail at record time
+func (m *TaskRunMonitor) Validate(ctx context.Context) *apis.FieldError {
+	errs := apis.ValidateObjectMetadata(m.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(validateMetrics(ctx, m.Spec.Metrics, m.Spec.Reasons, m.Spec.CommonTags, m.Spec.MetricPrefix).ViaField("spec"))
+}
+
+// Validate rejects PipelineMonitors whose metrics would fail at record time
+func (m *PipelineMonitor) Validate(ctx context.Context) *apis.FieldError {
+	errs := apis.ValidateObjectMetadata(m.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(validateMetrics(ctx, m.Spec.Metrics, m.Spec.Reasons, m.Spec.CommonTags, m.Spec.MetricPrefix).ViaField("spec"))
+}
+
+// Validate rejects PipelineRunMonitors whose metrics would fail at record
+// time
+func (m *PipelineRunMonitor) Validate(ctx context.Context) *apis.FieldError {
+	errs := apis.ValidateObjectMetadata(m.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(validateMetrics(ctx, m.Spec.Metrics, m.Spec.Reasons, m.Spec.CommonTags, m.Spec.MetricPrefix).ViaField("spec"))
+}
+
+// Validate checks the metrics of the spec, shared by TaskMonitors and
+// ClusterTaskMonitors
+func (s *TaskMonitorSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	switch s.Kind {
+	case "", TaskMonitorKindTaskRun, TaskMonitorKindCustomRun:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(s.Kind, "kind"))
+	}
+	return errs.Also(validateMetrics(ctx, s.Metrics, s.Reasons, s.CommonTags, s.MetricPrefix))
+}
+
+// validateMetrics checks the metrics of a monitor, its reasons, common tags
+// and metric prefix, metric names must be unique within the monitor. Names
+// exported by another monitor are rejected when the metric is registered.
+func validateMetrics(ctx context.Context, metrics []Metric, reasons []ReasonNormalization, commonTags map[string]string, metricPrefix string) *apis.FieldError {
+	errs := validateReasons(reasons)
+	if metricPrefix != "" {
+		if err := ValidateLabelName(metricPrefix); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(metricPrefix, "metricPrefix", err.Error()))
+		}
+	}
+	for _, key := range sets.List(sets.KeySet(commonTags)) {
+		if err := ValidateLabelName(key); err != nil {
+			errs = errs.Also(apis.ErrInvalidKeyName(key, "commonTags", err.Error()))
+		}
+	}
+	names := sets.New[string]()
+	for i := range metrics {
+		metric := &metrics[i]
+		if names.Has(metric.Name) {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("duplicate metric name %q", metric.Name), "name").ViaFieldIndex("metrics", i))
+		}
+		names.Insert(metric.Name)
+		errs = errs.Also(metric.Validate(ctx).ViaFieldIndex("metrics", i))
+	}
+	return errs
+}
+
+// Validate checks the names, JSONPath expressions and buckets of the metric,
+// the semantic checks of the lint package aren't repeated here
+func (m *Metric) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if !labelNamePattern.MatchString(m.Name) {
+		errs = errs.Also(apis.ErrInvalidValue(m.Name, "name", "must match "+labelNamePattern.String()))
+	}
+	for _, key := range sets.List(sets.KeySet(m.Tags)) {
+		if err := ValidateLabelName(key); err != nil {
+			errs = errs.Also(apis.ErrInvalidKeyName(key, "tags", err.Error()))
+		}
+	}
+	// label and param keys are sanitized by the exporters, only the tag of
+	// annotation documents is chosen freely
+	for i, by := range m.By {
+		if by.AnnotationJSON == nil {
+			continue
+		}
+		if by.AnnotationJSON.Tag != "" {
+			if err := ValidateLabelName(by.AnnotationJSON.Tag); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(by.AnnotationJSON.Tag, "annotationJSON.tag", err.Error()).ViaFieldIndex("by", i))
+			}
+		}
+		errs = errs.Also(validateJSONPath(by.AnnotationJSON.Path, "annotationJSON.path").ViaFieldIndex("by", i))
+	}
+	errs = errs.Also(validateReasons(m.Reasons))
+	if m.Duration != nil {
+		if len(m.Duration.Segments) == 0 {
+			errs = errs.Also(validateJSONPath(m.Duration.From, "duration.from"))
+			errs = errs.Also(validateJSONPath(m.Duration.To, "duration.to"))
+		}
+		for i, segment := range m.Duration.Segments {
+			errs = errs.Also(validateJSONPath(segment.From, "from").ViaFieldIndex("duration.segments", i))
+			errs = errs.Also(validateJSONPath(segment.To, "to").ViaFieldIndex("duration.segments", i))
+		}
+		if m.Duration.ToPod && len(m.Duration.Segments) > 0 {
+			errs = errs.Also(apis.ErrGeneric("toPod isn't supported by segments", "duration.toPod"))
+		}
+	}
+	if m.Value != nil {
+		if m.Value.Path != "" {
+			errs = errs.Also(validateJSONPath(m.Value.Path, "value.path"))
+		}
+		if m.Value.AnnotationJSON != nil {
+			errs = errs.Also(validateJSONPath(m.Value.AnnotationJSON.Path, "value.annotationJSON.path"))
+		}
+	}
+	if m.BucketStrategy != nil {
+		if len(m.Buckets) > 0 {
+			errs = errs.Also(apis.ErrMultipleOneOf("buckets", "bucketStrategy"))
+		}
+		if _, err := m.BucketStrategy.Buckets(); err != nil {
+			errs = errs.Also(apis.ErrGeneric(err.Error(), "bucketStrategy"))
+		}
+	}
+	if err := ValidateBuckets(m.Buckets); err != nil {
+		errs = errs.Also(apis.ErrInvalidValue(m.Buckets, "buckets", err.Error()))
+	}
+	if m.Unit != "" {
+		switch err := m.Unit.Validate(); {
+		case err != nil:
+			errs = errs.Also(apis.ErrInvalidValue(m.Unit, "unit", err.Error()))
+		case m.Type != "histogram" && m.Type != "durationBreakdown" && m.Type != "lastValue":
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("unit isn't supported by %s metrics", m.Type), "unit"))
+		case m.Value == nil && !m.Unit.IsTime():
+			errs = errs.Also(apis.ErrInvalidValue(m.Unit, "unit", "durations are measured in seconds, milliseconds or minutes"))
+		}
+	}
+	if m.Window != nil {
+		switch {
+		case m.Type != "rate":
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("window isn't supported by %s metrics", m.Type), "window"))
+		case m.Window.Duration <= 0:
+			errs = errs.Also(apis.ErrInvalidValue(m.Window.Duration.String(), "window", "must be positive"))
+		}
+	}
+	switch {
+	case m.Type == "slo" && m.Threshold == nil:
+		errs = errs.Also(apis.ErrMissingField("threshold"))
+	case m.Threshold == nil:
+	case m.Type != "slo":
+		errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("threshold isn't supported by %s metrics", m.Type), "threshold"))
+	case m.Threshold.Duration <= 0:
+		errs = errs.Also(apis.ErrInvalidValue(m.Threshold.Duration.String(), "threshold", "must be positive"))
+	}
+	return errs
+}
+
+// ValidateLabelName returns an error when the key isn't a valid Prometheus
+// label name
+func ValidateLabelName(key string) error {
+	if !labelNamePattern.MatchString(key) {
+		return fmt.Errorf("must match %s", labelNamePattern)
+	}
+	if strings.HasPrefix(key, "__") {
+		return fmt.Errorf("names starting with __ are reserved")
+	}
+	return nil
+}
+
+// ValidateBuckets returns an error when the bucket boundaries aren't
+// strictly increasing, or are more than MaxBucketCount
+func ValidateBuckets(buckets []float64) error {
+	if len(buckets) > MaxBucketCount {
+		return fmt.Errorf("at most %d boundaries are supported, got %d", MaxBucketCount, len(buckets))
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return fmt.Errorf("boundaries must be strictly increasing, %v follows %v", buckets[i], buckets[i-1])
+		}
+	}
+	return nil
+}
+
+// validateReasons rejects the patterns of reason normalizations that aren't
+// valid regular expressions, they would never match
+func validateReasons(reasons []ReasonNormalization) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, reason := range reasons {
+		for j, pattern := range reason.Patterns {
+			if _, err := compilePattern(pattern); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(pattern, apis.CurrentField, err.Error()).ViaFieldIndex("patterns", j).ViaFieldIndex("reasons", i))
+			}
+		}
+	}
+	return errs
+}
+
+func validateJSONPath(expression, field string) *apis.FieldError {
+	if expression == "" {
+		return apis.ErrMissingField(field)
+	}
+	if err := jsonpath.New(field).Parse(fmt.Sprintf("{%s}", expression)); err != nil {
+		return apis.ErrInvalidValue(expression, field, err.Error())
+	}
+	return nil
+}
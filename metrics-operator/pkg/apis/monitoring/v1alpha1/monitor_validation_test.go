@@ -0,0 +1,127 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTaskMonitorValidate(t *testing.T) {
+	duration := &MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"}
+	tooManyBuckets := make([]float64, MaxBucketCount+1)
+	for i := range tooManyBuckets {
+		tooManyBuckets[i] = float64(i + 1)
+	}
+	monitor := func(metrics ...Metric) *TaskMonitor {
+		return &TaskMonitor{
+			ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "dev"},
+			Spec:       TaskMonitorSpec{TaskName: "build", Metrics: metrics},
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		monitor *TaskMonitor
+		want    string
+	}{
+		"valid": {
+			monitor: monitor(
+				Metric{Type: "histogram", Name: "duration", Duration: duration, Buckets: []float64{1, 10, 100}},
+				Metric{Type: "counter", Name: "runs", Tags: map[string]string{"team": "ci"}},
+			),
+		},
+		"invalid jsonpath": {
+			monitor: monitor(Metric{Type: "histogram", Name: "duration", Duration: &MetricHistogramDuration{From: ".status.startTime[", To: ".status.completionTime"}}),
+			want:    "spec.metrics[0].duration.from",
+		},
+		"duplicate name": {
+			monitor: monitor(Metric{Type: "counter", Name: "runs"}, Metric{Type: "gauge", Name: "runs"}),
+			want:    "spec.metrics[1].name",
+		},
+		"non-monotonic buckets": {
+			monitor: monitor(Metric{Type: "histogram", Name: "duration", Duration: duration, Buckets: []float64{1, 10, 5}}),
+			want:    "spec.metrics[0].buckets",
+		},
+		"bucket strategy count above maximum": {
+			monitor: monitor(Metric{Type: "histogram", Name: "duration", Duration: duration, BucketStrategy: &MetricBucketStrategy{Type: BucketStrategyExponential, Start: 1, Factor: 2, Count: 1000}}),
+			want:    "spec.metrics[0].bucketStrategy",
+		},
+		"invalid bucket strategy type": {
+			monitor: monitor(Metric{Type: "histogram", Name: "duration", Duration: duration, BucketStrategy: &MetricBucketStrategy{Type: "quadratic", Start: 1, Count: 10}}),
+			want:    "spec.metrics[0].bucketStrategy",
+		},
+		"too many buckets": {
+			monitor: monitor(Metric{Type: "histogram", Name: "duration", Duration: duration, Buckets: tooManyBuckets}),
+			want:    "spec.metrics[0].buckets",
+		},
+		"invalid tag key": {
+			monitor: monitor(Metric{Type: "counter", Name: "runs", Tags: map[string]string{"team-name": "ci"}}),
+			want:    "spec.metrics[0].tags",
+		},
+		"reserved common tag key": {
+			monitor: &TaskMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "dev"},
+				Spec: TaskMonitorSpec{
+					TaskName:   "build",
+					CommonTags: map[string]string{"__name__": "runs"},
+					Metrics:    []Metric{{Type: "counter", Name: "runs"}},
+				},
+			},
+			want: "spec.commonTags",
+		},
+		"duration in bytes": {
+			monitor: monitor(Metric{Type: "histogram", Name: "duration", Duration: duration, Unit: UnitBytes}),
+			want:    "spec.metrics[0].unit",
+		},
+		"unknown unit": {
+			monitor: monitor(Metric{Type: "lastValue", Name: "size", Value: &MetricValue{Result: "size"}, Unit: "kilobytes"}),
+			want:    "spec.metrics[0].unit",
+		},
+		"window of a counter": {
+			monitor: monitor(Metric{Type: "counter", Name: "runs", Window: &metav1.Duration{Duration: time.Minute}}),
+			want:    "spec.metrics[0].window",
+		},
+		"slo without threshold": {
+			monitor: monitor(Metric{Type: "slo", Name: "latency", Duration: duration}),
+			want:    "spec.metrics[0].threshold",
+		},
+		"invalid metric prefix": {
+			monitor: &TaskMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "dev"},
+				Spec: TaskMonitorSpec{
+					TaskName:     "build",
+					MetricPrefix: "mycompany-ci",
+					Metrics:      []Metric{{Type: "counter", Name: "runs"}},
+				},
+			},
+			want: "spec.metricPrefix",
+		},
+		"invalid reason pattern": {
+			monitor: &TaskMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "dev"},
+				Spec: TaskMonitorSpec{
+					TaskName: "build",
+					Reasons:  []ReasonNormalization{{Value: "oom", Patterns: []string{"(?i)oom", "OOM("}}},
+					Metrics:  []Metric{{Type: "counter", Name: "runs"}},
+				},
+			},
+			want: "spec.reasons[0].patterns[1]",
+		},
+		"invalid metric reason pattern": {
+			monitor: monitor(Metric{Type: "counter", Name: "runs", Reasons: []ReasonNormalization{{Value: "timeout", Patterns: []string{"*Timeout"}}}}),
+			want:    "spec.metrics[0].reasons[0].patterns[0]",
+		},
+	} {
+		err := tc.monitor.Validate(context.Background())
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", name, err)
+		case tc.want != "" && err == nil:
+			t.Errorf("%s: want an error on %s", name, tc.want)
+		case tc.want != "" && !strings.Contains(err.Error(), tc.want):
+			t.Errorf("%s: want an error on %s, got %v", name, tc.want, err)
+		}
+	}
+}
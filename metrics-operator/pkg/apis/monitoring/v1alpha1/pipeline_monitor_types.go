@@ -22,11 +22,30 @@ type PipelineMonitor struct {
 type PipelineMonitorSpec struct {
 	PipelineName string   `json:"pipelineName"`
 	Metrics      []Metric `json:"metrics"`
+	// Reasons normalizes the values of reason dimensions of every metric
+	Reasons []ReasonNormalization `json:"reasons,omitempty"`
+	// CommonTags are constant tags added to every metric of the monitor
+	CommonTags map[string]string `json:"commonTags,omitempty"`
+	// MetricPrefix replaces the resource and monitor name starting the names
+	// of the exported metrics, e.g. mycompany_ci exports the duration metric
+	// as mycompany_ci_duration_seconds
+	MetricPrefix string `json:"metricPrefix,omitempty"`
+	// Matches are CEL expressions evaluated against the PipelineRun, exposed
+	// as pipelineRun, only runs matching every expression are recorded
+	Matches []string `json:"matches,omitempty"`
+	// Selector restricts the monitor to the runs of the pipeline carrying the
+	// labels, e.g. the runs of a team on a shared cluster
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// MarkRecorded writes the metrics that recorded a done run in its
+	// metrics.tekton.dev/recorded-by annotation, see TaskMonitorSpec
+	MarkRecorded bool `json:"markRecorded,omitempty"`
 }
 
 // PipelineMonitorStatus
 type PipelineMonitorStatus struct {
 	duckv1.Status `json:",inline"`
+	// Metrics reports the samples recorded by each metric of the spec
+	Metrics []MetricStatus `json:"metrics,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,165 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Presets of metrics, expanded by ExpandPreset
+const (
+	// PresetCompletions counts done runs by normalized status
+	PresetCompletions = "completions"
+	// PresetRunning gauges the runs in flight, a run leaves the gauge once
+	// done or deleted
+	PresetRunning = "running"
+	// PresetSchedulingLatency is a histogram of the time from the creation
+	// of TaskRuns to the scheduling of their pod, requires pod metrics
+	PresetSchedulingLatency = "schedulingLatency"
+)
+
+// Presets lists the supported presets
+var Presets = []string{PresetCompletions, PresetRunning, PresetSchedulingLatency}
+
+// ExpandPreset returns the metric the preset of the given metric expands
+// into, fields set on the metric are kept. Metrics without or with an unknown
+// preset are returned as is.
+func ExpandPreset(metric Metric) Metric {
+	switch metric.Preset {
+	case PresetCompletions:
+		enabled := true
+		metric.Type = "counter"
+		if metric.Name == "" {
+			metric.Name = PresetCompletions
+		}
+		by := []ByStatement{{MetricDimensionRef: MetricDimensionRef{Status: &enabled}}}
+		for _, statement := range metric.By {
+			if key, err := statement.Key(); err == nil && key == "status" {
+				continue
+			}
+			by = append(by, statement)
+		}
+		metric.By = by
+	case PresetRunning:
+		enabled := true
+		metric.Type = "gauge"
+		if metric.Name == "" {
+			metric.Name = PresetRunning
+		}
+		metric.Match = &MetricGaugeMatch{
+			Key:      MetricDimensionRef{Status: &enabled},
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{"running"},
+		}
+	case PresetSchedulingLatency:
+		metric.Type = "histogram"
+		if metric.Name == "" {
+			metric.Name = PresetSchedulingLatency
+		}
+		if metric.Duration == nil && metric.Value == nil {
+			metric.DurationPreset = DurationPresetSchedulingLatency
+		}
+	}
+	switch metric.ValuePreset {
+	case ValuePresetRetries, ValuePresetOOMKilled, ValuePresetContainerRestarts:
+		metric.Type = "counter"
+		if metric.Name == "" {
+			metric.Name = string(metric.ValuePreset)
+		}
+		if metric.Aggregation == "" {
+			metric.Aggregation = AggregationSum
+		}
+	}
+	if metric.ValuePreset == ValuePresetRetries {
+		enabled := true
+		task := "tekton.dev/task"
+		keys := map[string]bool{}
+		for _, statement := range metric.By {
+			if key, err := statement.Key(); err == nil {
+				keys[key] = true
+			}
+		}
+		by := append([]ByStatement{}, metric.By...)
+		if !keys[task] {
+			by = append(by, ByStatement{MetricDimensionRef: MetricDimensionRef{Label: &task}})
+		}
+		if !keys["namespace"] {
+			by = append(by, ByStatement{MetricDimensionRef: MetricDimensionRef{Namespace: &enabled}})
+		}
+		metric.By = by
+	}
+	return metric
+}
+
+type ValuePreset string
+
+// Presets of the number recorded by counters, expanded by ExpandPreset
+const (
+	// ValuePresetRetries adds the retries of each done TaskRun, the length
+	// of .status.retriesStatus, by task and namespace
+	ValuePresetRetries ValuePreset = "retries"
+	// ValuePresetOOMKilled adds the containers of the pod of each done
+	// TaskRun terminated as OOMKilled, requires pod metrics
+	ValuePresetOOMKilled ValuePreset = "oomKilled"
+	// ValuePresetContainerRestarts adds the restarts of the containers of
+	// the pod of each done TaskRun, requires pod metrics
+	ValuePresetContainerRestarts ValuePreset = "containerRestarts"
+)
+
+type DurationPreset string
+
+// Presets of histogram durations, expanded by DurationPreset.Duration
+const (
+	// DurationPresetQueueTime measures from the creation of the run to its
+	// start
+	DurationPresetQueueTime DurationPreset = "queueTime"
+	// DurationPresetExecutionTime measures from the start of the run to its
+	// completion
+	DurationPresetExecutionTime DurationPreset = "executionTime"
+	// DurationPresetSchedulingLatency measures from the creation of the
+	// TaskRun to the scheduling of its pod, stalls on cluster capacity
+	// happen before the run starts and aren't in the queue time
+	DurationPresetSchedulingLatency DurationPreset = "schedulingLatency"
+)
+
+// Duration returns the from/to pair of the preset
+func (p DurationPreset) Duration() (*MetricHistogramDuration, error) {
+	switch p {
+	case DurationPresetQueueTime:
+		return &MetricHistogramDuration{From: ".metadata.creationTimestamp", To: ".status.startTime"}, nil
+	case DurationPresetExecutionTime:
+		return &MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"}, nil
+	case DurationPresetSchedulingLatency:
+		return &MetricHistogramDuration{From: ".metadata.creationTimestamp", To: `.status.conditions[?(@.type=="PodScheduled")].lastTransitionTime`, ToPod: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown duration preset %q", p)
+	}
+}
+
+// ExpandGranularity returns the metric with the dimensions of its
+// granularity. Step metrics are tagged by step and image, step counters by
+// exit code too, dimensions already set are kept.
+func ExpandGranularity(metric Metric) Metric {
+	if metric.Granularity != GranularityStep {
+		return metric
+	}
+	enabled := true
+	dimensions := []MetricDimensionRef{{Step: &enabled}, {Image: &enabled}}
+	if metric.Type == "counter" {
+		dimensions = append(dimensions, MetricDimensionRef{ExitCode: &enabled})
+	}
+	keys := map[string]bool{}
+	for _, statement := range metric.By {
+		if key, err := statement.Key(); err == nil {
+			keys[key] = true
+		}
+	}
+	by := append([]ByStatement{}, metric.By...)
+	for _, dimension := range dimensions {
+		if key, _ := dimension.Key(); !keys[key] {
+			by = append(by, ByStatement{MetricDimensionRef: dimension})
+		}
+	}
+	metric.By = by
+	return metric
+}
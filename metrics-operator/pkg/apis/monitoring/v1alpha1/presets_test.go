@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
+)
+
+func TestExpandPreset(t *testing.T) {
+	status := ByStatement{MetricDimensionRef: MetricDimensionRef{Status: ptr.Bool(true)}}
+	team := ByStatement{MetricDimensionRef: MetricDimensionRef{Label: ptr.String("team")}}
+	tests := []struct {
+		name   string
+		metric Metric
+		want   Metric
+	}{{
+		name:   "no preset",
+		metric: Metric{Type: "histogram", Name: "duration"},
+		want:   Metric{Type: "histogram", Name: "duration"},
+	}, {
+		name:   "completions",
+		metric: Metric{Preset: PresetCompletions},
+		want:   Metric{Preset: PresetCompletions, Type: "counter", Name: "completions", By: []ByStatement{status}},
+	}, {
+		name:   "completions keep the name and the dimensions",
+		metric: Metric{Preset: PresetCompletions, Name: "done", By: []ByStatement{team, status}},
+		want:   Metric{Preset: PresetCompletions, Type: "counter", Name: "done", By: []ByStatement{status, team}},
+	}, {
+		name:   "unknown preset",
+		metric: Metric{Preset: "unknown", Name: "runs"},
+		want:   Metric{Preset: "unknown", Name: "runs"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, ExpandPreset(tt.metric)); diff != "" {
+				t.Errorf("metric (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompletionStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *apis.Condition
+		want      string
+	}{
+		{name: "no condition", want: "running"},
+		{name: "running", condition: &apis.Condition{Status: corev1.ConditionUnknown, Reason: "Running"}, want: "running"},
+		{name: "succeeded", condition: &apis.Condition{Status: corev1.ConditionTrue, Reason: "Succeeded"}, want: StatusSucceeded},
+		{name: "failed", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "Failed"}, want: StatusFailed},
+		{name: "taskrun timeout", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "TaskRunTimeout"}, want: StatusTimeout},
+		{name: "customrun timeout", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "CustomRunTimedOut"}, want: StatusTimeout},
+		{name: "taskrun cancelled", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "TaskRunCancelled"}, want: StatusCancelled},
+		{name: "pipelinerun cancelled", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "Cancelled"}, want: StatusCancelled},
+		{name: "pipelinerun stopped", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "StoppedRunFinally"}, want: StatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompletionStatus(tt.condition); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -31,6 +31,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&TaskMonitor{},
 		&TaskMonitorList{},
+		&ClusterTaskMonitor{},
+		&ClusterTaskMonitorList{},
+		&MetricsExporter{},
+		&MetricsExporterList{},
 		&TaskRunMonitor{},
 		&TaskRunMonitorList{},
 		&PipelineMonitor{},
@@ -1,13 +1,25 @@
 package v1alpha1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"knative.dev/pkg/apis"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -22,6 +34,13 @@ type RunDimensions struct {
 	Labels    map[string]string
 	Params    pipelinev1beta1.Params
 	Object    runtime.Object
+	// Step is the name of the step of per step samples
+	Step string
+	// StepImage is the image of the step of per step samples
+	StepImage string
+	// StepExitCode is the exit code of the step of per step samples, empty
+	// until the step terminated
+	StepExitCode string
 }
 
 func (r *RunDimensions) GetId() string {
@@ -32,6 +51,121 @@ type MetricDimensionRef struct {
 	Condition *string `json:"condition,omitempty"`
 	Param     *string `json:"param,omitempty"`
 	Label     *string `json:"label,omitempty"`
+	// Reason of the condition of the given type, e.g. Succeeded, tagged
+	// reason for the Succeeded condition and e.g. readyReason for others
+	Reason *string `json:"reason,omitempty"`
+	// AnnotationJSON reads the tag from the JSON document of an annotation
+	AnnotationJSON *AnnotationJSONRef `json:"annotationJSON,omitempty"`
+	// Status tags done runs with their normalized status: succeeded, failed,
+	// cancelled or timeout
+	Status *bool `json:"status,omitempty"`
+	// Attempt tags runs with the index of the recorded attempt, 0 for the
+	// first one, retries are counted from .status.retriesStatus
+	Attempt *bool `json:"attempt,omitempty"`
+	// Namespace tags runs with their namespace
+	Namespace *bool `json:"namespace,omitempty"`
+	// Step records a sample per step of TaskRuns, tagged with the step name.
+	// Histogram durations are then evaluated against each step state, e.g.
+	// from .terminated.startedAt to .terminated.finishedAt
+	Step *bool `json:"step,omitempty"`
+	// Image tags per step samples with the image of the step
+	Image *bool `json:"image,omitempty"`
+	// ExitCode tags per step samples with the exit code of the step
+	ExitCode *bool `json:"exitCode,omitempty"`
+	// Preset is a predefined dimension, reason tags runs with the reason of
+	// their Succeeded condition
+	Preset DimensionPreset `json:"preset,omitempty"`
+}
+
+type DimensionPreset string
+
+const (
+	// DimensionPresetReason is the reason of the Succeeded condition, e.g.
+	// TaskRunTimeout, normalized like reason dimensions
+	DimensionPresetReason DimensionPreset = "reason"
+)
+
+// ReasonCondition returns the type of the condition whose reason is the value
+// of the dimension, false for other dimensions
+func (t *MetricDimensionRef) ReasonCondition() (apis.ConditionType, bool) {
+	if t.Reason != nil {
+		return apis.ConditionType(*t.Reason), true
+	}
+	if t.Preset == DimensionPresetReason {
+		return apis.ConditionSucceeded, true
+	}
+	return "", false
+}
+
+// reasonKey is the key of the reason dimension of the condition, reason for
+// the Succeeded condition and e.g. readyReason for the others, so the reasons
+// of several conditions don't collide
+func reasonKey(conditionType apis.ConditionType) string {
+	if conditionType == apis.ConditionSucceeded {
+		return "reason"
+	}
+	return strings.ToLower(string(conditionType[:1])) + string(conditionType[1:]) + "Reason"
+}
+
+// AnnotationJSONRef is a field of a JSON document stored in a run annotation,
+// like the summaries attached by test and scan tools
+type AnnotationJSONRef struct {
+	// Annotation holding the document, e.g. example.com/test-summary
+	Annotation string `json:"annotation"`
+	// Path is a JSONPath evaluated against the document, e.g. .tests.failed
+	Path string `json:"path"`
+	// Tag is the key of the dimension, unused by values
+	Tag string `json:"tag,omitempty"`
+}
+
+// Find returns the field of the document, false when the annotation or the
+// field is missing
+func (r *AnnotationJSONRef) Find(obj runtime.Object) (any, bool, error) {
+	values, err := r.FindAll(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	switch len(values) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return values[0], true, nil
+	default:
+		return nil, false, fmt.Errorf("path %s of annotation %s matched %d values", r.Path, r.Annotation, len(values))
+	}
+}
+
+// FindAll returns every field of the document matching the path, none when
+// the annotation is missing
+func (r *AnnotationJSONRef) FindAll(obj runtime.Object) ([]any, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	annotation, exists := accessor.GetAnnotations()[r.Annotation]
+	if !exists {
+		return nil, nil
+	}
+	var document any
+	if err := json.Unmarshal([]byte(annotation), &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON in annotation %s: %w", r.Annotation, err)
+	}
+	return findJSON(r.Path, document)
+}
+
+// findJSON returns every value matching the JSONPath in the document
+func findJSON(path string, document any) ([]any, error) {
+	results, err := FindJSONPath(path, document)
+	if err != nil {
+		return nil, err
+	}
+	values := []any{}
+	for _, result := range results {
+		for _, value := range result {
+			values = append(values, value.Interface())
+		}
+	}
+	return values, nil
 }
 
 func (t *MetricDimensionRef) Key() (string, error) {
@@ -44,6 +178,30 @@ func (t *MetricDimensionRef) Key() (string, error) {
 		}
 		return "", errors.New("invalid")
 	}
+	if conditionType, ok := t.ReasonCondition(); ok {
+		if conditionType == "" {
+			return "", errors.New("invalid")
+		}
+		return reasonKey(conditionType), nil
+	}
+	if t.Status != nil && *t.Status {
+		return "status", nil
+	}
+	if t.Attempt != nil && *t.Attempt {
+		return "attempt", nil
+	}
+	if t.Namespace != nil && *t.Namespace {
+		return "namespace", nil
+	}
+	if t.Step != nil && *t.Step {
+		return "step", nil
+	}
+	if t.Image != nil && *t.Image {
+		return "image", nil
+	}
+	if t.ExitCode != nil && *t.ExitCode {
+		return "exitCode", nil
+	}
 	// TODO: sanatize string
 	if t.Param != nil {
 		return *t.Param, nil
@@ -52,23 +210,78 @@ func (t *MetricDimensionRef) Key() (string, error) {
 	if t.Label != nil {
 		return *t.Label, nil
 	}
+	if t.AnnotationJSON != nil && t.AnnotationJSON.Tag != "" {
+		return t.AnnotationJSON.Tag, nil
+	}
 	return "", errors.New("invalid")
 }
 
 func (t *MetricDimensionRef) Value(runDimentions *RunDimensions) (string, error) {
+	value, _, err := t.find(runDimentions, ByModeError, "")
+	return value, err
+}
+
+// find returns the value of the dimension, false with a placeholder when the
+// run has no value for it. Paths matching several values are handled by the
+// mode.
+func (t *MetricDimensionRef) find(runDimentions *RunDimensions, mode ByMode, separator string) (string, bool, error) {
 	if t.Condition != nil {
 		if *t.Condition == string(apis.ConditionSucceeded) {
-			return statusCondition(runDimentions.Status.GetCondition(apis.ConditionSucceeded)), nil
+			return statusCondition(runDimentions.Status.GetCondition(apis.ConditionSucceeded)), true, nil
+		}
+		return "INVALID", true, nil
+	}
+
+	if t.Status != nil && *t.Status {
+		return CompletionStatus(runDimentions.Status.GetCondition(apis.ConditionSucceeded)), true, nil
+	}
+
+	if t.Attempt != nil && *t.Attempt {
+		if taskRun, ok := runDimentions.Object.(*pipelinev1beta1.TaskRun); ok {
+			return strconv.Itoa(len(taskRun.Status.RetriesStatus)), true, nil
+		}
+		return "0", true, nil
+	}
+
+	if t.Namespace != nil && *t.Namespace {
+		return runDimentions.Namespace, true, nil
+	}
+
+	if t.Step != nil && *t.Step {
+		if runDimentions.Step == "" {
+			return "MISSING", false, nil
+		}
+		return runDimentions.Step, true, nil
+	}
+
+	if t.Image != nil && *t.Image {
+		if runDimentions.StepImage == "" {
+			return "MISSING", false, nil
+		}
+		return runDimentions.StepImage, true, nil
+	}
+
+	if t.ExitCode != nil && *t.ExitCode {
+		if runDimentions.StepExitCode == "" {
+			return "MISSING", false, nil
+		}
+		return runDimentions.StepExitCode, true, nil
+	}
+
+	if conditionType, ok := t.ReasonCondition(); ok {
+		cond := runDimentions.Status.GetCondition(conditionType)
+		if cond == nil {
+			return "", false, nil
 		}
-		return "INVALID", nil
+		return cond.Reason, true, nil
 	}
 
 	if t.Label != nil {
 		labelValue, exists := runDimentions.Labels[*t.Label]
 		if !exists {
-			return "MISSING", nil
+			return "MISSING", false, nil
 		}
-		return labelValue, nil
+		return labelValue, true, nil
 	}
 
 	if t.Param != nil {
@@ -76,21 +289,331 @@ func (t *MetricDimensionRef) Value(runDimentions *RunDimensions) (string, error)
 			if param.Name == *t.Param {
 				// TODO: support array and objects
 				if param.Value.StringVal != "" {
-					return param.Value.StringVal, nil
+					return param.Value.StringVal, true, nil
 				}
-				return "UNSUPPORTED_VALUE", nil
+				return "UNSUPPORTED_VALUE", true, nil
+			}
+		}
+		return "MISSING", false, nil
+	}
+
+	if t.AnnotationJSON != nil {
+		values, err := t.AnnotationJSON.FindAll(runDimentions.Object)
+		if err != nil {
+			return "", false, err
+		}
+		switch {
+		case len(values) == 0:
+			return "MISSING", false, nil
+		case len(values) == 1 || mode == ByModeFirst:
+			return formatJSONValue(values[0]), true, nil
+		case mode == ByModeJoin:
+			formatted := make([]string, 0, len(values))
+			for _, value := range values {
+				formatted = append(formatted, formatJSONValue(value))
 			}
+			return strings.Join(formatted, separator), true, nil
+		default:
+			return "", false, fmt.Errorf("path %s of annotation %s matched %d values", t.AnnotationJSON.Path, t.AnnotationJSON.Annotation, len(values))
 		}
-		return "MISSING", nil
 	}
-	return "", errors.New("invalid value")
+	return "", false, errors.New("invalid value")
+}
+
+// formatJSONValue returns the tag value of a scalar of a JSON document
+func formatJSONValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return "UNSUPPORTED_VALUE"
+	}
 }
 
 type ByStatement struct {
 	MetricDimensionRef `json:",inline"`
+	// Default replaces the placeholder of runs without value, e.g. a missing
+	// label, param or annotation field
+	Default string `json:"default,omitempty"`
+	// Mode handles paths matching several values: error, the default, first
+	// or join
+	Mode ByMode `json:"mode,omitempty"`
+	// Separator of joined values, defaults to a comma
+	Separator string `json:"separator,omitempty"`
+	// Transforms are applied in order to the value of the dimension, to
+	// normalize high variance values before they become tags
+	Transforms []TagTransform `json:"transforms,omitempty"`
+}
+
+// Value returns the value of the dimension, the default when the run has
+// none and a default is set
+func (b *ByStatement) Value(runDimentions *RunDimensions) (string, error) {
+	separator := b.Separator
+	if separator == "" {
+		separator = ","
+	}
+	value, found, err := b.find(runDimentions, b.Mode, separator)
+	if err != nil {
+		return "", err
+	}
+	if !found && b.Default != "" {
+		return b.Default, nil
+	}
+	return value, nil
+}
+
+type ByMode string
+
+const (
+	ByModeError ByMode = "error"
+	ByModeFirst ByMode = "first"
+	ByModeJoin  ByMode = "join"
+)
+
+// Transform returns the value after every transform of the statement
+func (b *ByStatement) Transform(value string) (string, error) {
+	for _, transform := range b.Transforms {
+		var err error
+		if value, err = transform.Apply(value); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+type TagTransformType string
+
+const (
+	TagTransformLowercase TagTransformType = "lowercase"
+	// TagTransformTruncate keeps the first Length characters
+	TagTransformTruncate TagTransformType = "truncate"
+	// TagTransformRegex keeps the first group of Pattern, or the whole match
+	// without groups, values not matching are replaced by NO_MATCH
+	TagTransformRegex TagTransformType = "regex"
+	// TagTransformHash replaces values by the first 8 bytes of their SHA-256,
+	// hex encoded
+	TagTransformHash TagTransformType = "hash"
+)
+
+// TagTransform normalizes the value of a dimension
+type TagTransform struct {
+	Type    TagTransformType `json:"type"`
+	Length  int              `json:"length,omitempty"`
+	Pattern string           `json:"pattern,omitempty"`
+}
+
+// Apply returns the transformed value
+func (t *TagTransform) Apply(value string) (string, error) {
+	switch t.Type {
+	case TagTransformLowercase:
+		return strings.ToLower(value), nil
+	case TagTransformTruncate:
+		if t.Length <= 0 {
+			return "", fmt.Errorf("truncate length must be positive, got %d", t.Length)
+		}
+		if runes := []rune(value); len(runes) > t.Length {
+			return string(runes[:t.Length]), nil
+		}
+		return value, nil
+	case TagTransformRegex:
+		if t.Pattern == "" {
+			return "", fmt.Errorf("regex requires a pattern")
+		}
+		pattern, err := compilePattern(t.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex pattern %q: %w", t.Pattern, err)
+		}
+		match := pattern.FindStringSubmatch(value)
+		switch {
+		case match == nil:
+			return "NO_MATCH", nil
+		case len(match) > 1:
+			return match[1], nil
+		default:
+			return match[0], nil
+		}
+	case TagTransformHash:
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:8]), nil
+	default:
+		return "", fmt.Errorf("invalid tag transform %q", t.Type)
+	}
 }
 
 type MetricHistogramDuration struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Segments sums the duration of each from/to pair into a single sample.
+	// Expressions matching several nodes, like .status.retriesStatus[*].startTime,
+	// are paired by position.
+	Segments []MetricDurationSegment `json:"segments,omitempty"`
+	// Negative is the policy applied when to precedes from, e.g. on clock skew:
+	// clamp records zero and drop skips the sample. Defaults to clamp.
+	Negative NegativeDurationPolicy `json:"negative,omitempty"`
+	// ToPod evaluates To against the pod of the TaskRun instead of the run,
+	// e.g. the PodScheduled condition. Requires pod metrics, runs whose pod
+	// is gone are skipped.
+	ToPod bool `json:"toPod,omitempty"`
+}
+
+// MetricValue is the number recorded for each run instead of a duration
+type MetricValue struct {
+	// AnnotationJSON reads the number from the JSON document of an
+	// annotation, numeric strings are accepted
+	AnnotationJSON *AnnotationJSONRef `json:"annotationJSON,omitempty"`
+	// Path is a JSONPath evaluated against the run as served by the API,
+	// e.g. .status.taskResults[?(@.name=="coverage")].value, numeric strings
+	// are accepted
+	Path string `json:"path,omitempty"`
+	// Result reads the number from the result of the given name of the
+	// TaskRun or PipelineRun, numeric strings are accepted
+	Result string `json:"result,omitempty"`
+}
+
+// resultPath returns the path of the result of the given name in the run
+func resultPath(obj runtime.Object, name string) (string, error) {
+	switch obj.(type) {
+	case *pipelinev1beta1.TaskRun:
+		return fmt.Sprintf(".status.taskResults[?(@.name==%q)].value", name), nil
+	case *pipelinev1beta1.PipelineRun:
+		return fmt.Sprintf(".status.pipelineResults[?(@.name==%q)].value", name), nil
+	default:
+		return "", fmt.Errorf("results are only read from taskruns and pipelineruns")
+	}
+}
+
+// Find returns the raw value of the run, false when it's missing
+func (v *MetricValue) Find(obj runtime.Object) (any, bool, error) {
+	if v.AnnotationJSON != nil {
+		return v.AnnotationJSON.Find(obj)
+	}
+	path := v.Path
+	if v.Result != "" {
+		var err error
+		if path, err = resultPath(obj, v.Result); err != nil {
+			return nil, false, err
+		}
+	}
+	if path == "" {
+		return nil, false, fmt.Errorf("value has no source")
+	}
+	// the run is evaluated as JSON, so results like task results are matched
+	// by their serialized form
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	var document any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, false, err
+	}
+	values, err := findJSON(path, document)
+	if err != nil {
+		return nil, false, err
+	}
+	switch len(values) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return values[0], true, nil
+	default:
+		return nil, false, fmt.Errorf("value path %s matched %d values", path, len(values))
+	}
+}
+
+// MetricStatus reports the samples of a metric since it was registered, so
+// the effect of a spec change can be verified
+type MetricStatus struct {
+	Name string `json:"name"`
+	// Recorded is the number of runs recorded
+	Recorded int64 `json:"recorded"`
+	// Skipped is the number of runs matching the monitor without sample, e.g.
+	// on missing timestamps or recording errors
+	Skipped int64 `json:"skipped"`
+	// Errors is the number of skipped runs that failed to be recorded
+	Errors int64 `json:"errors"`
+	// LastError is the last recording error, if any
+	LastError string `json:"lastError,omitempty"`
+	// LastRecordTime is the time the last run was recorded
+	LastRecordTime *metav1.Time `json:"lastRecordTime,omitempty"`
+	// Views are the names of the views exported by the metric
+	Views []string `json:"views,omitempty"`
+	// Suspended is true while the metric isn't attempted after too many
+	// consecutive failures, it's attempted again with exponential backoff
+	Suspended bool `json:"suspended,omitempty"`
+	// ConsecutiveFailures of a suspended metric
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+	// Overflowed is the number of samples recorded as __overflow__ after the
+	// max cardinality of the metric was reached
+	Overflowed int64 `json:"overflowed,omitempty"`
+}
+
+// MergeTags returns the common tags of a monitor overridden by the tags of a
+// metric
+func MergeTags(common, tags map[string]string) map[string]string {
+	if len(common) == 0 {
+		return tags
+	}
+	merged := make(map[string]string, len(common)+len(tags))
+	for key, value := range common {
+		merged[key] = value
+	}
+	for key, value := range tags {
+		merged[key] = value
+	}
+	return merged
+}
+
+// MonitorConditionDegraded is true while a metric of the monitor is suspended
+const MonitorConditionDegraded apis.ConditionType = "Degraded"
+
+// SetDegradedCondition sets the Degraded condition of a monitor from the
+// status of its metrics, the transition time only changes with the status
+func SetDegradedCondition(status *duckv1.Status, metrics []MetricStatus) {
+	condition := apis.Condition{
+		Type:     MonitorConditionDegraded,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+	}
+	messages := []string{}
+	for _, metric := range metrics {
+		if metric.Suspended {
+			messages = append(messages, fmt.Sprintf("metric %s suspended after %d consecutive failures: %s", metric.Name, metric.ConsecutiveFailures, metric.LastError))
+		}
+	}
+	if len(messages) > 0 {
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = "MetricSuspended"
+		condition.Message = strings.Join(messages, "; ")
+	}
+	conditions := duckv1.Conditions{}
+	for _, existing := range status.Conditions {
+		if existing.Type != MonitorConditionDegraded {
+			conditions = append(conditions, existing)
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+	if condition.LastTransitionTime.Inner.IsZero() {
+		condition.LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(time.Now())}
+	}
+	status.Conditions = append(conditions, condition)
+}
+
+type NegativeDurationPolicy string
+
+const (
+	NegativeDurationClamp NegativeDurationPolicy = "clamp"
+	NegativeDurationDrop  NegativeDurationPolicy = "drop"
+)
+
+type MetricDurationSegment struct {
 	From string `json:"from"`
 	To   string `json:"to"`
 }
@@ -101,6 +624,111 @@ type MetricGaugeMatch struct {
 	Values   []string                     `json:"values"`
 }
 
+// MetricNearTimeout restricts a counter to runs that used most of their timeout
+type MetricNearTimeout struct {
+	// Percent of the timeout a run must have used to be counted, e.g. 90
+	Percent int32 `json:"percent"`
+}
+
+// ReasonNormalization maps the condition reasons matching any of the patterns
+// to a single tag value
+type ReasonNormalization struct {
+	// Value of the tag, e.g. image-pull
+	Value string `json:"value"`
+	// Patterns are regular expressions matched against the reason, e.g. ^ErrImagePull$
+	Patterns []string `json:"patterns"`
+}
+
+var patterns sync.Map
+
+var jsonPaths sync.Map
+
+type parsedJSONPath struct {
+	path *jsonpath.JSONPath
+	err  error
+}
+
+// FindJSONPath returns the results of the expression, missing keys result in
+// no results. Expressions are parsed once and cached, as they are evaluated
+// for every run.
+func FindJSONPath(expression string, input any) ([][]reflect.Value, error) {
+	cached, ok := jsonPaths.Load(expression)
+	if !ok {
+		j := jsonpath.New(expression)
+		j.AllowMissingKeys(true)
+		err := j.Parse(fmt.Sprintf("{%s}", expression))
+		if err != nil {
+			err = fmt.Errorf("invalid JSONPath %s: %w", expression, err)
+		}
+		cached, _ = jsonPaths.LoadOrStore(expression, &parsedJSONPath{path: j, err: err})
+	}
+	parsed := cached.(*parsedJSONPath)
+	if parsed.err != nil {
+		return nil, parsed.err
+	}
+	// the evaluation state is kept in the JSONPath while the parsed tree is
+	// only read, so concurrent evaluations each use a copy
+	j := *parsed.path
+	return j.FindResults(input)
+}
+
+// compilePattern returns the compiled pattern, cached as patterns are
+// evaluated for every run
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	cached, _ := patterns.LoadOrStore(pattern, compiled)
+	return cached.(*regexp.Regexp), nil
+}
+
+// NormalizeReason returns the value of the first normalization with a pattern
+// matching the reason, the reason is returned as is when none matches.
+func NormalizeReason(reason string, normalizations []ReasonNormalization) string {
+	for _, normalization := range normalizations {
+		for _, pattern := range normalization.Patterns {
+			compiled, err := compilePattern(pattern)
+			if err != nil {
+				continue
+			}
+			if compiled.MatchString(reason) {
+				return normalization.Value
+			}
+		}
+	}
+	return reason
+}
+
+// Normalized statuses of done runs
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+	StatusTimeout   = "timeout"
+)
+
+// CompletionStatus normalizes the Succeeded condition of a run, the reasons
+// of cancelled and timed out runs differ between TaskRuns and PipelineRuns.
+// Runs not done yet are running.
+func CompletionStatus(cond *apis.Condition) string {
+	switch {
+	case cond == nil || cond.IsUnknown():
+		return "running"
+	case cond.IsTrue():
+		return StatusSucceeded
+	case strings.Contains(cond.Reason, "Timeout"), strings.Contains(cond.Reason, "TimedOut"):
+		return StatusTimeout
+	case strings.Contains(cond.Reason, "Cancelled"), cond.Reason == "StoppedRunFinally":
+		return StatusCancelled
+	default:
+		return StatusFailed
+	}
+}
+
 func statusCondition(cond *apis.Condition) string {
 	if cond == nil {
 		return ""
@@ -121,4 +749,187 @@ type Metric struct {
 	By       []ByStatement            `json:"by,omitempty"`
 	Duration *MetricHistogramDuration `json:"duration,omitempty"`
 	Match    *MetricGaugeMatch        `json:"match,omitempty"`
+	// DurationPreset measures a predefined duration of histograms instead of
+	// duration, queueTime or executionTime
+	DurationPreset DurationPreset `json:"durationPreset,omitempty"`
+	// Help is exported as the description of the metric, e.g. the HELP line
+	// in Prometheus
+	Help string `json:"help,omitempty"`
+	// NearTimeout only counts runs that completed within a margin of their timeout
+	NearTimeout *MetricNearTimeout `json:"nearTimeout,omitempty"`
+	// Reasons normalizes the values of reason dimensions, defaults to the
+	// reasons of the monitor
+	Reasons []ReasonNormalization `json:"reasons,omitempty"`
+	// CountOver exports, next to a histogram, a counter of the runs whose
+	// duration exceeded the threshold, e.g. 10m
+	CountOver *metav1.Duration `json:"countOver,omitempty"`
+	// Tags are constant tags added to every sample, on top of the common
+	// tags of the monitor
+	Tags map[string]string `json:"tags,omitempty"`
+	// Value records a number read from the run instead of a duration,
+	// supported by histograms
+	Value *MetricValue `json:"value,omitempty"`
+	// Preset expands into a predefined metric, see ExpandPreset
+	Preset string `json:"preset,omitempty"`
+	// ValuePreset records a predefined number of the run instead of one
+	// sample per run, e.g. retries, see ExpandPreset
+	ValuePreset ValuePreset `json:"valuePreset,omitempty"`
+	// Attempts selects the attempt of retried runs that is recorded, final or
+	// first. Defaults to final.
+	Attempts AttemptPolicy `json:"attempts,omitempty"`
+	// Granularity of the samples, run by default or step for a sample per
+	// step of TaskRuns, see ExpandGranularity
+	Granularity MetricGranularity `json:"granularity,omitempty"`
+	// Aggregation of the samples exported, defaults to the aggregation of the
+	// type, e.g. count for counters and distribution for histograms
+	Aggregation MetricAggregation `json:"aggregation,omitempty"`
+	// BucketStrategy generates the bucket boundaries of distributions,
+	// defaults to fixed boundaries from 0.25 to 10000
+	BucketStrategy *MetricBucketStrategy `json:"bucketStrategy,omitempty"`
+	// Buckets are explicit bucket boundaries of distributions, strictly
+	// increasing, instead of a bucket strategy
+	Buckets []float64 `json:"buckets,omitempty"`
+	// MaxCardinality limits the distinct combinations of by tags, samples of
+	// new combinations past the limit are recorded with every by tag set to
+	// __overflow__. Zero disables the limit.
+	MaxCardinality int `json:"maxCardinality,omitempty"`
+	// Unit of the samples of histograms and lastValues, durations are
+	// converted to seconds, milliseconds or minutes and named after their
+	// unit. Values are recorded as read unless they have a duration suffix,
+	// e.g. 1m30s. Defaults to seconds for durations.
+	Unit MetricUnit `json:"unit,omitempty"`
+	// Window of rate metrics, the rate is the number of runs completed
+	// within it per minute. Defaults to 5m.
+	Window *metav1.Duration `json:"window,omitempty"`
+	// Threshold of slo metrics, runs whose duration is within it are good
+	Threshold *metav1.Duration `json:"threshold,omitempty"`
+}
+
+type BucketStrategyType string
+
+const (
+	BucketStrategyLinear      BucketStrategyType = "linear"
+	BucketStrategyExponential BucketStrategyType = "exponential"
+)
+
+// MaxBucketCount is the maximum number of bucket boundaries of a metric,
+// every boundary is exported as a series per combination of tags
+const MaxBucketCount = 100
+
+// MetricBucketStrategy generates Count bucket boundaries from Start, each
+// boundary is the previous one plus Width for linear strategies, or times
+// Factor for exponential strategies
+type MetricBucketStrategy struct {
+	Type   BucketStrategyType `json:"type"`
+	Start  float64            `json:"start"`
+	Width  float64            `json:"width,omitempty"`
+	Factor float64            `json:"factor,omitempty"`
+	Count  int                `json:"count"`
+}
+
+// Buckets returns the boundaries of the strategy. Distributions drop a 0
+// boundary, so linear boundaries from 0 start at Width instead.
+func (s *MetricBucketStrategy) Buckets() ([]float64, error) {
+	if s.Count <= 0 || s.Count > MaxBucketCount {
+		return nil, fmt.Errorf("bucket count must be between 1 and %d, got %d", MaxBucketCount, s.Count)
+	}
+	buckets := make([]float64, 0, s.Count)
+	switch s.Type {
+	case BucketStrategyLinear:
+		if s.Width <= 0 {
+			return nil, fmt.Errorf("linear bucket width must be positive, got %v", s.Width)
+		}
+		if s.Start < 0 {
+			return nil, fmt.Errorf("linear buckets can't start below 0, got %v", s.Start)
+		}
+		start := s.Start
+		if start == 0 {
+			start = s.Width
+		}
+		for i := 0; i < s.Count; i++ {
+			buckets = append(buckets, start+float64(i)*s.Width)
+		}
+	case BucketStrategyExponential:
+		if s.Start <= 0 || s.Factor <= 1 {
+			return nil, fmt.Errorf("exponential buckets require a positive start and a factor above 1, got %v and %v", s.Start, s.Factor)
+		}
+		boundary := s.Start
+		for i := 0; i < s.Count; i++ {
+			buckets = append(buckets, boundary)
+			boundary *= s.Factor
+		}
+	default:
+		return nil, fmt.Errorf("invalid bucket strategy %q", s.Type)
+	}
+	return buckets, nil
+}
+
+type MetricAggregation string
+
+const (
+	AggregationSum          MetricAggregation = "sum"
+	AggregationCount        MetricAggregation = "count"
+	AggregationLastValue    MetricAggregation = "lastValue"
+	AggregationDistribution MetricAggregation = "distribution"
+	// AggregationSummary exports the 0.5, 0.9 and 0.99 quantiles of
+	// histograms, tagged with quantile, instead of their buckets
+	AggregationSummary MetricAggregation = "summary"
+)
+
+type MetricUnit string
+
+const (
+	UnitSeconds      MetricUnit = "seconds"
+	UnitMilliseconds MetricUnit = "milliseconds"
+	UnitMinutes      MetricUnit = "minutes"
+	UnitBytes        MetricUnit = "bytes"
+	UnitCount        MetricUnit = "count"
+)
+
+// IsTime returns true for the units durations can be converted to, an empty
+// unit is seconds
+func (u MetricUnit) IsTime() bool {
+	switch u {
+	case "", UnitSeconds, UnitMilliseconds, UnitMinutes:
+		return true
+	default:
+		return false
+	}
 }
+
+// FromSeconds converts seconds to the time unit, other units are returned
+// unchanged
+func (u MetricUnit) FromSeconds(seconds float64) float64 {
+	switch u {
+	case UnitMilliseconds:
+		return seconds * 1000
+	case UnitMinutes:
+		return seconds / 60
+	default:
+		return seconds
+	}
+}
+
+// Validate returns an error for unknown units
+func (u MetricUnit) Validate() error {
+	switch u {
+	case "", UnitSeconds, UnitMilliseconds, UnitMinutes, UnitBytes, UnitCount:
+		return nil
+	default:
+		return fmt.Errorf("unknown unit %q, must be seconds, milliseconds, minutes, bytes or count", u)
+	}
+}
+
+type MetricGranularity string
+
+const (
+	GranularityRun  MetricGranularity = "run"
+	GranularityStep MetricGranularity = "step"
+)
+
+type AttemptPolicy string
+
+const (
+	AttemptsFinal AttemptPolicy = "final"
+	AttemptsFirst AttemptPolicy = "first"
+)
@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/pkg/ptr"
+)
+
+func TestMetricDimensionRefKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     MetricDimensionRef
+		want    string
+		wantErr bool
+	}{
+		{name: "succeeded reason", ref: MetricDimensionRef{Reason: ptr.String("Succeeded")}, want: "reason"},
+		{name: "reason preset", ref: MetricDimensionRef{Preset: DimensionPresetReason}, want: "reason"},
+		{name: "ready reason", ref: MetricDimensionRef{Reason: ptr.String("Ready")}, want: "readyReason"},
+		{name: "custom condition reason", ref: MetricDimensionRef{Reason: ptr.String("ResultsVerified")}, want: "resultsVerifiedReason"},
+		{name: "reason without condition", ref: MetricDimensionRef{Reason: ptr.String("")}, wantErr: true},
+		{name: "succeeded condition", ref: MetricDimensionRef{Condition: ptr.String("Succeeded")}, want: "status"},
+		{name: "label", ref: MetricDimensionRef{Label: ptr.String("team")}, want: "team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ref.Key()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got key %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("want key %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeReason(t *testing.T) {
+	normalizations := []ReasonNormalization{
+		{Value: "image-pull", Patterns: []string{"^ErrImagePull$", "ImagePullBackOff"}},
+		{Value: "timeout", Patterns: []string{"Timeout$"}},
+	}
+	tests := []struct {
+		reason string
+		want   string
+	}{
+		{reason: "ErrImagePull", want: "image-pull"},
+		{reason: "ImagePullBackOff", want: "image-pull"},
+		{reason: "TaskRunTimeout", want: "timeout"},
+		{reason: "Failed", want: "Failed"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeReason(tt.reason, normalizations); got != tt.want {
+			t.Errorf("%s: want %q, got %q", tt.reason, tt.want, got)
+		}
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		common map[string]string
+		tags   map[string]string
+		want   map[string]string
+	}{{
+		name: "no common tags",
+		tags: map[string]string{"team": "ci"},
+		want: map[string]string{"team": "ci"},
+	}, {
+		name:   "no metric tags",
+		common: map[string]string{"team": "ci"},
+		want:   map[string]string{"team": "ci"},
+	}, {
+		name:   "metric tags override common tags",
+		common: map[string]string{"team": "ci", "tier": "1"},
+		tags:   map[string]string{"team": "release"},
+		want:   map[string]string{"team": "release", "tier": "1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, MergeTags(tt.common, tt.tags)); diff != "" {
+				t.Errorf("tags (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	// the tags of the monitor are shared by its metrics, they aren't modified
+	common := map[string]string{"team": "ci"}
+	MergeTags(common, map[string]string{"team": "release"})
+	if common["team"] != "ci" {
+		t.Errorf("want the common tags unchanged, got %v", common)
+	}
+}
+
+func TestMetricBucketStrategyBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy MetricBucketStrategy
+		want     []float64
+		wantErr  bool
+	}{
+		{name: "linear", strategy: MetricBucketStrategy{Type: BucketStrategyLinear, Start: 10, Width: 5, Count: 3}, want: []float64{10, 15, 20}},
+		// distributions drop a 0 boundary, count boundaries are kept
+		{name: "linear from 0", strategy: MetricBucketStrategy{Type: BucketStrategyLinear, Width: 30, Count: 4}, want: []float64{30, 60, 90, 120}},
+		{name: "exponential", strategy: MetricBucketStrategy{Type: BucketStrategyExponential, Start: 1, Factor: 2, Count: 5}, want: []float64{1, 2, 4, 8, 16}},
+		{name: "maximum count", strategy: MetricBucketStrategy{Type: BucketStrategyLinear, Start: 1, Width: 1, Count: MaxBucketCount}},
+		{name: "count above maximum", strategy: MetricBucketStrategy{Type: BucketStrategyLinear, Start: 1, Width: 1, Count: MaxBucketCount + 1}, wantErr: true},
+		{name: "no count", strategy: MetricBucketStrategy{Type: BucketStrategyLinear, Start: 1, Width: 1}, wantErr: true},
+		{name: "linear without width", strategy: MetricBucketStrategy{Type: BucketStrategyLinear, Start: 1, Count: 3}, wantErr: true},
+		{name: "linear below 0", strategy: MetricBucketStrategy{Type: BucketStrategyLinear, Start: -10, Width: 5, Count: 3}, wantErr: true},
+		{name: "exponential from 0", strategy: MetricBucketStrategy{Type: BucketStrategyExponential, Factor: 2, Count: 3}, wantErr: true},
+		{name: "exponential factor of 1", strategy: MetricBucketStrategy{Type: BucketStrategyExponential, Start: 1, Factor: 1, Count: 3}, wantErr: true},
+		{name: "unknown type", strategy: MetricBucketStrategy{Type: "quadratic", Start: 1, Count: 3}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.strategy.Buckets()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if len(got) != tt.strategy.Count {
+					t.Errorf("want %d boundaries, got %d", tt.strategy.Count, len(got))
+				}
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("buckets (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
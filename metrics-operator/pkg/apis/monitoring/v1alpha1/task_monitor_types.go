@@ -20,13 +20,45 @@ type TaskMonitor struct {
 
 // TaskMonitorSpec ...
 type TaskMonitorSpec struct {
-	TaskName string   `json:"taskName"`
-	Metrics  []Metric `json:"metrics"`
+	TaskName string `json:"taskName"`
+	// Kind of the monitored runs, TaskRun by default or CustomRun. The
+	// CustomRuns of a custom task are matched by the name of its reference,
+	// or by its kind when unnamed.
+	Kind    string   `json:"kind,omitempty"`
+	Metrics []Metric `json:"metrics"`
+	// Reasons normalizes the values of reason dimensions of every metric
+	Reasons []ReasonNormalization `json:"reasons,omitempty"`
+	// CommonTags are constant tags added to every metric of the monitor
+	CommonTags map[string]string `json:"commonTags,omitempty"`
+	// MetricPrefix replaces the resource and monitor name starting the names
+	// of the exported metrics, e.g. mycompany_ci exports the duration metric
+	// as mycompany_ci_duration_seconds
+	MetricPrefix string `json:"metricPrefix,omitempty"`
+	// Matches are CEL expressions evaluated against the TaskRun, exposed as
+	// taskRun, or customRun for CustomRuns, only runs matching every
+	// expression are recorded
+	Matches []string `json:"matches,omitempty"`
+	// Selector restricts the monitor to the runs of the task carrying the
+	// labels, e.g. the runs of a team on a shared cluster
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// MarkRecorded writes the metrics that recorded a done run in its
+	// metrics.tekton.dev/recorded-by annotation, so replays and restarts skip
+	// it. Off by default, it costs a write per run.
+	MarkRecorded bool `json:"markRecorded,omitempty"`
 }
 
+const (
+	// TaskMonitorKindTaskRun monitors the TaskRuns of the task
+	TaskMonitorKindTaskRun = "TaskRun"
+	// TaskMonitorKindCustomRun monitors the CustomRuns of the custom task
+	TaskMonitorKindCustomRun = "CustomRun"
+)
+
 // TaskMonitorStatus
 type TaskMonitorStatus struct {
 	duckv1.Status `json:",inline"`
+	// Metrics reports the samples recorded by each metric of the spec
+	Metrics []MetricStatus `json:"metrics,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -22,11 +22,25 @@ type TaskRunMonitor struct {
 type TaskRunMonitorSpec struct {
 	Selector metav1.LabelSelector `json:"selector"`
 	Metrics  []Metric         `json:"metrics"`
+	// Reasons normalizes the values of reason dimensions of every metric
+	Reasons []ReasonNormalization `json:"reasons,omitempty"`
+	// CommonTags are constant tags added to every metric of the monitor
+	CommonTags map[string]string `json:"commonTags,omitempty"`
+	// MetricPrefix replaces the resource and monitor name starting the names
+	// of the exported metrics, e.g. mycompany_ci exports the duration metric
+	// as mycompany_ci_duration_seconds
+	MetricPrefix string `json:"metricPrefix,omitempty"`
+	// MarkRecorded writes the metrics that recorded a done run in its
+	// metrics.tekton.dev/recorded-by annotation, so replays and restarts skip
+	// it. Off by default, it costs a write per run.
+	MarkRecorded bool `json:"markRecorded,omitempty"`
 }
 
 // TaskRunMonitorStatus
 type TaskRunMonitorStatus struct {
 	duckv1.Status `json:",inline"`
+	// Metrics reports the samples recorded by each metric of the spec
+	Metrics []MetricStatus `json:"metrics,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -21,13 +21,35 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnnotationJSONRef) DeepCopyInto(out *AnnotationJSONRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationJSONRef.
+func (in *AnnotationJSONRef) DeepCopy() *AnnotationJSONRef {
+	if in == nil {
+		return nil
+	}
+	out := new(AnnotationJSONRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ByStatement) DeepCopyInto(out *ByStatement) {
 	*out = *in
 	in.MetricDimensionRef.DeepCopyInto(&out.MetricDimensionRef)
+	if in.Transforms != nil {
+		in, out := &in.Transforms, &out.Transforms
+		*out = make([]TagTransform, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -41,6 +63,67 @@ func (in *ByStatement) DeepCopy() *ByStatement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTaskMonitor) DeepCopyInto(out *ClusterTaskMonitor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTaskMonitor.
+func (in *ClusterTaskMonitor) DeepCopy() *ClusterTaskMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTaskMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTaskMonitor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTaskMonitorList) DeepCopyInto(out *ClusterTaskMonitorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterTaskMonitor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTaskMonitorList.
+func (in *ClusterTaskMonitorList) DeepCopy() *ClusterTaskMonitorList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTaskMonitorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTaskMonitorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metric) DeepCopyInto(out *Metric) {
 	*out = *in
@@ -54,13 +137,62 @@ func (in *Metric) DeepCopyInto(out *Metric) {
 	if in.Duration != nil {
 		in, out := &in.Duration, &out.Duration
 		*out = new(MetricHistogramDuration)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Match != nil {
 		in, out := &in.Match, &out.Match
 		*out = new(MetricGaugeMatch)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NearTimeout != nil {
+		in, out := &in.NearTimeout, &out.NearTimeout
+		*out = new(MetricNearTimeout)
+		**out = **in
+	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]ReasonNormalization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CountOver != nil {
+		in, out := &in.CountOver, &out.CountOver
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(MetricValue)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketStrategy != nil {
+		in, out := &in.BucketStrategy, &out.BucketStrategy
+		*out = new(MetricBucketStrategy)
+		**out = **in
+	}
+	if in.Buckets != nil {
+		in, out := &in.Buckets, &out.Buckets
+		*out = make([]float64, len(*in))
+		copy(*out, *in)
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -74,6 +206,22 @@ func (in *Metric) DeepCopy() *Metric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricBucketStrategy) DeepCopyInto(out *MetricBucketStrategy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricBucketStrategy.
+func (in *MetricBucketStrategy) DeepCopy() *MetricBucketStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricBucketStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricDimensionRef) DeepCopyInto(out *MetricDimensionRef) {
 	*out = *in
@@ -92,6 +240,46 @@ func (in *MetricDimensionRef) DeepCopyInto(out *MetricDimensionRef) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+	if in.AnnotationJSON != nil {
+		in, out := &in.AnnotationJSON, &out.AnnotationJSON
+		*out = new(AnnotationJSONRef)
+		**out = **in
+	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Attempt != nil {
+		in, out := &in.Attempt, &out.Attempt
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Step != nil {
+		in, out := &in.Step, &out.Step
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -105,6 +293,22 @@ func (in *MetricDimensionRef) DeepCopy() *MetricDimensionRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricDurationSegment) DeepCopyInto(out *MetricDurationSegment) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricDurationSegment.
+func (in *MetricDurationSegment) DeepCopy() *MetricDurationSegment {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricDurationSegment)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricGaugeMatch) DeepCopyInto(out *MetricGaugeMatch) {
 	*out = *in
@@ -130,6 +334,11 @@ func (in *MetricGaugeMatch) DeepCopy() *MetricGaugeMatch {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricHistogramDuration) DeepCopyInto(out *MetricHistogramDuration) {
 	*out = *in
+	if in.Segments != nil {
+		in, out := &in.Segments, &out.Segments
+		*out = make([]MetricDurationSegment, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -143,6 +352,188 @@ func (in *MetricHistogramDuration) DeepCopy() *MetricHistogramDuration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricNearTimeout) DeepCopyInto(out *MetricNearTimeout) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricNearTimeout.
+func (in *MetricNearTimeout) DeepCopy() *MetricNearTimeout {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricNearTimeout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricStatus) DeepCopyInto(out *MetricStatus) {
+	*out = *in
+	if in.LastRecordTime != nil {
+		in, out := &in.LastRecordTime, &out.LastRecordTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Views != nil {
+		in, out := &in.Views, &out.Views
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricStatus.
+func (in *MetricStatus) DeepCopy() *MetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricValue) DeepCopyInto(out *MetricValue) {
+	*out = *in
+	if in.AnnotationJSON != nil {
+		in, out := &in.AnnotationJSON, &out.AnnotationJSON
+		*out = new(AnnotationJSONRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricValue.
+func (in *MetricValue) DeepCopy() *MetricValue {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsExporter) DeepCopyInto(out *MetricsExporter) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsExporter.
+func (in *MetricsExporter) DeepCopy() *MetricsExporter {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsExporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricsExporter) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsExporterList) DeepCopyInto(out *MetricsExporterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MetricsExporter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsExporterList.
+func (in *MetricsExporterList) DeepCopy() *MetricsExporterList {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsExporterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricsExporterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsExporterSpec) DeepCopyInto(out *MetricsExporterSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(MetricsExporterTLS)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsExporterSpec.
+func (in *MetricsExporterSpec) DeepCopy() *MetricsExporterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsExporterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsExporterStatus) DeepCopyInto(out *MetricsExporterStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsExporterStatus.
+func (in *MetricsExporterStatus) DeepCopy() *MetricsExporterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsExporterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsExporterTLS) DeepCopyInto(out *MetricsExporterTLS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsExporterTLS.
+func (in *MetricsExporterTLS) DeepCopy() *MetricsExporterTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsExporterTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineMonitor) DeepCopyInto(out *PipelineMonitor) {
 	*out = *in
@@ -214,6 +605,30 @@ func (in *PipelineMonitorSpec) DeepCopyInto(out *PipelineMonitorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]ReasonNormalization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CommonTags != nil {
+		in, out := &in.CommonTags, &out.CommonTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Matches != nil {
+		in, out := &in.Matches, &out.Matches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -231,6 +646,13 @@ func (in *PipelineMonitorSpec) DeepCopy() *PipelineMonitorSpec {
 func (in *PipelineMonitorStatus) DeepCopyInto(out *PipelineMonitorStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]MetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -316,6 +738,20 @@ func (in *PipelineRunMonitorSpec) DeepCopyInto(out *PipelineRunMonitorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]ReasonNormalization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CommonTags != nil {
+		in, out := &in.CommonTags, &out.CommonTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -333,6 +769,13 @@ func (in *PipelineRunMonitorSpec) DeepCopy() *PipelineRunMonitorSpec {
 func (in *PipelineRunMonitorStatus) DeepCopyInto(out *PipelineRunMonitorStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]MetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -346,6 +789,43 @@ func (in *PipelineRunMonitorStatus) DeepCopy() *PipelineRunMonitorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReasonNormalization) DeepCopyInto(out *ReasonNormalization) {
+	*out = *in
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReasonNormalization.
+func (in *ReasonNormalization) DeepCopy() *ReasonNormalization {
+	if in == nil {
+		return nil
+	}
+	out := new(ReasonNormalization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagTransform) DeepCopyInto(out *TagTransform) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagTransform.
+func (in *TagTransform) DeepCopy() *TagTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(TagTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskMonitor) DeepCopyInto(out *TaskMonitor) {
 	*out = *in
@@ -417,6 +897,30 @@ func (in *TaskMonitorSpec) DeepCopyInto(out *TaskMonitorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]ReasonNormalization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CommonTags != nil {
+		in, out := &in.CommonTags, &out.CommonTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Matches != nil {
+		in, out := &in.Matches, &out.Matches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -434,6 +938,13 @@ func (in *TaskMonitorSpec) DeepCopy() *TaskMonitorSpec {
 func (in *TaskMonitorStatus) DeepCopyInto(out *TaskMonitorStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]MetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -519,6 +1030,20 @@ func (in *TaskRunMonitorSpec) DeepCopyInto(out *TaskRunMonitorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]ReasonNormalization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CommonTags != nil {
+		in, out := &in.CommonTags, &out.CommonTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -536,6 +1061,13 @@ func (in *TaskRunMonitorSpec) DeepCopy() *TaskRunMonitorSpec {
 func (in *TaskRunMonitorStatus) DeepCopyInto(out *TaskRunMonitorStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]MetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
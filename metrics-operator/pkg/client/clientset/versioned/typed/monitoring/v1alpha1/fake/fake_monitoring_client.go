@@ -27,6 +27,14 @@ type FakeMetricsV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeMetricsV1alpha1) ClusterTaskMonitors() v1alpha1.ClusterTaskMonitorInterface {
+	return &FakeClusterTaskMonitors{c}
+}
+
+func (c *FakeMetricsV1alpha1) MetricsExporters() v1alpha1.MetricsExporterInterface {
+	return &FakeMetricsExporters{c}
+}
+
 func (c *FakeMetricsV1alpha1) PipelineMonitors(namespace string) v1alpha1.PipelineMonitorInterface {
 	return &FakePipelineMonitors{c, namespace}
 }
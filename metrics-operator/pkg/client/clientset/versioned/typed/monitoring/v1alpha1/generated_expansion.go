@@ -17,6 +17,10 @@ limitations under the License.
 
 package v1alpha1
 
+type ClusterTaskMonitorExpansion interface{}
+
+type MetricsExporterExpansion interface{}
+
 type PipelineMonitorExpansion interface{}
 
 type PipelineRunMonitorExpansion interface{}
@@ -27,6 +27,8 @@ import (
 
 type MetricsV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ClusterTaskMonitorsGetter
+	MetricsExportersGetter
 	PipelineMonitorsGetter
 	PipelineRunMonitorsGetter
 	TaskMonitorsGetter
@@ -38,6 +40,14 @@ type MetricsV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *MetricsV1alpha1Client) ClusterTaskMonitors() ClusterTaskMonitorInterface {
+	return newClusterTaskMonitors(c)
+}
+
+func (c *MetricsV1alpha1Client) MetricsExporters() MetricsExporterInterface {
+	return newMetricsExporters(c)
+}
+
 func (c *MetricsV1alpha1Client) PipelineMonitors(namespace string) PipelineMonitorInterface {
 	return newPipelineMonitors(c, namespace)
 }
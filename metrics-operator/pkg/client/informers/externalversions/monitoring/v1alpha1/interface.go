@@ -23,6 +23,10 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ClusterTaskMonitors returns a ClusterTaskMonitorInformer.
+	ClusterTaskMonitors() ClusterTaskMonitorInformer
+	// MetricsExporters returns a MetricsExporterInformer.
+	MetricsExporters() MetricsExporterInformer
 	// PipelineMonitors returns a PipelineMonitorInformer.
 	PipelineMonitors() PipelineMonitorInformer
 	// PipelineRunMonitors returns a PipelineRunMonitorInformer.
@@ -44,6 +48,16 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// ClusterTaskMonitors returns a ClusterTaskMonitorInformer.
+func (v *version) ClusterTaskMonitors() ClusterTaskMonitorInformer {
+	return &clusterTaskMonitorInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// MetricsExporters returns a MetricsExporterInformer.
+func (v *version) MetricsExporters() MetricsExporterInformer {
+	return &metricsExporterInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // PipelineMonitors returns a PipelineMonitorInformer.
 func (v *version) PipelineMonitors() PipelineMonitorInformer {
 	return &pipelineMonitorInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
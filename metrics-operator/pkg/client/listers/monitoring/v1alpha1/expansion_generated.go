@@ -17,6 +17,14 @@ limitations under the License.
 
 package v1alpha1
 
+// ClusterTaskMonitorListerExpansion allows custom methods to be added to
+// ClusterTaskMonitorLister.
+type ClusterTaskMonitorListerExpansion interface{}
+
+// MetricsExporterListerExpansion allows custom methods to be added to
+// MetricsExporterLister.
+type MetricsExporterListerExpansion interface{}
+
 // PipelineMonitorListerExpansion allows custom methods to be added to
 // PipelineMonitorLister.
 type PipelineMonitorListerExpansion interface{}
@@ -0,0 +1,130 @@
+package informers
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	"github.com/tektoncd/pipeline/pkg/client/injection/informers/factory"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+)
+
+func init() {
+	// Registered after the Tekton informer factory, so it replaces it when
+	// tuning options are set
+	injection.Default.RegisterInformerFactory(withInformerFactory)
+}
+
+// Tuning configures the list and watch calls of the TaskRun and PipelineRun
+// informers, which may cache a large number of retained runs.
+type Tuning struct {
+	// ResyncPeriod between full resyncs of the informer caches
+	ResyncPeriod time.Duration
+	// ListPageSize is the number of runs fetched per list call, zero uses the
+	// client default
+	ListPageSize int64
+	// WatchTimeout is the server side timeout of watch calls, zero uses the
+	// client default
+	WatchTimeout time.Duration
+	// LabelSelector restricts the cached runs to the ones matching it. It's
+	// set by the operator, not derived from the monitors, and should be
+	// shared by every monitor since other runs are never seen
+	LabelSelector string
+	// ExcludedNamespaces are never recorded, entries are namespace names or
+	// glob patterns like test-*. Names are excluded by the API server.
+	ExcludedNamespaces []string
+}
+
+type tuningKey struct{}
+
+// WithTuning attaches the informer tuning to the context, the resync period
+// applies to every informer factory.
+func WithTuning(ctx context.Context, tuning *Tuning) context.Context {
+	if tuning.ResyncPeriod > 0 {
+		ctx = controller.WithResyncPeriod(ctx, tuning.ResyncPeriod)
+	}
+	return context.WithValue(ctx, tuningKey{}, tuning)
+}
+
+// GetTuning returns the informer tuning attached to the context, if any.
+func GetTuning(ctx context.Context) *Tuning {
+	tuning, _ := ctx.Value(tuningKey{}).(*Tuning)
+	return tuning
+}
+
+// NamespaceExcluded returns true when runs of the namespace must not be
+// recorded
+func (t *Tuning) NamespaceExcluded(namespace string) bool {
+	if t == nil {
+		return false
+	}
+	for _, excluded := range t.ExcludedNamespaces {
+		if matched, _ := path.Match(excluded, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludedNamespaceFilter returns a filter of the objects of namespaces not
+// excluded, patterns can't be matched by the API server
+func ExcludedNamespaceFilter(ctx context.Context) func(obj any) bool {
+	tuning := GetTuning(ctx)
+	return func(obj any) bool {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		object, err := meta.Accessor(obj)
+		if err != nil {
+			return true
+		}
+		return !tuning.NamespaceExcluded(object.GetNamespace())
+	}
+}
+
+func (t *Tuning) tweakListOptions(options *metav1.ListOptions) {
+	if t.LabelSelector != "" {
+		options.LabelSelector = t.LabelSelector
+	}
+	excluded := []fields.Selector{}
+	for _, namespace := range t.ExcludedNamespaces {
+		if !strings.ContainsAny(namespace, `*?[\`) {
+			excluded = append(excluded, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+		}
+	}
+	if len(excluded) > 0 {
+		options.FieldSelector = fields.AndSelectors(excluded...).String()
+	}
+	if t.ListPageSize > 0 {
+		options.Limit = t.ListPageSize
+	}
+	// only watch calls have a timeout
+	if t.WatchTimeout > 0 && options.TimeoutSeconds != nil {
+		timeoutSeconds := int64(t.WatchTimeout.Seconds())
+		options.TimeoutSeconds = &timeoutSeconds
+	}
+}
+
+func withInformerFactory(ctx context.Context) context.Context {
+	tuning := GetTuning(ctx)
+	if tuning == nil {
+		return ctx
+	}
+	c := pipelineclient.Get(ctx)
+	opts := []externalversions.SharedInformerOption{
+		externalversions.WithTweakListOptions(tuning.tweakListOptions),
+	}
+	if injection.HasNamespaceScope(ctx) {
+		opts = append(opts, externalversions.WithNamespace(injection.GetNamespaceScope(ctx)))
+	}
+	return context.WithValue(ctx, factory.Key{},
+		externalversions.NewSharedInformerFactoryWithOptions(c, controller.GetResyncPeriod(ctx), opts...))
+}
@@ -0,0 +1,114 @@
+package informers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/ptr"
+)
+
+func TestTweakListOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		tuning  Tuning
+		options metav1.ListOptions
+		want    metav1.ListOptions
+	}{{
+		name: "defaults",
+	}, {
+		name:   "label selector",
+		tuning: Tuning{LabelSelector: "app.kubernetes.io/part-of=ci"},
+		want:   metav1.ListOptions{LabelSelector: "app.kubernetes.io/part-of=ci"},
+	}, {
+		name:   "excluded namespace names",
+		tuning: Tuning{ExcludedNamespaces: []string{"kube-system", "test-*", "tekton-pipelines"}},
+		want:   metav1.ListOptions{FieldSelector: "metadata.namespace!=kube-system,metadata.namespace!=tekton-pipelines"},
+	}, {
+		name:   "list page size",
+		tuning: Tuning{ListPageSize: 100, WatchTimeout: time.Minute},
+		want:   metav1.ListOptions{Limit: 100},
+	}, {
+		name:    "watch timeout",
+		tuning:  Tuning{WatchTimeout: time.Minute},
+		options: metav1.ListOptions{TimeoutSeconds: ptr.Int64(300)},
+		want:    metav1.ListOptions{TimeoutSeconds: ptr.Int64(60)},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			tt.tuning.tweakListOptions(&options)
+			if diff := cmp.Diff(tt.want, options); diff != "" {
+				t.Errorf("list options (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithTuning(t *testing.T) {
+	ctx := context.Background()
+	if GetTuning(ctx) != nil {
+		t.Error("want no tuning unless attached")
+	}
+	if got := withInformerFactory(ctx); got != ctx {
+		t.Error("want the default informer factory kept without tuning")
+	}
+
+	tuning := &Tuning{ResyncPeriod: 5 * time.Minute}
+	tuned := WithTuning(ctx, tuning)
+	if GetTuning(tuned) != tuning {
+		t.Error("want the attached tuning")
+	}
+	if got := controller.GetResyncPeriod(tuned); got != 5*time.Minute {
+		t.Errorf("want a resync period of 5m, got %v", got)
+	}
+	// a zero resync period keeps the default one
+	if got := controller.GetResyncPeriod(WithTuning(ctx, &Tuning{})); got != controller.DefaultResyncPeriod {
+		t.Errorf("want the default resync period, got %v", got)
+	}
+}
+
+func TestNamespaceExcluded(t *testing.T) {
+	tuning := &Tuning{ExcludedNamespaces: []string{"kube-system", "test-*"}}
+	for namespace, want := range map[string]bool{
+		"kube-system":  true,
+		"test-":        true,
+		"test-e2e":     true,
+		"dev":          false,
+		"kube-public":  false,
+		"pre-test-e2e": false,
+	} {
+		if got := tuning.NamespaceExcluded(namespace); got != want {
+			t.Errorf("namespace %s: want excluded %v, got %v", namespace, want, got)
+		}
+	}
+	if (*Tuning)(nil).NamespaceExcluded("kube-system") {
+		t.Error("want no namespace excluded without tuning")
+	}
+}
+
+func TestExcludedNamespaceFilter(t *testing.T) {
+	ctx := WithTuning(context.Background(), &Tuning{ExcludedNamespaces: []string{"test-*"}})
+	filter := ExcludedNamespaceFilter(ctx)
+	excluded := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-e2e"}}
+	kept := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "dev"}}
+
+	if filter(excluded) {
+		t.Error("want the object of an excluded namespace filtered out")
+	}
+	if !filter(kept) {
+		t.Error("want the object of another namespace kept")
+	}
+	// deletions seen after a missed watch event are filtered like the object
+	if filter(cache.DeletedFinalStateUnknown{Key: "test-e2e/a", Obj: excluded}) {
+		t.Error("want the tombstone of an excluded namespace filtered out")
+	}
+	if !filter("not an object") {
+		t.Error("want objects without metadata kept")
+	}
+}
@@ -0,0 +1,89 @@
+package informers
+
+import (
+	"context"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterClient(withMetadataClient)
+	injection.Default.RegisterInformer(withTaskRunMetadataInformer)
+	injection.Default.RegisterInformer(withPipelineRunMetadataInformer)
+}
+
+type metadataClientKey struct{}
+type taskRunMetadataKey struct{}
+type pipelineRunMetadataKey struct{}
+
+var (
+	taskRunResource     = pipelinev1beta1.SchemeGroupVersion.WithResource("taskruns")
+	pipelineRunResource = pipelinev1beta1.SchemeGroupVersion.WithResource("pipelineruns")
+)
+
+func withMetadataClient(ctx context.Context, cfg *rest.Config) context.Context {
+	return context.WithValue(ctx, metadataClientKey{}, metadata.NewForConfigOrDie(cfg))
+}
+
+func newMetadataInformer(ctx context.Context, gvr schema.GroupVersionResource) informers.GenericInformer {
+	client := ctx.Value(metadataClientKey{}).(metadata.Interface)
+	namespace := metav1.NamespaceAll
+	if injection.HasNamespaceScope(ctx) {
+		namespace = injection.GetNamespaceScope(ctx)
+	}
+	var tweak metadatainformer.TweakListOptionsFunc
+	if tuning := GetTuning(ctx); tuning != nil {
+		tweak = tuning.tweakListOptions
+	}
+	return metadatainformer.NewFilteredMetadataInformer(client, gvr, namespace, controller.GetResyncPeriod(ctx), cache.Indexers{}, tweak)
+}
+
+func withTaskRunMetadataInformer(ctx context.Context) (context.Context, controller.Informer) {
+	inf := newMetadataInformer(ctx, taskRunResource)
+	return context.WithValue(ctx, taskRunMetadataKey{}, inf), inf.Informer()
+}
+
+func withPipelineRunMetadataInformer(ctx context.Context) (context.Context, controller.Informer) {
+	inf := newMetadataInformer(ctx, pipelineRunResource)
+	return context.WithValue(ctx, pipelineRunMetadataKey{}, inf), inf.Informer()
+}
+
+// GetTaskRunMetadata returns the informer caching only the metadata of
+// TaskRuns, enough to track deletions.
+func GetTaskRunMetadata(ctx context.Context) informers.GenericInformer {
+	untyped := ctx.Value(taskRunMetadataKey{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic("Unable to fetch TaskRun metadata informer from context.")
+	}
+	return untyped.(informers.GenericInformer)
+}
+
+// GetPipelineRunMetadata returns the informer caching only the metadata of
+// PipelineRuns, enough to track deletions.
+func GetPipelineRunMetadata(ctx context.Context) informers.GenericInformer {
+	untyped := ctx.Value(pipelineRunMetadataKey{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic("Unable to fetch PipelineRun metadata informer from context.")
+	}
+	return untyped.(informers.GenericInformer)
+}
+
+// DeletedObject returns the metadata of a deleted object, unwrapping the
+// tombstones delivered when a deletion was missed.
+func DeletedObject(obj any) (*metav1.PartialObjectMetadata, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, ok := obj.(*metav1.PartialObjectMetadata)
+	return object, ok
+}
@@ -0,0 +1,24 @@
+package informers
+
+import (
+	"testing"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDeletedObject(t *testing.T) {
+	deleted := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "dev", UID: "1"}}
+
+	if object, ok := DeletedObject(deleted); !ok || object != deleted {
+		t.Errorf("want the metadata of the deleted object, got %v", object)
+	}
+	// deletions missed while the watch was down are delivered as tombstones
+	if object, ok := DeletedObject(cache.DeletedFinalStateUnknown{Key: "dev/hello", Obj: deleted}); !ok || object != deleted {
+		t.Errorf("want the metadata of the tombstone, got %v", object)
+	}
+	if _, ok := DeletedObject(&pipelinev1beta1.TaskRun{ObjectMeta: deleted.ObjectMeta}); ok {
+		t.Error("want objects other than metadata ignored")
+	}
+}
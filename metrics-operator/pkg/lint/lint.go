@@ -0,0 +1,422 @@
+// Package lint validates monitors offline, e.g. in a GitOps pipeline before
+// they are applied to a cluster.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem found in a metric of a monitor
+type Finding struct {
+	Severity Severity
+	File     string
+	Monitor  string
+	Metric   string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s/%s: %s", f.File, f.Severity, f.Monitor, f.Metric, f.Message)
+}
+
+type Options struct {
+	// MaxDimensions per metric before a cardinality warning is reported
+	MaxDimensions int
+}
+
+// runLabels identify a single run, using them as dimension creates a series
+// per run
+var runLabels = map[string]bool{
+	"tekton.dev/taskRun":     true,
+	"tekton.dev/pipelineRun": true,
+	"tekton.dev/run":         true,
+	"tekton.dev/customRun":   true,
+}
+
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Lint returns the findings of every monitor, including metric name
+// collisions across monitors.
+func Lint(monitors []Monitor, options Options) []Finding {
+	findings := []Finding{}
+	seen := map[string]Monitor{}
+	for _, monitor := range monitors {
+		for _, pattern := range reasonPatterns(monitor.Reasons) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				findings = append(findings, Finding{SeverityError, monitor.File, monitor.Name, "", fmt.Sprintf("invalid reason pattern %q: %v", pattern, err)})
+			}
+		}
+		if monitor.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(monitor.Selector); err != nil {
+				findings = append(findings, Finding{SeverityError, monitor.File, monitor.Name, "", fmt.Sprintf("invalid selector: %v", err)})
+			}
+		}
+		if monitor.MetricPrefix != "" {
+			if err := v1alpha1.ValidateLabelName(monitor.MetricPrefix); err != nil {
+				findings = append(findings, Finding{SeverityError, monitor.File, monitor.Name, "", fmt.Sprintf("invalid metric prefix %q: %v", monitor.MetricPrefix, err)})
+			}
+		}
+		if err := recorder.NewCELMatches(celVariable(monitor.Resource), monitor.Matches).Err(); err != nil {
+			findings = append(findings, Finding{SeverityError, monitor.File, monitor.Name, "", err.Error()})
+		}
+		for i := range monitor.Metrics {
+			metric := &monitor.Metrics[i]
+			*metric = v1alpha1.ExpandGranularity(v1alpha1.ExpandPreset(*metric))
+			metric.Tags = v1alpha1.MergeTags(monitor.CommonTags, metric.Tags)
+			report := func(severity Severity, format string, args ...any) {
+				findings = append(findings, Finding{severity, monitor.File, monitor.Name, metric.Name, fmt.Sprintf(format, args...)})
+			}
+			for _, message := range lintMetric(metric, options) {
+				report(message.severity, "%s", message.text)
+			}
+			if (metric.Type == "childStates" || metric.Type == "childOutcomes") && monitor.Resource != "pipeline" && monitor.Resource != "pipelinerun" {
+				report(SeverityError, "%s is only supported by pipeline monitors", metric.Type)
+			}
+			if metric.Granularity == v1alpha1.GranularityStep && (monitor.Resource == "pipeline" || monitor.Resource == "pipelinerun") {
+				report(SeverityError, "step granularity is only supported by task monitors")
+			}
+			if metric.ValuePreset != "" && (monitor.Resource == "pipeline" || monitor.Resource == "pipelinerun") {
+				report(SeverityError, "value preset %q is only supported by task monitors", metric.ValuePreset)
+			}
+			if metric.Duration != nil && metric.Duration.ToPod && (monitor.Resource == "pipeline" || monitor.Resource == "pipelinerun") {
+				report(SeverityError, "durations ending on the pod are only supported by task monitors")
+			}
+			name, ok := MetricName(monitor.Resource, monitor.Name, metric)
+			if !ok {
+				continue
+			}
+			name = naming.Prefixed(monitor.MetricPrefix, monitor.Resource, monitor.Name, name)
+			if previous, exists := seen[name]; exists {
+				report(SeverityError, "metric %s is also defined by %s in %s", name, previous.Name, previous.File)
+				continue
+			}
+			seen[name] = monitor
+		}
+	}
+	return findings
+}
+
+// MetricName returns the name of the exported metric, false when the type is
+// unknown
+func MetricName(resource, monitor string, metric *v1alpha1.Metric) (string, bool) {
+	switch metric.Type {
+	case "counter", "childOutcomes", "slo":
+		return naming.CounterMetric(resource, monitor, metric.Name), true
+	case "histogram", "durationBreakdown":
+		if metric.Type == "histogram" && metric.Value != nil {
+			return naming.GaugeMetric(resource, monitor, metric.Name), true
+		}
+		return naming.DurationMetric(resource, monitor, metric.Name, string(metric.Unit)), true
+	case "gauge", "childStates", "lastValue":
+		return naming.GaugeMetric(resource, monitor, metric.Name), true
+	case "timeoutRatio":
+		return naming.RatioMetric(resource, monitor, metric.Name), true
+	case "rate":
+		return naming.RateMetric(resource, monitor, metric.Name), true
+	default:
+		return "", false
+	}
+}
+
+// celVariable is the name of the run in the CEL matches of the monitor
+func celVariable(resource string) string {
+	if strings.HasPrefix(resource, "pipeline") {
+		return "pipelineRun"
+	}
+	return "taskRun"
+}
+
+type message struct {
+	severity Severity
+	text     string
+}
+
+func lintMetric(metric *v1alpha1.Metric, options Options) []message {
+	messages := []message{}
+	errorf := func(format string, args ...any) {
+		messages = append(messages, message{SeverityError, fmt.Sprintf(format, args...)})
+	}
+	warnf := func(format string, args ...any) {
+		messages = append(messages, message{SeverityWarning, fmt.Sprintf(format, args...)})
+	}
+
+	if metric.Preset != "" && !sets.New[string](v1alpha1.Presets...).Has(metric.Preset) {
+		errorf("unknown preset %q", metric.Preset)
+	}
+	switch metric.ValuePreset {
+	case "":
+	case v1alpha1.ValuePresetRetries, v1alpha1.ValuePresetOOMKilled, v1alpha1.ValuePresetContainerRestarts:
+		if metric.Type != "counter" {
+			errorf("value preset %q is only supported by counters", metric.ValuePreset)
+		}
+	default:
+		errorf("unknown value preset %q", metric.ValuePreset)
+	}
+	if !metricNamePattern.MatchString(metric.Name) {
+		errorf("invalid metric name %q", metric.Name)
+	} else if normalized := v1alpha1.NormalizeMetricName(metric.Name); normalized != metric.Name {
+		warnf("metric name %q is normalized to %q", metric.Name, normalized)
+	}
+	switch metric.Type {
+	case "counter", "gauge", "timeoutRatio", "childStates", "childOutcomes":
+	case "histogram":
+		if metric.Duration != nil && metric.Value != nil {
+			errorf("histogram requires either a duration or a value, not both")
+		}
+		if metric.DurationPreset != "" {
+			if _, err := metric.DurationPreset.Duration(); err != nil {
+				errorf("%v", err)
+			}
+			if metric.Duration != nil || metric.Value != nil {
+				warnf("durationPreset is ignored with a duration or a value")
+			}
+		}
+	case "durationBreakdown":
+		if metric.Duration != nil {
+			warnf("duration is ignored by durationBreakdown")
+		}
+	case "lastValue":
+		if metric.Value == nil {
+			errorf("lastValue requires a value")
+		}
+	case "rate":
+		if metric.Window != nil && metric.Window.Duration <= 0 {
+			errorf("window must be positive, got %s", metric.Window.Duration)
+		}
+	case "slo":
+		switch {
+		case metric.Threshold == nil:
+			errorf("slo requires a threshold")
+		case metric.Threshold.Duration <= 0:
+			errorf("threshold must be positive, got %s", metric.Threshold.Duration)
+		}
+		if metric.DurationPreset != "" {
+			if _, err := metric.DurationPreset.Duration(); err != nil {
+				errorf("%v", err)
+			}
+		}
+	default:
+		errorf("invalid metric type %q", metric.Type)
+	}
+	if metric.Window != nil && metric.Type != "rate" {
+		errorf("window is only supported by rate metrics")
+	}
+	if metric.Threshold != nil && metric.Type != "slo" {
+		errorf("threshold is only supported by slo metrics")
+	}
+
+	keys := map[string]bool{}
+	for i, by := range metric.By {
+		key, err := by.Key()
+		if err != nil {
+			errorf("invalid by statement %d", i)
+			continue
+		}
+		if keys[key] {
+			warnf("dimension %q is defined more than once", key)
+		}
+		keys[key] = true
+		if by.Label != nil && runLabels[*by.Label] {
+			warnf("dimension %q creates a series per run", key)
+		}
+		if by.AnnotationJSON != nil {
+			if err := compile(by.AnnotationJSON.Path); err != nil {
+				errorf("invalid annotation path %q of dimension %q: %v", by.AnnotationJSON.Path, key, err)
+			}
+		}
+		switch by.Mode {
+		case "", v1alpha1.ByModeError, v1alpha1.ByModeFirst, v1alpha1.ByModeJoin:
+		default:
+			errorf("invalid mode %q of dimension %q", by.Mode, key)
+		}
+		if _, reason := by.ReasonCondition(); by.Default != "" && by.Label == nil && by.Param == nil && by.AnnotationJSON == nil && !reason {
+			warnf("default of dimension %q is ignored, it always has a value", key)
+		}
+		for _, transform := range by.Transforms {
+			if _, err := transform.Apply(""); err != nil {
+				errorf("invalid transform of dimension %q: %v", key, err)
+			}
+		}
+	}
+	for _, by := range metric.By {
+		if by.Step != nil && *by.Step && ((metric.Type != "histogram" && metric.Type != "counter") || metric.Value != nil) {
+			warnf("step dimension only expands histogram durations and counters, other metrics report MISSING")
+		}
+	}
+	for _, key := range sets.List(sets.KeySet(metric.Tags)) {
+		if !metricNamePattern.MatchString(key) {
+			errorf("invalid tag %q", key)
+		}
+		if keys[key] {
+			warnf("tag %q is overridden by the dimension of the same name", key)
+		}
+	}
+	if options.MaxDimensions > 0 && len(metric.By) > options.MaxDimensions {
+		warnf("%d dimensions, series grow with the product of their values", len(metric.By))
+	}
+	switch {
+	case metric.MaxCardinality < 0:
+		errorf("max cardinality must not be negative, got %d", metric.MaxCardinality)
+	case metric.MaxCardinality > 0 && len(metric.By) == 0:
+		warnf("max cardinality is ignored without by statements")
+	}
+
+	if metric.Duration != nil {
+		switch metric.Duration.Negative {
+		case "", v1alpha1.NegativeDurationClamp, v1alpha1.NegativeDurationDrop:
+		default:
+			errorf("invalid negative duration policy %q", metric.Duration.Negative)
+		}
+		if len(metric.Duration.Segments) == 0 {
+			if err := compile(metric.Duration.From); err != nil {
+				errorf("invalid duration from %q: %v", metric.Duration.From, err)
+			}
+			if err := compile(metric.Duration.To); err != nil {
+				errorf("invalid duration to %q: %v", metric.Duration.To, err)
+			}
+		}
+		if metric.Duration.ToPod && len(metric.Duration.Segments) > 0 {
+			errorf("toPod isn't supported by segments")
+		}
+		for i, segment := range metric.Duration.Segments {
+			if err := compile(segment.From); err != nil {
+				errorf("invalid segment %d from %q: %v", i, segment.From, err)
+			}
+			if err := compile(segment.To); err != nil {
+				errorf("invalid segment %d to %q: %v", i, segment.To, err)
+			}
+		}
+	}
+
+	if metric.Value != nil {
+		sources := 0
+		for _, set := range []bool{metric.Value.AnnotationJSON != nil, metric.Value.Path != "", metric.Value.Result != ""} {
+			if set {
+				sources++
+			}
+		}
+		switch {
+		case metric.Type != "histogram" && metric.Type != "lastValue":
+			errorf("value is only supported by histograms and lastValue")
+		case sources == 0:
+			errorf("value requires an annotationJSON, a path or a result source")
+		case sources > 1:
+			errorf("value requires a single source among annotationJSON, path and result")
+		case metric.Value.Result != "":
+		case metric.Value.AnnotationJSON != nil:
+			if err := compile(metric.Value.AnnotationJSON.Path); err != nil {
+				errorf("invalid value path %q: %v", metric.Value.AnnotationJSON.Path, err)
+			}
+		default:
+			if err := compile(metric.Value.Path); err != nil {
+				errorf("invalid value path %q: %v", metric.Value.Path, err)
+			}
+		}
+		if metric.CountOver != nil && metric.Type == "histogram" {
+			warnf("countOver is ignored by histograms of values")
+		}
+	}
+
+	switch metric.Aggregation {
+	case "":
+	case v1alpha1.AggregationSum, v1alpha1.AggregationCount, v1alpha1.AggregationLastValue, v1alpha1.AggregationDistribution:
+		if metric.Type == "gauge" || metric.Type == "childStates" || metric.Type == "childOutcomes" || metric.Type == "lastValue" || metric.Type == "rate" || metric.Type == "slo" {
+			warnf("aggregation is ignored by %s", metric.Type)
+		}
+	case v1alpha1.AggregationSummary:
+		if metric.Type != "histogram" {
+			errorf("summary aggregation is only supported by histograms")
+		}
+	default:
+		errorf("invalid aggregation %q", metric.Aggregation)
+	}
+
+	if metric.BucketStrategy != nil {
+		if _, err := metric.BucketStrategy.Buckets(); err != nil {
+			errorf("invalid bucket strategy: %v", err)
+		}
+		if len(metric.Buckets) > 0 {
+			errorf("buckets and bucketStrategy are exclusive")
+		}
+	}
+	if err := v1alpha1.ValidateBuckets(metric.Buckets); err != nil {
+		errorf("invalid buckets: %v", err)
+	}
+	if err := metric.Unit.Validate(); err != nil {
+		errorf("%v", err)
+	} else if metric.Unit != "" && metric.Type != "histogram" && metric.Type != "durationBreakdown" && metric.Type != "lastValue" {
+		errorf("unit isn't supported by %s metrics", metric.Type)
+	} else if metric.Value == nil && !metric.Unit.IsTime() {
+		errorf("durations are measured in seconds, milliseconds or minutes, not %s", metric.Unit)
+	}
+
+	switch metric.Attempts {
+	case "", v1alpha1.AttemptsFinal, v1alpha1.AttemptsFirst:
+	default:
+		errorf("invalid attempts policy %q", metric.Attempts)
+	}
+
+	switch metric.Granularity {
+	case "", v1alpha1.GranularityRun:
+	case v1alpha1.GranularityStep:
+		if (metric.Type != "histogram" && metric.Type != "counter") || metric.Value != nil {
+			errorf("step granularity is only supported by histogram durations and counters")
+		}
+	default:
+		errorf("invalid granularity %q", metric.Granularity)
+	}
+
+	if metric.Match != nil {
+		if _, err := metric.Match.Key.Key(); err != nil {
+			errorf("invalid match key")
+		}
+		if metric.Match.Operator != metav1.LabelSelectorOpIn && metric.Match.Operator != metav1.LabelSelectorOpNotIn {
+			errorf("unsupported match operator %q", metric.Match.Operator)
+		}
+	}
+
+	if metric.CountOver != nil && metric.Type != "histogram" && metric.Type != "durationBreakdown" {
+		errorf("countOver is only supported by histograms and duration breakdowns")
+	}
+	if metric.NearTimeout != nil && (metric.NearTimeout.Percent <= 0 || metric.NearTimeout.Percent > 100) {
+		errorf("nearTimeout percent must be between 1 and 100, got %d", metric.NearTimeout.Percent)
+	}
+
+	for _, pattern := range reasonPatterns(metric.Reasons) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errorf("invalid reason pattern %q: %v", pattern, err)
+		}
+	}
+	return messages
+}
+
+func compile(expression string) error {
+	if expression == "" {
+		return fmt.Errorf("empty expression")
+	}
+	return jsonpath.New("lint").Parse(fmt.Sprintf("{%s}", expression))
+}
+
+func reasonPatterns(reasons []v1alpha1.ReasonNormalization) []string {
+	patterns := []string{}
+	for _, reason := range reasons {
+		patterns = append(patterns, reason.Patterns...)
+	}
+	return patterns
+}
@@ -0,0 +1,84 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"knative.dev/pkg/ptr"
+)
+
+func TestLint(t *testing.T) {
+	monitors := []Monitor{
+		{
+			File:     "a.yaml",
+			Resource: "task",
+			Name:     "hello",
+			Metrics: []v1alpha1.Metric{
+				{
+					Name: "duration",
+					Type: "histogram",
+					Duration: &v1alpha1.MetricHistogramDuration{
+						From: ".status.startTime",
+						To:   ".status.steps[?(@.name==",
+					},
+				},
+				{
+					Name: "status",
+					Type: "counter",
+					By: []v1alpha1.ByStatement{
+						{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: ptr.String("tekton.dev/taskRun")}},
+					},
+				},
+			},
+		},
+		{
+			File:      "b.yaml",
+			Resource:  "task",
+			Name:      "hello",
+			Namespace: "other",
+			Metrics: []v1alpha1.Metric{
+				{Name: "status", Type: "counter"},
+				{Name: "running", Type: "gague"},
+			},
+		},
+	}
+
+	findings := Lint(monitors, Options{MaxDimensions: 4})
+	expected := []struct {
+		severity Severity
+		file     string
+		metric   string
+	}{
+		{SeverityError, "a.yaml", "duration"},
+		{SeverityWarning, "a.yaml", "status"},
+		{SeverityError, "b.yaml", "status"},
+		{SeverityError, "b.yaml", "running"},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %v", len(expected), len(findings), findings)
+	}
+	for i, finding := range findings {
+		if finding.Severity != expected[i].severity || finding.File != expected[i].file || finding.Metric != expected[i].metric {
+			t.Errorf("finding %d: expected %s in %s/%s, got %v", i, expected[i].severity, expected[i].file, expected[i].metric, finding)
+		}
+	}
+}
+
+func TestLintMetricPrefix(t *testing.T) {
+	monitors := []Monitor{
+		{File: "a.yaml", Resource: "task", Name: "build", MetricPrefix: "ci", Metrics: []v1alpha1.Metric{{Name: "runs", Type: "counter"}}},
+		{File: "b.yaml", Resource: "pipeline", Name: "release", MetricPrefix: "ci", Metrics: []v1alpha1.Metric{{Name: "runs", Type: "counter"}}},
+		{File: "c.yaml", Resource: "task", Name: "test", MetricPrefix: "ci-", Metrics: []v1alpha1.Metric{{Name: "status", Type: "counter"}}},
+	}
+
+	findings := Lint(monitors, Options{})
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d: %v", len(findings), findings)
+	}
+	if findings[0].File != "b.yaml" || findings[0].Message != "metric ci_runs_total is also defined by build in a.yaml" {
+		t.Errorf("expected a collision of ci_runs_total, got %v", findings[0])
+	}
+	if findings[1].File != "c.yaml" || findings[1].Metric != "" {
+		t.Errorf("expected an invalid prefix, got %v", findings[1])
+	}
+}
@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/client/clientset/versioned/scheme"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Monitor is the kind independent view of a monitor found in a file
+type Monitor struct {
+	File       string
+	Resource   string
+	Name       string
+	Namespace  string
+	Metrics    []v1alpha1.Metric
+	Reasons    []v1alpha1.ReasonNormalization
+	CommonTags map[string]string
+	// MetricPrefix replaces the resource and monitor name in metric names
+	MetricPrefix string
+	Matches      []string
+	Selector     *metav1.LabelSelector
+}
+
+// Load reads the monitors of every YAML or JSON file in the given paths,
+// directories are walked recursively and other kinds are ignored.
+func Load(paths ...string) ([]Monitor, error) {
+	monitors := []Monitor{}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !isManifest(file) {
+				return nil
+			}
+			found, err := LoadFile(file)
+			if err != nil {
+				return err
+			}
+			monitors = append(monitors, found...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return monitors, nil
+}
+
+// LoadFile reads the monitors of a, possibly multi document, YAML or JSON file
+func LoadFile(file string) ([]Monitor, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	monitors := []Monitor{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		raw := runtime.RawExtension{}
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return monitors, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(raw.Raw) == 0 {
+			continue
+		}
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(raw.Raw, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if monitor, ok := toMonitor(file, obj); ok {
+			monitors = append(monitors, monitor)
+		}
+	}
+}
+
+func toMonitor(file string, obj runtime.Object) (Monitor, bool) {
+	switch m := obj.(type) {
+	case *v1alpha1.TaskMonitor:
+		return Monitor{File: file, Resource: "task", Name: m.Name, Namespace: m.Namespace, Metrics: m.Spec.Metrics, Reasons: m.Spec.Reasons, CommonTags: m.Spec.CommonTags, MetricPrefix: m.Spec.MetricPrefix, Matches: m.Spec.Matches, Selector: m.Spec.Selector}, true
+	case *v1alpha1.ClusterTaskMonitor:
+		return Monitor{File: file, Resource: naming.ClusterResource("task"), Name: m.Name, Metrics: m.Spec.Metrics, Reasons: m.Spec.Reasons, CommonTags: m.Spec.CommonTags, MetricPrefix: m.Spec.MetricPrefix, Matches: m.Spec.Matches, Selector: m.Spec.Selector}, true
+	case *v1alpha1.TaskRunMonitor:
+		return Monitor{File: file, Resource: "taskrun", Name: m.Name, Namespace: m.Namespace, Metrics: m.Spec.Metrics, Reasons: m.Spec.Reasons, CommonTags: m.Spec.CommonTags, MetricPrefix: m.Spec.MetricPrefix, Selector: &m.Spec.Selector}, true
+	case *v1alpha1.PipelineMonitor:
+		return Monitor{File: file, Resource: "pipeline", Name: m.Name, Namespace: m.Namespace, Metrics: m.Spec.Metrics, Reasons: m.Spec.Reasons, CommonTags: m.Spec.CommonTags, MetricPrefix: m.Spec.MetricPrefix, Matches: m.Spec.Matches, Selector: m.Spec.Selector}, true
+	case *v1alpha1.PipelineRunMonitor:
+		return Monitor{File: file, Resource: "pipelinerun", Name: m.Name, Namespace: m.Namespace, Metrics: m.Spec.Metrics, Reasons: m.Spec.Reasons, CommonTags: m.Spec.CommonTags, MetricPrefix: m.Spec.MetricPrefix, Selector: &m.Spec.Selector}, true
+	default:
+		return Monitor{}, false
+	}
+}
+
+func isManifest(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+package lint
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Run lints the monitors of the files and directories of the lint command
+// line, findings are printed to out. It returns the exit code of the command:
+// 1 when a finding is an error, or a warning with --strict, and 2 on usage
+// errors.
+func Run(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	maxDimensions := flags.Int("max-dimensions", 4, "Dimensions per metric before a cardinality warning is reported, 0 disables it.")
+	strict := flags.Bool("strict", false, "Fail on warnings.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "lint requires at least one file or directory")
+		return 2
+	}
+
+	monitors, err := Load(flags.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	failed := false
+	for _, finding := range Lint(monitors, Options{MaxDimensions: *maxDimensions}) {
+		fmt.Fprintln(out, finding)
+		if finding.Severity == SeverityError || *strict {
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
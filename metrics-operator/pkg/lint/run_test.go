@@ -0,0 +1,53 @@
+package lint
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const perRunMonitor = `apiVersion: metrics.tekton.dev/v1alpha1
+kind: TaskMonitor
+metadata:
+  name: hello
+spec:
+  taskName: hello
+  metrics:
+  - name: status
+    type: counter
+    by:
+    - label: tekton.dev/taskRun
+`
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "monitor.yaml")
+	if err := os.WriteFile(file, []byte(perRunMonitor), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{name: "warnings pass", args: []string{dir}, wantCode: 0, wantOut: file + ": warning: task/hello/status:"},
+		{name: "warnings fail when strict", args: []string{"--strict", dir}, wantCode: 1, wantOut: file + ": warning: task/hello/status:"},
+		{name: "unknown flag", args: []string{"--unknown", dir}, wantCode: 2},
+		{name: "no path", wantCode: 2},
+		{name: "missing path", args: []string{filepath.Join(dir, "missing")}, wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if code := Run(tt.args, out); code != tt.wantCode {
+				t.Errorf("want exit code %d, got %d", tt.wantCode, code)
+			}
+			if !strings.HasPrefix(out.String(), tt.wantOut) {
+				t.Errorf("want output starting with %q, got %q", tt.wantOut, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// ActivePipelinesMonitorName is the monitor name used in the name of the
+// active pipelines gauge
+const ActivePipelinesMonitorName = "active"
+
+// activePipelines counts the distinct pipelines with a run created within the
+// window, by namespace
+type activePipelines struct {
+	window  time.Duration
+	measure *stats.Float64Measure
+	view    *view.View
+	// created holds the creation time of the last run of every pipeline by
+	// namespace
+	created map[string]map[string]time.Time
+	// reported holds the namespaces of the last report
+	reported map[string]int
+	rw       sync.Mutex
+}
+
+func newActivePipelines(window time.Duration) *activePipelines {
+	a := &activePipelines{
+		window:  window,
+		created: map[string]map[string]time.Time{},
+	}
+	a.measure = stats.Float64(naming.GaugeMetric("pipelinerun", ActivePipelinesMonitorName, "pipelines"), "distinct pipelines with a run created within "+window.String(), stats.UnitDimensionless)
+	a.view = &view.View{
+		Description: a.measure.Description(),
+		Measure:     a.measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{namespaceKey},
+	}
+	return a
+}
+
+// pipelineOf returns the name of the pipeline of the run, false for runs of
+// embedded specs
+func pipelineOf(pipelineRun *pipelinev1beta1.PipelineRun) (string, bool) {
+	if name, exists := pipelineRun.Labels["tekton.dev/pipeline"]; exists {
+		return name, true
+	}
+	if ref := pipelineRun.Spec.PipelineRef; ref != nil && ref.Name != "" {
+		return ref.Name, true
+	}
+	return "", false
+}
+
+// EnableActivePipelines registers the active pipelines gauge, a pipeline is
+// active while it has a run created within the window
+func (m *MetricManager) EnableActivePipelines(window time.Duration) error {
+	active := newActivePipelines(window)
+	if err := m.Index.external.Register(active.view); err != nil {
+		return err
+	}
+	m.activePipelines = active
+	return nil
+}
+
+// observePipeline marks the pipeline of the run active when active pipelines
+// are enabled
+func (m *MetricManager) observePipeline(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) {
+	a := m.activePipelines
+	if a == nil {
+		return
+	}
+	pipeline, ok := pipelineOf(pipelineRun)
+	if !ok {
+		return
+	}
+	created := pipelineRun.CreationTimestamp.Time
+	a.rw.Lock()
+	pipelines, exists := a.created[pipelineRun.Namespace]
+	if !exists {
+		pipelines = map[string]time.Time{}
+		a.created[pipelineRun.Namespace] = pipelines
+	}
+	last, seen := pipelines[pipeline]
+	if seen && !created.After(last) {
+		a.rw.Unlock()
+		return
+	}
+	pipelines[pipeline] = created
+	a.rw.Unlock()
+	m.recordActivePipelines(ctx, time.Now())
+}
+
+// RunActivePipelinesLoop records the active pipelines at each interval, so
+// pipelines leave the gauge once their last run is out of the window
+func (m *MetricManager) RunActivePipelinesLoop(ctx context.Context, interval time.Duration) {
+	if m.activePipelines == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.recordActivePipelines(ctx, now)
+		}
+	}
+}
+
+func (m *MetricManager) recordActivePipelines(ctx context.Context, now time.Time) {
+	a := m.activePipelines
+	a.rw.Lock()
+	defer a.rw.Unlock()
+	counts := map[string]int{}
+	for namespace, pipelines := range a.created {
+		for pipeline, created := range pipelines {
+			if now.Sub(created) > a.window {
+				delete(pipelines, pipeline)
+				continue
+			}
+			counts[namespace]++
+		}
+		if len(pipelines) == 0 {
+			delete(a.created, namespace)
+		}
+	}
+	// namespaces without active pipeline are reported once more with zero
+	for namespace := range a.reported {
+		if _, exists := counts[namespace]; !exists {
+			m.recordActiveCount(ctx, namespace, 0)
+		}
+	}
+	for namespace, count := range counts {
+		m.recordActiveCount(ctx, namespace, count)
+	}
+	a.reported = counts
+}
+
+func (m *MetricManager) recordActiveCount(ctx context.Context, namespace string, count int) {
+	tagCtx, err := tag.New(ctx, tag.Upsert(namespaceKey, namespace))
+	if err != nil {
+		return
+	}
+	m.Index.external.Record(tag.FromContext(tagCtx), []stats.Measurement{m.activePipelines.measure.M(float64(count))}, map[string]any{})
+}
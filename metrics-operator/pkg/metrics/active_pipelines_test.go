@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestActivePipelines(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	if err := manager.EnableActivePipelines(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	active := func() map[string]float64 {
+		t.Helper()
+		rows, err := external.RetrieveData(manager.activePipelines.measure.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]float64{}
+		for _, row := range rows {
+			for _, tag := range row.Tags {
+				if tag.Key == namespaceKey {
+					got[tag.Value] = row.Data.(*view.LastValueData).Value
+				}
+			}
+		}
+		return got
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	pipelineRun := func(namespace, pipeline string, age time.Duration) *v1beta1.PipelineRun {
+		pipelineRun := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			CreationTimestamp: metav1.Time{Time: now.Add(-age)},
+			Labels:            map[string]string{"tekton.dev/pipeline": pipeline},
+		}}
+		if pipeline == "" {
+			pipelineRun.Labels = nil
+		}
+		return pipelineRun
+	}
+	build := pipelineRun("dev", "", 20*time.Minute)
+	build.Spec.PipelineRef = &v1beta1.PipelineRef{Name: "build"}
+	// the run of an embedded spec has no pipeline, the run of prod is out of
+	// the window
+	for _, pr := range []*v1beta1.PipelineRun{
+		pipelineRun("dev", "release", 10*time.Minute),
+		pipelineRun("dev", "release", 5*time.Minute),
+		build,
+		pipelineRun("dev", "", time.Minute),
+		pipelineRun("prod", "release", 2*time.Hour),
+	} {
+		manager.observePipeline(ctx, pr)
+	}
+	if diff := cmp.Diff(map[string]float64{"dev": 2}, active()); diff != "" {
+		t.Errorf("active pipelines (-want, +got):\n%s", diff)
+	}
+
+	// the pipelines leave the gauge an hour after their last run
+	manager.recordActivePipelines(ctx, now.Add(50*time.Minute))
+	if diff := cmp.Diff(map[string]float64{"dev": 1}, active()); diff != "" {
+		t.Errorf("active pipelines (-want, +got):\n%s", diff)
+	}
+	manager.recordActivePipelines(ctx, now.Add(2*time.Hour))
+	if diff := cmp.Diff(map[string]float64{"dev": 0}, active()); diff != "" {
+		t.Errorf("active pipelines (-want, +got):\n%s", diff)
+	}
+}
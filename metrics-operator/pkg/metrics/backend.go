@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// MetricBackend exports the metrics of monitors. Metrics are registered by
+// name before samples are recorded, a counter adds the value of every sample,
+// a histogram observes it and a gauge is set to it. The recorders build
+// opencensus views, the index registers them in the backend and translates
+// their measurements, so backends don't depend on opencensus.
+type MetricBackend interface {
+	RegisterCounter(descriptor MetricDescriptor) error
+	RegisterHistogram(descriptor MetricDescriptor) error
+	RegisterGauge(descriptor MetricDescriptor) error
+	Record(ctx context.Context, name string, tags map[string]string, value float64) error
+	Unregister(names ...string)
+}
+
+// MetricDescriptor describes a metric registered in a backend
+type MetricDescriptor struct {
+	Name        string
+	Description string
+	Unit        string
+	// TagKeys are the names of the tags of every sample of the metric
+	TagKeys []string
+	// Buckets are the bucket boundaries of histograms
+	Buckets []float64
+}
+
+// UseBackend registers and records the views of monitor metrics in the
+// backend instead of the meter, it must be called before batched recording is
+// enabled. Snapshots and cardinality reports are read from the meter and don't
+// include monitor metrics once a backend is used.
+func (m *MetricManager) UseBackend(backend MetricBackend) {
+	m.Index.rw.Lock()
+	defer m.Index.rw.Unlock()
+	m.Index.backend = &backendViews{
+		backend: backend,
+		views:   map[string]*view.View{},
+	}
+}
+
+// backendViews keeps the views registered in a backend, the measurements of
+// the recorders are matched to the views of their measure
+type backendViews struct {
+	backend MetricBackend
+	views   map[string]*view.View
+	rw      sync.RWMutex
+}
+
+func descriptorOf(v *view.View) MetricDescriptor {
+	descriptor := MetricDescriptor{
+		Name:        viewName(v),
+		Description: viewDescription(v),
+		Unit:        v.Measure.Unit(),
+		TagKeys:     tagKeyNames(v),
+	}
+	if v.Aggregation != nil {
+		descriptor.Buckets = v.Aggregation.Buckets
+	}
+	return descriptor
+}
+
+func (b *backendViews) find(name string) *view.View {
+	b.rw.RLock()
+	defer b.rw.RUnlock()
+	return b.views[name]
+}
+
+// register registers the views by the type of their aggregation, counts and
+// sums are counters, distributions histograms and last values gauges
+func (b *backendViews) register(views ...*view.View) error {
+	b.rw.Lock()
+	defer b.rw.Unlock()
+	for _, v := range views {
+		if v.Aggregation == nil {
+			return fmt.Errorf("view %s has no aggregation", viewName(v))
+		}
+		descriptor := descriptorOf(v)
+		var err error
+		switch v.Aggregation.Type {
+		case view.AggTypeCount, view.AggTypeSum:
+			err = b.backend.RegisterCounter(descriptor)
+		case view.AggTypeDistribution:
+			err = b.backend.RegisterHistogram(descriptor)
+		case view.AggTypeLastValue:
+			err = b.backend.RegisterGauge(descriptor)
+		default:
+			err = fmt.Errorf("unsupported aggregation %s", v.Aggregation.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("error registering %s: %w", descriptor.Name, err)
+		}
+		b.views[descriptor.Name] = v
+	}
+	return nil
+}
+
+func (b *backendViews) unregister(views ...*view.View) {
+	b.rw.Lock()
+	defer b.rw.Unlock()
+	names := make([]string, 0, len(views))
+	for _, v := range views {
+		names = append(names, viewName(v))
+		delete(b.views, viewName(v))
+	}
+	if len(names) > 0 {
+		b.backend.Unregister(names...)
+	}
+}
+
+// Record implements stats.Recorder, every measurement is recorded in the
+// views of its measure with the tags of the view. Counts count samples, the
+// value of the measurement is ignored.
+func (b *backendViews) Record(tagMap *tag.Map, measurements any, attachments map[string]any) {
+	b.record(tagMap, measurements, nil)
+}
+
+// record writes the measurements to the backend, failed writes are collected
+// as retryable when writes is set and logged otherwise
+func (b *backendViews) record(tagMap *tag.Map, measurements any, writes *failedWrites) {
+	values, ok := measurements.([]stats.Measurement)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	b.rw.RLock()
+	defer b.rw.RUnlock()
+	for _, measurement := range values {
+		for name, v := range b.views {
+			if v.Measure.Name() != measurement.Measure().Name() {
+				continue
+			}
+			value := measurement.Value()
+			if v.Aggregation.Type == view.AggTypeCount {
+				value = 1
+			}
+			name, tags := name, viewTags(v, tagMap)
+			write := func(ctx context.Context) error {
+				if err := b.backend.Record(ctx, name, tags, value); err != nil {
+					return recorder.Retryable(fmt.Errorf("backend failed to record %s: %w", name, err))
+				}
+				return nil
+			}
+			if err := write(ctx); err != nil && !writes.add(err, write) {
+				logging.FromContext(ctx).Errorw("backend failed to record", zap.String("metric", name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// trackedBackend records in the backend and collects the failed writes of a
+// single recording
+type trackedBackend struct {
+	*backendViews
+	writes *failedWrites
+}
+
+func (t *trackedBackend) Record(tagMap *tag.Map, measurements any, attachments map[string]any) {
+	t.backendViews.record(tagMap, measurements, t.writes)
+}
+
+// viewTags returns the tags of the tag map kept by the view, tag maps can't be
+// iterated
+func viewTags(v *view.View, tagMap *tag.Map) map[string]string {
+	tags := map[string]string{}
+	for _, key := range v.TagKeys {
+		if value, ok := tagMap.Value(key); ok {
+			tags[key.Name()] = value
+		}
+	}
+	return tags
+}
+
+// findView returns the registered view of the name, in the backend when used
+func (m *MetricIndex) findView(name string) *view.View {
+	if m.backend != nil {
+		return m.backend.find(name)
+	}
+	return m.external.Find(name)
+}
+
+func (m *MetricIndex) registerViews(views ...*view.View) error {
+	if m.backend != nil {
+		return m.backend.register(views...)
+	}
+	return m.external.Register(views...)
+}
+
+func (m *MetricIndex) unregisterViews(views ...*view.View) {
+	if m.backend != nil {
+		m.backend.unregister(views...)
+		return
+	}
+	m.external.Unregister(views...)
+}
+
+// output returns where the samples of monitor metrics are recorded, the
+// backend when used
+func (m *MetricIndex) output() stats.Recorder {
+	if m.backend != nil {
+		return m.backend
+	}
+	return m.external
+}
+
+// trackedOutput returns the output collecting its failed writes, batched
+// samples are written on flush and their failures only logged
+func (m *MetricIndex) trackedOutput(writes *failedWrites) stats.Recorder {
+	if m.batch == nil && m.backend != nil {
+		return &trackedBackend{backendViews: m.backend, writes: writes}
+	}
+	return m.recording()
+}
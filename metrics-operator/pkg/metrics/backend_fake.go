@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeSample is a sample recorded by the fake backend
+type FakeSample struct {
+	Tags  map[string]string
+	Value float64
+}
+
+// FakeBackend keeps the registered metrics and their samples in memory, for
+// tests that don't want opencensus global state
+type FakeBackend struct {
+	// Kinds of the registered metrics by name: counter, histogram or gauge
+	Kinds       map[string]string
+	Descriptors map[string]MetricDescriptor
+	samples     map[string][]FakeSample
+	rw          sync.Mutex
+}
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		Kinds:       map[string]string{},
+		Descriptors: map[string]MetricDescriptor{},
+		samples:     map[string][]FakeSample{},
+	}
+}
+
+func (f *FakeBackend) RegisterCounter(descriptor MetricDescriptor) error {
+	return f.register("counter", descriptor)
+}
+
+func (f *FakeBackend) RegisterHistogram(descriptor MetricDescriptor) error {
+	return f.register("histogram", descriptor)
+}
+
+func (f *FakeBackend) RegisterGauge(descriptor MetricDescriptor) error {
+	return f.register("gauge", descriptor)
+}
+
+func (f *FakeBackend) register(kind string, descriptor MetricDescriptor) error {
+	f.rw.Lock()
+	defer f.rw.Unlock()
+	if existing, exists := f.Kinds[descriptor.Name]; exists {
+		return fmt.Errorf("metric %s is already registered as a %s", descriptor.Name, existing)
+	}
+	f.Kinds[descriptor.Name] = kind
+	f.Descriptors[descriptor.Name] = descriptor
+	return nil
+}
+
+func (f *FakeBackend) Record(ctx context.Context, name string, tags map[string]string, value float64) error {
+	f.rw.Lock()
+	defer f.rw.Unlock()
+	if _, exists := f.Kinds[name]; !exists {
+		return fmt.Errorf("metric %s isn't registered", name)
+	}
+	f.samples[name] = append(f.samples[name], FakeSample{Tags: tags, Value: value})
+	return nil
+}
+
+func (f *FakeBackend) Unregister(names ...string) {
+	f.rw.Lock()
+	defer f.rw.Unlock()
+	for _, name := range names {
+		delete(f.Kinds, name)
+		delete(f.Descriptors, name)
+		delete(f.samples, name)
+	}
+}
+
+// Samples returns the samples recorded by the metric since its registration
+func (f *FakeBackend) Samples(name string) []FakeSample {
+	f.rw.Lock()
+	defer f.rw.Unlock()
+	return append([]FakeSample{}, f.samples[name]...)
+}
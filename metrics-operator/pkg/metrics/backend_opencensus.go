@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// openCensusBackend registers a view per metric in a meter, for exporters
+// reading opencensus views
+type openCensusBackend struct {
+	meter    view.Meter
+	measures map[string]*stats.Float64Measure
+	rw       sync.RWMutex
+}
+
+// NewOpenCensusBackend returns a backend registering views in the meter
+func NewOpenCensusBackend(meter view.Meter) MetricBackend {
+	return &openCensusBackend{
+		meter:    meter,
+		measures: map[string]*stats.Float64Measure{},
+	}
+}
+
+func (b *openCensusBackend) RegisterCounter(descriptor MetricDescriptor) error {
+	return b.register(descriptor, view.Sum())
+}
+
+func (b *openCensusBackend) RegisterHistogram(descriptor MetricDescriptor) error {
+	return b.register(descriptor, view.Distribution(descriptor.Buckets...))
+}
+
+func (b *openCensusBackend) RegisterGauge(descriptor MetricDescriptor) error {
+	return b.register(descriptor, view.LastValue())
+}
+
+func (b *openCensusBackend) register(descriptor MetricDescriptor, aggregation *view.Aggregation) error {
+	keys := make([]tag.Key, 0, len(descriptor.TagKeys))
+	for _, name := range descriptor.TagKeys {
+		key, err := tag.NewKey(name)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	measure := stats.Float64(descriptor.Name, descriptor.Description, descriptor.Unit)
+	if err := b.meter.Register(&view.View{
+		Name:        descriptor.Name,
+		Description: descriptor.Description,
+		Measure:     measure,
+		Aggregation: aggregation,
+		TagKeys:     keys,
+	}); err != nil {
+		return err
+	}
+	b.rw.Lock()
+	defer b.rw.Unlock()
+	b.measures[descriptor.Name] = measure
+	return nil
+}
+
+func (b *openCensusBackend) Record(ctx context.Context, name string, tags map[string]string, value float64) error {
+	b.rw.RLock()
+	measure, exists := b.measures[name]
+	b.rw.RUnlock()
+	if !exists {
+		return fmt.Errorf("metric %s isn't registered", name)
+	}
+	mutators := make([]tag.Mutator, 0, len(tags))
+	for name, value := range tags {
+		key, err := tag.NewKey(name)
+		if err != nil {
+			return err
+		}
+		mutators = append(mutators, tag.Upsert(key, value))
+	}
+	tagMap, err := tag.New(ctx, mutators...)
+	if err != nil {
+		return err
+	}
+	b.meter.Record(tag.FromContext(tagMap), []stats.Measurement{measure.M(value)}, map[string]any{})
+	return nil
+}
+
+func (b *openCensusBackend) Unregister(names ...string) {
+	b.rw.Lock()
+	defer b.rw.Unlock()
+	for _, name := range names {
+		if v := b.meter.Find(name); v != nil {
+			b.meter.Unregister(v)
+		}
+		delete(b.measures, name)
+	}
+}
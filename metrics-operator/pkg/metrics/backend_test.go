@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+)
+
+func TestMetricBackend(t *testing.T) {
+	// the meter only gets the self metrics
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	backend := NewFakeBackend()
+	manager := NewManager(meter, nil)
+	manager.UseBackend(backend)
+	index := manager.GetIndex()
+
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{
+				{
+					Name: "status",
+					Type: "counter",
+					By: []v1alpha1.ByStatement{
+						{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: ptr.String("Succeeded")}},
+					},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	if err := index.RegisterRunMetric(ctx, counter); err != nil {
+		t.Fatal(err)
+	}
+	if kind := backend.Kinds["task_hello_status_total"]; kind != "counter" {
+		t.Fatalf("want the metric registered as a counter, got %q", kind)
+	}
+	if meter.Find("task_hello_status_total") != nil {
+		t.Error("want the metric registered in the backend only")
+	}
+	if registered, _, err := index.IsRegistered(counter); err != nil || !registered {
+		t.Errorf("want the metric registered, got %v, %v", registered, err)
+	}
+
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello-world-xpto0",
+			Namespace: "dev",
+			Labels:    map[string]string{"tekton.dev/task": "hello-world"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				},
+			},
+		},
+	}
+	index.Record(ctx, recorder.TaskRunDimensions(taskRun), "counter")
+	want := []FakeSample{{Tags: map[string]string{"status": "success"}, Value: 1}}
+	if diff := cmp.Diff(want, backend.Samples("task_hello_status_total")); diff != "" {
+		t.Errorf("samples (-want, +got):\n%s", diff)
+	}
+
+	if err := index.UnregisterRunMetric(counter); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := backend.Kinds["task_hello_status_total"]; exists {
+		t.Error("want the metric unregistered from the backend")
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+type BatchOptions struct {
+	// Size is the number of buffered measurements flushing the batch before
+	// the interval
+	Size int
+	// FlushInterval between two flushes of the buffered measurements
+	FlushInterval time.Duration
+}
+
+// batchedRecorder buffers the measurements of monitor metrics and records
+// them per tag map on flush, so a burst of runs costs one pass through the
+// meter per tag set instead of one per sample
+type batchedRecorder struct {
+	recorder stats.Recorder
+	options  BatchOptions
+
+	// pending measurements by tag map, flushed in recording order
+	pending map[string]*pendingBatch
+	order   []string
+	size    int
+	mu      sync.Mutex
+
+	// full is signaled when the size is reached
+	full chan struct{}
+}
+
+type pendingBatch struct {
+	tags         *tag.Map
+	measurements []stats.Measurement
+}
+
+func newBatchedRecorder(recorder stats.Recorder, options BatchOptions) *batchedRecorder {
+	if options.Size <= 0 {
+		options.Size = 1000
+	}
+	return &batchedRecorder{
+		recorder: recorder,
+		options:  options,
+		pending:  map[string]*pendingBatch{},
+		full:     make(chan struct{}, 1),
+	}
+}
+
+// Record implements stats.Recorder, measurements with attachments are
+// recorded right away as they can't be merged
+func (b *batchedRecorder) Record(tags *tag.Map, measurements any, attachments map[string]any) {
+	values, ok := measurements.([]stats.Measurement)
+	if !ok || len(attachments) > 0 {
+		b.recorder.Record(tags, measurements, attachments)
+		return
+	}
+	key := ""
+	if tags != nil {
+		key = tags.String()
+	}
+	b.mu.Lock()
+	pending, exists := b.pending[key]
+	if !exists {
+		pending = &pendingBatch{tags: tags}
+		b.pending[key] = pending
+		b.order = append(b.order, key)
+	}
+	pending.measurements = append(pending.measurements, values...)
+	b.size += len(values)
+	full := b.size >= b.options.Size
+	b.mu.Unlock()
+	if full {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// EnableBatchedRecording buffers the samples of monitor metrics until the
+// next flush, run by RunBatchLoop. Samples show up in the exported views up
+// to an interval late.
+func (m *MetricManager) EnableBatchedRecording(options BatchOptions) {
+	m.Index.batch = newBatchedRecorder(m.Index.output(), options)
+}
+
+// RunBatchLoop flushes the batched samples until the context is done, a no-op
+// unless batched recording is enabled
+func (m *MetricManager) RunBatchLoop(ctx context.Context) {
+	if m.Index.batch == nil {
+		return
+	}
+	m.Index.batch.Run(ctx)
+}
+
+// recording returns the recorder of monitor metrics, the batch when enabled
+func (m *MetricIndex) recording() stats.Recorder {
+	if m.batch == nil {
+		return m.output()
+	}
+	return m.batch
+}
+
+// flush records the buffered measurements, one record per tag map
+func (b *batchedRecorder) flush() {
+	b.mu.Lock()
+	pending, order := b.pending, b.order
+	b.pending, b.order, b.size = map[string]*pendingBatch{}, nil, 0
+	b.mu.Unlock()
+	for _, key := range order {
+		batch := pending[key]
+		b.recorder.Record(batch.tags, batch.measurements, map[string]any{})
+	}
+}
+
+// Run flushes the batch at every interval or once full, until the context is
+// done and the remaining measurements are flushed
+func (b *batchedRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			b.flush()
+			return
+		case <-ticker.C:
+			b.flush()
+		case <-b.full:
+			b.flush()
+		}
+	}
+}
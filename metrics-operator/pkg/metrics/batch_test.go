@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+type recordedBatch struct {
+	tags         string
+	measurements int
+}
+
+type fakeRecorder struct {
+	records []recordedBatch
+	mu      sync.Mutex
+}
+
+func (f *fakeRecorder) Record(tags *tag.Map, measurements any, attachments map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records = append(f.records, recordedBatch{tags: tags.String(), measurements: len(measurements.([]stats.Measurement))})
+}
+
+func (f *fakeRecorder) get() []recordedBatch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]recordedBatch{}, f.records...)
+}
+
+func TestBatchedRecorder(t *testing.T) {
+	measure := stats.Float64("batch_test", "", stats.UnitDimensionless)
+	key := tag.MustNewKey("task")
+	tags := func(value string) *tag.Map {
+		ctx, err := tag.New(context.Background(), tag.Upsert(key, value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tag.FromContext(ctx)
+	}
+
+	fake := &fakeRecorder{}
+	batch := newBatchedRecorder(fake, BatchOptions{Size: 4, FlushInterval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		batch.Run(ctx)
+		close(done)
+	}()
+
+	for _, task := range []string{"build", "test", "build"} {
+		batch.Record(tags(task), []stats.Measurement{measure.M(1)}, map[string]any{})
+	}
+	if records := fake.get(); len(records) != 0 {
+		t.Fatalf("want nothing recorded before the batch is full, got %v", records)
+	}
+
+	batch.Record(tags("build"), []stats.Measurement{measure.M(1)}, map[string]any{})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fake.get()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	want := []recordedBatch{{tags: tags("build").String(), measurements: 3}, {tags: tags("test").String(), measurements: 1}}
+	if records := fake.get(); len(records) != 2 || records[0] != want[0] || records[1] != want[1] {
+		t.Errorf("want a record per tag map %v, got %v", want, records)
+	}
+
+	batch.Record(tags("test"), []stats.Measurement{measure.M(1)}, map[string]any{})
+	cancel()
+	<-done
+	if records := fake.get(); len(records) != 3 {
+		t.Errorf("want the remaining sample flushed on shutdown, got %v", records)
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerOptions configures the suspension of persistently failing metrics
+type BreakerOptions struct {
+	// Threshold of consecutive failures suspending a metric
+	Threshold int
+	// InitialBackoff before a suspended metric is attempted again, doubled
+	// every time the attempt fails
+	InitialBackoff time.Duration
+	// MaxBackoff between two attempts of a suspended metric
+	MaxBackoff time.Duration
+}
+
+// breaker counts the consecutive failures of a metric
+type breaker struct {
+	failures  int
+	backoff   time.Duration
+	openUntil time.Time
+	lastError string
+}
+
+// breakerStore holds the breakers of every failing metric by name, a broken
+// JSONPath fails on every run and would otherwise be evaluated and logged on
+// every event
+type breakerStore struct {
+	options BreakerOptions
+	m       map[string]*breaker
+	rw      sync.Mutex
+}
+
+// EnableCircuitBreaker suspends metrics failing more than the threshold in a
+// row, they're attempted again with exponential backoff
+func (m *MetricManager) EnableCircuitBreaker(options BreakerOptions) {
+	m.Index.breakers = &breakerStore{options: options, m: map[string]*breaker{}}
+}
+
+// allow returns false while the metric is suspended
+func (s *breakerStore) allow(metricName string, now time.Time) bool {
+	if s == nil {
+		return true
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	b, exists := s.m[metricName]
+	return !exists || !now.Before(b.openUntil)
+}
+
+// observe counts the outcome of an attempt, returns true when it suspends
+// the metric
+func (s *breakerStore) observe(metricName string, err error, now time.Time) bool {
+	if s == nil {
+		return false
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if err == nil {
+		delete(s.m, metricName)
+		return false
+	}
+	b, exists := s.m[metricName]
+	if !exists {
+		b = &breaker{}
+		s.m[metricName] = b
+	}
+	b.failures++
+	b.lastError = err.Error()
+	if b.failures < s.options.Threshold {
+		return false
+	}
+	switch {
+	case b.backoff == 0:
+		b.backoff = s.options.InitialBackoff
+	case b.backoff*2 > s.options.MaxBackoff:
+		b.backoff = s.options.MaxBackoff
+	default:
+		b.backoff *= 2
+	}
+	b.openUntil = now.Add(b.backoff)
+	return true
+}
+
+// suspended returns the breaker of the metric when it's suspended
+func (s *breakerStore) suspended(metricName string) (breaker, bool) {
+	if s == nil {
+		return breaker{}, false
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	b, exists := s.m[metricName]
+	if !exists || b.failures < s.options.Threshold {
+		return breaker{}, false
+	}
+	return *b, true
+}
+
+func (s *breakerStore) reset(metricName string) {
+	if s == nil {
+		return
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	delete(s.m, metricName)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerStore(t *testing.T) {
+	store := &breakerStore{
+		options: BreakerOptions{Threshold: 3, InitialBackoff: time.Minute, MaxBackoff: 3 * time.Minute},
+		m:       map[string]*breaker{},
+	}
+	now := time.Now()
+	failure := errors.New("broken jsonpath")
+
+	for i := 0; i < 2; i++ {
+		if store.observe("duration", failure, now) {
+			t.Fatalf("suspended after %d failures", i+1)
+		}
+	}
+	if !store.observe("duration", failure, now) {
+		t.Fatal("not suspended after reaching the threshold")
+	}
+	if store.allow("duration", now.Add(59*time.Second)) {
+		t.Error("allowed before the backoff elapsed")
+	}
+	if !store.allow("duration", now.Add(time.Minute)) {
+		t.Error("not allowed after the backoff elapsed")
+	}
+	if b, suspended := store.suspended("duration"); !suspended || b.lastError != failure.Error() {
+		t.Errorf("got suspended %v with last error %q", suspended, b.lastError)
+	}
+
+	// failed attempts double the backoff up to the maximum
+	for _, want := range []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		store.observe("duration", failure, now)
+		if b, _ := store.suspended("duration"); b.backoff != want {
+			t.Errorf("backoff %s, want %s", b.backoff, want)
+		}
+	}
+
+	store.observe("duration", nil, now)
+	if _, suspended := store.suspended("duration"); suspended {
+		t.Error("still suspended after a success")
+	}
+	if !store.allow("duration", now) {
+		t.Error("not allowed after a success")
+	}
+
+	var disabled *breakerStore
+	if !disabled.allow("duration", now) || disabled.observe("duration", failure, now) {
+		t.Error("disabled breakers must allow every attempt")
+	}
+}
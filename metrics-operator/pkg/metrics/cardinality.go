@@ -0,0 +1,220 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// CardinalityReport counts the active series of a view and the values of
+// each of its tags
+type CardinalityReport struct {
+	Metric string           `json:"metric"`
+	Series int              `json:"series"`
+	Tags   []TagCardinality `json:"tags"`
+}
+
+// TagCardinality counts the distinct values of a tag, Top lists the values
+// found in the most series
+type TagCardinality struct {
+	Key    string       `json:"key"`
+	Values int          `json:"values"`
+	Top    []ValueCount `json:"top"`
+}
+
+type ValueCount struct {
+	Value  string `json:"value"`
+	Series int    `json:"series"`
+}
+
+// Cardinality reports the views of the manager by decreasing number of
+// series, with the top values of each tag, restricted to the view with the
+// given name when not empty
+func (m *MetricManager) Cardinality(name string, top int) ([]CardinalityReport, error) {
+	reports := []CardinalityReport{}
+	for _, v := range m.views() {
+		if name != "" && v.Name != name {
+			continue
+		}
+		rows, err := m.Index.external.RetrieveData(v.Name)
+		if err != nil {
+			return nil, err
+		}
+		report := CardinalityReport{Metric: v.Name, Series: len(rows), Tags: []TagCardinality{}}
+		for _, key := range v.TagKeys {
+			counts := map[string]int{}
+			for _, row := range rows {
+				for _, t := range row.Tags {
+					if t.Key == key {
+						counts[t.Value]++
+					}
+				}
+			}
+			report.Tags = append(report.Tags, tagCardinality(key.Name(), counts, top))
+		}
+		reports = append(reports, report)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Series > reports[j].Series
+	})
+	return reports, nil
+}
+
+func tagCardinality(key string, counts map[string]int, top int) TagCardinality {
+	values := make([]ValueCount, 0, len(counts))
+	for value, series := range counts {
+		values = append(values, ValueCount{Value: value, Series: series})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Series != values[j].Series {
+			return values[i].Series > values[j].Series
+		}
+		return values[i].Value < values[j].Value
+	})
+	if top >= 0 && len(values) > top {
+		values = values[:top]
+	}
+	return TagCardinality{Key: key, Values: len(counts), Top: values}
+}
+
+// CardinalityHandler serves the cardinality report as JSON, the metric query
+// parameter restricts it to a single view and top sets the number of values
+// listed per tag, 10 by default
+func (m *MetricManager) CardinalityHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		top := 10
+		if value := r.URL.Query().Get("top"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, "invalid top: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			top = parsed
+		}
+		reports, err := m.Cardinality(r.URL.Query().Get("metric"), top)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(reports)
+	})
+}
+
+// RunSeriesLoop records the number of series of every monitor metric at each
+// interval, to alert on a growing cardinality before limits are hit
+func (m *MetricManager) RunSeriesLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// reported holds the views of the last report, a view no longer
+	// registered is reported once more with no series
+	reported := map[string]string{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reported = m.recordActiveSeries(ctx, reported)
+		}
+	}
+}
+
+func (m *MetricManager) recordActiveSeries(ctx context.Context, reported map[string]string) map[string]string {
+	monitors := map[string]string{}
+	for _, runMetric := range m.Index.store.List() {
+		for _, v := range runMetricViews(runMetric) {
+			monitors[v.Name] = runMetric.MonitorId()
+		}
+	}
+
+	for name, monitor := range monitors {
+		rows, err := m.Index.external.RetrieveData(name)
+		if err != nil {
+			logging.FromContext(ctx).Debugw("unable to count series", zap.String("metric", name), zap.Error(err))
+			continue
+		}
+		m.recordSeries(monitor, name, len(rows))
+	}
+	for name, monitor := range reported {
+		if _, exists := monitors[name]; !exists {
+			m.recordSeries(monitor, name, 0)
+		}
+	}
+	return monitors
+}
+
+func (m *MetricManager) recordSeries(monitor, name string, series int) {
+	selfmetrics.Record(m.Index.external, []tag.Mutator{
+		tag.Upsert(selfmetrics.MonitorKey, monitor),
+		tag.Upsert(selfmetrics.MetricKey, name),
+	}, selfmetrics.ActiveSeries.M(int64(series)))
+}
+
+// guardStore holds the cardinality guard of every metric with a max
+// cardinality by name
+type guardStore struct {
+	m  map[string]*recorder.CardinalityGuard
+	rw sync.Mutex
+}
+
+// get returns the guard of the metric, nil without max cardinality
+func (s *guardStore) get(metric RunMetric) *recorder.CardinalityGuard {
+	limit := metric.Metric().MaxCardinality
+	if limit <= 0 {
+		return nil
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if s.m == nil {
+		s.m = map[string]*recorder.CardinalityGuard{}
+	}
+	guard, exists := s.m[metric.MetricName()]
+	if !exists {
+		guard = recorder.NewCardinalityGuard(limit)
+		s.m[metric.MetricName()] = guard
+	}
+	return guard
+}
+
+func (s *guardStore) overflowed(metricName string) int64 {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if guard, exists := s.m[metricName]; exists {
+		return guard.Overflowed()
+	}
+	return 0
+}
+
+func (s *guardStore) reset(metricName string) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	delete(s.m, metricName)
+}
+
+// postCardinalityEvent warns that the metric reached its max cardinality,
+// on the run with the first combination past the limit
+func (m *MetricIndex) postCardinalityEvent(ctx context.Context, metric RunMetric, run *v1alpha1.RunDimensions) {
+	eventRecorder := controller.GetEventRecorder(ctx)
+	if eventRecorder == nil || run.Object == nil {
+		return
+	}
+	eventRecorder.Eventf(run.Object, corev1.EventTypeWarning, "MaxCardinalityExceeded", "metric %s of %s exceeded its max cardinality of %d, new tag combinations are recorded as %s", metric.MetricName(), metric.MonitorId(), metric.Metric().MaxCardinality, recorder.Overflow)
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+// cardinalityManager returns a manager with a counter by environment and team
+// that recorded the runs of 3 series
+func cardinalityManager(t *testing.T, external *view.Meter) (*MetricManager, RunMetric) {
+	t.Helper()
+	manager := NewManager(external, nil)
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "build"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "build",
+			Metrics: []v1alpha1.Metric{{
+				Name: "runs",
+				Type: "counter",
+				By: []v1alpha1.ByStatement{
+					{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("env")}},
+					{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("team")}},
+				},
+			}},
+		},
+	}
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	ctx := context.Background()
+	if err := manager.GetIndex().RegisterRunMetric(ctx, counter); err != nil {
+		t.Fatal(err)
+	}
+	for _, run := range []struct{ name, env, team string }{
+		{"a", "dev", "a"},
+		{"b", "dev", "a"},
+		{"c", "dev", "b"},
+		{"d", "prod", "a"},
+	} {
+		manager.GetIndex().Record(ctx, recorder.TaskRunDimensions(&v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: run.name, Namespace: "dev", Labels: map[string]string{"env": run.env, "team": run.team}},
+			Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "build"}},
+		}), "counter")
+	}
+	return manager, counter
+}
+
+func TestCardinality(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager, counter := cardinalityManager(t, external)
+
+	reports, err := manager.Cardinality(counter.MetricName(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CardinalityReport{{
+		Metric: counter.MetricName(),
+		Series: 3,
+		Tags: []TagCardinality{
+			{Key: "env", Values: 2, Top: []ValueCount{{Value: "dev", Series: 2}}},
+			{Key: "team", Values: 2, Top: []ValueCount{{Value: "a", Series: 2}}},
+		},
+	}}
+	if diff := cmp.Diff(want, reports); diff != "" {
+		t.Errorf("report (-want, +got):\n%s", diff)
+	}
+
+	response := httptest.NewRecorder()
+	manager.CardinalityHandler().ServeHTTP(response, httptest.NewRequest("GET", "/cardinality?metric="+counter.MetricName(), nil))
+	got := []CardinalityReport{}
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	// 10 values are listed per tag by default
+	if len(got) != 1 || len(got[0].Tags) != 2 || len(got[0].Tags[0].Top) != 2 {
+		t.Errorf("want every value of the tags, got %+v", got)
+	}
+
+	response = httptest.NewRecorder()
+	manager.CardinalityHandler().ServeHTTP(response, httptest.NewRequest("GET", "/cardinality?top=all", nil))
+	if response.Code != 400 {
+		t.Errorf("want a bad request for an invalid top, got %d", response.Code)
+	}
+}
+
+func TestRecordActiveSeries(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	if err := external.Register(selfmetrics.Views()...); err != nil {
+		t.Fatal(err)
+	}
+	manager, counter := cardinalityManager(t, external)
+	activeSeries := func() map[string]float64 {
+		rows, err := external.RetrieveData(selfmetrics.ActiveSeries.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		series := map[string]float64{}
+		for _, row := range rows {
+			for _, tag := range row.Tags {
+				if tag.Key == selfmetrics.MetricKey {
+					series[tag.Value] = row.Data.(*view.LastValueData).Value
+				}
+			}
+		}
+		return series
+	}
+
+	ctx := context.Background()
+	reported := manager.recordActiveSeries(ctx, map[string]string{})
+	if diff := cmp.Diff(map[string]float64{counter.MetricName(): 3}, activeSeries()); diff != "" {
+		t.Errorf("active series (-want, +got):\n%s", diff)
+	}
+
+	// a removed metric is reported once more with no series
+	if err := manager.GetIndex().UnregisterRunMetricByName(counter.MetricName()); err != nil {
+		t.Fatal(err)
+	}
+	reported = manager.recordActiveSeries(ctx, reported)
+	if diff := cmp.Diff(map[string]float64{counter.MetricName(): 0}, activeSeries()); diff != "" {
+		t.Errorf("active series (-want, +got):\n%s", diff)
+	}
+	if len(reported) != 0 {
+		t.Errorf("want no metric reported anymore, got %v", reported)
+	}
+}
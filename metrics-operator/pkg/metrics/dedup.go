@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"knative.dev/pkg/apis"
+)
+
+// cumulativeTypes are the metric types a done run must be recorded in only
+// once, gauges and child states follow the state of the run instead
+var cumulativeTypes = map[string]bool{
+	"counter":           true,
+	"histogram":         true,
+	"timeoutRatio":      true,
+	"durationBreakdown": true,
+	"lastValue":         true,
+	"childOutcomes":     true,
+	"rate":              true,
+	"slo":               true,
+}
+
+// recordedRuns remembers the latest runs recorded by each metric, bounded by
+// evicting the least recently recorded ones. Informers deliver several
+// updates of a done run, e.g. on label changes or resyncs.
+type recordedRuns struct {
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	mu      sync.Mutex
+}
+
+func newRecordedRuns(size int) *recordedRuns {
+	return &recordedRuns{size: size, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// EnableDeduplication records each done run once per metric, remembering the
+// given number of recordings
+func (m *MetricManager) EnableDeduplication(size int) {
+	m.Index.recorded = newRecordedRuns(size)
+}
+
+// recordedKey returns the key of the run and metric, false when the run
+// isn't deduplicated
+func recordedKey(metric RunMetric, run *v1alpha1.RunDimensions) (string, bool) {
+	if !cumulativeTypes[metric.Metric().Type] {
+		return "", false
+	}
+	condition := run.Status.GetCondition(apis.ConditionSucceeded)
+	if condition == nil || condition.IsUnknown() {
+		return "", false
+	}
+	object, err := meta.Accessor(run.Object)
+	if err != nil || object.GetUID() == "" {
+		return "", false
+	}
+	return string(object.GetUID()) + "/" + metric.MetricName(), true
+}
+
+// contains returns true when the key was recorded
+func (r *recordedRuns) contains(key string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.entries[key]
+	return exists
+}
+
+// add remembers the key, evicting the oldest one when full
+func (r *recordedRuns) add(key string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if element, exists := r.entries[key]; exists {
+		r.order.MoveToFront(element)
+		return
+	}
+	r.entries[key] = r.order.PushFront(key)
+	if r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(string))
+	}
+}
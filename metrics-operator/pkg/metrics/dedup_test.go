@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestRecordDeduplicatesDoneRuns(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	index := MetricIndex{
+		external: external,
+		store:    NewRegistry(),
+		recorded: newRecordedRuns(1),
+	}
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics:  []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+		},
+	}
+	ctx := context.Background()
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	if err := index.RegisterRunMetric(ctx, counter); err != nil {
+		t.Fatal(err)
+	}
+	taskRun := func(uid string) *v1alpha1.RunDimensions {
+		return recorder.TaskRunDimensions(&v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "hello-world-" + uid, Namespace: "dev", UID: types.UID("uid-" + uid)},
+			Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+			Status: v1beta1.TaskRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+				{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+			}}},
+		})
+	}
+	count := func() int64 {
+		rows, err := external.RetrieveData(counter.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) == 0 {
+			return 0
+		}
+		return rows[0].Data.(*view.CountData).Value
+	}
+
+	index.Record(ctx, taskRun("a"), "counter")
+	index.Record(ctx, taskRun("a"), "counter")
+	if got := count(); got != 1 {
+		t.Errorf("want a repeated update recorded once, got %d", got)
+	}
+	// the cache holds one run, a is evicted by b
+	index.Record(ctx, taskRun("b"), "counter")
+	index.Record(ctx, taskRun("a"), "counter")
+	if got := count(); got != 3 {
+		t.Errorf("want an evicted run recorded again, got %d", got)
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// DefaultMonitorName is the monitor name used in the names of the default
+// metrics
+const DefaultMonitorName = "default"
+
+var (
+	namespaceKey = tag.MustNewKey("namespace")
+	taskKey      = tag.MustNewKey("task")
+	pipelineKey  = tag.MustNewKey("pipeline")
+)
+
+var defaultDuration = &v1alpha1.MetricHistogramDuration{
+	From: ".status.startTime",
+	To:   ".status.completionTime",
+}
+
+// defaultMetrics are recorded for runs not covered by any monitor, a baseline
+// before teams author their own monitors
+type defaultMetrics struct {
+	resource  string
+	nameKey   tag.Key
+	nameLabel string
+	duration  *stats.Float64Measure
+	completed *stats.Float64Measure
+	views     []*view.View
+}
+
+func newDefaultMetrics(resource string, nameKey tag.Key, nameLabel string) *defaultMetrics {
+	d := &defaultMetrics{
+		resource:  resource,
+		nameKey:   nameKey,
+		nameLabel: nameLabel,
+	}
+	d.duration = stats.Float64(naming.HistogramMetric(resource, DefaultMonitorName, "duration"), fmt.Sprintf("duration of %s not covered by any monitor", resource), stats.UnitSeconds)
+	d.completed = stats.Float64(naming.CounterMetric(resource, DefaultMonitorName, "completed"), fmt.Sprintf("completed %s not covered by any monitor", resource), stats.UnitDimensionless)
+	tagKeys := []tag.Key{namespaceKey, nameKey}
+	d.views = []*view.View{
+		{
+			Description: d.duration.Description(),
+			Measure:     d.duration,
+			Aggregation: view.Distribution(.25, .5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000),
+			TagKeys:     tagKeys,
+		},
+		{
+			Description: d.completed.Description(),
+			Measure:     d.completed,
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		},
+	}
+	return d
+}
+
+func (d *defaultMetrics) record(ctx context.Context, meter view.Meter, run *v1alpha1.RunDimensions) error {
+	name, exists := run.Labels[d.nameLabel]
+	if !exists {
+		name = "MISSING"
+	}
+	ctx, err := tag.New(ctx, tag.Upsert(namespaceKey, run.Namespace), tag.Upsert(d.nameKey, name))
+	if err != nil {
+		return err
+	}
+	measurements := []stats.Measurement{d.completed.M(1)}
+	duration, found, err := recorder.MeasureDuration(defaultDuration, run.Object)
+	if err != nil {
+		return fmt.Errorf("error parsing duration: %w", err)
+	}
+	if found {
+		duration, _ = recorder.CheckDuration(meter, d.duration.Name(), defaultDuration, duration)
+		measurements = append(measurements, d.duration.M(duration.Seconds()))
+	}
+	meter.Record(tag.FromContext(ctx), measurements, map[string]any{})
+	return nil
+}
+
+// EnableDefaultMetrics registers the default metrics, recorded from then on
+// for the done runs no monitor matches
+func (m *MetricManager) EnableDefaultMetrics() error {
+	defaults := map[string]*defaultMetrics{
+		"taskrun":     newDefaultMetrics("taskrun", taskKey, "tekton.dev/task"),
+		"pipelinerun": newDefaultMetrics("pipelinerun", pipelineKey, "tekton.dev/pipeline"),
+	}
+	for _, d := range defaults {
+		if err := m.Index.external.Register(d.views...); err != nil {
+			return err
+		}
+	}
+	m.defaults = defaults
+	return nil
+}
+
+// recordDefaults records the default metrics when no monitor covers the run
+func (m *MetricManager) recordDefaults(ctx context.Context, run *v1alpha1.RunDimensions) {
+	d, enabled := m.defaults[run.Resource]
+	if !enabled || m.GetIndex().Covers(run) {
+		return
+	}
+	if err := d.record(ctx, m.Index.external, run); err != nil {
+		logging.FromContext(ctx).Errorw("recording default metrics failed", "run", run.GetId(), zap.Error(err))
+	}
+}
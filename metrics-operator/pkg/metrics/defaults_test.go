@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestDefaultMetrics(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	if err := manager.EnableDefaultMetrics(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics:  []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+		},
+	}
+	if err := manager.GetIndex().RegisterRunMetric(ctx, recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)); err != nil {
+		t.Fatal(err)
+	}
+
+	taskRun := func(name, task string) *v1beta1.TaskRun {
+		return &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "dev",
+				UID:       types.UID(name),
+				Labels:    map[string]string{"tekton.dev/task": task},
+			},
+			Spec: v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: task}},
+			Status: v1beta1.TaskRunStatus{
+				Status: duckv1.Status{Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				}},
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					StartTime:      recorder.MustParseRFC3339("2023-08-16T16:00:00Z"),
+					CompletionTime: recorder.MustParseRFC3339("2023-08-16T16:00:30Z"),
+				},
+			},
+		}
+	}
+	// only the run of the unmonitored task is recorded by the default metrics
+	for _, tr := range []*v1beta1.TaskRun{taskRun("hello-world-1", "hello-world"), taskRun("build-1", "build"), taskRun("build-2", "build")} {
+		if err := manager.RecordTaskRunDone(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defaults := manager.defaults["taskrun"]
+	rows, err := external.RetrieveData(defaults.completed.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.CountData).Value != 2 {
+		t.Fatalf("want 2 uncovered runs, got %v", rows)
+	}
+	for _, tag := range rows[0].Tags {
+		if tag.Key == taskKey && tag.Value != "build" {
+			t.Errorf("want the runs of the build task, got %s", tag.Value)
+		}
+	}
+	rows, err = external.RetrieveData(defaults.duration.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.DistributionData).Sum() != 60 {
+		t.Errorf("want two 30s durations, got %v", rows)
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// Evaluation is the outcome of a metric evaluated against a run without
+// recording it
+type Evaluation struct {
+	Monitor string `json:"monitor"`
+	Metric  string `json:"metric"`
+	Type    string `json:"type"`
+	// Matched is false when the run isn't matched by the monitor or the
+	// metric
+	Matched bool `json:"matched"`
+	// Samples are the measurements the metric would have recorded
+	Samples []EvaluatedSample `json:"samples,omitempty"`
+	// Skipped is the reason no sample would have been recorded
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EvaluatedSample is a measurement with its tags, as seen by exporters
+type EvaluatedSample struct {
+	Name  string            `json:"name"`
+	Tags  map[string]string `json:"tags"`
+	Value float64           `json:"value"`
+	Unit  string            `json:"unit,omitempty"`
+}
+
+// capturingRecorder keeps the measurements instead of recording them
+type capturingRecorder struct {
+	views   []*view.View
+	samples []EvaluatedSample
+}
+
+func (r *capturingRecorder) Record(tagMap *tag.Map, measurements any, attachments map[string]any) {
+	values, ok := measurements.([]stats.Measurement)
+	if !ok {
+		return
+	}
+	for _, measurement := range values {
+		sample := EvaluatedSample{
+			Name:  measurement.Measure().Name(),
+			Tags:  map[string]string{},
+			Value: measurement.Value(),
+			Unit:  measurement.Measure().Unit(),
+		}
+		for _, v := range r.views {
+			if v.Measure.Name() != sample.Name {
+				continue
+			}
+			for key, value := range viewTags(v, tagMap) {
+				sample.Tags[key] = value
+			}
+		}
+		r.samples = append(r.samples, sample)
+	}
+}
+
+// Evaluate evaluates every registered metric against the run without
+// recording it nor counting it in the stats, sorted by monitor and metric
+func (m *MetricIndex) Evaluate(ctx context.Context, run *v1alpha1.RunDimensions) []Evaluation {
+	evaluations := []Evaluation{}
+	for _, metric := range m.store.List() {
+		evaluation := Evaluation{
+			Monitor: metric.MonitorId(),
+			Metric:  metric.Metric().Name,
+			Type:    metric.Metric().Type,
+		}
+		if matcher, ok := metric.(recorder.Matcher); ok {
+			matched, err := matcher.Matches(run)
+			if err != nil {
+				evaluation.Error = err.Error()
+			}
+			if err != nil || !matched {
+				evaluations = append(evaluations, evaluation)
+				continue
+			}
+		}
+		evaluation.Matched = true
+		capture := &capturingRecorder{views: runMetricViews(metric)}
+		err := metric.Record(ctx, capture, recorder.ForAttempt(run, metric.Metric().Attempts))
+		switch {
+		case err == nil:
+		case recorder.IsSkipped(err):
+			evaluation.Skipped = err.Error()
+		default:
+			evaluation.Error = err.Error()
+		}
+		evaluation.Samples = capture.samples
+		evaluations = append(evaluations, evaluation)
+	}
+	sort.Slice(evaluations, func(i, j int) bool {
+		if evaluations[i].Monitor != evaluations[j].Monitor {
+			return evaluations[i].Monitor < evaluations[j].Monitor
+		}
+		return evaluations[i].Metric < evaluations[j].Metric
+	})
+	return evaluations
+}
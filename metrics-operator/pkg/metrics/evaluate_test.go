@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+)
+
+func TestMetricIndexEvaluate(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	manager := NewManager(meter, nil)
+	index := manager.GetIndex()
+
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{
+				{
+					Name: "status",
+					Type: "counter",
+					By: []v1alpha1.ByStatement{
+						{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: ptr.String("Succeeded")}},
+					},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)); err != nil {
+		t.Fatal(err)
+	}
+
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello-world-xpto0",
+			Namespace: "dev",
+			Labels:    map[string]string{"tekton.dev/task": "hello-world"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				},
+			},
+		},
+	}
+	got := index.Evaluate(ctx, recorder.TaskRunDimensions(taskRun))
+	want := []Evaluation{{
+		Monitor: "task/hello",
+		Metric:  "status",
+		Type:    "counter",
+		Matched: true,
+		Samples: []EvaluatedSample{{
+			Name:  "task_hello_status_total",
+			Tags:  map[string]string{"status": "success"},
+			Value: 1,
+			Unit:  "1",
+		}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("evaluations (-want, +got):\n%s", diff)
+	}
+
+	rows, err := meter.RetrieveData("task_hello_status_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("want nothing recorded by the evaluation, got %d rows", len(rows))
+	}
+}
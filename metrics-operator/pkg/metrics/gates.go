@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+// GateMonitorName is the monitor name used in the names of the gate metrics
+const GateMonitorName = "gate"
+
+var gateKey = tag.MustNewKey("gate")
+
+// gate identifies a wait or approval gate of a pipeline
+type gate struct {
+	namespace string
+	pipeline  string
+	name      string
+}
+
+// gateMetrics tracks CustomRuns implementing wait and approval gates, their
+// status has a different shape than TaskRuns and isn't covered by monitors
+type gateMetrics struct {
+	kinds    sets.Set[string]
+	pending  *stats.Float64Measure
+	latency  *stats.Float64Measure
+	rejected *stats.Float64Measure
+	views    []*view.View
+	// runs maps the key of every pending CustomRun to its gate
+	runs map[string]gate
+	done sets.Set[string]
+}
+
+func newGateMetrics(kinds []string) *gateMetrics {
+	g := &gateMetrics{
+		kinds: sets.New[string](kinds...),
+		runs:  map[string]gate{},
+		done:  sets.New[string](),
+	}
+	g.pending = stats.Float64(naming.GaugeMetric("customrun", GateMonitorName, "pending"), "number of gates waiting for approval", stats.UnitDimensionless)
+	g.latency = stats.Float64(naming.HistogramMetric("customrun", GateMonitorName, "approval"), "time from the start of a gate to its approval", stats.UnitSeconds)
+	g.rejected = stats.Float64(naming.CounterMetric("customrun", GateMonitorName, "rejected"), "gates rejected or timed out", stats.UnitDimensionless)
+	tagKeys := []tag.Key{namespaceKey, pipelineKey, gateKey}
+	g.views = []*view.View{
+		{
+			Description: g.pending.Description(),
+			Measure:     g.pending,
+			Aggregation: view.LastValue(),
+			TagKeys:     tagKeys,
+		},
+		{
+			Description: g.latency.Description(),
+			Measure:     g.latency,
+			Aggregation: view.Distribution(1, 10, 30, 60, 300, 600, 1800, 3600, 7200, 14400, 28800, 86400, 172800, 604800),
+			TagKeys:     tagKeys,
+		},
+		{
+			Description: g.rejected.Description(),
+			Measure:     g.rejected,
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		},
+	}
+	return g
+}
+
+// customRunKind returns the kind of the custom task run by the CustomRun
+func customRunKind(customRun *pipelinev1beta1.CustomRun) string {
+	if customRun.Spec.CustomRef != nil {
+		return string(customRun.Spec.CustomRef.Kind)
+	}
+	if customRun.Spec.CustomSpec != nil {
+		return customRun.Spec.CustomSpec.Kind
+	}
+	return ""
+}
+
+func gateOf(customRun *pipelinev1beta1.CustomRun) gate {
+	g := gate{namespace: customRun.Namespace, pipeline: "MISSING", name: "MISSING"}
+	if pipeline, exists := customRun.Labels["tekton.dev/pipeline"]; exists {
+		g.pipeline = pipeline
+	}
+	if name, exists := customRun.Labels["tekton.dev/pipelineTask"]; exists {
+		g.name = name
+	}
+	return g
+}
+
+func (g gate) tagMap(ctx context.Context) (*tag.Map, error) {
+	ctx, err := tag.New(ctx, tag.Upsert(namespaceKey, g.namespace), tag.Upsert(pipelineKey, g.pipeline), tag.Upsert(gateKey, g.name))
+	if err != nil {
+		return nil, err
+	}
+	return tag.FromContext(ctx), nil
+}
+
+// EnableGateMetrics registers the metrics of the CustomRuns of the given
+// kinds, which are then recorded by RecordGate
+func (m *MetricManager) EnableGateMetrics(kinds []string) error {
+	gates := newGateMetrics(kinds)
+	if err := m.Index.external.Register(gates.views...); err != nil {
+		return err
+	}
+	m.gates = gates
+	return nil
+}
+
+// RecordGate records the CustomRun if it runs one of the gate kinds, pending
+// gates are counted until they're done, then approvals are recorded in the
+// latency histogram and rejections counted.
+func (m *MetricManager) RecordGate(ctx context.Context, customRun *pipelinev1beta1.CustomRun) error {
+	if m.gates == nil || !m.gates.kinds.Has(customRunKind(customRun)) {
+		return nil
+	}
+	m.rw.Lock()
+	defer m.rw.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%s", customRun.Namespace, customRun.Name, customRun.UID)
+	g := gateOf(customRun)
+	if customRun.DeletionTimestamp != nil {
+		return m.forgetGate(ctx, key)
+	}
+	if !customRun.IsDone() {
+		if _, exists := m.gates.runs[key]; exists {
+			return nil
+		}
+		m.gates.runs[key] = g
+		return m.recordPending(ctx, g)
+	}
+	if m.gates.done.Has(key) {
+		return nil
+	}
+	m.gates.done.Insert(key)
+	delete(m.gates.runs, key)
+	if err := m.recordPending(ctx, g); err != nil {
+		return err
+	}
+
+	tagMap, err := g.tagMap(ctx)
+	if err != nil {
+		return err
+	}
+	condition := customRun.Status.GetCondition(apis.ConditionSucceeded)
+	if condition.IsFalse() {
+		m.Index.external.Record(tagMap, []stats.Measurement{m.gates.rejected.M(1)}, map[string]any{})
+		return nil
+	}
+	start, completion := customRun.Status.StartTime, customRun.Status.CompletionTime
+	if start == nil {
+		start = &customRun.CreationTimestamp
+	}
+	if completion == nil {
+		return nil
+	}
+	m.Index.external.Record(tagMap, []stats.Measurement{m.gates.latency.M(completion.Sub(start.Time).Seconds())}, map[string]any{})
+	return nil
+}
+
+// ForgetGate stops tracking a deleted CustomRun
+func (m *MetricManager) ForgetGate(ctx context.Context, namespace, name, uid string) error {
+	if m.gates == nil {
+		return nil
+	}
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	return m.forgetGate(ctx, fmt.Sprintf("%s/%s/%s", namespace, name, uid))
+}
+
+// forgetGate stops tracking the CustomRun, the lock must be held
+func (m *MetricManager) forgetGate(ctx context.Context, key string) error {
+	m.gates.done.Delete(key)
+	g, pending := m.gates.runs[key]
+	if !pending {
+		return nil
+	}
+	delete(m.gates.runs, key)
+	return m.recordPending(ctx, g)
+}
+
+// recordPending records the number of pending CustomRuns of the gate, the
+// lock must be held
+func (m *MetricManager) recordPending(ctx context.Context, g gate) error {
+	pending := 0
+	for _, other := range m.gates.runs {
+		if other == g {
+			pending++
+		}
+	}
+	tagMap, err := g.tagMap(ctx)
+	if err != nil {
+		return err
+	}
+	m.Index.external.Record(tagMap, []stats.Measurement{m.gates.pending.M(float64(pending))}, map[string]any{})
+	return nil
+}
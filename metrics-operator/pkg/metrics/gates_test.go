@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func gateRun(name, kind string, status v1.ConditionStatus) *v1beta1.CustomRun {
+	start := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	customRun := &v1beta1.CustomRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "dev",
+			UID:       types.UID(name),
+			Labels:    map[string]string{"tekton.dev/pipeline": "release", "tekton.dev/pipelineTask": "approve"},
+		},
+		Spec: v1beta1.CustomRunSpec{CustomRef: &v1beta1.TaskRef{APIVersion: "example.dev/v1alpha1", Kind: v1beta1.TaskKind(kind)}},
+	}
+	customRun.Status.StartTime = &metav1.Time{Time: start}
+	customRun.Status.Conditions = duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}}
+	if status != v1.ConditionUnknown {
+		customRun.Status.CompletionTime = &metav1.Time{Time: start.Add(10 * time.Minute)}
+	}
+	return customRun
+}
+
+func TestRecordGate(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	if err := manager.EnableGateMetrics([]string{"Approval"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	record := func(customRun *v1beta1.CustomRun) {
+		t.Helper()
+		if err := manager.RecordGate(ctx, customRun); err != nil {
+			t.Fatal(err)
+		}
+	}
+	value := func(measure string) float64 {
+		t.Helper()
+		rows, err := external.RetrieveData(measure)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) == 0 {
+			return 0
+		}
+		if len(rows) > 1 {
+			t.Fatalf("want a single series of %s, got %d", measure, len(rows))
+		}
+		switch data := rows[0].Data.(type) {
+		case *view.LastValueData:
+			return data.Value
+		case *view.CountData:
+			return float64(data.Value)
+		case *view.DistributionData:
+			return data.Sum()
+		}
+		t.Fatalf("unexpected data %T", rows[0].Data)
+		return 0
+	}
+	gates := manager.gates
+
+	record(gateRun("a", "Approval", v1.ConditionUnknown))
+	record(gateRun("b", "Approval", v1.ConditionUnknown))
+	// runs of other custom tasks aren't gates
+	record(gateRun("c", "Wait", v1.ConditionUnknown))
+	if got := value(gates.pending.Name()); got != 2 {
+		t.Errorf("want 2 pending gates, got %v", got)
+	}
+
+	record(gateRun("a", "Approval", v1.ConditionTrue))
+	// a done run is only recorded once
+	record(gateRun("a", "Approval", v1.ConditionTrue))
+	if got := value(gates.pending.Name()); got != 1 {
+		t.Errorf("want 1 pending gate, got %v", got)
+	}
+	if got := value(gates.latency.Name()); got != 600 {
+		t.Errorf("want an approval after 600s, got %v", got)
+	}
+
+	record(gateRun("b", "Approval", v1.ConditionFalse))
+	if got := value(gates.pending.Name()); got != 0 {
+		t.Errorf("want no pending gate, got %v", got)
+	}
+	if got := value(gates.rejected.Name()); got != 1 {
+		t.Errorf("want 1 rejected gate, got %v", got)
+	}
+
+	// deleted runs are no longer pending
+	record(gateRun("d", "Approval", v1.ConditionUnknown))
+	if err := manager.ForgetGate(ctx, "dev", "d", "d"); err != nil {
+		t.Fatal(err)
+	}
+	if got := value(gates.pending.Name()); got != 0 {
+		t.Errorf("want no pending gate after the deletion, got %v", got)
+	}
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/sink"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap"
-	"knative.dev/pkg/kmp"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 )
 
@@ -20,80 +24,291 @@ type RunMetric interface {
 	MetricName() string
 	Metric() *monitoringv1alpha1.Metric
 	View() *view.View
-	Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions)
+	Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error
 	Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions)
 }
 
+// MultiViewMetric is implemented by metrics exporting more than one view from
+// a single spec, View returns the main one
+type MultiViewMetric interface {
+	Views() []*view.View
+}
+
+func runMetricViews(runMetric RunMetric) []*view.View {
+	if multiView, ok := runMetric.(MultiViewMetric); ok {
+		return multiView.Views()
+	}
+	return []*view.View{runMetric.View()}
+}
+
+// ViewPurger drops the series kept by exporters for unregistered views
+type ViewPurger interface {
+	PurgeViews(names ...string)
+}
+
 type MetricIndex struct {
 	external view.Meter
-	store    map[string]RunMetric
-	rw       sync.RWMutex
+	store    *Registry
+	retries  *RetryQueue
+	stats    statsStore
+	guards   guardStore
+	// breakers suspend persistently failing metrics, nil when disabled
+	breakers *breakerStore
+	// runEvents posts a warning Event on runs failing a metric
+	runEvents bool
+	// exemplars attaches the run to histogram samples
+	exemplars bool
+	// monitorEvents posts warning Events on the monitors of failing metrics,
+	// nil when disabled
+	monitorEvents *monitorEventStore
+	// sinks receive a copy of every sample
+	sinks []*sink.Buffered
+	// purger is told about unregistered views, nil when unset
+	purger ViewPurger
+	// batch buffers the samples of monitor metrics, nil when disabled
+	batch *batchedRecorder
+	// recorded holds the done runs recorded by each metric, nil when
+	// deduplication is disabled
+	recorded *recordedRuns
+	// backend registers and records the views of monitor metrics instead of
+	// the meter, nil unless set
+	backend *backendViews
+	// rw serializes the registrations, the views of a metric and the store
+	// change together. Recordings hold it for reading, a run is never recorded
+	// against views being replaced.
+	rw sync.RWMutex
 }
 
 func (m *MetricIndex) Record(ctx context.Context, run *v1alpha1.RunDimensions, metricType string) {
-	for _, metric := range m.store {
-		if metric.Metric().Type == metricType {
-			metric.Record(ctx, m.external, run)
+	logger := logging.FromContext(ctx)
+	if m.exemplars {
+		ctx = recorder.WithExemplars(ctx)
+	}
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	for _, metric := range m.store.List() {
+		if metric.Metric().Type != metricType {
+			continue
+		}
+		if !m.breakers.allow(metric.MetricName(), time.Now()) {
+			continue
+		}
+		ctx := recorder.WithEvaluationTiming(ctx, m.external, metric.MetricName())
+		guard := m.guards.get(metric)
+		var overflowed int64
+		if guard != nil {
+			overflowed = guard.Overflowed()
+			ctx = recorder.WithCardinalityGuard(ctx, guard)
+		}
+		// runs not matching the monitor aren't counted in its stats
+		if matcher, ok := metric.(recorder.Matcher); ok {
+			start := time.Now()
+			matched, err := matcher.Matches(run)
+			recorder.ObserveEvaluation(ctx, recorder.StageFilter, start)
+			if err == nil && !matched {
+				continue
+			}
+		}
+		key, deduplicated := recordedKey(metric, run)
+		if deduplicated && (m.recorded.contains(key) || markedRecorded(metric, run)) {
+			continue
+		}
+		run := recorder.ForAttempt(run, metric.Metric().Attempts)
+		writes := &failedWrites{}
+		err := metric.Record(ctx, m.recorderFor(metric, run, writes), run)
+		if err == nil {
+			err = writes.Err()
+		}
+		m.stats.observe(metric.MetricName(), err, recorder.IsSkipped(err))
+		m.observeRecording(metric, err)
+		if guard != nil && overflowed == 0 && guard.Overflowed() > 0 {
+			logger.Warnw("max cardinality exceeded", zap.String("metric", metric.MetricName()), zap.String("monitor", metric.MonitorId()), zap.Int("maxCardinality", metric.Metric().MaxCardinality))
+			m.postCardinalityEvent(ctx, metric, run)
+		}
+		if err == nil {
+			if deduplicated {
+				m.recorded.add(key)
+				observeRecorded(ctx, metric)
+			}
+			m.breakers.observe(metric.MetricName(), nil, time.Now())
+			continue
+		}
+		logger := logger.With(zap.String("metric", metric.MetricName()), zap.String("monitor", metric.MonitorId()), zap.String("run", run.GetId()))
+		if recorder.IsSkipped(err) {
+			m.breakers.observe(metric.MetricName(), nil, time.Now())
+			logger.Debugw("no sample recorded", zap.Error(err))
+			continue
+		}
+		if recorder.IsRetryable(err) && m.retries != nil {
+			if m.retries.Add(metric.MonitorId()+"/"+metric.MetricName()+"/"+run.GetId(), m.retryRecording(metric, run, writes)) {
+				// the retry queue records it, later updates must not
+				if deduplicated {
+					m.recorded.add(key)
+				}
+				logger.Warnw("recording failed, retrying", zap.Error(err))
+				continue
+			}
+			logger.Errorw("recording failed, retry queue is full", zap.Error(err))
+			continue
+		}
+		logger.Errorw("recording failed", zap.Error(err))
+		if m.breakers.observe(metric.MetricName(), err, time.Now()) {
+			b, _ := m.breakers.suspended(metric.MetricName())
+			logger.Warnw("metric suspended after consecutive failures", zap.Int("failures", b.failures), zap.Duration("backoff", b.backoff))
+		}
+		if m.runEvents {
+			m.postRunEvent(ctx, metric, run, err)
+		}
+		m.postMonitorEvent(ctx, metric, run, err)
+	}
+}
+
+// retryRecording returns the retry of a failed recording. Once the metric
+// recorded the run only its failed writes are retried, the samples written
+// aren't counted twice.
+func (m *MetricIndex) retryRecording(metric RunMetric, run *v1alpha1.RunDimensions, writes *failedWrites) func(ctx context.Context) error {
+	recorded := writes.Err() != nil
+	return func(ctx context.Context) error {
+		m.rw.RLock()
+		defer m.rw.RUnlock()
+		if recorded {
+			return writes.retry(ctx)
+		}
+		if err := metric.Record(ctx, m.recorderFor(metric, run, writes), run); err != nil {
+			return err
+		}
+		recorded = true
+		return writes.Err()
+	}
+}
+
+// postRunEvent tells the authors of the run that it broke a metric, e.g. a
+// missing result or a bad timestamp. The event recorder is only in the
+// context when recording from a run reconciler.
+func (m *MetricIndex) postRunEvent(ctx context.Context, metric RunMetric, run *v1alpha1.RunDimensions, err error) {
+	eventRecorder := controller.GetEventRecorder(ctx)
+	if eventRecorder == nil || run.Object == nil {
+		return
+	}
+	eventRecorder.Eventf(run.Object, corev1.EventTypeWarning, "MetricRecordingFailed", "metric %s of %s could not be recorded: %v", metric.MetricName(), metric.MonitorId(), err)
+}
+
+// Covers returns true when a metric of any monitor matches the run
+func (m *MetricIndex) Covers(run *v1alpha1.RunDimensions) bool {
+	for _, metric := range m.store.List() {
+		matcher, ok := metric.(recorder.Matcher)
+		if !ok {
+			continue
+		}
+		if matched, err := matcher.Matches(run); err == nil && matched {
+			return true
 		}
 	}
+	return false
 }
 
 func (m *MetricIndex) Clean(ctx context.Context, run *v1alpha1.RunDimensions) {
-	for _, metric := range m.store {
-		metric.Clean(ctx, m.external, run)
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	for _, metric := range m.store.List() {
+		metric.Clean(ctx, m.recording(), run)
 	}
 }
 
+// RegisterRunMetric registers the views of a metric or replaces the views of
+// its previous spec. Views left unchanged keep their data, the others are
+// unregistered and registered again in a single critical section, so a run
+// is never recorded against a half-updated metric.
 func (m *MetricIndex) RegisterRunMetric(ctx context.Context, runMetric RunMetric) error {
-
-	logger := logging.FromContext(ctx)
-	isRegistered, isModified, err := m.IsRegistered(runMetric)
+	logger := logging.FromContext(ctx).With(zap.String("metric", runMetric.MetricName()), zap.String("monitor", runMetric.MonitorId()))
+	hash, err := recorder.SpecHash(runMetric.Metric())
 	if err != nil {
 		return fmt.Errorf("error verifying run metric registration: %w", err)
 	}
-	if isRegistered && isModified {
-		err := m.UnregisterRunMetric(runMetric)
-		if err != nil {
-			return err
-		}
-	}
-	if isRegistered && !isModified {
-		return nil
-	}
 
 	m.rw.Lock()
 	defer m.rw.Unlock()
 
-	logger = logger.With(zap.String("metric", runMetric.MetricName()), zap.String("monitor", runMetric.MonitorId()))
-	m.store[runMetric.MetricName()] = runMetric
-	err = m.external.Register(runMetric.View())
-	if err != nil {
+	// metric prefixes let monitors export the same name, the first one keeps
+	// it
+	if key, _, taken := m.store.Lookup(runMetric.MetricName()); taken && key != KeyOf(runMetric) {
+		return fmt.Errorf("metric %s is already exported by %s", runMetric.MetricName(), naming.MonitorId(key.Resource, key.Monitor))
+	}
+
+	var oldViews []*view.View
+	modified := true
+	lastSeen, exists := m.store.Get(KeyOf(runMetric))
+	if exists {
+		oldViews = runMetricViews(lastSeen)
+		lastSeenHash, err := recorder.SpecHash(lastSeen.Metric())
+		if err != nil {
+			return fmt.Errorf("error verifying run metric registration: %w", err)
+		}
+		// the name changes with the metric prefix of the monitor
+		modified = lastSeenHash != hash || lastSeen.MetricName() != runMetric.MetricName()
+	} else if leftover := m.findView(runMetric.MetricName()); leftover != nil {
+		// a view left without its metric, e.g. from a deleted monitor, is
+		// replaced
+		oldViews = []*view.View{leftover}
+	}
+
+	views := runMetricViews(runMetric)
+	removed, added := diffViews(oldViews, views)
+	m.unregisterViews(removed...)
+	m.purgeViews(removed)
+	if err := m.registerViews(added...); err != nil {
 		logger.Errorw("metric registration failed", zap.Error(err))
+		m.unregisterViews(added...)
+		if restoreErr := m.registerViews(removed...); restoreErr != nil {
+			logger.Errorw("previous metric views could not be restored", zap.Error(restoreErr))
+		}
 		return err
 	}
-	logger.Info("metric registered")
+	// the metric is swapped even when its spec is unchanged, the filters of
+	// the monitor may have changed. Its kept views are named like the
+	// registered ones, views are unregistered and retrieved by name.
+	for _, v := range views {
+		v.Name = viewName(v)
+	}
+	if inheritor, ok := runMetric.(recorder.Inheritor); ok && exists && !modified {
+		inheritor.Inherit(lastSeen)
+	}
+	m.store.Replace(runMetric)
+	if !modified {
+		return nil
+	}
+	if exists && lastSeen.MetricName() != runMetric.MetricName() {
+		// the type or prefix changed, the metric is exported under a new name
+		m.resetMetric(lastSeen.MetricName())
+	}
+	m.resetMetric(runMetric.MetricName())
+	if exists {
+		logger.Infow("metric updated", zap.Int("viewsReplaced", len(added)))
+	} else {
+		logger.Info("metric registered")
+	}
 	return nil
 }
 
 // IsRegistered returns two booleans: if it's registered, and if it's modified
 func (m *MetricIndex) IsRegistered(runMetric RunMetric) (bool, bool, error) {
-	m.rw.Lock()
-	defer m.rw.Unlock()
-
-	viewFound := m.external.Find(runMetric.MetricName())
+	viewFound := m.findView(runMetric.MetricName())
 	if viewFound != nil {
-		lastSeen, exists := m.store[runMetric.MetricName()]
+		_, lastSeen, exists := m.store.Lookup(runMetric.MetricName())
 		if exists {
-			isModified := false
-			equals, err := kmp.SafeEqual(lastSeen.Metric(), runMetric.Metric())
+			lastSeenHash, err := recorder.SpecHash(lastSeen.Metric())
 			if err != nil {
 				return true, false, err
 			}
-			if !equals {
-				isModified = true
+			hash, err := recorder.SpecHash(runMetric.Metric())
+			if err != nil {
+				return true, false, err
 			}
-			return true, isModified, nil
+			return true, lastSeenHash != hash, nil
 		}
+		// a view left without its metric, e.g. from a deleted monitor, is
+		// replaced
+		return true, true, nil
 	}
 	return false, false, nil
 }
@@ -102,26 +317,46 @@ func (m *MetricIndex) UnregisterRunMetricByName(runMetricName string) error {
 	m.rw.Lock()
 	defer m.rw.Unlock()
 
-	existingView := m.external.Find(runMetricName)
-	if existingView != nil {
-		m.external.Unregister(existingView)
+	if key, runMetric, exists := m.store.Lookup(runMetricName); exists {
+		m.unregisterViews(runMetricViews(runMetric)...)
+		m.purgeViews(runMetricViews(runMetric))
+		m.store.Remove(key)
+	} else if existingView := m.findView(runMetricName); existingView != nil {
+		m.unregisterViews(existingView)
+		m.purgeViews([]*view.View{existingView})
 	}
-	delete(m.store, runMetricName)
+	m.resetMetric(runMetricName)
 	return nil
 }
 
+// resetMetric drops the stats, cardinality guard and breaker of a metric
+func (m *MetricIndex) resetMetric(runMetricName string) {
+	m.stats.reset(runMetricName)
+	m.guards.reset(runMetricName)
+	m.breakers.reset(runMetricName)
+}
+
+// purgeViews tells the purger about unregistered views, their reports have
+// stopped once Unregister returns
+func (m *MetricIndex) purgeViews(views []*view.View) {
+	if m.purger == nil || len(views) == 0 {
+		return
+	}
+	names := make([]string, 0, len(views))
+	for _, v := range views {
+		names = append(names, viewName(v))
+	}
+	m.purger.PurgeViews(names...)
+}
+
 func (m *MetricIndex) UnregisterRunMetric(runMetric RunMetric) error {
 	return m.UnregisterRunMetricByName(runMetric.MetricName())
 }
 
 func (m *MetricIndex) GetAllMetricNamesFromMonitor(resource string, monitor string) []string {
 	metrics := []string{}
-	m.rw.Lock()
-	defer m.rw.Unlock()
-	for _, runMetric := range m.store {
-		if naming.MonitorId(resource, monitor) == runMetric.MonitorId() {
-			metrics = append(metrics, runMetric.MetricName())
-		}
+	for _, runMetric := range m.store.ListMonitor(resource, monitor) {
+		metrics = append(metrics, runMetric.MetricName())
 	}
 	return metrics
 }
@@ -133,5 +368,6 @@ func (m *MetricIndex) UnregisterAllMetricsMonitor(resource string, monitor strin
 			return err
 		}
 	}
+	m.monitorEvents.forget(naming.MonitorId(resource, monitor))
 	return nil
 }
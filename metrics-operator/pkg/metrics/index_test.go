@@ -40,7 +40,7 @@ func TestMetricIndex(t *testing.T) {
 	// Setup data
 	index := MetricIndex{
 		external: external,
-		store:    map[string]RunMetric{},
+		store:    NewRegistry(),
 	}
 
 	taskMonitor := &v1alpha1.TaskMonitor{
@@ -143,3 +143,165 @@ func TestMetricIndex(t *testing.T) {
 	})
 
 }
+
+func TestRegisterRunMetricReplacesViews(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	index := MetricIndex{
+		external: external,
+		store:    NewRegistry(),
+	}
+
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{{
+				Name: "status",
+				Type: "counter",
+				By: []v1alpha1.ByStatement{
+					{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: ptr.String("Succeeded")}},
+				},
+			}},
+		},
+	}
+	ctx := context.Background()
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	if err := index.RegisterRunMetric(ctx, counter); err != nil {
+		t.Fatal(err)
+	}
+	run := recorder.TaskRunDimensions(&v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world-xpto0", Namespace: "dev"},
+		Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+		Status: v1beta1.TaskRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+		}}},
+	})
+	index.Record(ctx, run, "counter")
+
+	t.Run("unchanged spec keeps the view data", func(t *testing.T) {
+		if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := external.RetrieveData(counter.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Errorf("want 1 row, got %d", len(rows))
+		}
+	})
+
+	t.Run("changed tags replace the view", func(t *testing.T) {
+		edited := taskMonitor.DeepCopy()
+		edited.Spec.Metrics[0].By = append(edited.Spec.Metrics[0].By,
+			v1alpha1.ByStatement{MetricDimensionRef: v1alpha1.MetricDimensionRef{Namespace: ptr.Bool(true)}})
+		if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&edited.Spec.Metrics[0], edited)); err != nil {
+			t.Fatal(err)
+		}
+		registered := external.Find(counter.MetricName())
+		if registered == nil {
+			t.Fatal("view not registered")
+		}
+		if len(registered.TagKeys) != 2 {
+			t.Errorf("want 2 tag keys, got %v", registered.TagKeys)
+		}
+		rows, err := external.RetrieveData(counter.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 0 {
+			t.Errorf("want the data of the old view dropped, got %d rows", len(rows))
+		}
+	})
+
+	t.Run("reordered tags keep the view", func(t *testing.T) {
+		edited := taskMonitor.DeepCopy()
+		edited.Spec.Metrics[0].By = append(edited.Spec.Metrics[0].By,
+			v1alpha1.ByStatement{MetricDimensionRef: v1alpha1.MetricDimensionRef{Namespace: ptr.Bool(true)}})
+		index.Record(ctx, run, "counter")
+
+		reordered := edited.DeepCopy()
+		by := reordered.Spec.Metrics[0].By
+		by[0], by[1] = by[1], by[0]
+		if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&reordered.Spec.Metrics[0], reordered)); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := external.RetrieveData(counter.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Errorf("want the data of the view kept, got %d rows", len(rows))
+		}
+	})
+}
+
+// TestRecordWhileRegistering replaces the views of a metric while runs are
+// recorded, run with -race
+func TestRecordWhileRegistering(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	index := &MetricIndex{
+		external: external,
+		store:    NewRegistry(),
+	}
+
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{{
+				Name: "status",
+				Type: "counter",
+				By: []v1alpha1.ByStatement{
+					{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: ptr.String("Succeeded")}},
+				},
+			}},
+		},
+	}
+	edited := taskMonitor.DeepCopy()
+	edited.Spec.Metrics[0].By = append(edited.Spec.Metrics[0].By,
+		v1alpha1.ByStatement{MetricDimensionRef: v1alpha1.MetricDimensionRef{Namespace: ptr.Bool(true)}})
+	ctx := context.Background()
+	if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)); err != nil {
+		t.Fatal(err)
+	}
+	run := recorder.TaskRunDimensions(&v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world-xpto0", Namespace: "dev"},
+		Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+		Status: v1beta1.TaskRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+		}}},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			index.Record(ctx, run, "counter")
+			index.Clean(ctx, run)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		monitor := taskMonitor
+		if i%2 == 0 {
+			monitor = edited
+		}
+		if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&monitor.Spec.Metrics[0], monitor)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+
+	// the last registration is the original spec
+	registered := external.Find("task_hello_status_total")
+	if registered == nil {
+		t.Fatal("view not registered")
+	}
+	if len(registered.TagKeys) != 1 {
+		t.Errorf("want the tag keys of the last spec, got %v", registered.TagKeys)
+	}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+// completionTime returns when the run completed, the transition time of its
+// Succeeded condition when the status has no completion time
+func completionTime(run *v1alpha1.RunDimensions) (time.Time, bool) {
+	switch r := run.Object.(type) {
+	case *pipelinev1beta1.TaskRun:
+		if r.Status.CompletionTime != nil {
+			return r.Status.CompletionTime.Time, true
+		}
+	case *pipelinev1beta1.PipelineRun:
+		if r.Status.CompletionTime != nil {
+			return r.Status.CompletionTime.Time, true
+		}
+	case *pipelinev1beta1.CustomRun:
+		if r.Status.CompletionTime != nil {
+			return r.Status.CompletionTime.Time, true
+		}
+	}
+	condition := run.Status.GetCondition(apis.ConditionSucceeded)
+	if condition == nil || condition.IsUnknown() || condition.LastTransitionTime.Inner.IsZero() {
+		return time.Time{}, false
+	}
+	return condition.LastTransitionTime.Inner.Time, true
+}
+
+// RecordLag records, for every monitor matching the done run, the delay
+// between its completion and now. A growing lag points to an informer or
+// queue backlog.
+func (m *MetricIndex) RecordLag(run *v1alpha1.RunDimensions, now time.Time) {
+	completed, ok := completionTime(run)
+	if !ok {
+		return
+	}
+	lag := now.Sub(completed)
+	if lag < 0 {
+		lag = 0
+	}
+	monitors := sets.New[string]()
+	for _, metric := range m.store.List() {
+		matcher, ok := metric.(recorder.Matcher)
+		if !ok || monitors.Has(metric.MonitorId()) {
+			continue
+		}
+		if matched, err := matcher.Matches(run); err == nil && matched {
+			monitors.Insert(metric.MonitorId())
+		}
+	}
+	for monitor := range monitors {
+		selfmetrics.Record(m.external, []tag.Mutator{tag.Upsert(selfmetrics.MonitorKey, monitor)}, selfmetrics.RecordingLag.M(lag.Seconds()))
+	}
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestCompletionTime(t *testing.T) {
+	completed := time.Date(2023, 8, 16, 16, 0, 30, 0, time.UTC)
+	pipelineRun := &v1beta1.PipelineRun{}
+	pipelineRun.Status.Conditions = duckv1.Conditions{{
+		Type:               apis.ConditionSucceeded,
+		Status:             v1.ConditionFalse,
+		LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: completed}},
+	}}
+	runningPipelineRun := pipelineRun.DeepCopy()
+	runningPipelineRun.Status.Conditions[0].Status = v1.ConditionUnknown
+
+	tests := []struct {
+		name      string
+		run       *v1alpha1.RunDimensions
+		want      time.Time
+		wantFound bool
+	}{{
+		name: "completion time",
+		run: recorder.TaskRunDimensions(&v1beta1.TaskRun{Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			CompletionTime: &metav1.Time{Time: completed},
+		}}}),
+		want:      completed,
+		wantFound: true,
+	}, {
+		name:      "transition time of the condition",
+		run:       recorder.PipelineRunDimensions(pipelineRun),
+		want:      completed,
+		wantFound: true,
+	}, {
+		name: "running",
+		run:  recorder.PipelineRunDimensions(runningPipelineRun),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := completionTime(tt.run)
+			if !got.Equal(tt.want) || found != tt.wantFound {
+				t.Errorf("want %v (found %v), got %v (found %v)", tt.want, tt.wantFound, got, found)
+			}
+		})
+	}
+}
+
+func TestRecordLag(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	if err := external.Register(selfmetrics.Views()...); err != nil {
+		t.Fatal(err)
+	}
+	manager := NewManager(external, nil)
+
+	ctx := context.Background()
+	for _, taskMonitor := range []*v1alpha1.TaskMonitor{{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics:  []v1alpha1.Metric{{Name: "runs", Type: "counter"}, {Name: "failures", Type: "counter"}},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "build"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "build",
+			Metrics:  []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+		},
+	}} {
+		for i := range taskMonitor.Spec.Metrics {
+			if err := manager.GetIndex().RegisterRunMetric(ctx, recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[i], taskMonitor)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	completed := time.Date(2023, 8, 16, 16, 0, 30, 0, time.UTC)
+	run := recorder.TaskRunDimensions(&v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world-1", Namespace: "dev"},
+		Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+		Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			CompletionTime: &metav1.Time{Time: completed},
+		}},
+	})
+	manager.GetIndex().RecordLag(run, completed.Add(90*time.Second))
+	// a clock behind the completion time doesn't record a negative lag
+	manager.GetIndex().RecordLag(run, completed.Add(-time.Second))
+
+	rows, err := external.RetrieveData(selfmetrics.RecordingLag.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]*view.DistributionData{}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == selfmetrics.MonitorKey {
+				got[tag.Value] = row.Data.(*view.DistributionData)
+			}
+		}
+	}
+	// the lag is recorded once per matching monitor, not per metric
+	if diff := cmp.Diff([]string{"task/hello"}, sets.List(sets.KeySet(got))); diff != "" {
+		t.Fatalf("monitors (-want, +got):\n%s", diff)
+	}
+	if lag := got["task/hello"]; lag.Count != 2 || lag.Sum() != 90 || lag.Min != 0 {
+		t.Errorf("want lags of 90s and 0s, got %+v", lag)
+	}
+}
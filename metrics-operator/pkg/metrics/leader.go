@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/controller"
+)
+
+// leaderChecker is implemented by the generated reconcilers, true for the keys
+// of the buckets led by the replica
+type leaderChecker interface {
+	IsLeaderFor(key types.NamespacedName) bool
+}
+
+// Leads returns true when the replica leads the key in the controller, so
+// work done outside of reconciles, like replays, happens once across
+// replicas. Always true when leader election is disabled.
+func Leads(impl *controller.Impl, key types.NamespacedName) bool {
+	r := impl.Reconciler
+	if timed, ok := r.(*timedReconciler); ok {
+		r = timed.leaderAwareReconciler
+	}
+	if checker, ok := r.(leaderChecker); ok {
+		return checker.IsLeaderFor(key)
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+)
+
+// fakeLeader leads the keys of a single namespace
+type fakeLeader struct {
+	reconciler.LeaderAwareFuncs
+	namespace string
+}
+
+func (f *fakeLeader) Reconcile(ctx context.Context, key string) error {
+	return nil
+}
+
+func (f *fakeLeader) IsLeaderFor(key types.NamespacedName) bool {
+	return key.Namespace == f.namespace
+}
+
+func TestLeads(t *testing.T) {
+	impl := &controller.Impl{Reconciler: &fakeLeader{namespace: "dev"}}
+	// the instrumented reconciler still answers for the wrapped one
+	impl.Reconciler = &timedReconciler{leaderAwareReconciler: impl.Reconciler.(leaderAwareReconciler), name: "taskrun"}
+
+	if !Leads(impl, types.NamespacedName{Namespace: "dev", Name: "build"}) {
+		t.Error("want the replica leading the runs of dev")
+	}
+	if Leads(impl, types.NamespacedName{Namespace: "prod", Name: "build"}) {
+		t.Error("want the replica following the runs of prod")
+	}
+
+	if !Leads(&controller.Impl{}, types.NamespacedName{Namespace: "prod", Name: "build"}) {
+		t.Error("want every key led without leader election")
+	}
+}
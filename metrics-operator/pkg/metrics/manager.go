@@ -4,17 +4,41 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
-	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	"go.opencensus.io/stats/view"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
 type MetricManager struct {
 	Index *MetricIndex
 	runs  map[string]*sync.Once
-	rw    sync.RWMutex
+	// running keeps the in-flight runs so gauges can be re-evaluated
+	running map[string]*v1alpha1.RunDimensions
+	// defaults by resource, nil unless default metrics are enabled
+	defaults map[string]*defaultMetrics
+	// gates is nil unless gate metrics are enabled
+	gates *gateMetrics
+	// steps is nil unless step metrics are enabled
+	steps *stepMetrics
+	// skippedTasks is nil unless skipped task metrics are enabled
+	skippedTasks *skippedTaskMetrics
+	// activePipelines is nil unless the active pipelines gauge is enabled
+	activePipelines *activePipelines
+	// podRestarts is nil unless pod restart metrics are enabled
+	podRestarts *podRestartMetrics
+	// replay is nil unless the replay of completed runs is enabled
+	replay *replay
+	// annotators write the recorded-by annotation of runs by resource
+	annotators map[string]AnnotateFunc
+	// queues return the depth of the work queue of instrumented controllers
+	// by reconciler name
+	queues map[string]func() int
+	rw     sync.RWMutex
 }
 
 func (m *MetricManager) GetIndex() *MetricIndex {
@@ -27,25 +51,63 @@ func (m *MetricManager) clean(ctx context.Context, run *v1alpha1.RunDimensions)
 	defer m.rw.Unlock()
 
 	var uid types.UID
-	switch r := run.Object.(type) {
-	case *pipelinev1beta1.PipelineRun:
-		uid = r.ObjectMeta.UID
-	case *pipelinev1beta1.TaskRun:
-		uid = r.ObjectMeta.UID
+	if object, err := meta.Accessor(run.Object); err == nil {
+		uid = object.GetUID()
 	}
 	key := fmt.Sprintf("%s/%s/%s", run.Namespace, run.Name, uid)
 	_, exists := m.runs[key]
 	if exists {
 		delete(m.runs, key)
 	}
+	delete(m.running, key)
 }
 
-func NewManager(external view.Meter) *MetricManager {
+// cleanLater cleans the run after a while, keeping only its metadata in the
+// meantime
+func (m *MetricManager) cleanLater(ctx context.Context, resource string, object metav1.Object) {
+	run := recorder.MetadataRunDimensions(resource, meta.AsPartialObjectMetadata(object))
+	time.AfterFunc(1*time.Hour, func() {
+		m.clean(ctx, run)
+	})
+}
+
+// CleanDeleted cleans every series of a deleted run, which is only known by
+// its metadata
+func (m *MetricManager) CleanDeleted(ctx context.Context, resource string, object *metav1.PartialObjectMetadata) {
+	run := recorder.MetadataRunDimensions(resource, object)
+	run.IsDeleted = true
+	m.clean(ctx, run)
+}
+
+// EnableRunEvents posts a warning Event on the runs failing to be recorded by
+// a metric, so pipeline authors learn their run broke a metric contract
+func (m *MetricManager) EnableRunEvents() {
+	m.Index.runEvents = true
+}
+
+// EnableExemplars attaches the name, uid and trace of the run to histogram
+// samples, exported as exemplars by the backends supporting them. Samples with
+// attachments skip batched recording.
+func (m *MetricManager) EnableExemplars() {
+	m.Index.exemplars = true
+}
+
+// PurgeSeriesWith tells the purger about every view unregistered with its
+// metric or monitor, so exporters stop pushing the series of deleted metrics
+func (m *MetricManager) PurgeSeriesWith(purger ViewPurger) {
+	m.Index.purger = purger
+}
+
+func NewManager(external view.Meter, retries *RetryQueue) *MetricManager {
 	return &MetricManager{
 		Index: &MetricIndex{
 			external: external,
-			store:    map[string]RunMetric{},
+			store:    NewRegistry(),
+			retries:  retries,
 		},
-		runs: map[string]*sync.Once{},
+		runs:       map[string]*sync.Once{},
+		running:    map[string]*v1alpha1.RunDimensions{},
+		annotators: map[string]AnnotateFunc{},
+		queues:     map[string]func() int{},
 	}
 }
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// RecordCustomRunDone records a done CustomRun in the metrics of the
+// TaskMonitors of kind CustomRun
+func (m *MetricManager) RecordCustomRunDone(ctx context.Context, customRun *pipelinev1beta1.CustomRun) error {
+	if !customRun.IsDone() {
+		return fmt.Errorf("record custom run done called with a running CustomRun")
+	}
+	m.rw.Lock()
+	key := fmt.Sprintf("%s/%s/%s", customRun.GetNamespace(), customRun.GetName(), customRun.GetUID())
+	_, exists := m.runs[key]
+	if !exists {
+		m.runs[key] = &sync.Once{}
+	}
+	delete(m.running, key)
+
+	run := recorder.CustomRunDimensions(customRun)
+	ctx, marks := withRecordedMarks(ctx)
+
+	m.runs[key].Do(func() {
+		m.GetIndex().Record(ctx, run, "histogram")
+		m.GetIndex().Record(ctx, run, "counter")
+		m.GetIndex().Record(ctx, run, "gauge")
+		m.GetIndex().Record(ctx, run, "timeoutRatio")
+		m.GetIndex().Record(ctx, run, "durationBreakdown")
+		m.GetIndex().Record(ctx, run, "lastValue")
+		m.GetIndex().Record(ctx, run, "rate")
+		m.GetIndex().Record(ctx, run, "slo")
+		m.GetIndex().RecordLag(run, time.Now())
+	})
+	m.cleanLater(ctx, "customrun", customRun)
+	m.rw.Unlock()
+	// the run is marked without holding the lock, it's an API write
+	m.markRecorded(ctx, "customrun", run, marks)
+	return nil
+}
+
+func (m *MetricManager) RecordCustomRunRunning(ctx context.Context, customRun *pipelinev1beta1.CustomRun) error {
+	if customRun.IsDone() {
+		return fmt.Errorf("record custom run running called with a done CustomRun")
+	}
+	run := recorder.CustomRunDimensions(customRun)
+	m.trackRunning(customRun, run)
+	m.GetIndex().Record(ctx, run, "gauge")
+	return nil
+}
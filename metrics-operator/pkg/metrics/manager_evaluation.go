@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+)
+
+// trackRunning keeps the latest dimensions of an in-flight run, deleted runs
+// are forgotten
+func (m *MetricManager) trackRunning(object metav1.Object, run *v1alpha1.RunDimensions) {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	key := fmt.Sprintf("%s/%s/%s", object.GetNamespace(), object.GetName(), object.GetUID())
+	if run.IsDeleted {
+		delete(m.running, key)
+		return
+	}
+	m.running[key] = run
+}
+
+func (m *MetricManager) runningRuns() []*v1alpha1.RunDimensions {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	runs := make([]*v1alpha1.RunDimensions, 0, len(m.running))
+	for _, run := range m.running {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// RunEvaluationLoop re-records the gauges of every in-flight run, and the
+// elapsed time and age of in-flight runs by resource, on each interval until
+// the context is done. Gauges depending on the current time can't rely on run
+// events only to stay fresh.
+func (m *MetricManager) RunEvaluationLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// reported holds the resources of the last report, a resource without
+	// in-flight runs is reported once more with zero
+	reported := map[string]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			reported = m.evaluateRunning(ctx, now, reported)
+		}
+	}
+}
+
+func (m *MetricManager) evaluateRunning(ctx context.Context, now time.Time, reported map[string]bool) map[string]bool {
+	runs := m.runningRuns()
+	logging.FromContext(ctx).Debugw("re-evaluating gauges of in-flight runs", "runs", len(runs))
+	current := map[string]bool{}
+	elapsed, oldest := map[string]float64{}, map[string]float64{}
+	for _, run := range runs {
+		m.GetIndex().Record(ctx, run, "gauge")
+
+		current[run.Resource] = true
+		if started := runStartTime(run); started != nil {
+			elapsed[run.Resource] += now.Sub(started.Time).Seconds()
+		}
+		if object, err := meta.Accessor(run.Object); err == nil {
+			if age := now.Sub(object.GetCreationTimestamp().Time).Seconds(); age > oldest[run.Resource] {
+				oldest[run.Resource] = age
+			}
+		}
+	}
+	for resource := range reported {
+		if !current[resource] {
+			m.recordRunning(resource, 0, 0)
+		}
+	}
+	for resource := range current {
+		m.recordRunning(resource, elapsed[resource], oldest[resource])
+	}
+	return current
+}
+
+func (m *MetricManager) recordRunning(resource string, elapsed, oldest float64) {
+	selfmetrics.Record(m.Index.external, []tag.Mutator{tag.Upsert(selfmetrics.ResourceKey, resource)},
+		selfmetrics.RunningElapsed.M(elapsed), selfmetrics.OldestRunningAge.M(oldest))
+}
+
+// runStartTime is the start time of the run, nil until it started
+func runStartTime(run *v1alpha1.RunDimensions) *metav1.Time {
+	switch object := run.Object.(type) {
+	case *pipelinev1beta1.TaskRun:
+		return object.Status.StartTime
+	case *pipelinev1beta1.PipelineRun:
+		return object.Status.StartTime
+	case *pipelinev1beta1.CustomRun:
+		return object.Status.StartTime
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// countingMetric counts the recordings of each run by name
+type countingMetric struct {
+	metric  v1alpha1.Metric
+	records map[string]int
+	mu      sync.Mutex
+}
+
+func (c *countingMetric) MonitorId() string {
+	return "task/hello"
+}
+
+func (c *countingMetric) MetricName() string {
+	return "task_hello_" + c.metric.Name
+}
+
+func (c *countingMetric) Metric() *v1alpha1.Metric {
+	return &c.metric
+}
+
+func (c *countingMetric) View() *view.View {
+	return nil
+}
+
+func (c *countingMetric) Clean(context.Context, stats.Recorder, *v1alpha1.RunDimensions) {
+}
+
+func (c *countingMetric) Record(_ context.Context, _ stats.Recorder, run *v1alpha1.RunDimensions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records[run.Name]++
+	return nil
+}
+
+func (c *countingMetric) get(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.records[name]
+}
+
+func runningTaskRun(name string, uid types.UID) *v1beta1.TaskRun {
+	return &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev", UID: uid},
+		Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+		Status: v1beta1.TaskRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: v1.ConditionUnknown, Reason: "Running"},
+		}}},
+	}
+}
+
+func runningNames(m *MetricManager) []string {
+	names := []string{}
+	for _, run := range m.runningRuns() {
+		names = append(names, run.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestTrackRunning(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	manager := NewManager(meter, nil)
+	ctx := context.Background()
+
+	first, second := runningTaskRun("first", "1"), runningTaskRun("second", "2")
+	for _, taskRun := range []*v1beta1.TaskRun{first, second, first} {
+		if err := manager.RecordTaskRunRunning(ctx, taskRun); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff([]string{"first", "second"}, runningNames(manager)); diff != "" {
+		t.Errorf("running runs (-want, +got):\n%s", diff)
+	}
+
+	// the latest dimensions of a run replace the previous ones
+	updated := first.DeepCopy()
+	updated.Labels = map[string]string{"team": "a"}
+	manager.trackRunning(updated, recorder.TaskRunDimensions(updated))
+	for _, run := range manager.runningRuns() {
+		if run.Name == "first" && run.Labels["team"] != "a" {
+			t.Errorf("want the latest dimensions of the run, got labels %v", run.Labels)
+		}
+	}
+
+	deleted := second.DeepCopy()
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	manager.trackRunning(deleted, recorder.TaskRunDimensions(deleted))
+	if diff := cmp.Diff([]string{"first"}, runningNames(manager)); diff != "" {
+		t.Errorf("deleted runs are forgotten (-want, +got):\n%s", diff)
+	}
+
+	done := first.DeepCopy()
+	done.Status.Conditions = duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue}}
+	if err := manager.RecordTaskRunDone(ctx, done); err != nil {
+		t.Fatal(err)
+	}
+	if names := runningNames(manager); len(names) != 0 {
+		t.Errorf("want done runs forgotten, got %v", names)
+	}
+}
+
+func TestEvaluateRunning(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := selfmetrics.Register(meter); err != nil {
+		t.Fatal(err)
+	}
+	manager := NewManager(meter, nil)
+	gauge := &countingMetric{metric: v1alpha1.Metric{Name: "running", Type: "gauge"}, records: map[string]int{}}
+	manager.Index.store.Replace(gauge)
+	ctx := context.Background()
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := runningTaskRun("first", "1")
+	first.CreationTimestamp = metav1.Time{Time: now.Add(-5 * time.Minute)}
+	first.Status.StartTime = &metav1.Time{Time: now.Add(-4 * time.Minute)}
+	second := runningTaskRun("second", "2")
+	second.CreationTimestamp = metav1.Time{Time: now.Add(-2 * time.Minute)}
+	second.Status.StartTime = &metav1.Time{Time: now.Add(-time.Minute)}
+	// pending runs count for the age but not the elapsed time
+	pending := runningTaskRun("pending", "3")
+	pending.CreationTimestamp = metav1.Time{Time: now.Add(-30 * time.Second)}
+	for _, taskRun := range []*v1beta1.TaskRun{first, second, pending} {
+		manager.trackRunning(taskRun, recorder.TaskRunDimensions(taskRun))
+	}
+
+	lastValues := func(name string) map[string]float64 {
+		t.Helper()
+		rows, err := meter.RetrieveData(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]float64{}
+		for _, row := range rows {
+			values[row.Tags[0].Value] = row.Data.(*view.LastValueData).Value
+		}
+		return values
+	}
+
+	reported := manager.evaluateRunning(ctx, now, map[string]bool{})
+	if records := gauge.get("first"); records != 1 {
+		t.Errorf("want the gauge re-recorded once, got %d recordings", records)
+	}
+	if diff := cmp.Diff(map[string]float64{"taskrun": 300}, lastValues(selfmetrics.RunningElapsed.Name())); diff != "" {
+		t.Errorf("elapsed seconds (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]float64{"taskrun": 300}, lastValues(selfmetrics.OldestRunningAge.Name())); diff != "" {
+		t.Errorf("oldest age (-want, +got):\n%s", diff)
+	}
+
+	// the gauges move with the clock without run events
+	reported = manager.evaluateRunning(ctx, now.Add(time.Minute), reported)
+	if diff := cmp.Diff(map[string]float64{"taskrun": 420}, lastValues(selfmetrics.RunningElapsed.Name())); diff != "" {
+		t.Errorf("elapsed seconds a minute later (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]float64{"taskrun": 360}, lastValues(selfmetrics.OldestRunningAge.Name())); diff != "" {
+		t.Errorf("oldest age a minute later (-want, +got):\n%s", diff)
+	}
+
+	// resources without in-flight runs are reported with zero
+	for _, taskRun := range []*v1beta1.TaskRun{first, second, pending} {
+		deleted := taskRun.DeepCopy()
+		deleted.DeletionTimestamp = &metav1.Time{Time: now}
+		manager.trackRunning(deleted, recorder.TaskRunDimensions(deleted))
+	}
+	if reported = manager.evaluateRunning(ctx, now.Add(2*time.Minute), reported); len(reported) != 0 {
+		t.Errorf("want no resource reported, got %v", reported)
+	}
+	if diff := cmp.Diff(map[string]float64{"taskrun": 0}, lastValues(selfmetrics.RunningElapsed.Name())); diff != "" {
+		t.Errorf("elapsed seconds without runs (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]float64{"taskrun": 0}, lastValues(selfmetrics.OldestRunningAge.Name())); diff != "" {
+		t.Errorf("oldest age without runs (-want, +got):\n%s", diff)
+	}
+}
+
+func TestRunEvaluationLoop(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	manager := NewManager(meter, nil)
+	gauge := &countingMetric{metric: v1alpha1.Metric{Name: "running", Type: "gauge"}, records: map[string]int{}}
+	manager.Index.store.Replace(gauge)
+	manager.trackRunning(runningTaskRun("first", "1"), recorder.TaskRunDimensions(runningTaskRun("first", "1")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		manager.RunEvaluationLoop(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for gauge.get("first") < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if records := gauge.get("first"); records < 2 {
+		t.Errorf("want the gauge re-recorded on every interval, got %d recordings", records)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("evaluation loop didn't stop with its context")
+	}
+}
+
+func TestRunEvaluationLoopDisabled(t *testing.T) {
+	manager := NewManager(view.NewMeter(), nil)
+	done := make(chan struct{})
+	go func() {
+		// returns right away without an interval
+		manager.RunEvaluationLoop(context.Background(), 0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("want the loop disabled without an interval")
+	}
+}
@@ -15,23 +15,36 @@ func (m *MetricManager) RecordPipelineRunDone(ctx context.Context, pipelineRun *
 		return fmt.Errorf("record pipeline run done called with a running TaskRun")
 	}
 	m.rw.Lock()
-	defer m.rw.Unlock()
 	key := fmt.Sprintf("%s/%s/%s", pipelineRun.GetNamespace(), pipelineRun.GetName(), pipelineRun.GetUID())
 	_, exists := m.runs[key]
 	if !exists {
 		m.runs[key] = &sync.Once{}
 	}
+	delete(m.running, key)
 
 	run := recorder.PipelineRunDimensions(pipelineRun)
+	m.observePipeline(ctx, pipelineRun)
+	ctx, marks := withRecordedMarks(ctx)
 
 	m.runs[key].Do(func() {
 		m.GetIndex().Record(ctx, run, "histogram")
 		m.GetIndex().Record(ctx, run, "counter")
 		m.GetIndex().Record(ctx, run, "gauge")
+		m.GetIndex().Record(ctx, run, "timeoutRatio")
+		m.GetIndex().Record(ctx, run, "durationBreakdown")
+		m.GetIndex().Record(ctx, run, "lastValue")
+		m.GetIndex().Record(ctx, run, "rate")
+		m.GetIndex().Record(ctx, run, "slo")
+		m.GetIndex().RecordLag(run, time.Now())
+		m.GetIndex().Record(ctx, run, "childStates")
+		m.GetIndex().Record(ctx, run, "childOutcomes")
+		m.recordDefaults(ctx, run)
+		m.recordSkippedTasks(ctx, run)
 	})
-	time.AfterFunc(1*time.Hour, func() {
-		m.clean(ctx, run)
-	})
+	m.cleanLater(ctx, "pipelinerun", pipelineRun)
+	m.rw.Unlock()
+	// the run is marked without holding the lock, it's an API write
+	m.markRecorded(ctx, "pipelinerun", run, marks)
 	return nil
 }
 
@@ -40,6 +53,9 @@ func (m *MetricManager) RecordPipelineRunRunning(ctx context.Context, pipelineRu
 		return fmt.Errorf("record task run running called with a done PipelineRun")
 	}
 	run := recorder.PipelineRunDimensions(pipelineRun)
+	m.observePipeline(ctx, pipelineRun)
+	m.trackRunning(pipelineRun, run)
 	m.GetIndex().Record(ctx, run, "gauge")
+	m.GetIndex().Record(ctx, run, "childStates")
 	return nil
 }
@@ -15,23 +15,34 @@ func (m *MetricManager) RecordTaskRunDone(ctx context.Context, taskRun *pipeline
 		return fmt.Errorf("record task run done called with a running TaskRun")
 	}
 	m.rw.Lock()
-	defer m.rw.Unlock()
 	key := fmt.Sprintf("%s/%s/%s", taskRun.GetNamespace(), taskRun.GetName(), taskRun.GetUID())
 	_, exists := m.runs[key]
 	if !exists {
 		m.runs[key] = &sync.Once{}
 	}
+	delete(m.running, key)
 
 	run := recorder.TaskRunDimensions(taskRun)
+	ctx, marks := withRecordedMarks(ctx)
 
 	m.runs[key].Do(func() {
 		m.GetIndex().Record(ctx, run, "histogram")
 		m.GetIndex().Record(ctx, run, "counter")
 		m.GetIndex().Record(ctx, run, "gauge")
+		m.GetIndex().Record(ctx, run, "timeoutRatio")
+		m.GetIndex().Record(ctx, run, "durationBreakdown")
+		m.GetIndex().Record(ctx, run, "lastValue")
+		m.GetIndex().Record(ctx, run, "rate")
+		m.GetIndex().Record(ctx, run, "slo")
+		m.GetIndex().RecordLag(run, time.Now())
+		m.recordDefaults(ctx, run)
+		m.recordSteps(ctx, run)
+		m.recordPodRestarts(ctx, taskRun)
 	})
-	time.AfterFunc(1*time.Hour, func() {
-		m.clean(ctx, run)
-	})
+	m.cleanLater(ctx, "taskrun", taskRun)
+	m.rw.Unlock()
+	// the run is marked without holding the lock, it's an API write
+	m.markRecorded(ctx, "taskrun", run, marks)
 	return nil
 }
 
@@ -40,6 +51,7 @@ func (m *MetricManager) RecordTaskRunRunning(ctx context.Context, taskRun *pipel
 		return fmt.Errorf("record task run running called with a done TaskRun")
 	}
 	run := recorder.TaskRunDimensions(taskRun)
+	m.trackRunning(taskRun, run)
 	m.GetIndex().Record(ctx, run, "gauge")
 	return nil
 }
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"go.opencensus.io/stats/view"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCleanDeleted(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	manager := NewManager(meter, nil)
+	ctx := context.Background()
+
+	metric := v1alpha1.ExpandPreset(v1alpha1.Metric{Preset: v1alpha1.PresetRunning})
+	gauge := recorder.NewTaskRunGauge(&metric, &v1alpha1.TaskRunMonitor{ObjectMeta: metav1.ObjectMeta{Name: "builds"}})
+	if err := manager.GetIndex().RegisterRunMetric(ctx, gauge); err != nil {
+		t.Fatal(err)
+	}
+	running := func() float64 {
+		t.Helper()
+		rows, err := meter.RetrieveData(gauge.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("want a single series, got %v", rows)
+		}
+		return rows[0].Data.(*view.LastValueData).Value
+	}
+
+	taskRun := runningTaskRun("first", "1")
+	if err := manager.RecordTaskRunRunning(ctx, taskRun); err != nil {
+		t.Fatal(err)
+	}
+	if got := running(); got != 1 {
+		t.Fatalf("want 1 running, got %v", got)
+	}
+
+	// the metadata informer only knows the metadata of the deleted run
+	deleted := meta.AsPartialObjectMetadata(taskRun)
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	manager.CleanDeleted(ctx, "taskrun", deleted)
+	if got := running(); got != 0 {
+		t.Errorf("want 0 running after the deletion, got %v", got)
+	}
+	if names := runningNames(manager); len(names) != 0 {
+		t.Errorf("want deleted runs forgotten, got %v", names)
+	}
+}
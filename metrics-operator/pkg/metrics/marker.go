@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+)
+
+// RecordedByAnnotation lists the metrics that recorded a done run, as comma
+// separated <monitor>/<metric>@<hash> entries, e.g.
+// task/hello/runs@1a2b3c4d. The hash identifies the spec of the metric, a run
+// isn't recorded again by the same spec after a restart.
+const RecordedByAnnotation = "metrics.tekton.dev/recorded-by"
+
+// markerHashLength is the number of hexadecimal digits of the spec hash kept
+// in markers
+const markerHashLength = 8
+
+// markerEntry returns the entry of the metric in the recorded-by annotation
+func markerEntry(metric RunMetric) (string, error) {
+	hash, err := recorder.SpecHash(metric.Metric())
+	if err != nil {
+		return "", err
+	}
+	return metric.MonitorId() + "/" + metric.Metric().Name + "@" + hash[:markerHashLength], nil
+}
+
+// markedRecorded returns true when the recorded-by annotation of the run
+// lists the current spec of the metric
+func markedRecorded(metric RunMetric, run *v1alpha1.RunDimensions) bool {
+	object, err := meta.Accessor(run.Object)
+	if err != nil {
+		return false
+	}
+	marker, exists := object.GetAnnotations()[RecordedByAnnotation]
+	if !exists {
+		return false
+	}
+	entry, err := markerEntry(metric)
+	if err != nil {
+		return false
+	}
+	for _, recorded := range strings.Split(marker, ",") {
+		if strings.TrimSpace(recorded) == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotateFunc writes an annotation on a run, failures that may succeed later
+// are wrapped with RetryableAPIError
+type AnnotateFunc func(ctx context.Context, object metav1.Object, key, value string) error
+
+// RetryableAPIError marks the API errors of a write that may succeed later,
+// like a conflict or a throttled or timed out request, as retryable
+func RetryableAPIError(err error) error {
+	if apierrors.IsConflict(err) || apierrors.IsTooManyRequests(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsServerTimeout(err) || apierrors.IsServiceUnavailable(err) {
+		return recorder.Retryable(err)
+	}
+	return err
+}
+
+// AnnotateRunsWith writes the recorded-by annotation of the runs of the
+// resource with annotate, the runs are never marked otherwise
+func (m *MetricManager) AnnotateRunsWith(resource string, annotate AnnotateFunc) {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	m.annotators[resource] = annotate
+}
+
+// recordedMarks collects the entries of the metrics that recorded a run and
+// mark the runs they record
+type recordedMarks struct {
+	entries []string
+	mu      sync.Mutex
+}
+
+type recordedMarksKey struct{}
+
+func withRecordedMarks(ctx context.Context) (context.Context, *recordedMarks) {
+	marks := &recordedMarks{}
+	return context.WithValue(ctx, recordedMarksKey{}, marks), marks
+}
+
+// observeRecorded adds the entry of the metric to the marks of the context
+// when its monitor marks the runs it records
+func observeRecorded(ctx context.Context, metric RunMetric) {
+	marks, ok := ctx.Value(recordedMarksKey{}).(*recordedMarks)
+	if !ok {
+		return
+	}
+	if marker, ok := metric.(recorder.RecordedMarker); !ok || !marker.MarksRecorded() {
+		return
+	}
+	entry, err := markerEntry(metric)
+	if err != nil {
+		return
+	}
+	marks.mu.Lock()
+	defer marks.mu.Unlock()
+	marks.entries = append(marks.entries, entry)
+}
+
+// annotation merges the collected entries into the current value of the
+// annotation, replacing the entries of previous specs of the same metrics.
+// Returns false when the value is unchanged.
+func (r *recordedMarks) annotation(current string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return current, false
+	}
+	entries := map[string]string{}
+	for _, entry := range strings.Split(current, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			metric, _, _ := strings.Cut(entry, "@")
+			entries[metric] = entry
+		}
+	}
+	for _, entry := range r.entries {
+		metric, _, _ := strings.Cut(entry, "@")
+		entries[metric] = entry
+	}
+	merged := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		merged = append(merged, entry)
+	}
+	sort.Strings(merged)
+	value := strings.Join(merged, ",")
+	return value, value != current
+}
+
+// markRecorded writes the marks collected while recording the run into its
+// recorded-by annotation. Marks are best effort, retryable failures are
+// retried and a failed write only means the run may be recorded again by a
+// replay.
+func (m *MetricManager) markRecorded(ctx context.Context, resource string, run *v1alpha1.RunDimensions, marks *recordedMarks) {
+	m.rw.RLock()
+	annotate := m.annotators[resource]
+	m.rw.RUnlock()
+	if annotate == nil {
+		return
+	}
+	object, err := meta.Accessor(run.Object)
+	if err != nil {
+		return
+	}
+	value, changed := marks.annotation(object.GetAnnotations()[RecordedByAnnotation])
+	if !changed {
+		return
+	}
+	write := func(ctx context.Context) error {
+		return annotate(ctx, object, RecordedByAnnotation, value)
+	}
+	err = write(ctx)
+	if err == nil {
+		return
+	}
+	logger := logging.FromContext(ctx).With(zap.String("run", run.GetId()))
+	if recorder.IsRetryable(err) && m.Index.retries != nil && m.Index.retries.Add("annotation/"+run.GetId(), write) {
+		logger.Warnw("failed to mark recorded run, retrying", zap.Error(err))
+		return
+	}
+	logger.Warnw("failed to mark recorded run", zap.Error(err))
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestRecordTaskRunDoneMarksRecordedRuns(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	annotations := map[string]string{}
+	manager.AnnotateRunsWith("taskrun", func(_ context.Context, object metav1.Object, key, value string) error {
+		annotations[object.GetName()+"/"+key] = value
+		return nil
+	})
+
+	ctx := context.Background()
+	register := func(name string, markRecorded bool) RunMetric {
+		taskMonitor := &v1alpha1.TaskMonitor{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.TaskMonitorSpec{
+				TaskName:     "hello-world",
+				Metrics:      []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+				MarkRecorded: markRecorded,
+			},
+		}
+		counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+		if err := manager.GetIndex().RegisterRunMetric(ctx, counter); err != nil {
+			t.Fatal(err)
+		}
+		return counter
+	}
+	marked := register("marked", true)
+	register("unmarked", false)
+	entry, err := markerEntry(marked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello-world-1",
+			Namespace: "dev",
+			UID:       types.UID("uid-1"),
+			// the entry of a previous spec is replaced
+			Annotations: map[string]string{RecordedByAnnotation: "task/marked/runs@00000000,task/other/runs@00000000"},
+		},
+		Spec: v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+		Status: v1beta1.TaskRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+		}}},
+	}
+	if err := manager.RecordTaskRunDone(ctx, taskRun); err != nil {
+		t.Fatal(err)
+	}
+	want := entry + ",task/other/runs@00000000"
+	if got := annotations["hello-world-1/"+RecordedByAnnotation]; got != want {
+		t.Errorf("want annotation %q, got %q", want, got)
+	}
+
+	// a run already marked by the current spec is neither recorded nor marked
+	delete(annotations, "hello-world-1/"+RecordedByAnnotation)
+	restarted := taskRun.DeepCopy()
+	restarted.UID = "uid-2"
+	restarted.Annotations[RecordedByAnnotation] = entry
+	if err := manager.RecordTaskRunDone(ctx, restarted); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := annotations["hello-world-1/"+RecordedByAnnotation]; exists {
+		t.Errorf("want a marked run left untouched")
+	}
+	rows, err := external.RetrieveData(marked.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows[0].Data.(*view.CountData).Value; got != 1 {
+		t.Errorf("want the marked run recorded once, got %d samples", got)
+	}
+}
+
+func TestRecordPipelineRunDoneMarksRecordedRuns(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	annotations := map[string]string{}
+	manager.AnnotateRunsWith("pipelinerun", func(_ context.Context, object metav1.Object, key, value string) error {
+		annotations[object.GetName()+"/"+key] = value
+		return nil
+	})
+
+	ctx := context.Background()
+	pipelineMonitor := func(name string, markRecorded bool) RunMetric {
+		monitor := &v1alpha1.PipelineMonitor{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.PipelineMonitorSpec{
+				PipelineName: "release",
+				Metrics:      []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+				MarkRecorded: markRecorded,
+			},
+		}
+		return recorder.NewPipelineCounter(&monitor.Spec.Metrics[0], monitor)
+	}
+	pipelineRunMonitor := &v1alpha1.PipelineRunMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "all"},
+		Spec: v1alpha1.PipelineRunMonitorSpec{
+			Metrics:      []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+			MarkRecorded: true,
+		},
+	}
+	marked := []RunMetric{
+		pipelineMonitor("marked", true),
+		recorder.NewPipelineRunCounter(&pipelineRunMonitor.Spec.Metrics[0], pipelineRunMonitor),
+	}
+	for _, runMetric := range append(marked, pipelineMonitor("unmarked", false)) {
+		if err := manager.GetIndex().RegisterRunMetric(ctx, runMetric); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := []string{}
+	for _, runMetric := range marked {
+		entry, err := markerEntry(runMetric)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	pipelineRun := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-1", Namespace: "dev", UID: types.UID("uid-1")},
+		Spec:       v1beta1.PipelineRunSpec{PipelineRef: &v1beta1.PipelineRef{Name: "release"}},
+		Status: v1beta1.PipelineRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+		}}},
+	}
+	if err := manager.RecordPipelineRunDone(ctx, pipelineRun); err != nil {
+		t.Fatal(err)
+	}
+	// entries are sorted, pipeline ones first
+	want := entries[0] + "," + entries[1]
+	if got := annotations["release-1/"+RecordedByAnnotation]; got != want {
+		t.Errorf("want annotation %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
+)
+
+// monitorEventStore holds the references of the reconciled monitors and when
+// each metric last posted an Event, a broken JSONPath fails on every run and
+// would otherwise flood the monitor with Events
+type monitorEventStore struct {
+	interval time.Duration
+	refs     map[string]*corev1.ObjectReference
+	posted   map[string]time.Time
+	rw       sync.Mutex
+}
+
+// EnableMonitorEvents posts a warning Event on the monitor of a metric failing
+// to record a run, at most once per interval and metric
+func (m *MetricManager) EnableMonitorEvents(interval time.Duration) {
+	m.Index.monitorEvents = &monitorEventStore{
+		interval: interval,
+		refs:     map[string]*corev1.ObjectReference{},
+		posted:   map[string]time.Time{},
+	}
+}
+
+// MonitorReference returns the reference of a monitor of the given kind, the
+// objects of listers have no type meta
+func MonitorReference(kind string, monitor metav1.Object) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       kind,
+		Namespace:  monitor.GetNamespace(),
+		Name:       monitor.GetName(),
+		UID:        monitor.GetUID(),
+	}
+}
+
+// SetMonitorReference tells the index which object Events about the metrics of
+// the monitor are posted on
+func (m *MetricIndex) SetMonitorReference(resource, monitor string, ref *corev1.ObjectReference) {
+	s := m.monitorEvents
+	if s == nil {
+		return
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	s.refs[naming.MonitorId(resource, monitor)] = ref
+}
+
+// forget drops the reference of a deleted monitor
+func (s *monitorEventStore) forget(monitorId string) {
+	if s == nil {
+		return
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	delete(s.refs, monitorId)
+}
+
+// allow returns the reference of the monitor of the metric when no Event was
+// posted for the metric within the interval
+func (s *monitorEventStore) allow(metric RunMetric, now time.Time) (*corev1.ObjectReference, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	ref, exists := s.refs[metric.MonitorId()]
+	if !exists {
+		return nil, false
+	}
+	if last, posted := s.posted[metric.MetricName()]; posted && now.Sub(last) < s.interval {
+		return nil, false
+	}
+	s.posted[metric.MetricName()] = now
+	return ref, true
+}
+
+// postMonitorEvent tells the authors of the monitor that a metric failed on a
+// run, e.g. on a broken JSONPath or tag. The event recorder is only in the
+// context when recording from a run reconciler.
+func (m *MetricIndex) postMonitorEvent(ctx context.Context, metric RunMetric, run *v1alpha1.RunDimensions, err error) {
+	eventRecorder := controller.GetEventRecorder(ctx)
+	if eventRecorder == nil {
+		return
+	}
+	ref, ok := m.monitorEvents.allow(metric, time.Now())
+	if !ok {
+		return
+	}
+	eventRecorder.Eventf(ref, corev1.EventTypeWarning, "MetricRecordingFailed", "metric %s could not record %s %s/%s: %v", metric.Metric().Name, run.Resource, run.Namespace, run.Name, err)
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
+)
+
+func TestMonitorEventStore(t *testing.T) {
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "dev"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "build",
+			Metrics:  []v1alpha1.Metric{{Type: "counter", Name: "runs"}},
+		},
+	}
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	manager := NewManager(nil, nil)
+	manager.EnableMonitorEvents(time.Minute)
+	index := manager.GetIndex()
+	now := time.Now()
+
+	if _, ok := index.monitorEvents.allow(counter, now); ok {
+		t.Error("want no event before the monitor is reconciled")
+	}
+	index.SetMonitorReference("task", taskMonitor.Name, MonitorReference("TaskMonitor", taskMonitor))
+	ref, ok := index.monitorEvents.allow(counter, now)
+	if !ok {
+		t.Fatal("want an event once the monitor is reconciled")
+	}
+	if ref.Kind != "TaskMonitor" || ref.Namespace != "dev" || ref.Name != "build" {
+		t.Errorf("unexpected monitor reference %+v", ref)
+	}
+	if _, ok := index.monitorEvents.allow(counter, now.Add(59*time.Second)); ok {
+		t.Error("want events rate limited within the interval")
+	}
+	if _, ok := index.monitorEvents.allow(counter, now.Add(time.Minute)); !ok {
+		t.Error("want an event once the interval elapsed")
+	}
+
+	if err := index.UnregisterAllMetricsMonitor("task", taskMonitor.Name); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := index.monitorEvents.allow(counter, now.Add(time.Hour)); ok {
+		t.Error("want no event once the monitor is deleted")
+	}
+}
+
+func TestRunEvents(t *testing.T) {
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "dev"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "build",
+			// a histogram without a duration nor a value fails every run
+			Metrics: []v1alpha1.Metric{{Type: "histogram", Name: "duration"}},
+		},
+	}
+	histogram := recorder.NewTaskHistogram(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	index := manager.GetIndex()
+	if err := index.RegisterRunMetric(context.Background(), histogram); err != nil {
+		t.Fatal(err)
+	}
+
+	eventRecorder := record.NewFakeRecorder(10)
+	ctx := controller.WithEventRecorder(context.Background(), eventRecorder)
+	run := recorder.TaskRunDimensions(&v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1", Namespace: "dev"},
+		Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "build"}},
+	})
+
+	index.Record(ctx, run, "histogram")
+	if len(eventRecorder.Events) != 0 {
+		t.Errorf("want no event unless enabled, got %s", <-eventRecorder.Events)
+	}
+
+	manager.EnableRunEvents()
+	index.Record(ctx, run, "histogram")
+	if len(eventRecorder.Events) != 1 {
+		t.Fatalf("want an event on the run, got %d", len(eventRecorder.Events))
+	}
+	want := "Warning MetricRecordingFailed metric " + histogram.MetricName() + " of task/build could not be recorded: histogram requires a duration or a value"
+	if got := <-eventRecorder.Events; got != want {
+		t.Errorf("want event %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+)
+
+// leaderAwareReconciler is implemented by the generated reconcilers, the
+// controller only runs leader election for them
+type leaderAwareReconciler interface {
+	controller.Reconciler
+	reconciler.LeaderAware
+}
+
+// timedReconciler records the duration and result of every reconcile
+type timedReconciler struct {
+	leaderAwareReconciler
+	name    string
+	manager *MetricManager
+}
+
+func (r *timedReconciler) Reconcile(ctx context.Context, key string) error {
+	start := time.Now()
+	err := r.leaderAwareReconciler.Reconcile(ctx, key)
+	// replicas not leading the key skip it
+	if controller.IsSkipKey(err) {
+		return err
+	}
+	result := selfmetrics.ResultSuccess
+	if requeue, _ := controller.IsRequeueKey(err); err != nil && !requeue {
+		result = selfmetrics.ResultFailure
+	}
+	selfmetrics.Record(r.manager.Index.external, []tag.Mutator{
+		tag.Upsert(selfmetrics.ReconcilerKey, r.name),
+		tag.Upsert(selfmetrics.ResultKey, result),
+	}, selfmetrics.ReconcileDuration.M(time.Since(start).Seconds()))
+	return err
+}
+
+// Instrument times the reconciles of the controller, and reports the depth of
+// its work queue on every operator loop, under the given reconciler name
+func (m *MetricManager) Instrument(name string, impl *controller.Impl) {
+	if r, ok := impl.Reconciler.(leaderAwareReconciler); ok {
+		impl.Reconciler = &timedReconciler{leaderAwareReconciler: r, name: name, manager: m}
+	}
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	m.queues[name] = impl.WorkQueue().Len
+}
+
+// observeRecording counts the result of recording a run by a monitor metric
+func (m *MetricIndex) observeRecording(metric RunMetric, err error) {
+	result := selfmetrics.ResultSuccess
+	switch {
+	case recorder.IsSkipped(err):
+		result = selfmetrics.ResultSkipped
+	case err != nil:
+		result = selfmetrics.ResultFailure
+	}
+	selfmetrics.Record(m.external, []tag.Mutator{
+		tag.Upsert(selfmetrics.MonitorKey, metric.MonitorId()),
+		tag.Upsert(selfmetrics.MetricKey, metric.MetricName()),
+		tag.Upsert(selfmetrics.ResultKey, result),
+	}, selfmetrics.Recordings.M(1))
+}
+
+// RunOperatorLoop records the depth of the work queues of instrumented
+// controllers and the number of views registered for monitors on each
+// interval until the context is done
+func (m *MetricManager) RunOperatorLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.recordOperatorGauges()
+		}
+	}
+}
+
+func (m *MetricManager) recordOperatorGauges() {
+	m.rw.RLock()
+	depths := make(map[string]int, len(m.queues))
+	for name, depth := range m.queues {
+		depths[name] = depth()
+	}
+	m.rw.RUnlock()
+	for name, depth := range depths {
+		selfmetrics.Record(m.Index.external, []tag.Mutator{tag.Upsert(selfmetrics.ReconcilerKey, name)}, selfmetrics.QueueDepth.M(int64(depth)))
+	}
+
+	views := 0
+	for _, metric := range m.Index.store.List() {
+		views += len(runMetricViews(metric))
+	}
+	selfmetrics.Record(m.Index.external, nil, selfmetrics.RegisteredViews.M(int64(views)))
+}
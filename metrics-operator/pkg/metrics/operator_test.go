@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestOperatorMetrics(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := selfmetrics.Register(meter); err != nil {
+		t.Fatal(err)
+	}
+	manager := NewManager(meter, nil)
+	index := manager.GetIndex()
+
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics:  []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+		},
+	}
+	ctx := context.Background()
+	if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)); err != nil {
+		t.Fatal(err)
+	}
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello-world-xpto0",
+			Namespace: "dev",
+			Labels:    map[string]string{"tekton.dev/task": "hello-world"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				},
+			},
+		},
+	}
+	index.Record(ctx, recorder.TaskRunDimensions(taskRun), "counter")
+
+	rows, err := meter.RetrieveData(selfmetrics.Recordings.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.CountData).Value != 1 {
+		t.Fatalf("want a single successful recording, got %v", rows)
+	}
+	tags := map[string]string{}
+	for _, tag := range rows[0].Tags {
+		tags[tag.Key.Name()] = tag.Value
+	}
+	if tags["monitor"] != "task/hello" || tags["metric"] != "task_hello_runs_total" || tags["result"] != selfmetrics.ResultSuccess {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	manager.queues["taskrun"] = func() int { return 3 }
+	manager.recordOperatorGauges()
+	lastValue := func(name string) float64 {
+		t.Helper()
+		rows, err := meter.RetrieveData(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("want a single series of %s, got %v", name, rows)
+		}
+		return rows[0].Data.(*view.LastValueData).Value
+	}
+	if depth := lastValue(selfmetrics.QueueDepth.Name()); depth != 3 {
+		t.Errorf("want a queue depth of 3, got %v", depth)
+	}
+	if views := lastValue(selfmetrics.RegisteredViews.Name()); views != 1 {
+		t.Errorf("want 1 registered view, got %v", views)
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// PodMonitorName is the monitor name used in the name of the pod restart
+// counter
+const PodMonitorName = "pod"
+
+var stepKey = tag.MustNewKey("step")
+
+// podRestartMetrics counts the restarts of the step containers of TaskRun
+// pods, silent restarts inside successful TaskRuns often explain duration
+// outliers
+type podRestartMetrics struct {
+	restarts *stats.Float64Measure
+	view     *view.View
+}
+
+// EnablePodRestartMetrics registers the pod restart counter, recorded from
+// then on for every done TaskRun whose pod is still cached. The pods are read
+// from the pod lister of the recording context.
+func (m *MetricManager) EnablePodRestartMetrics() error {
+	p := &podRestartMetrics{}
+	p.restarts = stats.Float64(naming.CounterMetric("taskrun", PodMonitorName, "restarts"), "restarts of the step containers of taskrun pods", stats.UnitDimensionless)
+	p.view = &view.View{
+		Description: p.restarts.Description(),
+		Measure:     p.restarts,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{namespaceKey, taskKey, stepKey},
+	}
+	if err := m.Index.external.Register(p.view); err != nil {
+		return err
+	}
+	m.podRestarts = p
+	return nil
+}
+
+// recordPodRestarts reads the pod of the done TaskRun from the pod lister of
+// the context, pods not cached are skipped
+func (m *MetricManager) recordPodRestarts(ctx context.Context, taskRun *pipelinev1beta1.TaskRun) {
+	if m.podRestarts == nil {
+		return
+	}
+	if err := m.podRestarts.record(ctx, m.Index.external, taskRun); err != nil {
+		logging.FromContext(ctx).Debugw("unable to record pod restarts", zap.String("pod", taskRun.Status.PodName), zap.Error(err))
+	}
+}
+
+func (p *podRestartMetrics) record(ctx context.Context, meter view.Meter, taskRun *pipelinev1beta1.TaskRun) error {
+	pod, err := recorder.TaskRunPod(ctx, taskRun)
+	if err != nil {
+		return err
+	}
+	task, exists := taskRun.Labels["tekton.dev/task"]
+	if !exists {
+		task = "MISSING"
+	}
+	for _, container := range pod.Status.ContainerStatuses {
+		if !strings.HasPrefix(container.Name, "step-") || container.RestartCount == 0 {
+			continue
+		}
+		step := strings.TrimPrefix(container.Name, "step-")
+		tagCtx, err := tag.New(ctx, tag.Upsert(namespaceKey, taskRun.Namespace), tag.Upsert(taskKey, task), tag.Upsert(stepKey, step))
+		if err != nil {
+			return err
+		}
+		meter.Record(tag.FromContext(tagCtx), []stats.Measurement{p.restarts.M(float64(container.RestartCount))}, map[string]any{})
+	}
+	return nil
+}
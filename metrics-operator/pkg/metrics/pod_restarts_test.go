@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestRecordPodRestarts(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1-pod", Namespace: "dev"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "prepare", RestartCount: 1}},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-build", RestartCount: 2},
+				{Name: "step-test"},
+				{Name: "sidecar-proxy", RestartCount: 3},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.EnablePodRestartMetrics(); err != nil {
+		t.Fatal(err)
+	}
+
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1", Namespace: "dev", UID: "1", Labels: map[string]string{"tekton.dev/task": "build"}},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1.Status{Conditions: duckv1.Conditions{
+				{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue},
+			}},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{PodName: "build-1-pod"},
+		},
+	}
+	// the reconciler reads the pods of TaskRuns from its lister
+	ctx := recorder.WithPodLister(context.Background(), corev1listers.NewPodLister(indexer))
+	// restarts of other containers and steps without restart aren't counted
+	if err := manager.RecordTaskRunDone(ctx, taskRun); err != nil {
+		t.Fatal(err)
+	}
+	// pods deleted or not cached yet are skipped
+	gone := taskRun.DeepCopy()
+	gone.Status.PodName = "build-2-pod"
+	if err := manager.podRestarts.record(ctx, external, gone); !recorder.IsSkipped(err) {
+		t.Errorf("want a pod already deleted skipped, got %v", err)
+	}
+	if err := manager.podRestarts.record(context.Background(), external, taskRun); !recorder.IsSkipped(err) {
+		t.Errorf("want pods skipped without lister, got %v", err)
+	}
+
+	rows, err := external.RetrieveData(manager.podRestarts.restarts.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tag := range row.Tags {
+			tags[tag.Key.Name()] = tag.Value
+		}
+		got[tags["namespace"]+"/"+tags["task"]+"/"+tags["step"]] = row.Data.(*view.SumData).Value
+	}
+	if diff := cmp.Diff(map[string]float64{"dev/build/build": 2}, got); diff != "" {
+		t.Errorf("restarts (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+// Tick recomputes the metrics depending on the current time, like rates
+// whose runs leave their window
+func (m *MetricIndex) Tick(ctx context.Context, now time.Time) {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	for _, metric := range m.store.List() {
+		if ticker, ok := metric.(recorder.Ticker); ok {
+			ticker.Tick(ctx, m.recording(), now)
+		}
+	}
+}
+
+// RunRateLoop recomputes the rate metrics of monitors on each interval until
+// the context is done, without it rates only change when runs complete
+func (m *MetricManager) RunRateLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.GetIndex().Tick(ctx, now)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package recorder
+
+import (
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// ForAttempt returns the run as seen by a metric recording the given attempt
+// of retried runs. The first attempt of a retried TaskRun is the first entry
+// of .status.retriesStatus once it's done, other runs are returned as is.
+func ForAttempt(run *v1alpha1.RunDimensions, policy v1alpha1.AttemptPolicy) *v1alpha1.RunDimensions {
+	if policy != v1alpha1.AttemptsFirst {
+		return run
+	}
+	taskRun, ok := run.Object.(*pipelinev1beta1.TaskRun)
+	if !ok || !taskRun.IsDone() || len(taskRun.Status.RetriesStatus) == 0 {
+		return run
+	}
+	first := taskRun.DeepCopy()
+	first.Status = *taskRun.Status.RetriesStatus[0].DeepCopy()
+	first.Status.RetriesStatus = nil
+	attempt := *run
+	attempt.Status = first.Status.Status
+	attempt.Object = first
+	return &attempt
+}
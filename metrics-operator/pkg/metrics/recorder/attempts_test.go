@@ -0,0 +1,59 @@
+package recorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// retriedTaskRun failed its first attempt and succeeded its second one
+func retriedTaskRun(status corev1.ConditionStatus) *pipelinev1beta1.TaskRun {
+	taskRun := timedTaskRun("a", &metav1.Duration{Duration: time.Hour}, 10*time.Minute)
+	taskRun.Status.Conditions = duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}}
+	first := pipelinev1beta1.TaskRunStatus{}
+	first.Conditions = duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed"}}
+	first.StartTime = taskRun.Status.StartTime
+	first.CompletionTime = &metav1.Time{Time: taskRun.Status.StartTime.Add(time.Minute)}
+	taskRun.Status.RetriesStatus = []pipelinev1beta1.TaskRunStatus{first}
+	return taskRun
+}
+
+func TestForAttempt(t *testing.T) {
+	taskRun := retriedTaskRun(corev1.ConditionTrue)
+	run := TaskRunDimensions(taskRun)
+
+	if got := ForAttempt(run, v1alpha1.AttemptsFinal); got != run {
+		t.Error("want the final attempt returned as is")
+	}
+	first := ForAttempt(run, v1alpha1.AttemptsFirst)
+	if condition := first.Status.GetCondition(apis.ConditionSucceeded); !condition.IsFalse() {
+		t.Errorf("want the failed condition of the first attempt, got %v", condition)
+	}
+	firstTaskRun := first.Object.(*pipelinev1beta1.TaskRun)
+	if got := firstTaskRun.Status.CompletionTime.Sub(firstTaskRun.Status.StartTime.Time); got != time.Minute {
+		t.Errorf("want the duration of the first attempt, got %v", got)
+	}
+	if firstTaskRun.Name != taskRun.Name || len(firstTaskRun.Status.RetriesStatus) != 0 {
+		t.Errorf("want the metadata of the run without retries, got %+v", firstTaskRun)
+	}
+	// the run itself isn't modified
+	if !taskRun.Status.GetCondition(apis.ConditionSucceeded).IsTrue() || len(taskRun.Status.RetriesStatus) != 1 {
+		t.Errorf("want the run unchanged, got %+v", taskRun.Status)
+	}
+
+	// a running retry has no first attempt yet, the run is returned as is
+	running := TaskRunDimensions(retriedTaskRun(corev1.ConditionUnknown))
+	if got := ForAttempt(running, v1alpha1.AttemptsFirst); got != running {
+		t.Error("want a running run returned as is")
+	}
+	notRetried := TaskRunDimensions(timedTaskRun("b", nil, time.Minute))
+	if got := ForAttempt(notRetried, v1alpha1.AttemptsFirst); got != notRetried {
+		t.Error("want a run without retries returned as is")
+	}
+}
@@ -0,0 +1,64 @@
+package recorder
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Overflow is the value of every dimension of the samples exceeding the
+// cardinality limit of their metric
+const Overflow = "__overflow__"
+
+// CardinalityGuard tracks the distinct combinations of dimensions recorded
+// by a metric, combinations past the limit are collapsed into Overflow
+type CardinalityGuard struct {
+	limit      int
+	seen       sets.Set[string]
+	overflowed int64
+	mu         sync.Mutex
+}
+
+// NewCardinalityGuard returns a guard admitting up to limit combinations
+func NewCardinalityGuard(limit int) *CardinalityGuard {
+	return &CardinalityGuard{limit: limit, seen: sets.New[string]()}
+}
+
+// admit returns true when the combination was already recorded or is still
+// within the limit
+func (g *CardinalityGuard) admit(values []string) bool {
+	combination := strings.Join(values, "\x00")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.seen.Has(combination) {
+		return true
+	}
+	if g.seen.Len() < g.limit {
+		g.seen.Insert(combination)
+		return true
+	}
+	g.overflowed++
+	return false
+}
+
+// Overflowed returns the number of samples collapsed into Overflow
+func (g *CardinalityGuard) Overflowed() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.overflowed
+}
+
+type cardinalityGuardKey struct{}
+
+// WithCardinalityGuard returns a context limiting the combinations of
+// dimensions recorded with it
+func WithCardinalityGuard(ctx context.Context, guard *CardinalityGuard) context.Context {
+	return context.WithValue(ctx, cardinalityGuardKey{}, guard)
+}
+
+func cardinalityGuardFrom(ctx context.Context) *CardinalityGuard {
+	guard, _ := ctx.Value(cardinalityGuardKey{}).(*CardinalityGuard)
+	return guard
+}
@@ -0,0 +1,88 @@
+package recorder
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CELMatches holds the CEL expressions of a monitor, compiled once when the
+// metric is created. Runs are exposed to the expressions as JSON, under the
+// variable name, e.g. taskRun.status.conditions.exists(c, c.type == 'Succeeded')
+type CELMatches struct {
+	variable string
+	programs []cel.Program
+	// err is the compilation error, reported on every evaluation
+	err error
+}
+
+// NewCELMatches compiles the expressions, nil without expressions
+func NewCELMatches(variable string, expressions []string) *CELMatches {
+	if len(expressions) == 0 {
+		return nil
+	}
+	m := &CELMatches{variable: variable}
+	env, err := cel.NewEnv(cel.Variable(variable, cel.DynType))
+	if err != nil {
+		m.err = err
+		return m
+	}
+	for _, expression := range expressions {
+		program, err := CompileCEL(env, expression)
+		if err != nil {
+			m.err = err
+			return m
+		}
+		m.programs = append(m.programs, program)
+	}
+	return m
+}
+
+// CompileCEL compiles the expression, it must evaluate to a boolean
+func CompileCEL(env *cel.Env, expression string) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid match %q: %w", expression, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("match %q must evaluate to a bool, got %s", expression, ast.OutputType())
+	}
+	return env.Program(ast)
+}
+
+// Err returns the compilation error of the expressions
+func (m *CELMatches) Err() error {
+	if m == nil {
+		return nil
+	}
+	return m.err
+}
+
+// Eval returns true when the run matches every expression
+func (m *CELMatches) Eval(obj runtime.Object) (bool, error) {
+	if m == nil {
+		return true, nil
+	}
+	if m.err != nil {
+		return false, m.err
+	}
+	document, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return false, err
+	}
+	for _, program := range m.programs {
+		out, _, err := program.Eval(map[string]any{m.variable: document})
+		if err != nil {
+			return false, fmt.Errorf("match evaluation failed: %w", err)
+		}
+		matched, ok := out.Value().(bool)
+		if !ok {
+			return false, fmt.Errorf("match evaluated to %v, not a bool", out.Value())
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,48 @@
+package recorder
+
+import (
+	"testing"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestTaskFilterCEL(t *testing.T) {
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1", Labels: map[string]string{"team": "ci"}},
+		Spec:       pipelinev1beta1.TaskRunSpec{TaskRef: &pipelinev1beta1.TaskRef{Name: "build"}},
+		Status: pipelinev1beta1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: "Succeeded", Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	run := TaskRunDimensions(taskRun)
+
+	tests := []struct {
+		name        string
+		expressions []string
+		matched     bool
+		err         bool
+	}{
+		{name: "no expression", matched: true},
+		{name: "succeeded", expressions: []string{"taskRun.status.conditions.exists(c, c.type == 'Succeeded' && c.status == 'True')"}, matched: true},
+		{name: "every expression", expressions: []string{"taskRun.metadata.labels.team == 'ci'", "taskRun.metadata.name.startsWith('deploy')"}},
+		{name: "invalid", expressions: []string{"taskRun.metadata.name =="}, err: true},
+		{name: "not a bool", expressions: []string{"'ci'"}, err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := TaskFilter{TaskName: "build", CEL: NewCELMatches("taskRun", tc.expressions)}
+			matched, err := filter.Filter(run)
+			if (err != nil) != tc.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if matched != tc.matched {
+				t.Errorf("got matched %v, want %v", matched, tc.matched)
+			}
+		})
+	}
+}
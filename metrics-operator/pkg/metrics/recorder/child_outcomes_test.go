@@ -0,0 +1,93 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestChildOutcome(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *apis.Condition
+		want      string
+		wantDone  bool
+	}{
+		{name: "no condition"},
+		{name: "running", condition: &apis.Condition{Status: corev1.ConditionUnknown}},
+		{name: "succeeded", condition: &apis.Condition{Status: corev1.ConditionTrue}, want: ChildSucceeded, wantDone: true},
+		{name: "failed", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "Failed"}, want: ChildFailed, wantDone: true},
+		{name: "taskrun cancelled", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "TaskRunCancelled"}, want: ChildCancelled, wantDone: true},
+		{name: "customrun cancelled", condition: &apis.Condition{Status: corev1.ConditionFalse, Reason: "CustomRunCancelled"}, want: ChildCancelled, wantDone: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, done := childOutcome(tt.condition)
+			if got != tt.want || done != tt.wantDone {
+				t.Errorf("want %q (done %v), got %q (done %v)", tt.want, tt.wantDone, got, done)
+			}
+		})
+	}
+}
+
+func TestChildOutcomes(t *testing.T) {
+	pipelineRun := childPipelineRun(corev1.ConditionFalse)
+	// the running child is left out
+	want := map[string]string{"build": ChildSucceeded, "test": ChildFailed, "deploy": ChildSkipped}
+	got := ChildOutcomes(WithChildConditions(context.Background(), childConditions), pipelineRun)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("outcomes (-want, +got):\n%s", diff)
+	}
+
+	// without conditions only the skipped tasks are known
+	want = map[string]string{"deploy": ChildSkipped}
+	if diff := cmp.Diff(want, ChildOutcomes(context.Background(), pipelineRun)); diff != "" {
+		t.Errorf("outcomes without conditions (-want, +got):\n%s", diff)
+	}
+}
+
+func TestChildOutcomesCounter(t *testing.T) {
+	outcomes := NewGenericRunChildOutcomes(&v1alpha1.Metric{Type: "childOutcomes", Name: "children"}, "pipelinerun", "all", "", nil)
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(outcomes.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithChildConditions(context.Background(), childConditions)
+	// the children of running pipelineruns aren't counted
+	for _, pipelineRun := range []*pipelinev1beta1.PipelineRun{childPipelineRun(corev1.ConditionUnknown), childPipelineRun(corev1.ConditionFalse)} {
+		if err := outcomes.Record(ctx, meter, PipelineRunDimensions(pipelineRun)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := meter.RetrieveData(outcomes.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, row := range rows {
+		var pipelineTask, outcome string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case pipelineTaskKey:
+				pipelineTask = tag.Value
+			case outcomeKey:
+				outcome = tag.Value
+			}
+		}
+		got[pipelineTask+"/"+outcome] = row.Data.(*view.CountData).Value
+	}
+	want := map[string]int64{"build/succeeded": 1, "test/failed": 1, "deploy/skipped": 1}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("children by outcome (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,106 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// childPipelineRun has a succeeded, a failed and a running child, a skipped
+// task and a finally task not started yet
+func childPipelineRun(status corev1.ConditionStatus) *pipelinev1beta1.PipelineRun {
+	pipelineRun := &pipelinev1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-1", Namespace: "dev", UID: "release-1"},
+	}
+	pipelineRun.Status.Conditions = duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}}
+	pipelineRun.Status.PipelineSpec = &pipelinev1beta1.PipelineSpec{
+		Tasks:   []pipelinev1beta1.PipelineTask{{Name: "build"}, {Name: "test"}, {Name: "lint"}, {Name: "deploy"}},
+		Finally: []pipelinev1beta1.PipelineTask{{Name: "notify"}},
+	}
+	pipelineRun.Status.ChildReferences = []pipelinev1beta1.ChildStatusReference{
+		{Name: "release-1-build", PipelineTaskName: "build"},
+		{Name: "release-1-test", PipelineTaskName: "test"},
+		{Name: "release-1-lint", PipelineTaskName: "lint"},
+	}
+	pipelineRun.Status.SkippedTasks = []pipelinev1beta1.SkippedTask{{Name: "deploy"}}
+	return pipelineRun
+}
+
+func childConditions(_ string, child pipelinev1beta1.ChildStatusReference) (*apis.Condition, bool) {
+	status, found := map[string]corev1.ConditionStatus{
+		"release-1-build": corev1.ConditionTrue,
+		"release-1-test":  corev1.ConditionFalse,
+		"release-1-lint":  corev1.ConditionUnknown,
+	}[child.Name]
+	if !found {
+		return nil, false
+	}
+	return &apis.Condition{Type: apis.ConditionSucceeded, Status: status}, true
+}
+
+func TestChildStates(t *testing.T) {
+	pipelineRun := childPipelineRun(corev1.ConditionUnknown)
+	want := map[string]int{ChildSucceeded: 1, ChildFailed: 1, ChildRunning: 1, ChildPending: 1}
+	got := ChildStates(WithChildConditions(context.Background(), childConditions), pipelineRun)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("states (-want, +got):\n%s", diff)
+	}
+
+	// without conditions every started child is running
+	want = map[string]int{ChildSucceeded: 0, ChildFailed: 0, ChildRunning: 3, ChildPending: 1}
+	if diff := cmp.Diff(want, ChildStates(context.Background(), pipelineRun)); diff != "" {
+		t.Errorf("states without conditions (-want, +got):\n%s", diff)
+	}
+}
+
+func TestChildStatesGauge(t *testing.T) {
+	states := NewGenericRunChildStates(&v1alpha1.Metric{Type: "childStates", Name: "children"}, "pipelinerun", "all", "", nil)
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(states.View()); err != nil {
+		t.Fatal(err)
+	}
+	values := func() map[string]float64 {
+		t.Helper()
+		rows, err := meter.RetrieveData(states.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]float64{}
+		for _, row := range rows {
+			for _, tag := range row.Tags {
+				if tag.Key == stateKey {
+					values[tag.Value] = row.Data.(*view.LastValueData).Value
+				}
+			}
+		}
+		return values
+	}
+
+	ctx := WithChildConditions(context.Background(), childConditions)
+	if err := states.Record(ctx, meter, PipelineRunDimensions(childPipelineRun(corev1.ConditionUnknown))); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{ChildSucceeded: 1, ChildFailed: 1, ChildRunning: 1, ChildPending: 1}
+	if diff := cmp.Diff(want, values()); diff != "" {
+		t.Errorf("running run (-want, +got):\n%s", diff)
+	}
+
+	// the children of done runs aren't reported anymore
+	if err := states.Record(ctx, meter, PipelineRunDimensions(childPipelineRun(corev1.ConditionTrue))); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]float64{ChildSucceeded: 0, ChildFailed: 0, ChildRunning: 0, ChildPending: 0}
+	if diff := cmp.Diff(want, values()); diff != "" {
+		t.Errorf("done run (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,49 @@
+package recorder
+
+import (
+	"context"
+	"sync"
+
+	"go.opencensus.io/tag"
+)
+
+var (
+	contextTagKeys   []string
+	contextTagKeysMu sync.RWMutex
+)
+
+// SetContextTagKeys declares the keys of the tags attached with WithTags that
+// are exported. It must be called before monitors are registered, views of
+// registered metrics keep the keys they were created with.
+func SetContextTagKeys(keys ...string) error {
+	for _, key := range keys {
+		if _, err := tag.NewKey(key); err != nil {
+			return err
+		}
+	}
+	contextTagKeysMu.Lock()
+	defer contextTagKeysMu.Unlock()
+	contextTagKeys = append([]string{}, keys...)
+	return nil
+}
+
+func getContextTagKeys() []string {
+	contextTagKeysMu.RLock()
+	defer contextTagKeysMu.RUnlock()
+	return contextTagKeys
+}
+
+// WithTags returns a context adding the tags to every sample recorded with it,
+// e.g. a request or shard id. Dimensions of the metric take precedence over
+// tags with the same key.
+func WithTags(ctx context.Context, tags map[string]string) (context.Context, error) {
+	mutators := []tag.Mutator{}
+	for key, value := range tags {
+		tagKey, err := tag.NewKey(key)
+		if err != nil {
+			return nil, err
+		}
+		mutators = append(mutators, tag.Upsert(tagKey, value))
+	}
+	return tag.New(ctx, mutators...)
+}
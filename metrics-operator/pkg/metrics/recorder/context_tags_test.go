@@ -0,0 +1,55 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/tag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestWithTags(t *testing.T) {
+	if err := SetContextTagKeys("shard"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetContextTagKeys()
+	metric := &v1alpha1.Metric{
+		Type: "counter",
+		Name: "runs",
+		By:   []v1alpha1.ByStatement{{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("team")}}},
+	}
+	keys := []string{}
+	for _, key := range viewTags(metric) {
+		keys = append(keys, key.Name())
+	}
+	if diff := cmp.Diff([]string{"shard", "team"}, keys); diff != "" {
+		t.Errorf("view tags (-want, +got):\n%s", diff)
+	}
+
+	ctx, err := WithTags(context.Background(), map[string]string{"shard": "1", "team": "overridden"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "build-run", Namespace: "dev", Labels: map[string]string{"team": "a"}}})
+	tagMap, err := tagMapFromMetric(ctx, metric, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dimensions of the metric take precedence over the tags of the context
+	for key, want := range map[string]string{"shard": "1", "team": "a"} {
+		if got, _ := tagMap.Value(tag.MustNewKey(key)); got != want {
+			t.Errorf("tag %s: want %q, got %q", key, want, got)
+		}
+	}
+
+	if _, err := WithTags(context.Background(), map[string]string{"": "1"}); err == nil {
+		t.Error("want an error for an empty tag key")
+	}
+	if err := SetContextTagKeys("shard", ""); err == nil {
+		t.Error("want an error for an empty tag key")
+	}
+}
@@ -0,0 +1,56 @@
+package recorder
+
+import (
+	"encoding/json"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func CustomRunDimensions(customRun *pipelinev1beta1.CustomRun) *v1alpha1.RunDimensions {
+	return &v1alpha1.RunDimensions{
+		Resource:  "customrun",
+		Name:      customRun.Name,
+		Namespace: customRun.Namespace,
+		IsDeleted: customRun.DeletionTimestamp != nil,
+		Status:    customRun.Status.Status,
+		Labels:    customRun.Labels,
+		Params:    customRun.Spec.Params,
+		Object:    customRun,
+	}
+}
+
+// CustomTaskName returns the name of the custom task referenced by the
+// CustomRun, its kind when the reference is unnamed or the spec embedded
+func CustomTaskName(customRun *pipelinev1beta1.CustomRun) string {
+	if ref := customRun.Spec.CustomRef; ref != nil {
+		if ref.Name != "" {
+			return ref.Name
+		}
+		return string(ref.Kind)
+	}
+	if customRun.Spec.CustomSpec != nil {
+		return customRun.Spec.CustomSpec.Kind
+	}
+	return ""
+}
+
+// durationInput returns the document durations are evaluated against. The
+// extra fields of a CustomRun status are raw JSON the JSONPath can't walk
+// into, so CustomRuns are evaluated as served by the API, with timestamps as
+// RFC3339 strings.
+func durationInput(input any) (any, error) {
+	customRun, ok := input.(*pipelinev1beta1.CustomRun)
+	if !ok {
+		return input, nil
+	}
+	raw, err := json.Marshal(customRun)
+	if err != nil {
+		return nil, err
+	}
+	var document any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
@@ -0,0 +1,83 @@
+package recorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1beta1 "github.com/tektoncd/pipeline/pkg/apis/run/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTaskFilterCustomRun(t *testing.T) {
+	customRun := func(ref *pipelinev1beta1.TaskRef) *pipelinev1beta1.CustomRun {
+		return &pipelinev1beta1.CustomRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "approval-run", Namespace: "dev"},
+			Spec:       pipelinev1beta1.CustomRunSpec{CustomRef: ref},
+		}
+	}
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "approval-run", Namespace: "dev"},
+		Spec:       pipelinev1beta1.TaskRunSpec{TaskRef: &pipelinev1beta1.TaskRef{Name: "approval"}},
+	}
+	filter := NewTaskFilter("dev", &v1alpha1.TaskMonitorSpec{
+		TaskName: "approval",
+		Kind:     v1alpha1.TaskMonitorKindCustomRun,
+		Matches:  []string{"customRun.metadata.namespace == 'dev'"},
+	})
+
+	for name, tc := range map[string]struct {
+		run  *v1alpha1.RunDimensions
+		want bool
+	}{
+		"named reference": {run: CustomRunDimensions(customRun(&pipelinev1beta1.TaskRef{APIVersion: "example.dev/v1", Kind: "Approval", Name: "approval"})), want: true},
+		"other task":      {run: CustomRunDimensions(customRun(&pipelinev1beta1.TaskRef{APIVersion: "example.dev/v1", Kind: "Approval", Name: "wait"})), want: false},
+		"task run":        {run: TaskRunDimensions(taskRun), want: false},
+	} {
+		matched, err := filter.Filter(tc.run)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if matched != tc.want {
+			t.Errorf("%s: got matched %v, want %v", name, matched, tc.want)
+		}
+	}
+}
+
+func TestMeasureDurationCustomRunExtraFields(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	customRun := &pipelinev1beta1.CustomRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "approval-run", Namespace: "dev"},
+		Status: pipelinev1beta1.CustomRunStatus{
+			CustomRunStatusFields: runv1beta1.CustomRunStatusFields{
+				StartTime:   &metav1.Time{Time: start},
+				ExtraFields: runtime.RawExtension{Raw: []byte(`{"approvedAt":"2023-01-01T10:05:00Z"}`)},
+			},
+		},
+	}
+
+	duration, found, err := MeasureDuration(&v1alpha1.MetricHistogramDuration{
+		From: ".status.startTime",
+		To:   ".status.extraFields.approvedAt",
+	}, customRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || duration != 5*time.Minute {
+		t.Errorf("want 5m found, got %v found %v", duration, found)
+	}
+
+	// a field missing from the extra fields is no duration, not an error
+	_, found, err = MeasureDuration(&v1alpha1.MetricHistogramDuration{
+		From: ".status.startTime",
+		To:   ".status.extraFields.rejectedAt",
+	}, customRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found {
+		t.Error("want no duration without the extra field")
+	}
+}
@@ -0,0 +1,142 @@
+package recorder
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"go.opencensus.io/tag"
+)
+
+// summaryQuantiles are the quantiles exported by summaries
+var summaryQuantiles = []float64{0.5, 0.9, 0.99}
+
+var quantileKey = tag.MustNewKey("quantile")
+
+const (
+	// digestCompression bounds the number of centroids of a digest to a few
+	// times its value
+	digestCompression = 100
+	digestBufferSize  = 500
+)
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// digest is a merging t-digest, it estimates the quantiles of every sample
+// added in bounded memory. Centroids near the tails are kept small so the
+// extreme quantiles stay accurate.
+type digest struct {
+	centroids []centroid
+	buffer    []float64
+	count     float64
+	min       float64
+	max       float64
+}
+
+func (d *digest) add(sample float64) {
+	if d.count == 0 || sample < d.min {
+		d.min = sample
+	}
+	if d.count == 0 || sample > d.max {
+		d.max = sample
+	}
+	d.count++
+	d.buffer = append(d.buffer, sample)
+	if len(d.buffer) >= digestBufferSize {
+		d.compress()
+	}
+}
+
+// compress merges the buffered samples into the centroids, neighbours are
+// merged while the weight stays under the size limit of their quantile
+func (d *digest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	all := make([]centroid, 0, len(d.centroids)+len(d.buffer))
+	all = append(all, d.centroids...)
+	for _, sample := range d.buffer {
+		all = append(all, centroid{mean: sample, weight: 1})
+	}
+	d.buffer = d.buffer[:0]
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].mean < all[j].mean
+	})
+
+	merged := []centroid{all[0]}
+	soFar := 0.
+	for _, c := range all[1:] {
+		current := &merged[len(merged)-1]
+		proposed := current.weight + c.weight
+		q := (soFar + proposed/2) / d.count
+		if proposed <= math.Max(1, 4*d.count*q*(1-q)/digestCompression) {
+			current.mean += (c.mean - current.mean) * c.weight / proposed
+			current.weight = proposed
+			continue
+		}
+		soFar += current.weight
+		merged = append(merged, c)
+	}
+	d.centroids = merged
+}
+
+// quantile estimates the quantile q between 0 and 1, interpolating between
+// the centers of the centroids
+func (d *digest) quantile(q float64) float64 {
+	d.compress()
+	if len(d.centroids) == 0 {
+		return math.NaN()
+	}
+	if len(d.centroids) == 1 {
+		return d.centroids[0].mean
+	}
+	rank := q * d.count
+	first, last := d.centroids[0], d.centroids[len(d.centroids)-1]
+	if rank < first.weight/2 {
+		return d.min + (first.mean-d.min)*rank/(first.weight/2)
+	}
+	if rank > d.count-last.weight/2 {
+		return last.mean + (d.max-last.mean)*(rank-(d.count-last.weight/2))/(last.weight/2)
+	}
+	center := first.weight / 2
+	for i := 1; i < len(d.centroids); i++ {
+		previous, next := d.centroids[i-1], d.centroids[i]
+		nextCenter := center + (previous.weight+next.weight)/2
+		if rank <= nextCenter {
+			return previous.mean + (next.mean-previous.mean)*(rank-center)/(nextCenter-center)
+		}
+		center = nextCenter
+	}
+	return last.mean
+}
+
+// summaryValue holds a digest per tag map
+type summaryValue struct {
+	m  map[string]*digest
+	rw sync.Mutex
+}
+
+// observe adds the sample to the digest of the tag map, returns the
+// summary quantiles updated with it
+func (s *summaryValue) observe(tagMap *tag.Map, sample float64) map[string]float64 {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if s.m == nil {
+		s.m = map[string]*digest{}
+	}
+	d, exists := s.m[tagMap.String()]
+	if !exists {
+		d = &digest{}
+		s.m[tagMap.String()] = d
+	}
+	d.add(sample)
+	quantiles := map[string]float64{}
+	for _, q := range summaryQuantiles {
+		quantiles[strconv.FormatFloat(q, 'f', -1, 64)] = d.quantile(q)
+	}
+	return quantiles
+}
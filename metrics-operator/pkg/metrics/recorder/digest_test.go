@@ -0,0 +1,89 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDigestQuantile(t *testing.T) {
+	d := &digest{}
+	for _, i := range rand.New(rand.NewSource(1)).Perm(10000) {
+		d.add(float64(i + 1))
+	}
+	for _, q := range []float64{0.01, 0.5, 0.9, 0.99, 0.999} {
+		want := q * 10000
+		if got := d.quantile(q); math.Abs(got-want) > 0.01*want+1 {
+			t.Errorf("quantile %v: got %f, want %f", q, got, want)
+		}
+	}
+	if len(d.centroids) > 10*digestCompression {
+		t.Errorf("expected the centroids to be bounded, got %d", len(d.centroids))
+	}
+	if got := (&digest{}).quantile(0.5); !math.IsNaN(got) {
+		t.Errorf("expected NaN for an empty digest, got %f", got)
+	}
+}
+
+func TestHistogramSummary(t *testing.T) {
+	histogram := NewTaskHistogram(&v1alpha1.Metric{
+		Type:        "histogram",
+		Name:        "duration",
+		Aggregation: v1alpha1.AggregationSummary,
+		Duration: &v1alpha1.MetricHistogramDuration{
+			From: ".metadata.creationTimestamp",
+			To:   ".status.completionTime",
+		},
+	}, &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "build"},
+		Spec:       v1alpha1.TaskMonitorSpec{TaskName: "build"},
+	})
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(histogram.View()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 100; i++ {
+		start := metav1.Now()
+		run := TaskRunDimensions(&pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("build-%d", i), CreationTimestamp: start},
+			Spec:       pipelinev1beta1.TaskRunSpec{TaskRef: &pipelinev1beta1.TaskRef{Name: "build"}},
+			Status: pipelinev1beta1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+					CompletionTime: &metav1.Time{Time: start.Add(time.Duration(i) * time.Second)},
+				},
+			},
+		})
+		if err := histogram.Record(context.Background(), meter, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := meter.RetrieveData(histogram.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == quantileKey {
+				got[tag.Value] = row.Data.(*view.LastValueData).Value
+			}
+		}
+	}
+	for quantile, want := range map[string]float64{"0.5": 50, "0.9": 90, "0.99": 99} {
+		if math.Abs(got[quantile]-want) > 1 {
+			t.Errorf("quantile %s: got %f, want %f", quantile, got[quantile], want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package recorder
+
+import (
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// CheckDuration applies the negative duration policy of the spec, counting the
+// anomaly instead of recording a negative value that would ruin percentiles.
+// The boolean is false when the sample must be dropped.
+func CheckDuration(recorder stats.Recorder, metricName string, spec *v1alpha1.MetricHistogramDuration, duration time.Duration) (time.Duration, bool) {
+	if duration >= 0 {
+		return duration, true
+	}
+	// the anomaly counter is best effort, failing it must not fail the metric
+	_ = selfmetrics.Record(recorder, []tag.Mutator{tag.Upsert(selfmetrics.MetricKey, metricName)}, selfmetrics.DurationAnomalies.M(1))
+	if spec != nil && spec.Negative == v1alpha1.NegativeDurationDrop {
+		return 0, false
+	}
+	return 0, true
+}
@@ -0,0 +1,99 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckDuration(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        *v1alpha1.MetricHistogramDuration
+		duration    time.Duration
+		want        time.Duration
+		wantKept    bool
+		wantAnomaly bool
+	}{{
+		name:     "positive",
+		spec:     &v1alpha1.MetricHistogramDuration{Negative: v1alpha1.NegativeDurationDrop},
+		duration: time.Minute,
+		want:     time.Minute,
+		wantKept: true,
+	}, {
+		name:     "zero",
+		duration: 0,
+		wantKept: true,
+	}, {
+		name:        "negative clamped by default",
+		duration:    -time.Second,
+		wantKept:    true,
+		wantAnomaly: true,
+	}, {
+		name:        "negative clamped",
+		spec:        &v1alpha1.MetricHistogramDuration{Negative: v1alpha1.NegativeDurationClamp},
+		duration:    -time.Second,
+		wantKept:    true,
+		wantAnomaly: true,
+	}, {
+		name:        "negative dropped",
+		spec:        &v1alpha1.MetricHistogramDuration{Negative: v1alpha1.NegativeDurationDrop},
+		duration:    -time.Second,
+		wantAnomaly: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meter := view.NewMeter()
+			meter.Start()
+			defer meter.Stop()
+			if err := meter.Register(selfmetrics.Views()...); err != nil {
+				t.Fatal(err)
+			}
+			got, kept := CheckDuration(meter, "duration", tt.spec, tt.duration)
+			if got != tt.want || kept != tt.wantKept {
+				t.Errorf("want %v (kept %v), got %v (kept %v)", tt.want, tt.wantKept, got, kept)
+			}
+			rows, err := meter.RetrieveData(selfmetrics.DurationAnomalies.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if anomaly := len(rows) > 0; anomaly != tt.wantAnomaly {
+				t.Errorf("want anomaly counted %v, got %v", tt.wantAnomaly, rows)
+			}
+		})
+	}
+}
+
+func TestHistogramNegativeDuration(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type:     "histogram",
+		Name:     "duration",
+		Duration: &v1alpha1.MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime", Negative: v1alpha1.NegativeDurationDrop},
+	}
+	histogram := NewGenericRunHistogram(metric, "taskrun", "all", "", nil)
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(histogram.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	// completed before it started, e.g. on clock skew between nodes
+	taskRun := timedTaskRun("a", &metav1.Duration{Duration: time.Hour}, time.Minute)
+	taskRun.Status.StartTime = &metav1.Time{Time: taskRun.Status.CompletionTime.Add(time.Second)}
+	if err := histogram.Record(context.Background(), meter, TaskRunDimensions(taskRun)); !IsSkipped(err) {
+		t.Errorf("want the negative duration dropped, got %v", err)
+	}
+	rows, err := meter.RetrieveData(histogram.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("want no sample, got %v", rows)
+	}
+}
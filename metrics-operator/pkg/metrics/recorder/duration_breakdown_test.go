@@ -0,0 +1,88 @@
+package recorder
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDurationBreakdown(t *testing.T) {
+	metric := &v1alpha1.Metric{Type: "durationBreakdown", Name: "time"}
+	breakdown := NewGenericRunDurationBreakdown(metric, "taskrun", "all", "", nil)
+	names := []string{}
+	for _, v := range breakdown.Views() {
+		names = append(names, v.Measure.Name())
+	}
+	want := []string{"taskrun_all_time_queue_seconds", "taskrun_all_time_execution_seconds", "taskrun_all_time_seconds"}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Fatalf("views (-want, +got):\n%s", diff)
+	}
+	if breakdown.MetricName() != "taskrun_all_time_seconds" {
+		t.Errorf("want the total as the main metric, got %s", breakdown.MetricName())
+	}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(breakdown.Views()...); err != nil {
+		t.Fatal(err)
+	}
+	timeout := &metav1.Duration{Duration: time.Hour}
+	// queued for 5 minutes, executed for 9
+	if err := breakdown.Record(context.Background(), meter, TaskRunDimensions(timedTaskRun("a", timeout, 9*time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	// a running run only has a queue time
+	err := breakdown.Record(context.Background(), meter, TaskRunDimensions(timedTaskRun("b", timeout, 0)))
+	if !IsSkipped(err) {
+		t.Errorf("want the missing completion time skipped, got %v", err)
+	}
+
+	for i, wantSum := range []float64{600, 540, 840} {
+		rows, err := meter.RetrieveData(want[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0].Data.(*view.DistributionData).Sum() != wantSum {
+			t.Errorf("%s: want a sum of %vs, got %v", want[i], wantSum, rows)
+		}
+	}
+}
+
+func TestDurationBreakdownErrors(t *testing.T) {
+	metric := &v1alpha1.Metric{Type: "durationBreakdown", Name: "time"}
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+
+	// a queued run misses every part of the breakdown
+	queued := timedTaskRun("a", nil, 0)
+	queued.Status.StartTime = nil
+	breakdown := NewGenericRunDurationBreakdown(metric, "taskrun", "all", "", nil)
+	if err := meter.Register(breakdown.Views()...); err != nil {
+		t.Fatal(err)
+	}
+	err := breakdown.Record(context.Background(), meter, TaskRunDimensions(queued))
+	if !IsSkipped(err) {
+		t.Fatalf("want the queued run skipped, got %v", err)
+	}
+	if got := strings.Count(err.Error(), "error recording"); got != 3 {
+		t.Errorf("want the three parts reported, got %q", err)
+	}
+
+	// a failing part isn't hidden by a skipped one
+	breakdown.execution = NewGenericRunHistogram(&v1alpha1.Metric{Type: "histogram", Name: "broken"}, "taskrun", "all", "", nil)
+	err = breakdown.Record(context.Background(), meter, TaskRunDimensions(timedTaskRun("b", nil, 0)))
+	if err == nil || IsSkipped(err) {
+		t.Fatalf("want the failing part reported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "taskrun_all_broken") || strings.Contains(err.Error(), "missing duration timestamp") {
+		t.Errorf("want only the failing part reported, got %q", err)
+	}
+}
@@ -0,0 +1,50 @@
+package recorder
+
+import "errors"
+
+type retryableError struct {
+	err error
+}
+
+func (r *retryableError) Error() string {
+	return r.err.Error()
+}
+
+func (r *retryableError) Unwrap() error {
+	return r.err
+}
+
+// Retryable marks the failure of a write that may succeed later, like a
+// failing exporter or state store. Evaluation errors are never retryable.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// IsRetryable returns true when the recording should be retried
+func IsRetryable(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
+
+type skippedError struct {
+	reason string
+}
+
+func (s *skippedError) Error() string {
+	return s.reason
+}
+
+// Skipped reports a run matching the metric that couldn't produce a sample,
+// like a run missing a timestamp. It is counted but not logged as an error.
+func Skipped(reason string) error {
+	return &skippedError{reason: reason}
+}
+
+// IsSkipped returns true when no sample was recorded for the run
+func IsSkipped(err error) bool {
+	var skipped *skippedError
+	return errors.As(err, &skipped)
+}
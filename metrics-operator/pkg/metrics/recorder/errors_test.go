@@ -0,0 +1,37 @@
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRetryable(t *testing.T) {
+	if Retryable(nil) != nil {
+		t.Error("want no error without failure")
+	}
+	cause := errors.New("exporter unavailable")
+	err := fmt.Errorf("recording run: %w", Retryable(cause))
+	if !IsRetryable(err) {
+		t.Error("want wrapped retryable errors retryable")
+	}
+	if !errors.Is(err, cause) || err.Error() != "recording run: exporter unavailable" {
+		t.Errorf("want the cause kept, got %v", err)
+	}
+	if IsRetryable(cause) {
+		t.Error("want unmarked errors not retryable")
+	}
+	if IsRetryable(Skipped("missing start time")) {
+		t.Error("want skipped runs not retryable")
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	err := fmt.Errorf("recording run: %w", Skipped("missing start time"))
+	if !IsSkipped(err) {
+		t.Error("want wrapped skipped errors skipped")
+	}
+	if IsSkipped(Retryable(errors.New("exporter unavailable"))) {
+		t.Error("want failures not skipped")
+	}
+}
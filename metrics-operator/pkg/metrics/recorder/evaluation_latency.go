@@ -0,0 +1,50 @@
+package recorder
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// Evaluation stages timed by the evaluation latency histogram
+const (
+	StageFilter   = "filter"
+	StageJSONPath = "jsonpath"
+	StageTags     = "tags"
+)
+
+type evaluationTimingKey struct{}
+
+type evaluationTiming struct {
+	recorder   stats.Recorder
+	metricName string
+}
+
+// WithEvaluationTiming returns a context timing the expressions evaluated
+// while recording the metric
+func WithEvaluationTiming(ctx context.Context, recorder stats.Recorder, metricName string) context.Context {
+	return context.WithValue(ctx, evaluationTimingKey{}, evaluationTiming{recorder: recorder, metricName: metricName})
+}
+
+// ObserveEvaluation records the time spent in the stage since start, when the
+// context times evaluations
+func ObserveEvaluation(ctx context.Context, stage string, start time.Time) {
+	timing, ok := ctx.Value(evaluationTimingKey{}).(evaluationTiming)
+	if !ok {
+		return
+	}
+	_ = selfmetrics.Record(timing.recorder, []tag.Mutator{
+		tag.Upsert(selfmetrics.MetricKey, timing.metricName),
+		tag.Upsert(selfmetrics.StageKey, stage),
+	}, selfmetrics.EvaluationLatency.M(time.Since(start).Seconds()))
+}
+
+// measureDuration is MeasureDuration timed as a JSONPath evaluation
+func measureDuration(ctx context.Context, duration *v1alpha1.MetricHistogramDuration, input any) (time.Duration, bool, error) {
+	defer ObserveEvaluation(ctx, StageJSONPath, time.Now())
+	return MeasureDuration(duration, input)
+}
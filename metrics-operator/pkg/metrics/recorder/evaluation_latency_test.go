@@ -0,0 +1,64 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluationLatency(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(selfmetrics.Views()...); err != nil {
+		t.Fatal(err)
+	}
+	histogram := NewGenericRunHistogram(&v1alpha1.Metric{
+		Type:     "histogram",
+		Name:     "duration",
+		Duration: &v1alpha1.MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"},
+	}, "taskrun", "all", "", nil)
+	if err := meter.Register(histogram.View()); err != nil {
+		t.Fatal(err)
+	}
+	run := TaskRunDimensions(timedTaskRun("a", &metav1.Duration{Duration: time.Hour}, time.Minute))
+
+	// evaluations are only timed with a context timing them
+	if err := histogram.Record(context.Background(), meter, run); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithEvaluationTiming(context.Background(), meter, histogram.MetricName())
+	if err := histogram.Record(ctx, meter, run); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := meter.RetrieveData(selfmetrics.EvaluationLatency.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stages := map[string]int64{}
+	for _, row := range rows {
+		var metric, stage string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case selfmetrics.MetricKey:
+				metric = tag.Value
+			case selfmetrics.StageKey:
+				stage = tag.Value
+			}
+		}
+		if metric != histogram.MetricName() {
+			t.Errorf("unexpected metric %q", metric)
+		}
+		stages[stage] = row.Data.(*view.DistributionData).Count
+	}
+	if diff := cmp.Diff(map[string]int64{StageJSONPath: 1, StageTags: 1}, stages); diff != "" {
+		t.Errorf("evaluations by stage (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,70 @@
+package recorder
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// spanContextAnnotations hold the trace context propagated by Tekton when
+// tracing is enabled, a JSON carrier with a W3C traceparent
+var spanContextAnnotations = []string{"tekton.dev/taskrunSpanContext", "tekton.dev/pipelinerunSpanContext"}
+
+type exemplarsKey struct{}
+
+// WithExemplars returns a context attaching the run to histogram samples, so
+// the backends supporting exemplars can link slow buckets to runs
+func WithExemplars(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exemplarsKey{}, true)
+}
+
+// exemplarAttachments returns the attachments linking a sample to the run,
+// empty unless exemplars are enabled
+func exemplarAttachments(ctx context.Context, run *v1alpha1.RunDimensions) map[string]any {
+	if enabled, _ := ctx.Value(exemplarsKey{}).(bool); !enabled {
+		return map[string]any{}
+	}
+	attachments := map[string]any{naming.ExemplarRunKey: run.Name}
+	accessor, err := meta.Accessor(run.Object)
+	if err != nil {
+		return attachments
+	}
+	if uid := accessor.GetUID(); uid != "" {
+		attachments[naming.ExemplarRunUIDKey] = string(uid)
+	}
+	if traceID, spanID, ok := runSpanContext(accessor.GetAnnotations()); ok {
+		attachments[naming.ExemplarTraceIDKey] = traceID
+		attachments[naming.ExemplarSpanIDKey] = spanID
+	}
+	return attachments
+}
+
+// runSpanContext returns the hex trace and span ids of the traceparent of the
+// run, false without a valid one
+func runSpanContext(annotations map[string]string) (string, string, bool) {
+	for _, annotation := range spanContextAnnotations {
+		value, exists := annotations[annotation]
+		if !exists {
+			continue
+		}
+		carrier := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &carrier); err != nil {
+			continue
+		}
+		// version-traceid-spanid-flags
+		parts := strings.Split(carrier["traceparent"], "-")
+		if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+			continue
+		}
+		if _, err := hex.DecodeString(parts[1] + parts[2]); err != nil {
+			continue
+		}
+		return parts[1], parts[2], true
+	}
+	return "", "", false
+}
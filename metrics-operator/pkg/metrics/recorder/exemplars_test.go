@@ -0,0 +1,49 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExemplarAttachments(t *testing.T) {
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "build-abc",
+			UID:  "6f1c1e52-2f3c-4d41-9a3f-3f1c7c1d2e10",
+			Annotations: map[string]string{
+				"tekton.dev/taskrunSpanContext": `{"traceparent":"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}`,
+			},
+		},
+	}
+	run := TaskRunDimensions(taskRun)
+
+	if attachments := exemplarAttachments(context.Background(), run); len(attachments) != 0 {
+		t.Errorf("want no attachments unless enabled, got %v", attachments)
+	}
+
+	attachments := exemplarAttachments(WithExemplars(context.Background()), run)
+	want := map[string]string{
+		naming.ExemplarRunKey:     "build-abc",
+		naming.ExemplarRunUIDKey:  "6f1c1e52-2f3c-4d41-9a3f-3f1c7c1d2e10",
+		naming.ExemplarTraceIDKey: "4bf92f3577b34da6a3ce929d0e0e4736",
+		naming.ExemplarSpanIDKey:  "00f067aa0ba902b7",
+	}
+	if len(attachments) != len(want) {
+		t.Errorf("want %v, got %v", want, attachments)
+	}
+	for key, value := range want {
+		if attachments[key] != value {
+			t.Errorf("want %s %q, got %v", key, value, attachments[key])
+		}
+	}
+
+	taskRun.Annotations["tekton.dev/taskrunSpanContext"] = `{"traceparent":"invalid"}`
+	attachments = exemplarAttachments(WithExemplars(context.Background()), TaskRunDimensions(taskRun))
+	if _, ok := attachments[naming.ExemplarTraceIDKey]; ok {
+		t.Errorf("want no trace for an invalid traceparent, got %v", attachments)
+	}
+}
@@ -9,70 +9,257 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// Matcher is implemented by the metrics of a monitor, it tells whether a run
+// is covered by the monitor
+type Matcher interface {
+	Matches(run *v1alpha1.RunDimensions) (bool, error)
+}
+
+// RecordedMarker is implemented by the metrics of monitors, it tells whether
+// the runs recorded by the metric are marked with an annotation
+type RecordedMarker interface {
+	MarksRecorded() bool
+}
+
+// RunFilter selects the runs recorded by the metrics of a monitor
+type RunFilter interface {
+	Filter(run *v1alpha1.RunDimensions) (bool, error)
+}
+
+// monitorFilter is embedded by the generic metrics, it makes them implement
+// Matcher and RecordedMarker for the filter of their monitor. A nil filter
+// records every run.
+type monitorFilter struct {
+	filter RunFilter
+}
+
+// Matches implements Matcher
+func (m monitorFilter) Matches(run *v1alpha1.RunDimensions) (bool, error) {
+	if m.filter == nil {
+		return true, nil
+	}
+	return m.filter.Filter(run)
+}
+
+// MarksRecorded implements RecordedMarker
+func (m monitorFilter) MarksRecorded() bool {
+	marker, ok := m.filter.(RecordedMarker)
+	return ok && marker.MarksRecorded()
+}
+
+// filterRun returns true when the run is recorded by the metric
+func (m monitorFilter) filterRun(run *v1alpha1.RunDimensions) (bool, error) {
+	matched, err := m.Matches(run)
+	if err != nil {
+		return false, fmt.Errorf("could not record metric: %w", err)
+	}
+	return matched, nil
+}
+
 type PipelineFilter struct {
 	PipelineName string
+	// Selector is nil unless the monitor has a selector
+	Selector *metav1.LabelSelector
+	// CEL is nil unless the monitor has matches
+	CEL *CELMatches
+	// MarkRecorded marks the runs recorded by the metrics of the monitor
+	MarkRecorded bool
 }
 
 // Filter returns true when the PipelineRun should be recorded, independent of value
-func (p *PipelineFilter) Filter(run *v1alpha1.RunDimensions) bool {
+func (p *PipelineFilter) Filter(run *v1alpha1.RunDimensions) (bool, error) {
 	pipelineRun, ok := run.Object.(*pipelinev1beta1.PipelineRun)
 	if !ok {
-		return false
+		return false, nil
 	}
 	ref := pipelineRun.Spec.PipelineRef
-	return ref != nil && ref.Name == p.PipelineName
+	if ref == nil || ref.Name != p.PipelineName {
+		return false, nil
+	}
+	if matched, err := matchSelector(p.Selector, pipelineRun.Labels); err != nil || !matched {
+		return false, err
+	}
+	return p.CEL.Eval(pipelineRun)
+}
+
+// NewPipelineFilter returns the filter of a pipeline monitor spec
+func NewPipelineFilter(spec *v1alpha1.PipelineMonitorSpec) PipelineFilter {
+	return PipelineFilter{
+		PipelineName: spec.PipelineName,
+		Selector:     spec.Selector.DeepCopy(),
+		CEL:          NewCELMatches("pipelineRun", spec.Matches),
+		MarkRecorded: spec.MarkRecorded,
+	}
 }
 
 type PipelineRunFilter struct {
 	Selector *metav1.LabelSelector
+	// MarkRecorded marks the runs recorded by the metrics of the monitor
+	MarkRecorded bool
+}
+
+// NewPipelineRunFilter returns the filter of a pipelinerun monitor spec
+func NewPipelineRunFilter(spec *v1alpha1.PipelineRunMonitorSpec) PipelineRunFilter {
+	return PipelineRunFilter{
+		Selector:     spec.Selector.DeepCopy(),
+		MarkRecorded: spec.MarkRecorded,
+	}
 }
 
 // Filter returns true when the PipelineRun should be recorded, independent of value
 func (p *PipelineRunFilter) Filter(run *v1alpha1.RunDimensions) (bool, error) {
 	pipelineRun, ok := run.Object.(*pipelinev1beta1.PipelineRun)
 	if !ok {
-		return false, fmt.Errorf("expected PipelineRun, but got %T", run.Object)
-	}
-	if p.Selector == nil {
-		return true, nil
+		// runs of other kinds are not an error, every run is offered to every metric
+		return false, nil
 	}
-	selector, err := metav1.LabelSelectorAsSelector(p.Selector)
-	if err != nil {
-		return false, err
-	}
-	return selector.Matches(labels.Set(pipelineRun.Labels)), nil
+	return matchSelector(p.Selector, pipelineRun.Labels)
 }
 
 type TaskFilter struct {
 	TaskName string
+	// Kind of the matched runs, TaskRun unless CustomRun
+	Kind string
+	// Namespace of the matched runs, empty for every namespace
+	Namespace string
+	// Selector is nil unless the monitor has a selector
+	Selector *metav1.LabelSelector
+	// CEL is nil unless the monitor has matches
+	CEL *CELMatches
+	// MarkRecorded marks the runs recorded by the metrics of the monitor
+	MarkRecorded bool
 }
 
 // Filter returns true when the TaskRun should be recorded, independent of value
-func (t *TaskFilter) Filter(run *v1alpha1.RunDimensions) bool {
+func (t *TaskFilter) Filter(run *v1alpha1.RunDimensions) (bool, error) {
+	if t.Kind == v1alpha1.TaskMonitorKindCustomRun {
+		return t.filterCustomRun(run)
+	}
 	taskRun, ok := run.Object.(*pipelinev1beta1.TaskRun)
 	if !ok {
-		return false
+		return false, nil
+	}
+	if t.Namespace != "" && taskRun.Namespace != t.Namespace {
+		return false, nil
 	}
 	ref := taskRun.Spec.TaskRef
-	return ref != nil && ref.Name == t.TaskName
+	if ref == nil || ref.Name != t.TaskName {
+		return false, nil
+	}
+	if matched, err := matchSelector(t.Selector, taskRun.Labels); err != nil || !matched {
+		return false, err
+	}
+	return t.CEL.Eval(taskRun)
+}
+
+// filterCustomRun returns true when the CustomRun should be recorded,
+// independent of value
+func (t *TaskFilter) filterCustomRun(run *v1alpha1.RunDimensions) (bool, error) {
+	customRun, ok := run.Object.(*pipelinev1beta1.CustomRun)
+	if !ok {
+		return false, nil
+	}
+	if t.Namespace != "" && customRun.Namespace != t.Namespace {
+		return false, nil
+	}
+	if CustomTaskName(customRun) != t.TaskName {
+		return false, nil
+	}
+	if matched, err := matchSelector(t.Selector, customRun.Labels); err != nil || !matched {
+		return false, err
+	}
+	return t.CEL.Eval(customRun)
+}
+
+// NewTaskFilter returns the filter of a task monitor spec, restricted to the
+// namespace unless empty
+func NewTaskFilter(namespace string, spec *v1alpha1.TaskMonitorSpec) TaskFilter {
+	variable := "taskRun"
+	if spec.Kind == v1alpha1.TaskMonitorKindCustomRun {
+		variable = "customRun"
+	}
+	return TaskFilter{
+		TaskName:     spec.TaskName,
+		Kind:         spec.Kind,
+		Namespace:    namespace,
+		Selector:     spec.Selector.DeepCopy(),
+		CEL:          NewCELMatches(variable, spec.Matches),
+		MarkRecorded: spec.MarkRecorded,
+	}
 }
 
 type TaskRunFilter struct {
 	Selector *metav1.LabelSelector
+	// MarkRecorded marks the runs recorded by the metrics of the monitor
+	MarkRecorded bool
+}
+
+// NewTaskRunFilter returns the filter of a taskrun monitor spec
+func NewTaskRunFilter(spec *v1alpha1.TaskRunMonitorSpec) TaskRunFilter {
+	return TaskRunFilter{
+		Selector:     spec.Selector.DeepCopy(),
+		MarkRecorded: spec.MarkRecorded,
+	}
 }
 
 // Filter returns true when the TaskRun should be recorded, independent of value
 func (t *TaskRunFilter) Filter(run *v1alpha1.RunDimensions) (bool, error) {
 	taskRun, ok := run.Object.(*pipelinev1beta1.TaskRun)
 	if !ok {
-		return false, fmt.Errorf("expected taskRun, but got %T", run.Object)
+		return false, nil
 	}
-	if t.Selector == nil {
+	return matchSelector(t.Selector, taskRun.Labels)
+}
+
+// matchSelector returns true when the labels match the selector, or without
+// selector
+func matchSelector(labelSelector *metav1.LabelSelector, runLabels map[string]string) (bool, error) {
+	if labelSelector == nil {
 		return true, nil
 	}
-	selector, err := metav1.LabelSelectorAsSelector(t.Selector)
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
 	if err != nil {
 		return false, err
 	}
-	return selector.Matches(labels.Set(taskRun.Labels)), nil
+	return selector.Matches(labels.Set(runLabels)), nil
+}
+
+// Matches implements Matcher
+func (p *PipelineFilter) Matches(run *v1alpha1.RunDimensions) (bool, error) {
+	return p.Filter(run)
+}
+
+// Matches implements Matcher
+func (p *PipelineRunFilter) Matches(run *v1alpha1.RunDimensions) (bool, error) {
+	return p.Filter(run)
+}
+
+// Matches implements Matcher
+func (t *TaskFilter) Matches(run *v1alpha1.RunDimensions) (bool, error) {
+	return t.Filter(run)
+}
+
+// Matches implements Matcher
+func (t *TaskRunFilter) Matches(run *v1alpha1.RunDimensions) (bool, error) {
+	return t.Filter(run)
+}
+
+// MarksRecorded implements RecordedMarker
+func (p *PipelineFilter) MarksRecorded() bool {
+	return p.MarkRecorded
+}
+
+// MarksRecorded implements RecordedMarker
+func (p *PipelineRunFilter) MarksRecorded() bool {
+	return p.MarkRecorded
+}
+
+// MarksRecorded implements RecordedMarker
+func (t *TaskFilter) MarksRecorded() bool {
+	return t.MarkRecorded
+}
+
+// MarksRecorded implements RecordedMarker
+func (t *TaskRunFilter) MarksRecorded() bool {
+	return t.MarkRecorded
 }
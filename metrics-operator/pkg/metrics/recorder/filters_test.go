@@ -0,0 +1,110 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPipelineFilterSelector(t *testing.T) {
+	pipelineRun := func(team string) *pipelinev1beta1.PipelineRun {
+		return &pipelinev1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "release-" + team, Labels: map[string]string{"team": team}},
+			Spec:       pipelinev1beta1.PipelineRunSpec{PipelineRef: &pipelinev1beta1.PipelineRef{Name: "release"}},
+		}
+	}
+	filter := PipelineFilter{
+		PipelineName: "release",
+		Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+	}
+
+	for team, want := range map[string]bool{"payments": true, "search": false} {
+		matched, err := filter.Filter(PipelineRunDimensions(pipelineRun(team)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != want {
+			t.Errorf("team %s: got matched %v, want %v", team, matched, want)
+		}
+	}
+
+	filter.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Invalid"}}
+	if _, err := filter.Filter(PipelineRunDimensions(pipelineRun("payments"))); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestTaskFilterNamespace(t *testing.T) {
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-run", Namespace: "team-a"},
+		Spec:       pipelinev1beta1.TaskRunSpec{TaskRef: &pipelinev1beta1.TaskRef{Name: "hello"}},
+	}
+	spec := &v1alpha1.TaskMonitorSpec{TaskName: "hello"}
+
+	for namespace, want := range map[string]bool{"team-a": true, "team-b": false, "": true} {
+		filter := NewTaskFilter(namespace, spec)
+		matched, err := filter.Filter(TaskRunDimensions(taskRun))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != want {
+			t.Errorf("namespace %q: got matched %v, want %v", namespace, matched, want)
+		}
+	}
+}
+
+func TestMonitorFilter(t *testing.T) {
+	taskRun := func(task string) *v1alpha1.RunDimensions {
+		return TaskRunDimensions(&pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: task + "-run", Namespace: "dev"},
+			Spec:       pipelinev1beta1.TaskRunSpec{TaskRef: &pipelinev1beta1.TaskRef{Name: task}},
+		})
+	}
+	monitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "dev"},
+		Spec:       v1alpha1.TaskMonitorSpec{TaskName: "build", MarkRecorded: true},
+	}
+	counter := NewTaskCounter(&v1alpha1.Metric{Type: "counter", Name: "runs"}, monitor)
+	unfiltered := NewGenericRunCounter(&v1alpha1.Metric{Type: "counter", Name: "runs"}, "taskrun", "all", "", nil)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(counter.View(), unfiltered.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	for task, want := range map[string]bool{"build": true, "test": false} {
+		if matched, err := counter.Matches(taskRun(task)); err != nil || matched != want {
+			t.Errorf("task %s: want matched %v, got %v (%v)", task, want, matched, err)
+		}
+		if matched, err := unfiltered.Matches(taskRun(task)); err != nil || !matched {
+			t.Errorf("task %s: want a metric without a filter to match, got %v (%v)", task, matched, err)
+		}
+		if err := counter.Record(context.Background(), meter, taskRun(task)); err != nil {
+			t.Fatal(err)
+		}
+		if err := unfiltered.Record(context.Background(), meter, taskRun(task)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !counter.MarksRecorded() || unfiltered.MarksRecorded() {
+		t.Errorf("want only the counter of the monitor to mark runs, got %v and %v", counter.MarksRecorded(), unfiltered.MarksRecorded())
+	}
+
+	// only the run of the monitored task is counted by the monitor
+	for metric, want := range map[string]int64{counter.MetricName(): 1, unfiltered.MetricName(): 2} {
+		rows, err := meter.RetrieveData(metric)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0].Data.(*view.CountData).Value != want {
+			t.Errorf("%s: want %d counted runs, got %v", metric, want, rows)
+		}
+	}
+}
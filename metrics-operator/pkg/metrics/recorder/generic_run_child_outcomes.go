@@ -0,0 +1,138 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/apis"
+)
+
+// Child outcomes reported by childOutcomes metrics on top of the succeeded and
+// failed child states
+const (
+	ChildSkipped   = "skipped"
+	ChildCancelled = "cancelled"
+)
+
+var (
+	pipelineTaskKey = tag.MustNewKey("pipelineTask")
+	outcomeKey      = tag.MustNewKey("outcome")
+)
+
+// ChildOutcomes returns the outcome of every pipeline task of a done
+// PipelineRun by name, children whose condition can't be resolved are left out
+func ChildOutcomes(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) map[string]string {
+	conditions, _ := ctx.Value(childConditionKey{}).(ChildConditionFunc)
+	outcomes := map[string]string{}
+	for _, task := range pipelineRun.Status.SkippedTasks {
+		outcomes[task.Name] = ChildSkipped
+	}
+	if conditions == nil {
+		return outcomes
+	}
+	for _, child := range pipelineRun.Status.ChildReferences {
+		condition, found := conditions(pipelineRun.Namespace, child)
+		if !found {
+			continue
+		}
+		if outcome, done := childOutcome(condition); done {
+			outcomes[child.PipelineTaskName] = outcome
+		}
+	}
+	return outcomes
+}
+
+func childOutcome(condition *apis.Condition) (string, bool) {
+	switch {
+	case condition == nil:
+		return "", false
+	case condition.IsTrue():
+		return ChildSucceeded, true
+	case condition.IsFalse() && strings.HasSuffix(condition.Reason, "Cancelled"):
+		return ChildCancelled, true
+	case condition.IsFalse():
+		return ChildFailed, true
+	default:
+		return "", false
+	}
+}
+
+// GenericRunChildOutcomes counts the pipeline tasks of done PipelineRuns by
+// pipeline task name and outcome, to find the failure hot spots of large
+// pipelines without a monitor per task
+type GenericRunChildOutcomes struct {
+	monitorFilter
+	Resource  string
+	Monitor   string
+	Prefix    string
+	RunMetric *v1alpha1.Metric
+	view      *view.View
+	measure   *stats.Float64Measure
+}
+
+func (g *GenericRunChildOutcomes) Metric() *v1alpha1.Metric {
+	return g.RunMetric
+}
+
+func (g *GenericRunChildOutcomes) MetricName() string {
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.CounterMetric(g.Resource, g.Monitor, g.RunMetric.Name))
+}
+
+func (g *GenericRunChildOutcomes) MonitorId() string {
+	return naming.MonitorId(g.Resource, g.Monitor)
+}
+
+func (g *GenericRunChildOutcomes) View() *view.View {
+	return g.view
+}
+
+func (g *GenericRunChildOutcomes) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	pipelineRun, ok := run.Object.(*pipelinev1beta1.PipelineRun)
+	if !ok || !pipelineRun.IsDone() {
+		return nil
+	}
+	for pipelineTask, outcome := range ChildOutcomes(ctx, pipelineRun) {
+		ctx, err := tag.New(ctx, tag.Upsert(pipelineTaskKey, pipelineTask), tag.Upsert(outcomeKey, outcome))
+		if err != nil {
+			return err
+		}
+		tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
+		if err != nil {
+			return fmt.Errorf("unable to render tag map for metric: %w", err)
+		}
+		recorder.Record(tagMap, []stats.Measurement{g.measure.M(1)}, map[string]any{})
+	}
+	return nil
+}
+
+func (g *GenericRunChildOutcomes) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+}
+
+func NewGenericRunChildOutcomes(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunChildOutcomes {
+	outcomes := &GenericRunChildOutcomes{
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
+	}
+	description := metricDescription(metric, fmt.Sprintf("children of done %s by pipeline task and outcome for %s/%s", outcomes.Resource, outcomes.Monitor, outcomes.RunMetric.Name))
+	outcomes.measure = stats.Float64(outcomes.MetricName(), description, stats.UnitDimensionless)
+	outcomes.view = &view.View{
+		Description: description,
+		Measure:     outcomes.measure,
+		Aggregation: view.Count(),
+		TagKeys:     append(viewTags(metric), pipelineTaskKey, outcomeKey),
+	}
+	return outcomes
+}
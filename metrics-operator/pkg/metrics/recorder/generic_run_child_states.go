@@ -0,0 +1,200 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+// Child states reported by childStates metrics
+const (
+	ChildSucceeded = "succeeded"
+	ChildFailed    = "failed"
+	ChildRunning   = "running"
+	ChildPending   = "pending"
+)
+
+var childStates = []string{ChildSucceeded, ChildFailed, ChildRunning, ChildPending}
+
+var stateKey = tag.MustNewKey("state")
+
+// ChildConditionFunc returns the Succeeded condition of a child of a
+// PipelineRun, false when the child isn't known
+type ChildConditionFunc func(namespace string, child pipelinev1beta1.ChildStatusReference) (*apis.Condition, bool)
+
+type childConditionKey struct{}
+
+// WithChildConditions returns a context resolving the conditions of the
+// children of PipelineRuns with the function, without it children are
+// reported as running until the PipelineRun is done
+func WithChildConditions(ctx context.Context, conditions ChildConditionFunc) context.Context {
+	return context.WithValue(ctx, childConditionKey{}, conditions)
+}
+
+// ChildStates counts the pipeline tasks of a PipelineRun by state, tasks
+// without child yet are pending and skipped tasks are left out
+func ChildStates(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) map[string]int {
+	conditions, _ := ctx.Value(childConditionKey{}).(ChildConditionFunc)
+	counts := map[string]int{}
+	for _, state := range childStates {
+		counts[state] = 0
+	}
+	started := sets.New[string]()
+	for _, child := range pipelineRun.Status.ChildReferences {
+		started.Insert(child.PipelineTaskName)
+		state := ChildRunning
+		if conditions != nil {
+			if condition, found := conditions(pipelineRun.Namespace, child); found {
+				state = childState(condition)
+			}
+		}
+		counts[state]++
+	}
+	skipped := sets.New[string]()
+	for _, task := range pipelineRun.Status.SkippedTasks {
+		skipped.Insert(task.Name)
+	}
+	if spec := pipelineRun.Status.PipelineSpec; spec != nil {
+		for _, task := range append(append([]pipelinev1beta1.PipelineTask{}, spec.Tasks...), spec.Finally...) {
+			if !started.Has(task.Name) && !skipped.Has(task.Name) {
+				counts[ChildPending]++
+			}
+		}
+	}
+	return counts
+}
+
+func childState(condition *apis.Condition) string {
+	switch {
+	case condition == nil:
+		return ChildPending
+	case condition.IsTrue():
+		return ChildSucceeded
+	case condition.IsFalse():
+		return ChildFailed
+	default:
+		return ChildRunning
+	}
+}
+
+// GenericRunChildStates reports the children of running PipelineRuns by
+// state, summed for each combination of the by dimensions
+type GenericRunChildStates struct {
+	monitorFilter
+	Resource  string
+	Monitor   string
+	Prefix    string
+	RunMetric *v1alpha1.Metric
+	view      *view.View
+	measure   *stats.Float64Measure
+	counts    *childCounts
+}
+
+// childCounts holds the counts of every running run by tag map, shared by the
+// copies of the metric
+type childCounts struct {
+	runs    map[string]map[string]int
+	tagMaps map[string]*tag.Map
+	rw      sync.Mutex
+}
+
+func (g *GenericRunChildStates) Metric() *v1alpha1.Metric {
+	return g.RunMetric
+}
+
+func (g *GenericRunChildStates) MetricName() string {
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.GaugeMetric(g.Resource, g.Monitor, g.RunMetric.Name))
+}
+
+func (g *GenericRunChildStates) MonitorId() string {
+	return naming.MonitorId(g.Resource, g.Monitor)
+}
+
+func (g *GenericRunChildStates) View() *view.View {
+	return g.view
+}
+
+func (g *GenericRunChildStates) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	pipelineRun, ok := run.Object.(*pipelinev1beta1.PipelineRun)
+	if !ok || run.IsDeleted || pipelineRun.IsDone() {
+		g.Clean(ctx, recorder, run)
+		return nil
+	}
+	counts := map[string]int{}
+	for state, count := range ChildStates(ctx, pipelineRun) {
+		ctx, err := tag.New(ctx, tag.Upsert(stateKey, state))
+		if err != nil {
+			return err
+		}
+		tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
+		if err != nil {
+			return fmt.Errorf("unable to render tag map for metric: %w", err)
+		}
+		counts[tagMap.String()] = count
+		g.counts.rw.Lock()
+		g.counts.tagMaps[tagMap.String()] = tagMap
+		g.counts.rw.Unlock()
+	}
+	g.counts.rw.Lock()
+	g.counts.runs[run.GetId()] = counts
+	g.counts.rw.Unlock()
+	g.reportAll(recorder)
+	return nil
+}
+
+func (g *GenericRunChildStates) reportAll(recorder stats.Recorder) {
+	g.counts.rw.Lock()
+	defer g.counts.rw.Unlock()
+	for key, tagMap := range g.counts.tagMaps {
+		total := 0
+		for _, counts := range g.counts.runs {
+			total += counts[key]
+		}
+		recorder.Record(tagMap, []stats.Measurement{g.measure.M(float64(total))}, map[string]any{})
+	}
+}
+
+func (g *GenericRunChildStates) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+	g.counts.rw.Lock()
+	_, exists := g.counts.runs[run.GetId()]
+	delete(g.counts.runs, run.GetId())
+	g.counts.rw.Unlock()
+	if exists {
+		g.reportAll(recorder)
+	}
+}
+
+func NewGenericRunChildStates(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunChildStates {
+	states := &GenericRunChildStates{
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
+		counts: &childCounts{
+			runs:    map[string]map[string]int{},
+			tagMaps: map[string]*tag.Map{},
+		},
+	}
+	description := metricDescription(metric, fmt.Sprintf("children of running %s by state for %s/%s", states.Resource, states.Monitor, states.RunMetric.Name))
+	states.measure = stats.Float64(states.MetricName(), description, stats.UnitDimensionless)
+	states.view = &view.View{
+		Description: description,
+		Measure:     states.measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     append(viewTags(metric), stateKey),
+	}
+	return states
+}
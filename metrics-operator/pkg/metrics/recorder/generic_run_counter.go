@@ -8,12 +8,13 @@ import (
 	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
-	"knative.dev/pkg/logging"
 )
 
 type GenericRunCounter struct {
+	monitorFilter
 	Resource  string
 	Monitor   string
+	Prefix    string
 	RunMetric *v1alpha1.Metric
 	view      *view.View
 	measure   *stats.Float64Measure
@@ -24,7 +25,7 @@ func (g *GenericRunCounter) Metric() *v1alpha1.Metric {
 }
 
 func (t *GenericRunCounter) MetricName() string {
-	return naming.CounterMetric(t.Resource, t.Monitor, t.RunMetric.Name)
+	return naming.Prefixed(t.Prefix, t.Resource, t.Monitor, naming.CounterMetric(t.Resource, t.Monitor, t.RunMetric.Name))
 }
 
 func (t *GenericRunCounter) MonitorId() string {
@@ -35,31 +36,81 @@ func (t *GenericRunCounter) View() *view.View {
 	return t.view
 }
 
-func (t *GenericRunCounter) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
-	logger := logging.FromContext(ctx)
-	tagMap, err := tagMapFromByStatements(t.RunMetric.By, run)
+func (t *GenericRunCounter) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := t.filterRun(run); err != nil || !matched {
+		return err
+	}
+	if t.RunMetric.NearTimeout != nil {
+		ratio, found, err := TimeoutRatio(ctx, t.RunMetric.Duration, run)
+		if err != nil {
+			return fmt.Errorf("error computing timeout ratio: %w", err)
+		}
+		if !found || ratio*100 < float64(t.RunMetric.NearTimeout.Percent) {
+			return nil
+		}
+	}
+	if hasStepDimension(t.RunMetric) {
+		return t.recordSteps(ctx, recorder, run)
+	}
+	sample := 1.0
+	if t.RunMetric.ValuePreset != "" {
+		value, err := MeasureValuePreset(ctx, t.RunMetric.ValuePreset, run)
+		if err != nil {
+			return err
+		}
+		sample = value
+	}
+	tagMap, err := tagMapFromMetric(ctx, t.RunMetric, run)
 	if err != nil {
-		logger.Errorw("error recording value", "resource", t.Resource, "monitor", t.Monitor, "metric", t.RunMetric)
-		return
+		return fmt.Errorf("error recording value, invalid tag map: %w", err)
+	}
+	recorder.Record(tagMap, []stats.Measurement{t.measure.M(sample)}, map[string]any{})
+	return nil
+}
+
+// recordSteps counts every terminated step of a TaskRun, including the steps
+// of its retried attempts, so steps failing before a retry are counted too
+func (t *GenericRunCounter) recordSteps(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	samples, ok := stepSamples(run, true)
+	if !ok {
+		return Skipped("steps are only recorded for taskruns")
+	}
+	recorded := false
+	for _, sample := range samples {
+		if sample.state.Terminated == nil {
+			continue
+		}
+		tagMap, err := tagMapFromMetric(ctx, t.RunMetric, sample.run)
+		if err != nil {
+			return fmt.Errorf("error recording step %s, invalid tag map: %w", sample.state.Name, err)
+		}
+		recorder.Record(tagMap, []stats.Measurement{t.measure.M(1)}, map[string]any{})
+		recorded = true
+	}
+	if !recorded {
+		return Skipped("no terminated step")
 	}
-	recorder.Record(tagMap, []stats.Measurement{t.measure.M(1)}, map[string]any{})
+	return nil
 }
 
 func (t *GenericRunCounter) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
 }
 
-func NewGenericRunCounter(metric *v1alpha1.Metric, resource, monitorName string) *GenericRunCounter {
+func NewGenericRunCounter(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunCounter {
 	counter := &GenericRunCounter{
-		Resource:  resource,
-		Monitor:   monitorName,
-		RunMetric: metric,
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
 	}
-	counter.measure = stats.Float64(counter.MetricName(), fmt.Sprintf("count samples for %s %s/%s", counter.Resource, counter.Monitor, counter.RunMetric.Name), stats.UnitDimensionless)
+	description := metricDescription(metric, fmt.Sprintf("count samples for %s %s/%s", counter.Resource, counter.Monitor, counter.RunMetric.Name))
+	counter.measure = stats.Float64(counter.MetricName(), description, stats.UnitDimensionless)
 	view := &view.View{
-		Description: counter.measure.Description(),
+		Description: description,
 		Measure:     counter.measure,
-		Aggregation: view.Count(),
-		TagKeys:     viewTags(metric.By),
+		Aggregation: viewAggregation(metric, view.Count()),
+		TagKeys:     viewTags(metric),
 	}
 	counter.view = view
 	return counter
@@ -0,0 +1,99 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// GenericRunDurationBreakdown expands a single spec into queue, execution and
+// total time histograms sharing the same dimensions
+type GenericRunDurationBreakdown struct {
+	monitorFilter
+	Resource  string
+	Monitor   string
+	Prefix    string
+	RunMetric *v1alpha1.Metric
+	queue     *GenericRunHistogram
+	execution *GenericRunHistogram
+	total     *GenericRunHistogram
+}
+
+func (g *GenericRunDurationBreakdown) Metric() *v1alpha1.Metric {
+	return g.RunMetric
+}
+
+func (g *GenericRunDurationBreakdown) MetricName() string {
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.DurationMetric(g.Resource, g.Monitor, g.RunMetric.Name, string(g.RunMetric.Unit)))
+}
+
+func (g *GenericRunDurationBreakdown) MonitorId() string {
+	return naming.MonitorId(g.Resource, g.Monitor)
+}
+
+func (g *GenericRunDurationBreakdown) View() *view.View {
+	return g.total.View()
+}
+
+func (g *GenericRunDurationBreakdown) Views() []*view.View {
+	views := []*view.View{}
+	for _, histogram := range []*GenericRunHistogram{g.queue, g.execution, g.total} {
+		views = append(views, histogram.Views()...)
+	}
+	return views
+}
+
+func (g *GenericRunDurationBreakdown) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	var failed, skipped []error
+	for _, histogram := range []*GenericRunHistogram{g.queue, g.execution, g.total} {
+		// a missing timestamp only skips its part of the breakdown
+		err := histogram.Record(ctx, recorder, run)
+		switch {
+		case err == nil:
+		case IsSkipped(err):
+			skipped = append(skipped, fmt.Errorf("error recording %s: %w", histogram.MetricName(), err))
+		default:
+			failed = append(failed, fmt.Errorf("error recording %s: %w", histogram.MetricName(), err))
+		}
+	}
+	// the run is only reported skipped when no part failed for another reason
+	if len(failed) > 0 {
+		return errors.Join(failed...)
+	}
+	return errors.Join(skipped...)
+}
+
+func (g *GenericRunDurationBreakdown) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+}
+
+func NewGenericRunDurationBreakdown(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunDurationBreakdown {
+	histogram := func(suffix, from, to string) *GenericRunHistogram {
+		part := metric.DeepCopy()
+		part.Type = "histogram"
+		part.Name = metric.Name + suffix
+		part.Duration = &v1alpha1.MetricHistogramDuration{From: from, To: to}
+		if suffix != "" {
+			part.CountOver = nil
+			part.Help = ""
+		}
+		return NewGenericRunHistogram(part, resource, monitorName, prefix, nil)
+	}
+	return &GenericRunDurationBreakdown{
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
+		queue:         histogram("_queue", ".metadata.creationTimestamp", ".status.startTime"),
+		execution:     histogram("_execution", ".status.startTime", ".status.completionTime"),
+		total:         histogram("", ".metadata.creationTimestamp", ".status.completionTime"),
+	}
+}
@@ -3,6 +3,7 @@ package recorder
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
@@ -12,7 +13,9 @@ import (
 )
 
 type GenericRunGauge struct {
+	monitorFilter
 	Monitor   string
+	Prefix    string
 	Resource  string
 	RunMetric *v1alpha1.Metric
 	value     GaugeValue
@@ -24,7 +27,7 @@ func (g *GenericRunGauge) Metric() *v1alpha1.Metric {
 	return g.RunMetric
 }
 func (g *GenericRunGauge) MetricName() string {
-	return naming.GaugeMetric(g.Resource, g.Monitor, g.RunMetric.Name)
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.GaugeMetric(g.Resource, g.Monitor, g.RunMetric.Name))
 }
 
 func (g *GenericRunGauge) MonitorId() string {
@@ -35,36 +38,41 @@ func (g *GenericRunGauge) View() *view.View {
 	return g.view
 }
 
-func (g *GenericRunGauge) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+func (g *GenericRunGauge) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		g.Clean(ctx, recorder, run)
+		return err
+	}
 	logger := logging.FromContext(ctx)
 	if g.RunMetric.Match != nil {
+		start := time.Now()
 		matched, err := match(g.RunMetric.Match, run)
+		ObserveEvaluation(ctx, StageJSONPath, start)
 		if err != nil {
-			logger.Errorf("skipping run, match failed: %w", err)
 			g.Clean(ctx, recorder, run)
-			return
+			return fmt.Errorf("skipping run, match failed: %w", err)
 		}
 		if !matched {
 			logger.Infof("skipping run, match is false")
 			g.Clean(ctx, recorder, run)
-			return
+			return nil
 		}
 	}
 
 	if run.IsDeleted {
 		logger.Infof("cleanup run, deleted")
 		g.Clean(ctx, recorder, run)
-		return
+		return nil
 	}
 
-	tagMap, err := tagMapFromByStatements(g.RunMetric.By, run)
+	tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
 	if err != nil {
-		logger.Errorf("unable to render tag map for metric: %w", err)
-		return
+		return fmt.Errorf("unable to render tag map for metric: %w", err)
 	}
 
 	g.value.Update(run, tagMap)
 	g.reportAll(ctx, recorder, run)
+	return nil
 }
 
 func (g *GenericRunGauge) reportAll(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
@@ -84,18 +92,21 @@ func (g *GenericRunGauge) Clean(ctx context.Context, recorder stats.Recorder, ru
 	g.reportAll(ctx, recorder, run)
 }
 
-func NewGenericRunGauge(metric *v1alpha1.Metric, resource, monitorName string) *GenericRunGauge {
+func NewGenericRunGauge(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunGauge {
 	gauge := &GenericRunGauge{
-		Resource:  resource,
-		Monitor:   monitorName,
-		RunMetric: metric,
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
 	}
-	gauge.measure = stats.Float64(gauge.MetricName(), fmt.Sprintf("gauge samples for %s %s/%s", gauge.Resource, gauge.Monitor, gauge.RunMetric.Name), stats.UnitDimensionless)
+	description := metricDescription(metric, fmt.Sprintf("gauge samples for %s %s/%s", gauge.Resource, gauge.Monitor, gauge.RunMetric.Name))
+	gauge.measure = stats.Float64(gauge.MetricName(), description, stats.UnitDimensionless)
 	view := &view.View{
-		Description: gauge.measure.Description(),
+		Description: description,
 		Measure:     gauge.measure,
 		Aggregation: view.LastValue(),
-		TagKeys:     viewTags(metric.By),
+		TagKeys:     viewTags(metric),
 	}
 	gauge.view = view
 	return gauge
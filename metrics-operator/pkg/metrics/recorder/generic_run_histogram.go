@@ -11,26 +11,36 @@ import (
 	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
-	"go.uber.org/zap"
+	"go.opencensus.io/tag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/util/jsonpath"
 	"knative.dev/pkg/logging"
 )
 
 type GenericRunHistogram struct {
-	Resource  string
-	Monitor   string
-	RunMetric *v1alpha1.Metric
-	view      *view.View
-	measure   *stats.Float64Measure
+	monitorFilter
+	Resource    string
+	Monitor     string
+	Prefix      string
+	RunMetric   *v1alpha1.Metric
+	view        *view.View
+	measure     *stats.Float64Measure
+	overView    *view.View
+	overMeasure *stats.Float64Measure
+	// summary is nil unless the histogram exports quantiles
+	summary *summaryValue
 }
 
 func (g *GenericRunHistogram) Metric() *v1alpha1.Metric {
 	return g.RunMetric
 }
 
+// MetricName has no unit suffix when the histogram records values instead of
+// durations
 func (g *GenericRunHistogram) MetricName() string {
-	return naming.HistogramMetric(g.Resource, g.Monitor, g.RunMetric.Name)
+	if g.RunMetric.Value != nil {
+		return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.GaugeMetric(g.Resource, g.Monitor, g.RunMetric.Name))
+	}
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.DurationMetric(g.Resource, g.Monitor, g.RunMetric.Name, string(g.RunMetric.Unit)))
 }
 
 func (g *GenericRunHistogram) MonitorId() string {
@@ -41,49 +51,169 @@ func (g *GenericRunHistogram) View() *view.View {
 	return g.view
 }
 
-func (g *GenericRunHistogram) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
-	logger := logging.FromContext(ctx).With("resource", g.Resource, "monitor", g.Monitor, "metric", g.RunMetric)
-	tagMap, err := tagMapFromByStatements(g.RunMetric.By, run)
+// Views returns the histogram view, and the counter of runs over the
+// threshold when countOver is set
+func (g *GenericRunHistogram) Views() []*view.View {
+	if g.overView == nil {
+		return []*view.View{g.view}
+	}
+	return []*view.View{g.view, g.overView}
+}
+
+func (g *GenericRunHistogram) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	if g.RunMetric.Duration == nil && g.RunMetric.Value == nil {
+		return fmt.Errorf("histogram requires a duration or a value")
+	}
+	if hasStepDimension(g.RunMetric) && g.RunMetric.Value == nil {
+		return g.recordSteps(ctx, recorder, run)
+	}
+	tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
 	if err != nil {
-		logger.Errorw("error recording value, invalid tag map", zap.Error(err))
-		return
+		return fmt.Errorf("error recording value, invalid tag map: %w", err)
 	}
 
-	from, to, err := ParseDuration(g.RunMetric.Duration, run.Object)
+	if g.RunMetric.Value != nil {
+		value, found, err := measureValue(ctx, g.RunMetric.Value, run, g.RunMetric.Unit)
+		if err != nil {
+			return fmt.Errorf("error reading value: %w", err)
+		}
+		if !found {
+			return Skipped("missing value")
+		}
+		g.observe(ctx, recorder, tagMap, value, exemplarAttachments(ctx, run))
+		return nil
+	}
+
+	duration, found, err := measureRunDuration(ctx, g.RunMetric.Duration, run)
 	if err != nil {
-		logger.Errorw("error parsing duration", zap.Error(err))
-		return
+		return fmt.Errorf("error parsing duration: %w", err)
+	}
+	if !found {
+		return Skipped("missing duration timestamp")
+	}
+	duration, ok := CheckDuration(recorder, g.MetricName(), g.RunMetric.Duration, duration)
+	if !ok {
+		return Skipped("negative duration dropped")
+	}
+	if g.overMeasure != nil && duration > g.RunMetric.CountOver.Duration {
+		recorder.Record(tagMap, []stats.Measurement{g.overMeasure.M(1)}, map[string]any{})
+	}
+	g.observe(ctx, recorder, tagMap, g.RunMetric.Unit.FromSeconds(duration.Seconds()), exemplarAttachments(ctx, run))
+	return nil
+}
+
+// recordSteps records the duration of every step of a TaskRun, steps without
+// both timestamps are skipped
+func (g *GenericRunHistogram) recordSteps(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	samples, ok := stepSamples(run, false)
+	if !ok {
+		return Skipped("steps are only recorded for taskruns")
+	}
+	recorded := false
+	for _, sample := range samples {
+		step := sample.state
+		tagMap, err := tagMapFromMetric(ctx, g.RunMetric, sample.run)
+		if err != nil {
+			return fmt.Errorf("error recording step %s, invalid tag map: %w", step.Name, err)
+		}
+		duration, found, err := measureDuration(ctx, g.RunMetric.Duration, step)
+		if err != nil {
+			return fmt.Errorf("error parsing duration of step %s: %w", step.Name, err)
+		}
+		if !found {
+			continue
+		}
+		duration, ok := CheckDuration(recorder, g.MetricName(), g.RunMetric.Duration, duration)
+		if !ok {
+			continue
+		}
+		if g.overMeasure != nil && duration > g.RunMetric.CountOver.Duration {
+			recorder.Record(tagMap, []stats.Measurement{g.overMeasure.M(1)}, map[string]any{})
+		}
+		g.observe(ctx, recorder, tagMap, g.RunMetric.Unit.FromSeconds(duration.Seconds()), exemplarAttachments(ctx, run))
+		recorded = true
+	}
+	if !recorded {
+		return Skipped("no step with both duration timestamps")
+	}
+	return nil
+}
+
+// hasStepDimension returns true when the metric records a sample per step
+func hasStepDimension(metric *v1alpha1.Metric) bool {
+	for _, by := range metric.By {
+		if by.Step != nil && *by.Step {
+			return true
+		}
 	}
-	if from == nil || to == nil {
-		logger.Info("missing duration timestamp")
+	return false
+}
+
+// observe records the sample with the exemplar attachments of the run,
+// summaries record the quantiles of every sample with the same tags instead
+func (g *GenericRunHistogram) observe(ctx context.Context, recorder stats.Recorder, tagMap *tag.Map, sample float64, attachments map[string]any) {
+	if g.summary == nil {
+		recorder.Record(tagMap, []stats.Measurement{g.measure.M(sample)}, attachments)
 		return
 	}
-	duration := to.Sub(from.Time).Seconds()
-	recorder.Record(tagMap, []stats.Measurement{g.measure.M(duration)}, map[string]any{})
+	for quantile, value := range g.summary.observe(tagMap, sample) {
+		quantileCtx, err := tag.New(tag.NewContext(ctx, tagMap), tag.Upsert(quantileKey, quantile))
+		if err != nil {
+			logging.FromContext(ctx).Errorf("unable to tag quantile %s: %v", quantile, err)
+			continue
+		}
+		recorder.Record(tag.FromContext(quantileCtx), []stats.Measurement{g.measure.M(value)}, map[string]any{})
+	}
 }
 
 func (t *GenericRunHistogram) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
 }
 
-func NewGenericRunHistogram(metric *v1alpha1.Metric, resource, monitorName string) *GenericRunHistogram {
-	// TODO: make buckets configurable
-	buckets := []float64{.25, .5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+func NewGenericRunHistogram(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunHistogram {
 	histogram := &GenericRunHistogram{
-		Resource:  resource,
-		Monitor:   monitorName,
-		RunMetric: metric,
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
 	}
-	histogram.measure = stats.Float64(histogram.MetricName(), fmt.Sprintf("histogram samples in seconds for %s %s/%s", histogram.Resource, histogram.Monitor, histogram.RunMetric.Name), stats.UnitSeconds)
+	description := metricDescription(metric, fmt.Sprintf("histogram samples in %s for %s %s/%s", durationUnit(metric.Unit), histogram.Resource, histogram.Monitor, histogram.RunMetric.Name))
+	if metric.Value != nil {
+		description = metricDescription(metric, fmt.Sprintf("histogram samples for %s %s/%s", histogram.Resource, histogram.Monitor, histogram.RunMetric.Name))
+	}
+	histogram.measure = stats.Float64(histogram.MetricName(), description, measureUnit(metric))
 	view := &view.View{
-		Description: histogram.measure.Description(),
+		Description: description,
 		Measure:     histogram.measure,
-		Aggregation: view.Distribution(buckets...),
-		TagKeys:     viewTags(metric.By),
+		Aggregation: viewAggregation(metric, view.Distribution(metricBuckets(metric)...)),
+		TagKeys:     viewTags(metric),
+	}
+	if metric.Aggregation == v1alpha1.AggregationSummary {
+		histogram.summary = &summaryValue{}
+		view.Aggregation = viewAggregation(metric, nil)
+		view.TagKeys = append(view.TagKeys, quantileKey)
 	}
 	histogram.view = view
+	if metric.CountOver != nil && metric.Value == nil {
+		histogram.overMeasure, histogram.overView = newCountOverView(metric, resource, monitorName, prefix)
+	}
 	return histogram
 }
 
+func newCountOverView(metric *v1alpha1.Metric, resource, monitorName, prefix string) (*stats.Float64Measure, *view.View) {
+	name := naming.Prefixed(prefix, resource, monitorName, naming.CountOverMetric(resource, monitorName, metric.Name))
+	measure := stats.Float64(name, fmt.Sprintf("count of runs over %s for %s %s/%s", metric.CountOver.Duration, resource, monitorName, metric.Name), stats.UnitDimensionless)
+	return measure, &view.View{
+		Description: measure.Description(),
+		Measure:     measure,
+		Aggregation: view.Count(),
+		TagKeys:     viewTags(metric),
+	}
+}
+
 func parseTime(field string, value reflect.Value) (*metav1.Time, error) {
 	switch k := value.Interface().(type) {
 	case *metav1.Time:
@@ -98,46 +228,105 @@ func parseTime(field string, value reflect.Value) (*metav1.Time, error) {
 		}
 		result := metav1.NewTime(*k)
 		return &result, nil
+	case string:
+		// timestamps of JSON documents, e.g. the extra fields of a CustomRun
+		t, err := ParseRFC3339(k)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse '%s' duration: %w", field, err)
+		}
+		return t, nil
+	case nil:
+		return nil, nil
 	default:
 		return nil, fmt.Errorf("could not parse '%s' duration, wrong type", field)
 	}
 }
 
-// ParseDuration returns from, to and error
+// ParseDuration returns from, to and error, from and to are nil when their
+// expression matches nothing, e.g. a step that didn't terminate
 func ParseDuration(duration *monitoringv1alpha1.MetricHistogramDuration, input any) (*metav1.Time, *metav1.Time, error) {
-	j := jsonpath.New("duration")
-	templateFrom := fmt.Sprintf("{%s}{%s}", duration.From, duration.To)
-	err := j.Parse(templateFrom)
-
+	froms, err := parseTimes("from", duration.From, input)
 	if err != nil {
 		return nil, nil, err
 	}
-	results, err := j.FindResults(input)
+	tos, err := parseTimes("to", duration.To, input)
 	if err != nil {
 		return nil, nil, err
 	}
-	if len(results) != 2 {
-		return nil, nil, fmt.Errorf("unable to parse duration, got %d results", len(results))
+	if len(froms) > 1 {
+		return nil, nil, fmt.Errorf("unable to parse 'from' duration, got %d results", len(froms))
 	}
-	if len(results[0]) != 1 {
-		return nil, nil, fmt.Errorf("unable to parse 'from' duration, got %d results", len(results[0]))
+	if len(tos) > 1 {
+		return nil, nil, fmt.Errorf("unable to parse 'to' duration, got %d results", len(tos))
 	}
-	if len(results[1]) != 1 {
-		return nil, nil, fmt.Errorf("unable to parse 'to' duration, got %d results", len(results[1]))
+	if len(froms) == 0 || len(tos) == 0 {
+		return nil, nil, nil
 	}
+	return froms[0], tos[0], nil
+}
 
-	var from *metav1.Time
-	var to *metav1.Time
-	from, err = parseTime("from", results[0][0])
+// MeasureDuration returns the duration described by the spec, summing every
+// segment when segments are set. The boolean is false when no complete
+// from/to pair could be found.
+func MeasureDuration(duration *monitoringv1alpha1.MetricHistogramDuration, input any) (time.Duration, bool, error) {
+	input, err := durationInput(input)
 	if err != nil {
-		return nil, nil, err
+		return 0, false, err
 	}
-	to, err = parseTime("to", results[1][0])
-	if err != nil {
-		return nil, nil, err
+	if len(duration.Segments) == 0 {
+		from, to, err := ParseDuration(duration, input)
+		if err != nil {
+			return 0, false, err
+		}
+		if from == nil || to == nil {
+			return 0, false, nil
+		}
+		return to.Sub(from.Time), true, nil
 	}
-	return from, to, nil
 
+	var total time.Duration
+	found := false
+	for i, segment := range duration.Segments {
+		froms, err := parseTimes("from", segment.From, input)
+		if err != nil {
+			return 0, false, err
+		}
+		tos, err := parseTimes("to", segment.To, input)
+		if err != nil {
+			return 0, false, err
+		}
+		if len(froms) != len(tos) {
+			return 0, false, fmt.Errorf("unable to pair segment %d, got %d 'from' and %d 'to' results", i, len(froms), len(tos))
+		}
+		for j := range froms {
+			if froms[j] == nil || tos[j] == nil {
+				continue
+			}
+			total += tos[j].Sub(froms[j].Time)
+			found = true
+		}
+	}
+	return total, found, nil
+}
+
+// parseTimes returns every timestamp matched by the expression, missing keys
+// result in no timestamps instead of an error.
+func parseTimes(field, expression string, input any) ([]*metav1.Time, error) {
+	results, err := monitoringv1alpha1.FindJSONPath(expression, input)
+	if err != nil {
+		return nil, err
+	}
+	times := []*metav1.Time{}
+	for _, result := range results {
+		for _, value := range result {
+			t, err := parseTime(field, value)
+			if err != nil {
+				return nil, err
+			}
+			times = append(times, t)
+		}
+	}
+	return times, nil
 }
 
 func ParseRFC3339(s string) (*metav1.Time, error) {
@@ -0,0 +1,83 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// GenericRunLastValue exports the value of the last done run, like a result
+// emitted by the task
+type GenericRunLastValue struct {
+	monitorFilter
+	Resource  string
+	Monitor   string
+	Prefix    string
+	RunMetric *v1alpha1.Metric
+	view      *view.View
+	measure   *stats.Float64Measure
+}
+
+func (g *GenericRunLastValue) Metric() *v1alpha1.Metric {
+	return g.RunMetric
+}
+
+func (g *GenericRunLastValue) MetricName() string {
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.GaugeMetric(g.Resource, g.Monitor, g.RunMetric.Name))
+}
+
+func (g *GenericRunLastValue) MonitorId() string {
+	return naming.MonitorId(g.Resource, g.Monitor)
+}
+
+func (g *GenericRunLastValue) View() *view.View {
+	return g.view
+}
+
+func (g *GenericRunLastValue) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	if g.RunMetric.Value == nil {
+		return fmt.Errorf("lastValue requires a value")
+	}
+	tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
+	if err != nil {
+		return fmt.Errorf("error recording value, invalid tag map: %w", err)
+	}
+	value, found, err := measureValue(ctx, g.RunMetric.Value, run, g.RunMetric.Unit)
+	if err != nil {
+		return fmt.Errorf("error reading value: %w", err)
+	}
+	if !found {
+		return Skipped("missing value")
+	}
+	recorder.Record(tagMap, []stats.Measurement{g.measure.M(value)}, map[string]any{})
+	return nil
+}
+
+func (g *GenericRunLastValue) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+}
+
+func NewGenericRunLastValue(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunLastValue {
+	lastValue := &GenericRunLastValue{
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
+	}
+	description := metricDescription(metric, fmt.Sprintf("last value for %s %s/%s", lastValue.Resource, lastValue.Monitor, lastValue.RunMetric.Name))
+	lastValue.measure = stats.Float64(lastValue.MetricName(), description, measureUnit(metric))
+	lastValue.view = &view.View{
+		Description: description,
+		Measure:     lastValue.measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     viewTags(metric),
+	}
+	return lastValue
+}
@@ -0,0 +1,176 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/apis"
+)
+
+// Ticker is implemented by metrics recomputed at an interval, their samples
+// change with the current time and not only with run events
+type Ticker interface {
+	Tick(ctx context.Context, recorder stats.Recorder, now time.Time)
+}
+
+// Inheritor is implemented by metrics keeping samples in memory, they take
+// over the samples of the metric they replace when its spec is unchanged, so
+// reconciling a monitor doesn't reset them
+type Inheritor interface {
+	Inherit(previous any)
+}
+
+// rateSeries holds the completion times of the runs of a tag map within the
+// window
+type rateSeries struct {
+	tagMap      *tag.Map
+	completions []time.Time
+}
+
+// rateWindow holds the series of a rate metric by tag map
+type rateWindow struct {
+	series map[string]*rateSeries
+	rw     sync.Mutex
+}
+
+// GenericRunRate exports the number of runs completed per minute over a
+// sliding window, recomputed when runs complete and on every tick so the rate
+// decreases once runs leave the window
+type GenericRunRate struct {
+	monitorFilter
+	Resource  string
+	Monitor   string
+	Prefix    string
+	RunMetric *v1alpha1.Metric
+	rates     *rateWindow
+	view      *view.View
+	measure   *stats.Float64Measure
+}
+
+func (g *GenericRunRate) Metric() *v1alpha1.Metric {
+	return g.RunMetric
+}
+
+func (g *GenericRunRate) MetricName() string {
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.RateMetric(g.Resource, g.Monitor, g.RunMetric.Name))
+}
+
+func (g *GenericRunRate) MonitorId() string {
+	return naming.MonitorId(g.Resource, g.Monitor)
+}
+
+func (g *GenericRunRate) View() *view.View {
+	return g.view
+}
+
+func (g *GenericRunRate) window() time.Duration {
+	if g.RunMetric.Window == nil {
+		return v1alpha1.DefaultRateWindow
+	}
+	return g.RunMetric.Window.Duration
+}
+
+// completionTime returns the last transition of the Succeeded condition of
+// the run, the current time when unset
+func completionTime(run *v1alpha1.RunDimensions, now time.Time) time.Time {
+	cond := run.Status.GetCondition(apis.ConditionSucceeded)
+	if cond == nil || cond.LastTransitionTime.Inner.IsZero() {
+		return now
+	}
+	return cond.LastTransitionTime.Inner.Time
+}
+
+func (g *GenericRunRate) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
+	if err != nil {
+		return fmt.Errorf("error recording rate, invalid tag map: %w", err)
+	}
+	now := time.Now()
+	completed := completionTime(run, now)
+	// replayed runs may have completed before the window
+	if now.Sub(completed) > g.window() {
+		return Skipped("completed before the window")
+	}
+	g.rates.rw.Lock()
+	defer g.rates.rw.Unlock()
+	series, exists := g.rates.series[tagMap.String()]
+	if !exists {
+		series = &rateSeries{tagMap: tagMap}
+		g.rates.series[tagMap.String()] = series
+	}
+	series.completions = append(series.completions, completed)
+	g.report(recorder, series, now)
+	return nil
+}
+
+// Tick drops the completions out of the window and records the rate of
+// every series, series without completion left report 0 once and are
+// forgotten
+func (g *GenericRunRate) Tick(ctx context.Context, recorder stats.Recorder, now time.Time) {
+	g.rates.rw.Lock()
+	defer g.rates.rw.Unlock()
+	for key, series := range g.rates.series {
+		if g.report(recorder, series, now) == 0 {
+			delete(g.rates.series, key)
+		}
+	}
+}
+
+// report records the rate of the series within the window ending at now,
+// returns the number of completions kept
+func (g *GenericRunRate) report(recorder stats.Recorder, series *rateSeries, now time.Time) int {
+	window := g.window()
+	kept := series.completions[:0]
+	for _, completed := range series.completions {
+		if now.Sub(completed) <= window {
+			kept = append(kept, completed)
+		}
+	}
+	series.completions = kept
+	rate := float64(len(kept)) / window.Minutes()
+	recorder.Record(series.tagMap, []stats.Measurement{g.measure.M(rate)}, map[string]any{})
+	return len(kept)
+}
+
+func (g *GenericRunRate) Inherit(previous any) {
+	if p, ok := previous.(interface{ rateState() *rateWindow }); ok {
+		g.rates = p.rateState()
+	}
+}
+
+func (g *GenericRunRate) rateState() *rateWindow {
+	return g.rates
+}
+
+func (g *GenericRunRate) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+}
+
+func NewGenericRunRate(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunRate {
+	rate := &GenericRunRate{
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
+		rates:         &rateWindow{series: map[string]*rateSeries{}},
+	}
+	description := metricDescription(metric, fmt.Sprintf("runs completed per minute over %s for %s %s/%s", rate.window(), rate.Resource, rate.Monitor, rate.RunMetric.Name))
+	rate.measure = stats.Float64(rate.MetricName(), description, stats.UnitDimensionless)
+	rate.view = &view.View{
+		Description: description,
+		Measure:     rate.measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     viewTags(metric),
+	}
+	return rate
+}
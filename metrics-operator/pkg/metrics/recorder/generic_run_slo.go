@@ -0,0 +1,113 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// GenericRunSLO classifies runs against a duration threshold, it counts every
+// run and the good runs whose duration is within the threshold. Both counters
+// are sums, so a series of good runs exists at 0 as soon as a run is counted
+// and the error ratio of burn-rate alerts is defined.
+type GenericRunSLO struct {
+	monitorFilter
+	Resource    string
+	Monitor     string
+	Prefix      string
+	RunMetric   *v1alpha1.Metric
+	view        *view.View
+	measure     *stats.Float64Measure
+	goodView    *view.View
+	goodMeasure *stats.Float64Measure
+}
+
+func (g *GenericRunSLO) Metric() *v1alpha1.Metric {
+	return g.RunMetric
+}
+
+// MetricName is the name of the counter of every run
+func (g *GenericRunSLO) MetricName() string {
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.CounterMetric(g.Resource, g.Monitor, g.RunMetric.Name))
+}
+
+func (g *GenericRunSLO) MonitorId() string {
+	return naming.MonitorId(g.Resource, g.Monitor)
+}
+
+func (g *GenericRunSLO) View() *view.View {
+	return g.view
+}
+
+// Views returns the counters of every run and of good runs
+func (g *GenericRunSLO) Views() []*view.View {
+	return []*view.View{g.view, g.goodView}
+}
+
+func (g *GenericRunSLO) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	if g.RunMetric.Duration == nil || g.RunMetric.Threshold == nil {
+		return fmt.Errorf("slo requires a duration and a threshold")
+	}
+	tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
+	if err != nil {
+		return fmt.Errorf("error recording slo, invalid tag map: %w", err)
+	}
+	duration, found, err := measureRunDuration(ctx, g.RunMetric.Duration, run)
+	if err != nil {
+		return fmt.Errorf("error parsing duration: %w", err)
+	}
+	if !found {
+		return Skipped("missing duration timestamp")
+	}
+	duration, ok := CheckDuration(recorder, g.MetricName(), g.RunMetric.Duration, duration)
+	if !ok {
+		return Skipped("negative duration dropped")
+	}
+	good := 0.
+	if duration <= g.RunMetric.Threshold.Duration {
+		good = 1
+	}
+	recorder.Record(tagMap, []stats.Measurement{g.measure.M(1), g.goodMeasure.M(good)}, map[string]any{})
+	return nil
+}
+
+func (g *GenericRunSLO) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+}
+
+func NewGenericRunSLO(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunSLO {
+	slo := &GenericRunSLO{
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
+	}
+	threshold := "no threshold"
+	if metric.Threshold != nil {
+		threshold = metric.Threshold.Duration.String()
+	}
+	description := metricDescription(metric, fmt.Sprintf("runs counted by the slo of %s %s/%s", slo.Resource, slo.Monitor, slo.RunMetric.Name))
+	slo.measure = stats.Float64(slo.MetricName(), description, stats.UnitDimensionless)
+	slo.view = &view.View{
+		Description: description,
+		Measure:     slo.measure,
+		Aggregation: view.Sum(),
+		TagKeys:     viewTags(metric),
+	}
+	goodName := naming.Prefixed(prefix, resource, monitorName, naming.SLOGoodMetric(resource, monitorName, metric.Name))
+	slo.goodMeasure = stats.Float64(goodName, fmt.Sprintf("runs within %s counted by the slo of %s %s/%s", threshold, resource, monitorName, metric.Name), stats.UnitDimensionless)
+	slo.goodView = &view.View{
+		Description: slo.goodMeasure.Description(),
+		Measure:     slo.goodMeasure,
+		Aggregation: view.Sum(),
+		TagKeys:     viewTags(metric),
+	}
+	return slo
+}
@@ -0,0 +1,83 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"knative.dev/pkg/logging"
+)
+
+type GenericRunTimeoutRatio struct {
+	monitorFilter
+	Resource  string
+	Monitor   string
+	Prefix    string
+	RunMetric *v1alpha1.Metric
+	view      *view.View
+	measure   *stats.Float64Measure
+}
+
+func (g *GenericRunTimeoutRatio) Metric() *v1alpha1.Metric {
+	return g.RunMetric
+}
+
+func (g *GenericRunTimeoutRatio) MetricName() string {
+	return naming.Prefixed(g.Prefix, g.Resource, g.Monitor, naming.RatioMetric(g.Resource, g.Monitor, g.RunMetric.Name))
+}
+
+func (g *GenericRunTimeoutRatio) MonitorId() string {
+	return naming.MonitorId(g.Resource, g.Monitor)
+}
+
+func (g *GenericRunTimeoutRatio) View() *view.View {
+	return g.view
+}
+
+func (g *GenericRunTimeoutRatio) Record(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) error {
+	if matched, err := g.filterRun(run); err != nil || !matched {
+		return err
+	}
+	logger := logging.FromContext(ctx).With("resource", g.Resource, "monitor", g.Monitor, "metric", g.RunMetric)
+	tagMap, err := tagMapFromMetric(ctx, g.RunMetric, run)
+	if err != nil {
+		return fmt.Errorf("error recording value, invalid tag map: %w", err)
+	}
+
+	ratio, found, err := TimeoutRatio(ctx, g.RunMetric.Duration, run)
+	if err != nil {
+		return fmt.Errorf("error computing timeout ratio: %w", err)
+	}
+	if !found {
+		logger.Info("missing timeout or duration timestamp")
+		return nil
+	}
+	recorder.Record(tagMap, []stats.Measurement{g.measure.M(ratio)}, map[string]any{})
+	return nil
+}
+
+func (g *GenericRunTimeoutRatio) Clean(ctx context.Context, recorder stats.Recorder, run *v1alpha1.RunDimensions) {
+}
+
+func NewGenericRunTimeoutRatio(metric *v1alpha1.Metric, resource, monitorName, prefix string, filter RunFilter) *GenericRunTimeoutRatio {
+	ratio := &GenericRunTimeoutRatio{
+		Resource:      resource,
+		Monitor:       monitorName,
+		Prefix:        prefix,
+		RunMetric:     metric,
+		monitorFilter: monitorFilter{filter: filter},
+	}
+	description := metricDescription(metric, fmt.Sprintf("fraction of the timeout used for %s %s/%s", ratio.Resource, ratio.Monitor, ratio.RunMetric.Name))
+	ratio.measure = stats.Float64(ratio.MetricName(), description, stats.UnitDimensionless)
+	view := &view.View{
+		Description: description,
+		Measure:     ratio.measure,
+		Aggregation: viewAggregation(metric, view.LastValue()),
+		TagKeys:     viewTags(metric),
+	}
+	ratio.view = view
+	return ratio
+}
@@ -0,0 +1,68 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestPipelineHistogram(t *testing.T) {
+	monitor := &v1alpha1.PipelineMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "release"},
+		Spec:       v1alpha1.PipelineMonitorSpec{PipelineName: "release"},
+	}
+	histogram := NewPipelineHistogram(&v1alpha1.Metric{
+		Type: "histogram",
+		Name: "duration",
+		By:   []v1alpha1.ByStatement{{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("env")}}},
+		Duration: &v1alpha1.MetricHistogramDuration{
+			From: ".status.startTime",
+			To:   ".status.completionTime",
+		},
+	}, monitor)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(histogram.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	pipelineRun := func(pipeline string) *pipelinev1beta1.PipelineRun {
+		return &pipelinev1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: pipeline + "-run", Labels: map[string]string{"env": "prod"}},
+			Spec:       pipelinev1beta1.PipelineRunSpec{PipelineRef: &pipelinev1beta1.PipelineRef{Name: pipeline}},
+			Status: pipelinev1beta1.PipelineRunStatus{
+				PipelineRunStatusFields: pipelinev1beta1.PipelineRunStatusFields{
+					StartTime:      MustParseRFC3339("2023-08-16T16:00:00Z"),
+					CompletionTime: MustParseRFC3339("2023-08-16T16:01:30Z"),
+				},
+			},
+		}
+	}
+	for _, pipeline := range []string{"release", "lint"} {
+		if err := histogram.Record(context.Background(), meter, PipelineRunDimensions(pipelineRun(pipeline))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := meter.RetrieveData(histogram.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	distribution, ok := rows[0].Data.(*view.DistributionData)
+	if !ok || distribution.Count != 1 || distribution.Sum() != 90 {
+		t.Errorf("expected a single sample of 90s, got %+v", rows[0].Data)
+	}
+	if len(rows[0].Tags) != 1 || rows[0].Tags[0].Value != "prod" {
+		t.Errorf("expected the env tag, got %v", rows[0].Tags)
+	}
+}
@@ -0,0 +1,56 @@
+package recorder
+
+import "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+
+// The metrics of PipelineMonitors are the generic metrics filtered by the
+// pipeline of the monitor
+
+func NewPipelineCounter(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunCounter {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunCounter(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineHistogram(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunHistogram {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunHistogram(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineGauge(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunGauge {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunGauge(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineTimeoutRatio(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunTimeoutRatio {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunTimeoutRatio(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineDurationBreakdown(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunDurationBreakdown {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunDurationBreakdown(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineLastValue(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunLastValue {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunLastValue(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRate(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunRate {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunRate(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineSLO(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunSLO {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunSLO(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineChildStates(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunChildStates {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunChildStates(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineChildOutcomes(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineMonitor) *GenericRunChildOutcomes {
+	filter := NewPipelineFilter(&monitor.Spec)
+	return NewGenericRunChildOutcomes(metric, "pipeline", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
@@ -0,0 +1,56 @@
+package recorder
+
+import "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+
+// The metrics of PipelineRunMonitors are the generic metrics filtered by the
+// selector of the monitor
+
+func NewPipelineRunCounter(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunCounter {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunCounter(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunHistogram(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunHistogram {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunHistogram(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunGauge(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunGauge {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunGauge(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunTimeoutRatio(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunTimeoutRatio {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunTimeoutRatio(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunDurationBreakdown(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunDurationBreakdown {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunDurationBreakdown(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunLastValue(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunLastValue {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunLastValue(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunRate(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunRate {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunRate(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunSLO(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunSLO {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunSLO(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunChildStates(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunChildStates {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunChildStates(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewPipelineRunChildOutcomes(metric *v1alpha1.Metric, monitor *v1alpha1.PipelineRunMonitor) *GenericRunChildOutcomes {
+	filter := NewPipelineRunFilter(&monitor.Spec)
+	return NewGenericRunChildOutcomes(metric, "pipelinerun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
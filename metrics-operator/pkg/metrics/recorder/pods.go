@@ -0,0 +1,107 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+type podListerKey struct{}
+
+// WithPodLister returns a context reading the pods of TaskRuns from the
+// lister, for the value presets measuring pods
+func WithPodLister(ctx context.Context, lister corev1listers.PodLister) context.Context {
+	return context.WithValue(ctx, podListerKey{}, lister)
+}
+
+// runPod returns the pod of the TaskRun, skipped when pods aren't listed or
+// the pod is gone
+func runPod(ctx context.Context, run *v1alpha1.RunDimensions) (*corev1.Pod, error) {
+	taskRun, ok := run.Object.(*pipelinev1beta1.TaskRun)
+	if !ok {
+		return nil, Skipped("pods are only read for taskruns")
+	}
+	return TaskRunPod(ctx, taskRun)
+}
+
+// TaskRunPod returns the pod of the TaskRun from the lister of the context,
+// skipped when pods aren't listed or the pod is gone
+func TaskRunPod(ctx context.Context, taskRun *pipelinev1beta1.TaskRun) (*corev1.Pod, error) {
+	lister, ok := ctx.Value(podListerKey{}).(corev1listers.PodLister)
+	if !ok {
+		return nil, Skipped("pod metrics are disabled")
+	}
+	if taskRun.Status.PodName == "" {
+		return nil, Skipped("taskrun has no pod")
+	}
+	pod, err := lister.Pods(taskRun.Namespace).Get(taskRun.Status.PodName)
+	if errors.IsNotFound(err) {
+		return nil, Skipped("pod of the taskrun is gone")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading pod %s: %w", taskRun.Status.PodName, err)
+	}
+	return pod, nil
+}
+
+// measureRunDuration measures the duration of the run, the end of durations
+// ending on the pod is evaluated against the pod of the TaskRun
+func measureRunDuration(ctx context.Context, duration *v1alpha1.MetricHistogramDuration, run *v1alpha1.RunDimensions) (time.Duration, bool, error) {
+	if !duration.ToPod {
+		return measureDuration(ctx, duration, run.Object)
+	}
+	pod, err := runPod(ctx, run)
+	if err != nil {
+		return 0, false, err
+	}
+	defer ObserveEvaluation(ctx, StageJSONPath, time.Now())
+	froms, err := parseTimes("from", duration.From, run.Object)
+	if err != nil {
+		return 0, false, err
+	}
+	tos, err := parseTimes("to", duration.To, pod)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(froms) > 1 || len(tos) > 1 {
+		return 0, false, fmt.Errorf("unable to parse duration, got %d 'from' and %d 'to' results", len(froms), len(tos))
+	}
+	if len(froms) == 0 || len(tos) == 0 || froms[0] == nil || tos[0] == nil {
+		return 0, false, nil
+	}
+	return tos[0].Sub(froms[0].Time), true, nil
+}
+
+// podContainers returns the statuses of the init and regular containers of
+// the pod
+func podContainers(pod *corev1.Pod) []corev1.ContainerStatus {
+	return append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+}
+
+// oomKilled returns the containers of the pod terminated as OOMKilled, now
+// or before their last restart
+func oomKilled(pod *corev1.Pod) int {
+	killed := 0
+	for _, container := range podContainers(pod) {
+		current, last := container.State.Terminated, container.LastTerminationState.Terminated
+		if (current != nil && current.Reason == "OOMKilled") || (last != nil && last.Reason == "OOMKilled") {
+			killed++
+		}
+	}
+	return killed
+}
+
+// containerRestarts returns the restarts of the containers of the pod
+func containerRestarts(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, container := range podContainers(pod) {
+		restarts += container.RestartCount
+	}
+	return restarts
+}
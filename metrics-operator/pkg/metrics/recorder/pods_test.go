@@ -0,0 +1,93 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPodValuePresets(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-run-pod", Namespace: "dev"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "prepare"}},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-build", RestartCount: 2, LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}},
+				{Name: "step-test", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}},
+				{Name: "step-push", RestartCount: 1},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithPodLister(context.Background(), corev1listers.NewPodLister(indexer))
+	taskRun := func(pod string) *v1alpha1.RunDimensions {
+		return TaskRunDimensions(&pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "build-run", Namespace: "dev"},
+			Status: pipelinev1beta1.TaskRunStatus{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				PodName: pod,
+			}},
+		})
+	}
+
+	for preset, want := range map[v1alpha1.ValuePreset]float64{v1alpha1.ValuePresetOOMKilled: 2, v1alpha1.ValuePresetContainerRestarts: 3} {
+		value, err := MeasureValuePreset(ctx, preset, taskRun("build-run-pod"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != want {
+			t.Errorf("%s: got %v, want %v", preset, value, want)
+		}
+	}
+	if _, err := MeasureValuePreset(ctx, v1alpha1.ValuePresetOOMKilled, taskRun("pruned-pod")); !IsSkipped(err) {
+		t.Errorf("want a run without pod skipped, got %v", err)
+	}
+	if _, err := MeasureValuePreset(context.Background(), v1alpha1.ValuePresetOOMKilled, taskRun("build-run-pod")); !IsSkipped(err) {
+		t.Errorf("want runs skipped without pod lister, got %v", err)
+	}
+}
+
+func TestSchedulingLatency(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-run-pod", Namespace: "dev"},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodInitialized, Status: corev1.ConditionTrue, LastTransitionTime: *MustParseRFC3339("2023-08-16T16:00:50Z")},
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: *MustParseRFC3339("2023-08-16T16:00:40Z")},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithPodLister(context.Background(), corev1listers.NewPodLister(indexer))
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-run", Namespace: "dev", CreationTimestamp: *MustParseRFC3339("2023-08-16T16:00:00Z")},
+		Status: pipelinev1beta1.TaskRunStatus{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+			PodName: "build-run-pod",
+		}},
+	})
+
+	metric := v1alpha1.ExpandPreset(v1alpha1.Metric{Preset: v1alpha1.PresetSchedulingLatency})
+	metric.SetDefaults(ctx)
+	if metric.Type != "histogram" || metric.Name != "scheduling_latency" || metric.Duration == nil || !metric.Duration.ToPod {
+		t.Fatalf("unexpected expansion %+v", metric)
+	}
+	duration, found, err := measureRunDuration(ctx, metric.Duration, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || duration.Seconds() != 40 {
+		t.Errorf("want 40s to the scheduling of the pod, got %v, %v", duration, found)
+	}
+	if _, _, err := measureRunDuration(context.Background(), metric.Duration, run); !IsSkipped(err) {
+		t.Errorf("want runs skipped without pod lister, got %v", err)
+	}
+}
@@ -2,10 +2,15 @@ package recorder
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -13,8 +18,28 @@ import (
 )
 
 func tagMapFromByStatements(by []v1alpha1.ByStatement, run *v1alpha1.RunDimensions) (*tag.Map, error) {
+	return tagMapFromMetric(context.Background(), &v1alpha1.Metric{By: by}, run)
+}
+
+// tagMapFromMetric returns the tags of the by statements on top of the
+// constant tags of the metric, the static tags and the tags attached to the
+// context
+func tagMapFromMetric(ctx context.Context, metric *v1alpha1.Metric, run *v1alpha1.RunDimensions) (*tag.Map, error) {
+	defer ObserveEvaluation(ctx, StageTags, time.Now())
 	mutators := []tag.Mutator{}
-	for _, byStatement := range by {
+	for key, value := range getStaticTags() {
+		mutators = append(mutators, tag.Upsert(tag.MustNewKey(key), value))
+	}
+	for key, value := range metric.Tags {
+		tagKey, err := tag.NewKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", key, err)
+		}
+		mutators = append(mutators, tag.Upsert(tagKey, value))
+	}
+	keys := make([]tag.Key, 0, len(metric.By))
+	values := make([]string, 0, len(metric.By))
+	for _, byStatement := range metric.By {
 		byKey, err := byStatement.Key()
 		if err != nil {
 			return nil, err
@@ -23,25 +48,97 @@ func tagMapFromByStatements(by []v1alpha1.ByStatement, run *v1alpha1.RunDimensio
 		if err != nil {
 			return nil, err
 		}
+		if _, ok := byStatement.ReasonCondition(); ok {
+			byValue = v1alpha1.NormalizeReason(byValue, metric.Reasons)
+		}
+		if byValue, err = byStatement.Transform(byValue); err != nil {
+			return nil, fmt.Errorf("could not transform dimension %q: %w", byKey, err)
+		}
 		// TODO: error handling
-		tagKey := tag.MustNewKey(byKey)
-		mutators = append(mutators, tag.Upsert(tagKey, byValue))
+		keys = append(keys, tag.MustNewKey(byKey))
+		values = append(values, byValue)
+	}
+	if guard := cardinalityGuardFrom(ctx); guard != nil && !guard.admit(values) {
+		for i := range values {
+			values[i] = Overflow
+		}
+	}
+	for i, tagKey := range keys {
+		mutators = append(mutators, tag.Upsert(tagKey, values[i]))
 	}
-	ctx, err := tag.New(context.Background(), mutators...)
+	ctx, err := tag.New(ctx, mutators...)
 	if err != nil {
 		return nil, err
 	}
 	return tag.FromContext(ctx), nil
 }
 
-func viewTags(by []v1alpha1.ByStatement) []tag.Key {
-	keys := []tag.Key{}
-	for _, byStatement := range by {
+// metricDescription returns the help text of the metric, or the generated
+// description when none is given
+func metricDescription(metric *v1alpha1.Metric, generated string) string {
+	if metric.Help != "" {
+		return metric.Help
+	}
+	return generated
+}
+
+// viewAggregation returns the aggregation selected by the metric, the
+// aggregation of its type when none is
+func viewAggregation(metric *v1alpha1.Metric, typeAggregation *view.Aggregation) *view.Aggregation {
+	switch metric.Aggregation {
+	case v1alpha1.AggregationSum:
+		return view.Sum()
+	case v1alpha1.AggregationCount:
+		return view.Count()
+	case v1alpha1.AggregationLastValue, v1alpha1.AggregationSummary:
+		// summaries record precomputed quantiles
+		return view.LastValue()
+	case v1alpha1.AggregationDistribution:
+		return view.Distribution(metricBuckets(metric)...)
+	default:
+		return typeAggregation
+	}
+}
+
+// metricBuckets returns the explicit boundaries of the metric or the ones
+// generated by its bucket strategy, the default ones without a strategy.
+// Monitors with an invalid strategy are rejected before their metrics are
+// registered.
+func metricBuckets(metric *v1alpha1.Metric) []float64 {
+	if len(metric.Buckets) > 0 {
+		return metric.Buckets
+	}
+	if metric.BucketStrategy == nil {
+		return v1alpha1.DefaultBuckets
+	}
+	buckets, err := metric.BucketStrategy.Buckets()
+	if err != nil {
+		return v1alpha1.DefaultBuckets
+	}
+	return buckets
+}
+
+// viewTags returns the tag keys of the by statements and the constant tags of
+// the metric, of the static tags and of the context, sorted by name so the
+// same statements in a different order produce the same view
+func viewTags(metric *v1alpha1.Metric) []tag.Key {
+	names := sets.New[string](getContextTagKeys()...)
+	for key := range getStaticTags() {
+		names.Insert(key)
+	}
+	for key := range metric.Tags {
+		names.Insert(key)
+	}
+	for _, byStatement := range metric.By {
 		key, err := byStatement.Key()
 		if err != nil {
 			continue
 		}
-		tagKey, err := tag.NewKey(key)
+		names.Insert(key)
+	}
+	keys := []tag.Key{}
+	for _, name := range sets.List(names) {
+		tagKey, err := tag.NewKey(name)
 		if err != nil {
 			continue
 		}
@@ -50,6 +147,22 @@ func viewTags(by []v1alpha1.ByStatement) []tag.Key {
 	return keys
 }
 
+// SpecHash returns a stable identity of the metric spec, by statements are
+// sorted first since their order doesn't change the view
+func SpecHash(metric *v1alpha1.Metric) (string, error) {
+	normalized := metric.DeepCopy()
+	sort.SliceStable(normalized.By, func(i, j int) bool {
+		ki, _ := normalized.By[i].Key()
+		kj, _ := normalized.By[j].Key()
+		return ki < kj
+	})
+	spec, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(spec)), nil
+}
+
 func match(m *v1alpha1.MetricGaugeMatch, run *v1alpha1.RunDimensions) (bool, error) {
 	v, err := m.Key.Value(run)
 	if err != nil {
@@ -92,3 +205,16 @@ func PipelineRunDimensions(pipelineRun *pipelinev1beta1.PipelineRun) *v1alpha1.R
 		Object:    pipelineRun,
 	}
 }
+
+// MetadataRunDimensions returns the dimensions of a run known only by its
+// metadata, enough to clean its series without keeping the whole run around.
+func MetadataRunDimensions(resource string, object *metav1.PartialObjectMetadata) *v1alpha1.RunDimensions {
+	return &v1alpha1.RunDimensions{
+		Resource:  resource,
+		Name:      object.Name,
+		Namespace: object.Namespace,
+		IsDeleted: object.DeletionTimestamp != nil,
+		Labels:    object.Labels,
+		Object:    object,
+	}
+}
@@ -0,0 +1,151 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestViewAggregation(t *testing.T) {
+	tests := []struct {
+		aggregation v1alpha1.MetricAggregation
+		want        view.AggType
+	}{
+		{want: view.AggTypeCount},
+		{aggregation: v1alpha1.AggregationSum, want: view.AggTypeSum},
+		{aggregation: v1alpha1.AggregationCount, want: view.AggTypeCount},
+		{aggregation: v1alpha1.AggregationLastValue, want: view.AggTypeLastValue},
+		{aggregation: v1alpha1.AggregationSummary, want: view.AggTypeLastValue},
+		{aggregation: v1alpha1.AggregationDistribution, want: view.AggTypeDistribution},
+	}
+	for _, tt := range tests {
+		got := viewAggregation(&v1alpha1.Metric{Aggregation: tt.aggregation}, view.Count())
+		if got.Type != tt.want {
+			t.Errorf("aggregation %q: want %s, got %s", tt.aggregation, tt.want, got.Type)
+		}
+	}
+	distribution := viewAggregation(&v1alpha1.Metric{Aggregation: v1alpha1.AggregationDistribution, Buckets: []float64{10, 60}}, view.Count())
+	if diff := cmp.Diff([]float64{10, 60}, distribution.Buckets); diff != "" {
+		t.Errorf("buckets (-want, +got):\n%s", diff)
+	}
+}
+
+func TestHistogramLastValue(t *testing.T) {
+	histogram := NewGenericRunHistogram(&v1alpha1.Metric{
+		Type:        "histogram",
+		Name:        "duration",
+		Duration:    &v1alpha1.MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"},
+		Aggregation: v1alpha1.AggregationLastValue,
+	}, "taskrun", "all", "", nil)
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(histogram.View()); err != nil {
+		t.Fatal(err)
+	}
+	for _, duration := range []time.Duration{5 * time.Minute, 2 * time.Minute} {
+		taskRun := timedTaskRun("a", &metav1.Duration{Duration: time.Hour}, duration)
+		if err := histogram.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := meter.RetrieveData(histogram.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.LastValueData).Value != 120 {
+		t.Errorf("want the duration of the last run, got %v", rows)
+	}
+}
+
+func TestMetricHelp(t *testing.T) {
+	monitor := &v1alpha1.TaskMonitor{ObjectMeta: metav1.ObjectMeta{Name: "build"}}
+	tests := []struct {
+		metricType string
+		view       func(m *v1alpha1.Metric) *view.View
+		generated  string
+	}{{
+		metricType: "counter",
+		view:       func(m *v1alpha1.Metric) *view.View { return NewTaskCounter(m, monitor).View() },
+		generated:  "count samples for task build/runs",
+	}, {
+		metricType: "gauge",
+		view:       func(m *v1alpha1.Metric) *view.View { return NewTaskGauge(m, monitor).View() },
+		generated:  "gauge samples for task build/runs",
+	}, {
+		metricType: "histogram",
+		view:       func(m *v1alpha1.Metric) *view.View { return NewTaskHistogram(m, monitor).View() },
+		generated:  "histogram samples in seconds for task build/runs",
+	}, {
+		metricType: "timeoutRatio",
+		view:       func(m *v1alpha1.Metric) *view.View { return NewTaskTimeoutRatio(m, monitor).View() },
+		generated:  "fraction of the timeout used for task build/runs",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.metricType, func(t *testing.T) {
+			if got := tt.view(&v1alpha1.Metric{Name: "runs", Type: tt.metricType}).Description; got != tt.generated {
+				t.Errorf("want the generated description %q, got %q", tt.generated, got)
+			}
+			// the help text is exported verbatim
+			help := "Builds of the release pipeline, see https://example.com/runbook"
+			v := tt.view(&v1alpha1.Metric{Name: "runs", Type: tt.metricType, Help: help})
+			if v.Description != help || v.Measure.Description() != help {
+				t.Errorf("want the help text as description, got %q and %q", v.Description, v.Measure.Description())
+			}
+		})
+	}
+}
+
+func TestViewTagsSorted(t *testing.T) {
+	by := []v1alpha1.ByStatement{
+		{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("team")}},
+		{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: pointer.String("Succeeded")}},
+		{MetricDimensionRef: v1alpha1.MetricDimensionRef{Namespace: pointer.Bool(true)}},
+	}
+	reversed := []v1alpha1.ByStatement{by[2], by[1], by[0]}
+	for _, by := range [][]v1alpha1.ByStatement{by, reversed} {
+		names := []string{}
+		for _, key := range viewTags(&v1alpha1.Metric{By: by, Tags: map[string]string{"region": "us"}}) {
+			names = append(names, key.Name())
+		}
+		if diff := cmp.Diff([]string{"namespace", "region", "status", "team"}, names); diff != "" {
+			t.Errorf("tag keys (-want, +got):\n%s", diff)
+		}
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Name: "runs",
+		Type: "counter",
+		By: []v1alpha1.ByStatement{
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("team")}},
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: pointer.String("Succeeded")}},
+		},
+	}
+	hash, err := SpecHash(metric)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reordered := metric.DeepCopy()
+	reordered.By[0], reordered.By[1] = reordered.By[1], reordered.By[0]
+	if got, err := SpecHash(reordered); err != nil || got != hash {
+		t.Errorf("want the same hash when by statements are reordered, got %s, %v", got, err)
+	}
+	if metric.By[0].Label == nil {
+		t.Error("want the spec left unsorted")
+	}
+
+	edited := metric.DeepCopy()
+	edited.Help = "Runs by team"
+	if got, err := SpecHash(edited); err != nil || got == hash {
+		t.Errorf("want another hash when the spec changes, got %s, %v", got, err)
+	}
+}
@@ -0,0 +1,35 @@
+package recorder
+
+import (
+	"sync"
+
+	"go.opencensus.io/tag"
+)
+
+var (
+	staticTags   map[string]string
+	staticTagsMu sync.RWMutex
+)
+
+// SetStaticTags sets the tags added to every sample of monitor metrics, e.g.
+// the environment or region of the installation. Values can be changed at any
+// time, views of registered metrics keep the keys they were created with.
+func SetStaticTags(tags map[string]string) error {
+	copied := make(map[string]string, len(tags))
+	for key, value := range tags {
+		if _, err := tag.NewKey(key); err != nil {
+			return err
+		}
+		copied[key] = value
+	}
+	staticTagsMu.Lock()
+	defer staticTagsMu.Unlock()
+	staticTags = copied
+	return nil
+}
+
+func getStaticTags() map[string]string {
+	staticTagsMu.RLock()
+	defer staticTagsMu.RUnlock()
+	return staticTags
+}
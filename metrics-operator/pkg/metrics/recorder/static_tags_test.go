@@ -0,0 +1,52 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/tag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestStaticTags(t *testing.T) {
+	if err := SetStaticTags(map[string]string{"environment": "prod", "region": "eu", "team": "overridden"}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetStaticTags(nil)
+	metric := &v1alpha1.Metric{
+		Type: "counter",
+		Name: "runs",
+		By:   []v1alpha1.ByStatement{{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("team")}}},
+		Tags: map[string]string{"region": "us"},
+	}
+	keys := []string{}
+	for _, key := range viewTags(metric) {
+		keys = append(keys, key.Name())
+	}
+	if diff := cmp.Diff([]string{"environment", "region", "team"}, keys); diff != "" {
+		t.Errorf("view tags (-want, +got):\n%s", diff)
+	}
+
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "build-run", Namespace: "dev", Labels: map[string]string{"team": "a"}}})
+	tagMap, err := tagMapFromMetric(context.Background(), metric, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// tags and dimensions of the metric take precedence over the static tags
+	for key, want := range map[string]string{"environment": "prod", "region": "us", "team": "a"} {
+		if got, _ := tagMap.Value(tag.MustNewKey(key)); got != want {
+			t.Errorf("tag %s: want %q, got %q", key, want, got)
+		}
+	}
+
+	if err := SetStaticTags(map[string]string{"": "prod"}); err == nil {
+		t.Error("want an error for an empty tag key")
+	}
+	if _, found := getStaticTags()["environment"]; !found {
+		t.Error("want the static tags kept after an invalid update")
+	}
+}
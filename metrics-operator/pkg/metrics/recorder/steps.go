@@ -0,0 +1,65 @@
+package recorder
+
+import (
+	"strconv"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// stepSample is a step of a TaskRun recorded as a sample of its own
+type stepSample struct {
+	run   *v1alpha1.RunDimensions
+	state *pipelinev1beta1.StepState
+}
+
+// stepSamples returns a sample per step of the TaskRun, with the name, image
+// and exit code of the step. With retries, the steps of the attempts in
+// .status.retriesStatus come first, each seen as a run of its own attempt.
+// The boolean is false when the run isn't a TaskRun.
+func stepSamples(run *v1alpha1.RunDimensions, retries bool) ([]stepSample, bool) {
+	taskRun, ok := run.Object.(*pipelinev1beta1.TaskRun)
+	if !ok {
+		return nil, false
+	}
+	attempts := []*pipelinev1beta1.TaskRun{taskRun}
+	if retries {
+		attempts = make([]*pipelinev1beta1.TaskRun, 0, len(taskRun.Status.RetriesStatus)+1)
+		for i := range taskRun.Status.RetriesStatus {
+			attempt := taskRun.DeepCopy()
+			attempt.Status = *taskRun.Status.RetriesStatus[i].DeepCopy()
+			attempt.Status.RetriesStatus = taskRun.Status.RetriesStatus[:i]
+			attempts = append(attempts, attempt)
+		}
+		attempts = append(attempts, taskRun)
+	}
+	samples := []stepSample{}
+	for _, attempt := range attempts {
+		for i := range attempt.Status.Steps {
+			step := &attempt.Status.Steps[i]
+			stepRun := *run
+			stepRun.Status = attempt.Status.Status
+			stepRun.Object = attempt
+			stepRun.Step = step.Name
+			stepRun.StepImage = stepImage(attempt, step)
+			if step.Terminated != nil {
+				stepRun.StepExitCode = strconv.Itoa(int(step.Terminated.ExitCode))
+			}
+			samples = append(samples, stepSample{run: &stepRun, state: step})
+		}
+	}
+	return samples, true
+}
+
+// stepImage returns the image of the step as written in the task spec, the
+// image ID reported by the container runtime otherwise
+func stepImage(taskRun *pipelinev1beta1.TaskRun, step *pipelinev1beta1.StepState) string {
+	if taskRun.Status.TaskSpec != nil {
+		for _, spec := range taskRun.Status.TaskSpec.Steps {
+			if spec.Name == step.Name && spec.Image != "" {
+				return spec.Image
+			}
+		}
+	}
+	return step.ImageID
+}
@@ -0,0 +1,75 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStepGranularityCounter(t *testing.T) {
+	metric := v1alpha1.ExpandGranularity(v1alpha1.Metric{
+		Type:        "counter",
+		Name:        "steps",
+		Granularity: v1alpha1.GranularityStep,
+	})
+	counter := NewGenericRunCounter(&metric, "task", "build", "", nil)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(counter.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	exited := func(name string, exitCode int32) pipelinev1beta1.StepState {
+		return pipelinev1beta1.StepState{Name: name, ImageID: "docker.io/library/" + name + "@sha256:1234", ContainerState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode},
+		}}
+	}
+	taskSpec := &pipelinev1beta1.TaskSpec{Steps: []pipelinev1beta1.Step{{Name: "test", Image: "golang:1.21"}}}
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-run"},
+		Status: pipelinev1beta1.TaskRunStatus{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+			TaskSpec: taskSpec,
+			Steps:    []pipelinev1beta1.StepState{exited("test", 0), exited("upload", 0)},
+			RetriesStatus: []pipelinev1beta1.TaskRunStatus{{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				TaskSpec: taskSpec,
+				Steps:    []pipelinev1beta1.StepState{exited("test", 1), {Name: "upload"}},
+			}}},
+		}},
+	}
+	if err := counter.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := meter.RetrieveData(counter.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{}
+	for _, row := range rows {
+		key := ""
+		for _, tag := range row.Tags {
+			key += tag.Key.Name() + "=" + tag.Value + ","
+		}
+		counts[key] = row.Data.(*view.CountData).Value
+	}
+	expected := map[string]int64{
+		"exitCode=0,image=golang:1.21,step=test,":                            1,
+		"exitCode=1,image=golang:1.21,step=test,":                            1,
+		"exitCode=0,image=docker.io/library/upload@sha256:1234,step=upload,": 1,
+	}
+	if len(counts) != len(expected) {
+		t.Errorf("unexpected step counts %v", counts)
+	}
+	for key, count := range expected {
+		if counts[key] != count {
+			t.Errorf("expected %d steps for %s, got %d", count, key, counts[key])
+		}
+	}
+}
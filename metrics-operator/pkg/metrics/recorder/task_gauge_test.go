@@ -1,10 +1,13 @@
 package recorder
 
 import (
+	"context"
 	"testing"
 
 	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -113,3 +116,212 @@ func TestGaugeValue(t *testing.T) {
 		t.Errorf("Expected 0, got %f", gauge)
 	}
 }
+
+func TestRunningPreset(t *testing.T) {
+	metric := v1alpha1.ExpandPreset(v1alpha1.Metric{Preset: v1alpha1.PresetRunning})
+	gauge := NewTaskRunGauge(&metric, &v1alpha1.TaskRunMonitor{ObjectMeta: metav1.ObjectMeta{Name: "builds"}})
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(gauge.View()); err != nil {
+		t.Fatal(err)
+	}
+	running := func() float64 {
+		rows, err := meter.RetrieveData(gauge.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("expected 1 row, got %d", len(rows))
+		}
+		return rows[0].Data.(*view.LastValueData).Value
+	}
+	taskRun := func(name string, status corev1.ConditionStatus) *pipelinev1beta1.TaskRun {
+		return &pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: pipelinev1beta1.TaskRunStatus{
+				Status: duckv1.Status{
+					Conditions: duckv1.Conditions{{Type: "Succeeded", Status: status}},
+				},
+			},
+		}
+	}
+
+	ctx := context.Background()
+	for _, name := range []string{"build-1", "build-2"} {
+		if err := gauge.Record(ctx, meter, TaskRunDimensions(taskRun(name, corev1.ConditionUnknown))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := running(); got != 2 {
+		t.Errorf("expected 2 running, got %f", got)
+	}
+	if err := gauge.Record(ctx, meter, TaskRunDimensions(taskRun("build-1", corev1.ConditionTrue))); err != nil {
+		t.Fatal(err)
+	}
+	if got := running(); got != 1 {
+		t.Errorf("expected 1 running after completion, got %f", got)
+	}
+	gauge.Clean(ctx, meter, TaskRunDimensions(taskRun("build-2", corev1.ConditionUnknown)))
+	if got := running(); got != 0 {
+		t.Errorf("expected 0 running after clean, got %f", got)
+	}
+}
+
+func TestTagMapFromMetricTags(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type: "gauge",
+		Name: "status",
+		Tags: map[string]string{"team": "platform", "repository": "constant"},
+		By: []v1alpha1.ByStatement{
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("repository")}},
+		},
+	}
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world", Labels: map[string]string{"repository": "repo0"}},
+	})
+
+	tagMap, err := tagMapFromMetric(context.Background(), metric, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"team": "platform", "repository": "repo0"} {
+		if got, _ := tagMap.Value(tag.MustNewKey(key)); got != want {
+			t.Errorf("tag %s: got %q, want %q", key, got, want)
+		}
+	}
+	if got := len(viewTags(metric)); got != 2 {
+		t.Errorf("got %d view tags, want 2", got)
+	}
+}
+
+func TestTagMapFromMetricTransforms(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type: "counter",
+		Name: "runs",
+		By: []v1alpha1.ByStatement{
+			{
+				MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("branch")},
+				Transforms: []v1alpha1.TagTransform{
+					{Type: v1alpha1.TagTransformLowercase},
+					{Type: v1alpha1.TagTransformRegex, Pattern: "^(release|feature)/"},
+				},
+			},
+			{
+				MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("service")},
+				Transforms:         []v1alpha1.TagTransform{{Type: v1alpha1.TagTransformTruncate, Length: 4}},
+			},
+		},
+	}
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world", Labels: map[string]string{
+			"branch":  "Feature/JIRA-42",
+			"service": "payments",
+		}},
+	})
+
+	tagMap, err := tagMapFromMetric(context.Background(), metric, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"branch": "feature", "service": "paym"} {
+		if got, _ := tagMap.Value(tag.MustNewKey(key)); got != want {
+			t.Errorf("tag %s: got %q, want %q", key, got, want)
+		}
+	}
+
+	metric.By[1].Transforms[0].Length = 0
+	if _, err := tagMapFromMetric(context.Background(), metric, run); err == nil {
+		t.Error("expected an error for an invalid transform")
+	}
+}
+
+func TestTagMapFromMetricMaxCardinality(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type:           "counter",
+		Name:           "runs",
+		MaxCardinality: 2,
+		By: []v1alpha1.ByStatement{
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("revision")}},
+		},
+	}
+	guard := NewCardinalityGuard(metric.MaxCardinality)
+	ctx := WithCardinalityGuard(context.Background(), guard)
+
+	for _, tc := range []struct{ revision, want string }{
+		{"a", "a"}, {"b", "b"}, {"c", Overflow}, {"a", "a"}, {"d", Overflow},
+	} {
+		run := TaskRunDimensions(&pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "build-" + tc.revision, Labels: map[string]string{"revision": tc.revision}},
+		})
+		tagMap, err := tagMapFromMetric(ctx, metric, run)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := tagMap.Value(tag.MustNewKey("revision")); got != tc.want {
+			t.Errorf("revision %s: got %q, want %q", tc.revision, got, tc.want)
+		}
+	}
+	if got := guard.Overflowed(); got != 2 {
+		t.Errorf("got %d overflowed samples, want 2", got)
+	}
+}
+
+func TestTagMapFromMetricDefault(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type: "counter",
+		Name: "runs",
+		By: []v1alpha1.ByStatement{
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("team")}, Default: "unknown"},
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Param: pointer.String("environment")}},
+		},
+	}
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "hello-world"}})
+
+	tagMap, err := tagMapFromMetric(context.Background(), metric, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"team": "unknown", "environment": "MISSING"} {
+		if got, _ := tagMap.Value(tag.MustNewKey(key)); got != want {
+			t.Errorf("tag %s: got %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestTagMapFromMetricMode(t *testing.T) {
+	by := v1alpha1.ByStatement{MetricDimensionRef: v1alpha1.MetricDimensionRef{AnnotationJSON: &v1alpha1.AnnotationJSONRef{
+		Annotation: "example.com/test-summary",
+		Path:       ".suites[*].name",
+		Tag:        "suites",
+	}}}
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{
+		Name:        "hello-world",
+		Annotations: map[string]string{"example.com/test-summary": `{"suites": [{"name": "unit"}, {"name": "e2e"}]}`},
+	}})
+
+	for _, tc := range []struct {
+		mode      v1alpha1.ByMode
+		separator string
+		want      string
+	}{
+		{v1alpha1.ByModeFirst, "", "unit"},
+		{v1alpha1.ByModeJoin, "", "unit,e2e"},
+		{v1alpha1.ByModeJoin, "+", "unit+e2e"},
+	} {
+		by.Mode, by.Separator = tc.mode, tc.separator
+		tagMap, err := tagMapFromByStatements([]v1alpha1.ByStatement{by}, run)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := tagMap.Value(tag.MustNewKey("suites")); got != tc.want {
+			t.Errorf("mode %s: got %q, want %q", tc.mode, got, tc.want)
+		}
+	}
+
+	by.Mode = v1alpha1.ByModeError
+	if _, err := tagMapFromByStatements([]v1alpha1.ByStatement{by}, run); err == nil {
+		t.Error("expected an error for several values")
+	}
+}
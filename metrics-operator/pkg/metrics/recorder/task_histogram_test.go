@@ -1,11 +1,17 @@
 package recorder
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestParseDuration(t *testing.T) {
@@ -42,3 +48,378 @@ func TestParseDuration(t *testing.T) {
 		t.Errorf("expected 30s, but got %fs", duration)
 	}
 }
+
+func TestMeasureDurationSegments(t *testing.T) {
+	duration := &monitoringv1alpha1.MetricHistogramDuration{
+		Segments: []monitoringv1alpha1.MetricDurationSegment{
+			{
+				From: ".status.retriesStatus[*].startTime",
+				To:   ".status.retriesStatus[*].completionTime",
+			},
+			{
+				From: ".status.startTime",
+				To:   ".status.completionTime",
+			},
+		},
+	}
+
+	taskRun := &pipelinev1beta1.TaskRun{
+		Status: pipelinev1beta1.TaskRunStatus{
+			TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				StartTime:      MustParseRFC3339("2023-08-16T16:00:00Z"),
+				CompletionTime: MustParseRFC3339("2023-08-16T16:00:30Z"),
+				RetriesStatus: []pipelinev1beta1.TaskRunStatus{
+					{
+						TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+							StartTime:      MustParseRFC3339("2023-08-16T15:59:00Z"),
+							CompletionTime: MustParseRFC3339("2023-08-16T15:59:10Z"),
+						},
+					},
+					{
+						TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+							StartTime:      MustParseRFC3339("2023-08-16T15:59:20Z"),
+							CompletionTime: MustParseRFC3339("2023-08-16T15:59:40Z"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	measured, found, err := MeasureDuration(duration, taskRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("expected duration to be found")
+	}
+	if measured.Seconds() != 60 {
+		t.Errorf("expected 60s, but got %fs", measured.Seconds())
+	}
+
+	taskRun.Status.RetriesStatus = nil
+	measured, _, err = MeasureDuration(duration, taskRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if measured.Seconds() != 30 {
+		t.Errorf("expected 30s without retries, but got %fs", measured.Seconds())
+	}
+}
+
+func TestMeasureValueFromAnnotation(t *testing.T) {
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"example.com/test-summary": `{"tests": {"failed": "3", "suite": "unit"}}`,
+			},
+		},
+	}
+	run := &monitoringv1alpha1.RunDimensions{Object: taskRun}
+	summary := func(path string) *monitoringv1alpha1.AnnotationJSONRef {
+		return &monitoringv1alpha1.AnnotationJSONRef{Annotation: "example.com/test-summary", Path: path, Tag: "suite"}
+	}
+
+	value, found, err := MeasureValue(&monitoringv1alpha1.MetricValue{AnnotationJSON: summary(".tests.failed")}, run)
+	if err != nil || !found || value != 3 {
+		t.Errorf("got value %f, found %v, error %v", value, found, err)
+	}
+	if _, found, err := MeasureValue(&monitoringv1alpha1.MetricValue{AnnotationJSON: summary(".tests.skipped")}, run); err != nil || found {
+		t.Errorf("missing field found %v, error %v", found, err)
+	}
+
+	by := monitoringv1alpha1.MetricDimensionRef{AnnotationJSON: summary(".tests.suite")}
+	if tag, err := by.Value(run); err != nil || tag != "unit" {
+		t.Errorf("got tag %q, error %v", tag, err)
+	}
+}
+
+func TestMeasureValueFromPath(t *testing.T) {
+	taskRun := &pipelinev1beta1.TaskRun{
+		Status: pipelinev1beta1.TaskRunStatus{
+			TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				TaskRunResults: []pipelinev1beta1.TaskRunResult{
+					{Name: "coverage", Value: *pipelinev1beta1.NewStructuredValues("0.87")},
+					{Name: "digest", Value: *pipelinev1beta1.NewStructuredValues("sha256:abc")},
+				},
+			},
+		},
+	}
+	run := &monitoringv1alpha1.RunDimensions{Object: taskRun}
+	result := func(name string) *monitoringv1alpha1.MetricValue {
+		return &monitoringv1alpha1.MetricValue{Path: fmt.Sprintf(`.status.taskResults[?(@.name=="%s")].value`, name)}
+	}
+
+	value, found, err := MeasureValue(result("coverage"), run)
+	if err != nil || !found || value != 0.87 {
+		t.Errorf("got value %f, found %v, error %v", value, found, err)
+	}
+	if _, found, err := MeasureValue(result("missing"), run); err != nil || found {
+		t.Errorf("missing result found %v, error %v", found, err)
+	}
+	if _, _, err := MeasureValue(result("digest"), run); err == nil {
+		t.Error("expected an error for a non numeric result")
+	}
+	if _, _, err := MeasureValue(&monitoringv1alpha1.MetricValue{Path: ".status.taskResults[*].name"}, run); err == nil {
+		t.Error("expected an error for several values")
+	}
+}
+
+func TestHistogramSteps(t *testing.T) {
+	histogram := NewGenericRunHistogram(&monitoringv1alpha1.Metric{
+		Type: "histogram",
+		Name: "step_duration",
+		By:   []monitoringv1alpha1.ByStatement{{MetricDimensionRef: monitoringv1alpha1.MetricDimensionRef{Step: pointer.Bool(true)}}},
+		Duration: &monitoringv1alpha1.MetricHistogramDuration{
+			From: ".terminated.startedAt",
+			To:   ".terminated.finishedAt",
+		},
+	}, "task", "build", "", nil)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(histogram.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	terminated := func(name, startedAt, finishedAt string) pipelinev1beta1.StepState {
+		return pipelinev1beta1.StepState{Name: name, ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			StartedAt:  *MustParseRFC3339(startedAt),
+			FinishedAt: *MustParseRFC3339(finishedAt),
+		}}}
+	}
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-run"},
+		Status: pipelinev1beta1.TaskRunStatus{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{Steps: []pipelinev1beta1.StepState{
+			terminated("clone", "2023-08-16T16:00:00Z", "2023-08-16T16:00:10Z"),
+			terminated("build", "2023-08-16T16:00:10Z", "2023-08-16T16:01:10Z"),
+			{Name: "push", ContainerState: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		}}},
+	}
+	if err := histogram.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := meter.RetrieveData(histogram.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	durations := map[string]float64{}
+	for _, row := range rows {
+		durations[row.Tags[0].Value] = row.Data.(*view.DistributionData).Sum()
+	}
+	if len(durations) != 2 || durations["clone"] != 10 || durations["build"] != 60 {
+		t.Errorf("unexpected step durations %v", durations)
+	}
+}
+
+func TestHistogramDurationPreset(t *testing.T) {
+	metric := &monitoringv1alpha1.Metric{
+		Type:           "histogram",
+		Name:           "queue_time",
+		DurationPreset: monitoringv1alpha1.DurationPresetQueueTime,
+	}
+	metric.SetDefaults(context.Background())
+	histogram := NewGenericRunHistogram(metric, "task", "build", "", nil)
+	if got := histogram.Metric().Duration; got == nil || got.From != ".metadata.creationTimestamp" || got.To != ".status.startTime" {
+		t.Errorf("unexpected duration %+v", got)
+	}
+
+	unknown := &monitoringv1alpha1.Metric{
+		Type:           "histogram",
+		Name:           "queue_time",
+		DurationPreset: "queue",
+	}
+	unknown.SetDefaults(context.Background())
+	invalid := NewGenericRunHistogram(unknown, "task", "build", "", nil)
+	run := TaskRunDimensions(&pipelinev1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "build-run"}})
+	if err := invalid.Record(context.Background(), view.NewMeter(), run); err == nil {
+		t.Error("expected an error for an unknown duration preset")
+	}
+}
+
+func TestHistogramUnit(t *testing.T) {
+	metric := &monitoringv1alpha1.Metric{
+		Type: "histogram",
+		Name: "duration",
+		Unit: monitoringv1alpha1.UnitMilliseconds,
+		Duration: &monitoringv1alpha1.MetricHistogramDuration{
+			From: ".status.startTime",
+			To:   ".status.completionTime",
+		},
+	}
+	metric.SetDefaults(context.Background())
+	histogram := NewGenericRunHistogram(metric, "task", "build", "", nil)
+	if name := histogram.MetricName(); name != "task_build_duration_milliseconds" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if unit := histogram.View().Measure.Unit(); unit != "ms" {
+		t.Errorf("unexpected unit %s", unit)
+	}
+	if got := histogram.View().Aggregation.Buckets[0]; got != 250 {
+		t.Errorf("want default buckets in milliseconds, got %v", histogram.View().Aggregation.Buckets)
+	}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(histogram.View()); err != nil {
+		t.Fatal(err)
+	}
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-run"},
+		Status: pipelinev1beta1.TaskRunStatus{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+			StartTime:      MustParseRFC3339("2023-08-16T16:00:00Z"),
+			CompletionTime: MustParseRFC3339("2023-08-16T16:00:02Z"),
+		}},
+	}
+	if err := histogram.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := meter.RetrieveData(histogram.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.DistributionData).Sum() != 2000 {
+		t.Errorf("want a 2000ms sample, got %v", rows)
+	}
+}
+
+func TestHistogramCountOver(t *testing.T) {
+	histogram := NewGenericRunHistogram(&monitoringv1alpha1.Metric{
+		Type:      "histogram",
+		Name:      "duration",
+		Duration:  &monitoringv1alpha1.MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"},
+		CountOver: &metav1.Duration{Duration: 10 * time.Minute},
+	}, "taskrun", "all", "", nil)
+	views := histogram.Views()
+	if len(views) != 2 {
+		t.Fatalf("want the histogram and the counter views, got %d", len(views))
+	}
+	if name := views[1].Measure.Name(); name != "taskrun_all_duration_over_total" {
+		t.Errorf("unexpected counter name %s", name)
+	}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(views...); err != nil {
+		t.Fatal(err)
+	}
+	timeout := &metav1.Duration{Duration: time.Hour}
+	for _, duration := range []time.Duration{5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 20 * time.Minute} {
+		if err := histogram.Record(context.Background(), meter, TaskRunDimensions(timedTaskRun("a", timeout, duration))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// runs lasting exactly the threshold aren't over it
+	rows, err := meter.RetrieveData(views[1].Measure.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.CountData).Value != 2 {
+		t.Errorf("want 2 runs over the threshold, got %v", rows)
+	}
+	rows, err = meter.RetrieveData(histogram.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.DistributionData).Count != 4 {
+		t.Errorf("want every run in the histogram, got %v", rows)
+	}
+}
+
+func BenchmarkMeasureDuration(b *testing.B) {
+	duration := &monitoringv1alpha1.MetricHistogramDuration{
+		From: ".metadata.creationTimestamp",
+		To:   ".status.completionTime",
+	}
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: *MustParseRFC3339("2023-08-16T15:59:06Z"),
+		},
+		Status: pipelinev1beta1.TaskRunStatus{
+			TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				CompletionTime: MustParseRFC3339("2023-08-16T15:59:36Z"),
+			},
+		},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := MeasureDuration(duration, taskRun); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTagMapFromAnnotationJSON(b *testing.B) {
+	run := &monitoringv1alpha1.RunDimensions{Object: &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"example.com/test-summary": `{"tests": {"failed": "3", "suite": "unit"}}`,
+			},
+		},
+	}}
+	by := []monitoringv1alpha1.ByStatement{{MetricDimensionRef: monitoringv1alpha1.MetricDimensionRef{
+		AnnotationJSON: &monitoringv1alpha1.AnnotationJSONRef{Annotation: "example.com/test-summary", Path: ".tests.suite", Tag: "suite"},
+	}}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := tagMapFromByStatements(by, run); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMeasureValueFromResult(t *testing.T) {
+	pipelineRun := &pipelinev1beta1.PipelineRun{
+		Status: pipelinev1beta1.PipelineRunStatus{
+			PipelineRunStatusFields: pipelinev1beta1.PipelineRunStatusFields{
+				PipelineResults: []pipelinev1beta1.PipelineRunResult{
+					{Name: "tests", Value: *pipelinev1beta1.NewStructuredValues("42")},
+					{Name: "image-size", Value: *pipelinev1beta1.NewStructuredValues("12Mi")},
+					{Name: "scan-time", Value: *pipelinev1beta1.NewStructuredValues("1m30s")},
+					{Name: "latency", Value: *pipelinev1beta1.NewStructuredValues("250ms")},
+					{Name: "digest", Value: *pipelinev1beta1.NewStructuredValues("sha256:abc")},
+				},
+			},
+		},
+	}
+	run := PipelineRunDimensions(pipelineRun)
+
+	for name, want := range map[string]float64{"tests": 42, "image-size": 12 * 1024 * 1024, "scan-time": 90, "latency": 0.25} {
+		value, found, err := MeasureValue(&monitoringv1alpha1.MetricValue{Result: name}, run)
+		if err != nil || !found || value != want {
+			t.Errorf("%s: got value %f, found %v, error %v, want %f", name, value, found, err, want)
+		}
+	}
+	if _, found, err := MeasureValue(&monitoringv1alpha1.MetricValue{Result: "missing"}, run); err != nil || found {
+		t.Errorf("missing result found %v, error %v", found, err)
+	}
+	if _, _, err := MeasureValue(&monitoringv1alpha1.MetricValue{Result: "digest"}, run); err == nil {
+		t.Error("expected an error for a non numeric result")
+	}
+}
+
+func TestHistogramLinearBucketsFromZero(t *testing.T) {
+	histogram := NewGenericRunHistogram(&monitoringv1alpha1.Metric{
+		Type:           "histogram",
+		Name:           "duration",
+		Duration:       &monitoringv1alpha1.MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"},
+		BucketStrategy: &monitoringv1alpha1.MetricBucketStrategy{Type: monitoringv1alpha1.BucketStrategyLinear, Width: 60, Count: 5},
+	}, "taskrun", "all", "", nil)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	v := histogram.View()
+	if err := meter.Register(v); err != nil {
+		t.Fatal(err)
+	}
+	// registering drops a 0 boundary, every generated boundary is kept
+	if buckets := v.Aggregation.Buckets; len(buckets) != 5 || buckets[0] != 60 {
+		t.Errorf("want 5 boundaries from 60, got %v", buckets)
+	}
+}
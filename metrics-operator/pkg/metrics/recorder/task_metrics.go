@@ -0,0 +1,106 @@
+package recorder
+
+import (
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+)
+
+// The metrics of TaskMonitors and ClusterTaskMonitors are the generic metrics
+// filtered by the task of the monitor, restricted to its namespace for a
+// TaskMonitor
+
+func NewTaskCounter(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunCounter {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunCounter(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskCounter returns the counter of a ClusterTaskMonitor, matching
+// the runs of every namespace
+func NewClusterTaskCounter(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunCounter {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunCounter(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskHistogram(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunHistogram {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunHistogram(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskHistogram returns the histogram of a ClusterTaskMonitor,
+// matching the runs of every namespace
+func NewClusterTaskHistogram(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunHistogram {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunHistogram(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskGauge(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunGauge {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunGauge(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskGauge returns the gauge of a ClusterTaskMonitor, matching the
+// runs of every namespace
+func NewClusterTaskGauge(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunGauge {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunGauge(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskTimeoutRatio(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunTimeoutRatio {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunTimeoutRatio(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskTimeoutRatio returns the timeout ratio of a ClusterTaskMonitor,
+// matching the runs of every namespace
+func NewClusterTaskTimeoutRatio(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunTimeoutRatio {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunTimeoutRatio(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskDurationBreakdown(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunDurationBreakdown {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunDurationBreakdown(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskDurationBreakdown returns the duration breakdown of a
+// ClusterTaskMonitor, matching the runs of every namespace
+func NewClusterTaskDurationBreakdown(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunDurationBreakdown {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunDurationBreakdown(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskLastValue(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunLastValue {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunLastValue(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskLastValue returns the last value of a ClusterTaskMonitor,
+// matching the runs of every namespace
+func NewClusterTaskLastValue(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunLastValue {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunLastValue(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRate(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunRate {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunRate(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskRate returns the rate of a ClusterTaskMonitor,
+// matching the runs of every namespace
+func NewClusterTaskRate(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunRate {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunRate(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskSLO(metric *v1alpha1.Metric, monitor *v1alpha1.TaskMonitor) *GenericRunSLO {
+	filter := NewTaskFilter(monitor.Namespace, &monitor.Spec)
+	return NewGenericRunSLO(metric, "task", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+// NewClusterTaskSLO returns the slo of a ClusterTaskMonitor,
+// matching the runs of every namespace
+func NewClusterTaskSLO(metric *v1alpha1.Metric, monitor *v1alpha1.ClusterTaskMonitor) *GenericRunSLO {
+	filter := NewTaskFilter("", &monitor.Spec)
+	return NewGenericRunSLO(metric, naming.ClusterResource("task"), monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
@@ -0,0 +1,91 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func completedTaskRun(name string, completed time.Time) *pipelinev1beta1.TaskRun {
+	return &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: pipelinev1beta1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{
+						Type:               apis.ConditionSucceeded,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(completed)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRate(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type:   "rate",
+		Name:   "builds",
+		Window: &metav1.Duration{Duration: 2 * time.Minute},
+	}
+	rate := NewGenericRunRate(metric, "task", "build", "", nil)
+	if name := rate.MetricName(); name != "task_build_builds_per_minute" {
+		t.Errorf("unexpected name %s", name)
+	}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(rate.View()); err != nil {
+		t.Fatal(err)
+	}
+	lastValue := func() float64 {
+		t.Helper()
+		rows, err := meter.RetrieveData(rate.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("want a single series, got %v", rows)
+		}
+		return rows[0].Data.(*view.LastValueData).Value
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	for i, completed := range []time.Time{now.Add(-30 * time.Second), now.Add(-90 * time.Second)} {
+		if err := rate.Record(ctx, meter, TaskRunDimensions(completedTaskRun(string(rune('a'+i)), completed))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rate.Record(ctx, meter, TaskRunDimensions(completedTaskRun("old", now.Add(-time.Hour)))); !IsSkipped(err) {
+		t.Errorf("want runs completed before the window skipped, got %v", err)
+	}
+	if got := lastValue(); got != 1 {
+		t.Errorf("want 2 runs over 2 minutes, got %v per minute", got)
+	}
+
+	// the spec is unchanged, the registered metric is replaced on reconcile
+	replaced := NewGenericRunRate(metric.DeepCopy(), "task", "build", "", nil)
+	replaced.Inherit(rate)
+	replaced.Tick(ctx, meter, now.Add(time.Minute))
+	if got := lastValue(); got != 0.5 {
+		t.Errorf("want 1 run left in the window, got %v per minute", got)
+	}
+	replaced.Tick(ctx, meter, now.Add(5*time.Minute))
+	if got := lastValue(); got != 0 {
+		t.Errorf("want no run left in the window, got %v per minute", got)
+	}
+	if len(replaced.rates.series) != 0 {
+		t.Errorf("want empty series forgotten, got %d", len(replaced.rates.series))
+	}
+}
@@ -0,0 +1,216 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestTaskRunCounter(t *testing.T) {
+	monitor := &v1alpha1.TaskRunMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "all"},
+		Spec:       v1alpha1.TaskRunMonitorSpec{Selector: metav1.LabelSelector{}},
+	}
+	counter := NewTaskRunCounter(&v1alpha1.Metric{
+		Type: "counter",
+		Name: "runs",
+		By: []v1alpha1.ByStatement{
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: pointer.String("Succeeded")}},
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Namespace: pointer.Bool(true)}},
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Label: pointer.String("tekton.dev/task")}},
+		},
+	}, monitor)
+	if counter.MetricName() != "taskrun_all_runs_total" {
+		t.Errorf("unexpected metric name %s", counter.MetricName())
+	}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(counter.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	taskRun := func(name, namespace string, status corev1.ConditionStatus) *pipelinev1beta1.TaskRun {
+		return &pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"tekton.dev/task": "build"}},
+			Status: pipelinev1beta1.TaskRunStatus{
+				Status: duckv1.Status{Conditions: duckv1.Conditions{{Type: "Succeeded", Status: status}}},
+			},
+		}
+	}
+	for _, tr := range []*pipelinev1beta1.TaskRun{
+		taskRun("a", "team-a", corev1.ConditionTrue),
+		taskRun("b", "team-a", corev1.ConditionTrue),
+		taskRun("c", "team-a", corev1.ConditionFalse),
+		taskRun("d", "team-b", corev1.ConditionTrue),
+	} {
+		if err := counter.Record(context.Background(), meter, TaskRunDimensions(tr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := meter.RetrieveData(counter.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{}
+	for _, row := range rows {
+		key := ""
+		for _, tag := range row.Tags {
+			key += tag.Key.Name() + "=" + tag.Value + ","
+		}
+		counts[key] = row.Data.(*view.CountData).Value
+	}
+	expected := map[string]int64{
+		"namespace=team-a,status=success,tekton.dev/task=build,": 2,
+		"namespace=team-a,status=failed,tekton.dev/task=build,":  1,
+		"namespace=team-b,status=success,tekton.dev/task=build,": 1,
+	}
+	for key, count := range expected {
+		if counts[key] != count {
+			t.Errorf("expected %d runs for %s, got %d", count, key, counts[key])
+		}
+	}
+}
+
+func TestRetriesValuePreset(t *testing.T) {
+	metric := v1alpha1.ExpandPreset(v1alpha1.Metric{ValuePreset: v1alpha1.ValuePresetRetries})
+	counter := NewGenericRunCounter(&metric, "taskrun", "all", "", nil)
+	if counter.MetricName() != "taskrun_all_retries_total" {
+		t.Errorf("unexpected metric name %s", counter.MetricName())
+	}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(counter.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	taskRun := func(name string, retries int) *pipelinev1beta1.TaskRun {
+		return &pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev", Labels: map[string]string{"tekton.dev/task": "build"}},
+			Status: pipelinev1beta1.TaskRunStatus{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				RetriesStatus: make([]pipelinev1beta1.TaskRunStatus, retries),
+			}},
+		}
+	}
+	for _, tr := range []*pipelinev1beta1.TaskRun{taskRun("a", 2), taskRun("b", 0), taskRun("c", 1)} {
+		if err := counter.Record(context.Background(), meter, TaskRunDimensions(tr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := meter.RetrieveData(counter.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected a single series, got %d", len(rows))
+	}
+	if got := rows[0].Data.(*view.SumData).Value; got != 3 {
+		t.Errorf("expected 3 retries, got %v", got)
+	}
+}
+
+func TestReasonDimensionPreset(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type:    "counter",
+		Name:    "failures",
+		By:      []v1alpha1.ByStatement{{MetricDimensionRef: v1alpha1.MetricDimensionRef{Preset: v1alpha1.DimensionPresetReason}}},
+		Reasons: []v1alpha1.ReasonNormalization{{Value: "timeout", Patterns: []string{"Timeout$"}}},
+	}
+	counter := NewGenericRunCounter(metric, "taskrun", "all", "", nil)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(counter.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	taskRun := func(reason string) *pipelinev1beta1.TaskRun {
+		return &pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "build-run", Namespace: "dev"},
+			Status: pipelinev1beta1.TaskRunStatus{
+				Status: duckv1.Status{Conditions: duckv1.Conditions{{Type: "Succeeded", Status: corev1.ConditionFalse, Reason: reason}}},
+			},
+		}
+	}
+	for _, reason := range []string{"TaskRunTimeout", "Failed", "Failed"} {
+		if err := counter.Record(context.Background(), meter, TaskRunDimensions(taskRun(reason))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := meter.RetrieveData(counter.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{}
+	for _, row := range rows {
+		key := ""
+		for _, tag := range row.Tags {
+			key += tag.Key.Name() + "=" + tag.Value + ","
+		}
+		counts[key] = row.Data.(*view.CountData).Value
+	}
+	if len(counts) != 2 || counts["reason=timeout,"] != 1 || counts["reason=Failed,"] != 2 {
+		t.Errorf("unexpected reasons %v", counts)
+	}
+}
+
+func TestReasonDimensionsOfConditions(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type: "counter",
+		Name: "failures",
+		By: []v1alpha1.ByStatement{
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Reason: pointer.String("Succeeded")}},
+			{MetricDimensionRef: v1alpha1.MetricDimensionRef{Reason: pointer.String("ResultsVerified")}},
+		},
+	}
+	counter := NewGenericRunCounter(metric, "taskrun", "all", "", nil)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(counter.View()); err != nil {
+		t.Fatal(err)
+	}
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-run", Namespace: "dev"},
+		Status: pipelinev1beta1.TaskRunStatus{
+			Status: duckv1.Status{Conditions: duckv1.Conditions{
+				{Type: "Succeeded", Status: corev1.ConditionFalse, Reason: "Failed"},
+				{Type: "ResultsVerified", Status: corev1.ConditionFalse, Reason: "SignatureMissing"},
+			}},
+		},
+	}
+	if err := counter.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := meter.RetrieveData(counter.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected a single series, got %d", len(rows))
+	}
+	// each condition has its own tag
+	tags := map[string]string{}
+	for _, tag := range rows[0].Tags {
+		tags[tag.Key.Name()] = tag.Value
+	}
+	if len(tags) != 2 || tags["reason"] != "Failed" || tags["resultsVerifiedReason"] != "SignatureMissing" {
+		t.Errorf("unexpected reasons %v", tags)
+	}
+}
@@ -0,0 +1,46 @@
+package recorder
+
+import "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+
+// The metrics of TaskRunMonitors are the generic metrics filtered by the
+// selector of the monitor
+
+func NewTaskRunCounter(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunCounter {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunCounter(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRunHistogram(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunHistogram {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunHistogram(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRunGauge(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunGauge {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunGauge(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRunTimeoutRatio(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunTimeoutRatio {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunTimeoutRatio(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRunDurationBreakdown(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunDurationBreakdown {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunDurationBreakdown(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRunLastValue(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunLastValue {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunLastValue(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRunRate(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunRate {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunRate(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
+
+func NewTaskRunSLO(metric *v1alpha1.Metric, monitor *v1alpha1.TaskRunMonitor) *GenericRunSLO {
+	filter := NewTaskRunFilter(&monitor.Spec)
+	return NewGenericRunSLO(metric, "taskrun", monitor.Name, monitor.Spec.MetricPrefix, &filter)
+}
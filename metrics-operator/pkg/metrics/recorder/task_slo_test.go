@@ -0,0 +1,57 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSLO(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type:      "slo",
+		Name:      "latency",
+		Threshold: &metav1.Duration{Duration: time.Minute},
+	}
+	metric.SetDefaults(context.Background())
+	slo := NewGenericRunSLO(metric, "task", "build", "", nil)
+	if name := slo.MetricName(); name != "task_build_latency_total" {
+		t.Errorf("unexpected name %s", name)
+	}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(slo.Views()...); err != nil {
+		t.Fatal(err)
+	}
+	for _, completion := range []string{"2023-08-16T16:00:30Z", "2023-08-16T16:02:00Z"} {
+		taskRun := &pipelinev1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "build-run"},
+			Status: pipelinev1beta1.TaskRunStatus{TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				StartTime:      MustParseRFC3339("2023-08-16T16:00:00Z"),
+				CompletionTime: MustParseRFC3339(completion),
+			}},
+		}
+		if err := slo.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]float64{
+		"task_build_latency_total":      2,
+		"task_build_latency_good_total": 1,
+	} {
+		rows, err := meter.RetrieveData(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0].Data.(*view.SumData).Value != want {
+			t.Errorf("want %s at %v, got %v", name, want, rows)
+		}
+	}
+}
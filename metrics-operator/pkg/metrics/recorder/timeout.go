@@ -0,0 +1,48 @@
+package recorder
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// runDuration is used when a metric relies on the run duration without
+// specifying one
+var runDuration = &v1alpha1.MetricHistogramDuration{
+	From: ".status.startTime",
+	To:   ".status.completionTime",
+}
+
+// runTimeout returns the timeout configured for the run, zero means no timeout
+func runTimeout(ctx context.Context, run *v1alpha1.RunDimensions) time.Duration {
+	switch r := run.Object.(type) {
+	case *pipelinev1beta1.TaskRun:
+		return r.GetTimeout(ctx)
+	case *pipelinev1beta1.PipelineRun:
+		return r.PipelineTimeout(ctx)
+	case *pipelinev1beta1.CustomRun:
+		if r.Spec.Timeout != nil {
+			return r.Spec.Timeout.Duration
+		}
+	}
+	return 0
+}
+
+// TimeoutRatio returns the fraction of the timeout used by the run. The
+// boolean is false when the run has no timeout or its duration is unknown.
+func TimeoutRatio(ctx context.Context, duration *v1alpha1.MetricHistogramDuration, run *v1alpha1.RunDimensions) (float64, bool, error) {
+	timeout := runTimeout(ctx, run)
+	if timeout <= 0 {
+		return 0, false, nil
+	}
+	if duration == nil {
+		duration = runDuration
+	}
+	measured, found, err := measureDuration(ctx, duration, run.Object)
+	if err != nil || !found {
+		return 0, false, err
+	}
+	return measured.Seconds() / timeout.Seconds(), true, nil
+}
@@ -0,0 +1,157 @@
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func timedTaskRun(name string, timeout *metav1.Duration, duration time.Duration) *pipelinev1beta1.TaskRun {
+	start := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	taskRun := &pipelinev1beta1.TaskRun{
+		// queued for 5 minutes
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev", CreationTimestamp: metav1.Time{Time: start.Add(-5 * time.Minute)}},
+		Spec:       pipelinev1beta1.TaskRunSpec{Timeout: timeout},
+	}
+	taskRun.Status.StartTime = &metav1.Time{Time: start}
+	if duration > 0 {
+		taskRun.Status.CompletionTime = &metav1.Time{Time: start.Add(duration)}
+	}
+	return taskRun
+}
+
+func TestTimeoutRatio(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       *v1alpha1.RunDimensions
+		duration  *v1alpha1.MetricHistogramDuration
+		want      float64
+		wantFound bool
+	}{{
+		name:      "half of the timeout",
+		run:       TaskRunDimensions(timedTaskRun("a", &metav1.Duration{Duration: 10 * time.Minute}, 5*time.Minute)),
+		want:      0.5,
+		wantFound: true,
+	}, {
+		name:      "default timeout of an hour",
+		run:       TaskRunDimensions(timedTaskRun("b", nil, 15*time.Minute)),
+		want:      0.25,
+		wantFound: true,
+	}, {
+		name: "timeout disabled",
+		run:  TaskRunDimensions(timedTaskRun("c", &metav1.Duration{}, 5*time.Minute)),
+	}, {
+		name: "still running",
+		run:  TaskRunDimensions(timedTaskRun("d", &metav1.Duration{Duration: 10 * time.Minute}, 0)),
+	}, {
+		name:      "duration of the metric",
+		run:       TaskRunDimensions(timedTaskRun("e", &metav1.Duration{Duration: 10 * time.Minute}, 5*time.Minute)),
+		duration:  &v1alpha1.MetricHistogramDuration{From: ".metadata.creationTimestamp", To: ".status.completionTime"},
+		want:      1,
+		wantFound: true,
+	}, {
+		name: "pipelinerun timeouts",
+		run: PipelineRunDimensions(&pipelinev1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "f", Namespace: "dev"},
+			Spec: pipelinev1beta1.PipelineRunSpec{
+				Timeouts: &pipelinev1beta1.TimeoutFields{Pipeline: &metav1.Duration{Duration: time.Hour}},
+			},
+			Status: pipelinev1beta1.PipelineRunStatus{PipelineRunStatusFields: pipelinev1beta1.PipelineRunStatusFields{
+				StartTime:      &metav1.Time{Time: time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)},
+				CompletionTime: &metav1.Time{Time: time.Date(2023, 8, 1, 10, 45, 0, 0, time.UTC)},
+			}},
+		}),
+		want:      0.75,
+		wantFound: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := TimeoutRatio(context.Background(), tt.duration, tt.run)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != tt.wantFound || got != tt.want {
+				t.Errorf("want %v (found %v), got %v (found %v)", tt.want, tt.wantFound, got, found)
+			}
+		})
+	}
+}
+
+func TestTimeoutRatioMetric(t *testing.T) {
+	monitor := &v1alpha1.TaskRunMonitor{ObjectMeta: metav1.ObjectMeta{Name: "all"}}
+	ratio := NewTaskRunTimeoutRatio(&v1alpha1.Metric{Type: "timeoutRatio", Name: "timeout_ratio"}, monitor)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(ratio.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a run without a completion time isn't recorded
+	for _, taskRun := range []*pipelinev1beta1.TaskRun{
+		timedTaskRun("a", &metav1.Duration{Duration: 10 * time.Minute}, 9*time.Minute),
+		timedTaskRun("b", &metav1.Duration{Duration: 10 * time.Minute}, 0),
+	} {
+		if err := ratio.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := meter.RetrieveData(ratio.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("want a single series, got %d", len(rows))
+	}
+	if got := rows[0].Data.(*view.LastValueData).Value; got != 0.9 {
+		t.Errorf("want a ratio of 0.9, got %v", got)
+	}
+}
+
+func TestNearTimeoutCounter(t *testing.T) {
+	metric := &v1alpha1.Metric{
+		Type:        "counter",
+		Name:        "near_timeout",
+		NearTimeout: &v1alpha1.MetricNearTimeout{Percent: 90},
+	}
+	counter := NewGenericRunCounter(metric, "taskrun", "all", "", nil)
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := meter.Register(counter.View()); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := &metav1.Duration{Duration: 10 * time.Minute}
+	for _, taskRun := range []*pipelinev1beta1.TaskRun{
+		timedTaskRun("a", timeout, 9*time.Minute),
+		timedTaskRun("b", timeout, 10*time.Minute),
+		timedTaskRun("c", timeout, 8*time.Minute),
+		timedTaskRun("d", timeout, 0),
+		timedTaskRun("e", &metav1.Duration{}, 9*time.Minute),
+	} {
+		if err := counter.Record(context.Background(), meter, TaskRunDimensions(taskRun)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only the runs using 90% of their timeout or more are counted
+	rows, err := meter.RetrieveData(counter.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("want a single series, got %d", len(rows))
+	}
+	if got := rows[0].Data.(*view.CountData).Value; got != 2 {
+		t.Errorf("want 2 runs near their timeout, got %v", got)
+	}
+}
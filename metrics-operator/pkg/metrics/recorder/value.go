@@ -0,0 +1,152 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// MeasureValue returns the number described by the spec, false when it's
+// missing from the run
+func MeasureValue(value *v1alpha1.MetricValue, run *v1alpha1.RunDimensions) (float64, bool, error) {
+	found, exists, err := value.Find(run.Object)
+	if err != nil || !exists {
+		return 0, false, err
+	}
+	return toFloat(found)
+}
+
+// toFloat coerces a JSON scalar to a number, numeric strings are parsed
+func toFloat(value any) (float64, bool, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, true, nil
+	case string:
+		parsed, err := parseNumber(strings.TrimSpace(v))
+		if err != nil {
+			return 0, false, fmt.Errorf("value %q is not a number", v)
+		}
+		return parsed, true, nil
+	case bool:
+		if v {
+			return 1, true, nil
+		}
+		return 0, true, nil
+	case nil:
+		return 0, false, nil
+	default:
+		return 0, false, fmt.Errorf("value of type %T is not a number", value)
+	}
+}
+
+// parseNumber parses a number with an optional unit suffix. Durations like
+// 1m30s or 250ms are converted to seconds, quantities like 512Ki or 1.5G to
+// their plain value.
+func parseNumber(s string) (float64, error) {
+	if parsed, err := strconv.ParseFloat(s, 64); err == nil {
+		return parsed, nil
+	}
+	if duration, err := time.ParseDuration(s); err == nil {
+		return duration.Seconds(), nil
+	}
+	quantity, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, err
+	}
+	return quantity.AsApproximateFloat64(), nil
+}
+
+// MeasureValuePreset returns the number of the run described by the preset
+func MeasureValuePreset(ctx context.Context, preset v1alpha1.ValuePreset, run *v1alpha1.RunDimensions) (float64, error) {
+	switch preset {
+	case v1alpha1.ValuePresetRetries:
+		taskRun, ok := run.Object.(*pipelinev1beta1.TaskRun)
+		if !ok {
+			return 0, Skipped("retries are only recorded for taskruns")
+		}
+		return float64(len(taskRun.Status.RetriesStatus)), nil
+	case v1alpha1.ValuePresetOOMKilled:
+		pod, err := runPod(ctx, run)
+		if err != nil {
+			return 0, err
+		}
+		return float64(oomKilled(pod)), nil
+	case v1alpha1.ValuePresetContainerRestarts:
+		pod, err := runPod(ctx, run)
+		if err != nil {
+			return 0, err
+		}
+		return float64(containerRestarts(pod)), nil
+	default:
+		return 0, fmt.Errorf("unknown value preset %q", preset)
+	}
+}
+
+// measureValue is MeasureValue timed as a JSONPath evaluation, values with a
+// duration suffix are converted from seconds to the time unit of the metric
+func measureValue(ctx context.Context, value *v1alpha1.MetricValue, run *v1alpha1.RunDimensions, unit v1alpha1.MetricUnit) (float64, bool, error) {
+	defer ObserveEvaluation(ctx, StageJSONPath, time.Now())
+	found, exists, err := value.Find(run.Object)
+	if err != nil || !exists {
+		return 0, false, err
+	}
+	number, ok, err := toFloat(found)
+	if err != nil || !ok {
+		return number, ok, err
+	}
+	if isDuration(found) {
+		number = unit.FromSeconds(number)
+	}
+	return number, true, nil
+}
+
+// isDuration returns true for strings parsed as a duration like 1m30s, rather
+// than a number or a quantity
+func isDuration(value any) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	s = strings.TrimSpace(s)
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+// measureUnit returns the unit of the measure of the metric, as expected by
+// exporters. Durations default to seconds, values to dimensionless.
+func measureUnit(metric *v1alpha1.Metric) string {
+	switch metric.Unit {
+	case v1alpha1.UnitSeconds:
+		return stats.UnitSeconds
+	case v1alpha1.UnitMilliseconds:
+		return stats.UnitMilliseconds
+	case v1alpha1.UnitMinutes:
+		return "min"
+	case v1alpha1.UnitBytes:
+		return stats.UnitBytes
+	case v1alpha1.UnitCount:
+		return stats.UnitDimensionless
+	}
+	if metric.Value == nil && metric.Type != "lastValue" {
+		return stats.UnitSeconds
+	}
+	return stats.UnitDimensionless
+}
+
+// durationUnit returns the name of the time unit, seconds when empty
+func durationUnit(unit v1alpha1.MetricUnit) v1alpha1.MetricUnit {
+	if unit == "" {
+		return v1alpha1.UnitSeconds
+	}
+	return unit
+}
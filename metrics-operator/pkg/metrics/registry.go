@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+)
+
+// RegistryKey identifies a metric by the resource and name of its monitor and
+// its name in the monitor spec, e.g. task, build and duration
+type RegistryKey struct {
+	Resource string
+	Monitor  string
+	Metric   string
+}
+
+// KeyOf returns the key of a run metric
+func KeyOf(runMetric RunMetric) RegistryKey {
+	resource, monitor, _ := strings.Cut(runMetric.MonitorId(), "/")
+	return RegistryKey{Resource: resource, Monitor: monitor, Metric: runMetric.Metric().Name}
+}
+
+// Registry stores the run metrics of every monitor. It's shared by the monitor
+// reconcilers replacing metrics and the run handlers recording them, lists
+// are copies that can be iterated without holding the lock.
+type Registry struct {
+	metrics map[RegistryKey]RunMetric
+	// byName indexes the keys by exported metric name
+	byName map[string]RegistryKey
+	rw     sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: map[RegistryKey]RunMetric{},
+		byName:  map[string]RegistryKey{},
+	}
+}
+
+// Get returns the metric of the key
+func (r *Registry) Get(key RegistryKey) (RunMetric, bool) {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+	runMetric, exists := r.metrics[key]
+	return runMetric, exists
+}
+
+// Lookup returns the key and metric exported under the name
+func (r *Registry) Lookup(metricName string) (RegistryKey, RunMetric, bool) {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+	key, exists := r.byName[metricName]
+	if !exists {
+		return RegistryKey{}, nil, false
+	}
+	return key, r.metrics[key], true
+}
+
+// Replace stores the metric under its key and returns the metric it replaced,
+// which may be exported under another name, e.g. when its type changed
+func (r *Registry) Replace(runMetric RunMetric) (RunMetric, bool) {
+	key := KeyOf(runMetric)
+	r.rw.Lock()
+	defer r.rw.Unlock()
+	previous, exists := r.metrics[key]
+	if exists {
+		r.unindex(key, previous)
+	}
+	r.metrics[key] = runMetric
+	r.byName[runMetric.MetricName()] = key
+	return previous, exists
+}
+
+// Remove deletes the metric of the key and returns it
+func (r *Registry) Remove(key RegistryKey) (RunMetric, bool) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+	runMetric, exists := r.metrics[key]
+	if !exists {
+		return nil, false
+	}
+	r.unindex(key, runMetric)
+	delete(r.metrics, key)
+	return runMetric, true
+}
+
+// unindex removes the name of the metric unless another key took it over, the
+// lock must be held
+func (r *Registry) unindex(key RegistryKey, runMetric RunMetric) {
+	if r.byName[runMetric.MetricName()] == key {
+		delete(r.byName, runMetric.MetricName())
+	}
+}
+
+// List returns every metric
+func (r *Registry) List() []RunMetric {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+	runMetrics := make([]RunMetric, 0, len(r.metrics))
+	for _, runMetric := range r.metrics {
+		runMetrics = append(runMetrics, runMetric)
+	}
+	return runMetrics
+}
+
+// ListMonitor returns the metrics of a monitor
+func (r *Registry) ListMonitor(resource, monitor string) []RunMetric {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+	runMetrics := []RunMetric{}
+	for key, runMetric := range r.metrics {
+		if key.Resource == resource && key.Monitor == monitor {
+			runMetrics = append(runMetrics, runMetric)
+		}
+	}
+	return runMetrics
+}
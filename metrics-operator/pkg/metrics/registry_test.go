@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRegistry(t *testing.T) {
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{
+				{Name: "status", Type: "counter"},
+				{Name: "status", Type: "gauge"},
+			},
+		},
+	}
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	gauge := recorder.NewTaskGauge(&taskMonitor.Spec.Metrics[1], taskMonitor)
+	key := RegistryKey{Resource: "task", Monitor: "hello", Metric: "status"}
+	if got := KeyOf(counter); got != key {
+		t.Fatalf("want key %v, got %v", key, got)
+	}
+
+	registry := NewRegistry()
+	if _, exists := registry.Replace(counter); exists {
+		t.Error("want no previous metric")
+	}
+	if _, runMetric, exists := registry.Lookup(counter.MetricName()); !exists || runMetric != counter {
+		t.Errorf("want the counter under %s", counter.MetricName())
+	}
+
+	t.Run("type change replaces the metric of the key", func(t *testing.T) {
+		previous, exists := registry.Replace(gauge)
+		if !exists || previous != counter {
+			t.Fatal("want the counter replaced")
+		}
+		if _, _, exists := registry.Lookup(counter.MetricName()); exists {
+			t.Errorf("want %s unindexed", counter.MetricName())
+		}
+		if got := registry.ListMonitor("task", "hello"); len(got) != 1 || got[0] != gauge {
+			t.Errorf("want only the gauge listed, got %v", got)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if _, exists := registry.Remove(key); !exists {
+			t.Fatal("want the gauge removed")
+		}
+		if got := registry.List(); len(got) != 0 {
+			t.Errorf("want an empty registry, got %v", got)
+		}
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					registry.Replace(counter)
+					registry.Remove(key)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					for _, runMetric := range registry.List() {
+						registry.Lookup(runMetric.MetricName())
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/logging"
+)
+
+// replayTypes are the metric types replayed by run resource, the other types
+// follow the state of in-flight runs
+var replayTypes = map[string][]string{
+	"taskrun":     {"histogram", "counter", "timeoutRatio", "durationBreakdown", "lastValue", "rate", "slo"},
+	"pipelinerun": {"histogram", "counter", "timeoutRatio", "durationBreakdown", "lastValue", "childOutcomes", "rate", "slo"},
+}
+
+type ReplayOptions struct {
+	// Window before the start of the operator in which the completed runs
+	// are replayed
+	Window time.Duration
+	// Delay after the run caches synced, so monitors have registered their
+	// metrics before runs are replayed
+	Delay time.Duration
+}
+
+type replay struct {
+	options ReplayOptions
+	started time.Time
+}
+
+// EnableReplay replays the runs completed within the window before startup.
+// Replays rely on deduplication to skip the metrics that already recorded a
+// run since startup.
+func (m *MetricManager) EnableReplay(options ReplayOptions) {
+	m.replay = &replay{options: options, started: time.Now()}
+}
+
+// ReplayRuns records the done runs returned by list that completed within the
+// replay window, once the caches synced and the delay passed. Run controllers
+// replay the runs of every bucket they are promoted leader of. Runs reconciled
+// on startup before the metrics of their monitors were registered are
+// recorded this way. Metrics that recorded a run since startup, or listed in
+// its recorded-by annotation, skip it. Blocks until the replay is over.
+func (m *MetricManager) ReplayRuns(ctx context.Context, resource string, list func() ([]*v1alpha1.RunDimensions, error), synced ...cache.InformerSynced) {
+	if m.replay == nil {
+		return
+	}
+	logger := logging.FromContext(ctx).With(zap.String("resource", resource))
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return
+	}
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(m.replay.options.Delay):
+	}
+	runs, err := list()
+	if err != nil {
+		logger.Errorw("failed to list runs to replay", zap.Error(err))
+		return
+	}
+	since := m.replay.started.Add(-m.replay.options.Window)
+	replayed := 0
+	for _, run := range runs {
+		completed, ok := completionTime(run)
+		if !ok || completed.Before(since) {
+			continue
+		}
+		ctx, marks := withRecordedMarks(ctx)
+		for _, metricType := range replayTypes[resource] {
+			m.GetIndex().Record(ctx, run, metricType)
+		}
+		m.markRecorded(ctx, resource, run, marks)
+		replayed++
+	}
+	logger.Infow("replayed completed runs", zap.Int("runs", replayed), zap.Time("since", since))
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestReplayRuns(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+	manager.EnableDeduplication(100)
+	manager.EnableReplay(ReplayOptions{Window: time.Hour})
+
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics:  []v1alpha1.Metric{{Name: "runs", Type: "counter"}},
+		},
+	}
+	ctx := context.Background()
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	if err := manager.GetIndex().RegisterRunMetric(ctx, counter); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := markerEntry(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskRun := func(uid string, completed time.Time, annotations map[string]string) *v1alpha1.RunDimensions {
+		return recorder.TaskRunDimensions(&v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "hello-world-" + uid, Namespace: "dev", UID: types.UID("uid-" + uid), Annotations: annotations},
+			Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+			Status: v1beta1.TaskRunStatus{
+				Status: duckv1.Status{Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				}},
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{CompletionTime: &metav1.Time{Time: completed}},
+			},
+		})
+	}
+	now := time.Now()
+	recent := taskRun("recent", now.Add(-10*time.Minute), nil)
+	// recorded since startup, deduplicated
+	manager.GetIndex().Record(ctx, recent, "counter")
+	runs := []*v1alpha1.RunDimensions{
+		recent,
+		taskRun("missed", now.Add(-20*time.Minute), nil),
+		taskRun("old", now.Add(-2*time.Hour), nil),
+		taskRun("marked", now.Add(-30*time.Minute), map[string]string{RecordedByAnnotation: "task/other/runs@00000000, " + entry}),
+	}
+	manager.ReplayRuns(ctx, "taskrun", func() ([]*v1alpha1.RunDimensions, error) {
+		return runs, nil
+	})
+
+	rows, err := external.RetrieveData(counter.MetricName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("want 1 row, got %d", len(rows))
+	}
+	if got := rows[0].Data.(*view.CountData).Value; got != 2 {
+		t.Errorf("want the recent and missed runs recorded once, got %d samples", got)
+	}
+}
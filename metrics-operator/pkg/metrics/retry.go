@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"go.opencensus.io/stats"
+	"go.uber.org/zap"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/logging"
+)
+
+// RetryOptions configures the retries of failed recordings
+type RetryOptions struct {
+	// MaxQueueSize bounds the recordings waiting to be retried, recordings
+	// failing when the queue is full are dropped
+	MaxQueueSize int
+	// InitialBackoff before the first retry, doubled on every attempt
+	InitialBackoff time.Duration
+	// MaxBackoff between two attempts
+	MaxBackoff time.Duration
+	// MaxAttempts before a recording is dropped
+	MaxAttempts int
+}
+
+type retryItem struct {
+	// id names the write in logs, e.g. the metric and run
+	id    string
+	write func(ctx context.Context) error
+}
+
+// RetryQueue retries failed writes with exponential backoff, a write is
+// retried while it fails with a retryable error
+type RetryQueue struct {
+	options  RetryOptions
+	recorder stats.Recorder
+	queue    workqueue.RateLimitingInterface
+	pending  int
+	rw       sync.Mutex
+}
+
+func NewRetryQueue(recorder stats.Recorder, options RetryOptions) *RetryQueue {
+	return &RetryQueue{
+		options:  options,
+		recorder: recorder,
+		queue: workqueue.NewRateLimitingQueue(
+			workqueue.NewItemExponentialFailureRateLimiter(options.InitialBackoff, options.MaxBackoff),
+		),
+	}
+}
+
+// Add schedules the write after a backoff, returns false when the queue is
+// full
+func (q *RetryQueue) Add(id string, write func(ctx context.Context) error) bool {
+	q.rw.Lock()
+	if q.pending >= q.options.MaxQueueSize {
+		q.rw.Unlock()
+		return false
+	}
+	q.pending++
+	q.rw.Unlock()
+	q.reportDepth()
+	q.queue.AddRateLimited(&retryItem{id: id, write: write})
+	return true
+}
+
+// Run retries writes until the context is done
+func (q *RetryQueue) Run(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		q.queue.ShutDown()
+	}()
+	for q.processNext(ctx) {
+	}
+}
+
+func (q *RetryQueue) processNext(ctx context.Context) bool {
+	obj, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(obj)
+	item := obj.(*retryItem)
+	logger := logging.FromContext(ctx).With(zap.String("write", item.id))
+
+	err := item.write(ctx)
+	// the first attempt is the one that failed before the write was queued
+	if err != nil && recorder.IsRetryable(err) && q.queue.NumRequeues(obj)+1 < q.options.MaxAttempts {
+		q.queue.AddRateLimited(obj)
+		return true
+	}
+	if err != nil {
+		logger.Errorw("dropping write after retries", zap.Int("attempts", q.queue.NumRequeues(obj)+1), zap.Error(err))
+	}
+	q.queue.Forget(obj)
+	q.rw.Lock()
+	q.pending--
+	q.rw.Unlock()
+	q.reportDepth()
+	return true
+}
+
+func (q *RetryQueue) reportDepth() {
+	q.rw.Lock()
+	pending := q.pending
+	q.rw.Unlock()
+	selfmetrics.Record(q.recorder, nil, selfmetrics.RetryQueueDepth.M(int64(pending)))
+}
+
+// failedWrites collects the writes failing while a run is recorded, only they
+// are retried so the samples written on the first attempt aren't counted
+// twice
+type failedWrites struct {
+	writes []func(ctx context.Context) error
+	err    error
+	mu     sync.Mutex
+}
+
+// add keeps the failed write, returns false when writes aren't collected
+func (f *failedWrites) add(err error, write func(ctx context.Context) error) bool {
+	if f == nil {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err == nil {
+		f.err = err
+	}
+	f.writes = append(f.writes, write)
+	return true
+}
+
+// Err returns the first failure, nil when every write succeeded
+func (f *failedWrites) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+// retry runs the failed writes again, the ones failing again are kept for the
+// next attempt
+func (f *failedWrites) retry(ctx context.Context) error {
+	f.mu.Lock()
+	writes := f.writes
+	f.writes, f.err = nil, nil
+	f.mu.Unlock()
+	for _, write := range writes {
+		if err := write(ctx); err != nil {
+			f.add(err, write)
+		}
+	}
+	return f.Err()
+}
@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/selfmetrics"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+)
+
+// flakyWrite fails with err on its first failures attempts
+type flakyWrite struct {
+	failures int
+	err      error
+	attempts []time.Time
+	mu       sync.Mutex
+}
+
+func (f *flakyWrite) write(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts = append(f.attempts, time.Now())
+	if len(f.attempts) <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flakyWrite) get() []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Time{}, f.attempts...)
+}
+
+func (q *RetryQueue) getPending() int {
+	q.rw.Lock()
+	defer q.rw.Unlock()
+	return q.pending
+}
+
+// waitDrained waits until the queue has no pending write
+func waitDrained(t *testing.T, q *RetryQueue) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for q.getPending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if pending := q.getPending(); pending > 0 {
+		t.Fatalf("want the queue drained, %d writes pending", pending)
+	}
+}
+
+func TestRetryQueue(t *testing.T) {
+	retryable := recorder.Retryable(errors.New("exporter unavailable"))
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+	}{
+		{name: "succeeds on a retry", failures: 2, err: retryable, wantAttempts: 3},
+		// the first attempt failed before the write was queued
+		{name: "dropped after max attempts", failures: 10, err: retryable, wantAttempts: 3},
+		{name: "not retryable", failures: 10, err: errors.New("invalid sample"), wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meter := view.NewMeter()
+			meter.Start()
+			defer meter.Stop()
+			q := NewRetryQueue(meter, RetryOptions{
+				MaxQueueSize:   10,
+				InitialBackoff: 10 * time.Millisecond,
+				MaxBackoff:     time.Second,
+				MaxAttempts:    4,
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go q.Run(ctx)
+
+			write := &flakyWrite{failures: tt.failures, err: tt.err}
+			if !q.Add("test", write.write) {
+				t.Fatal("want the write queued")
+			}
+			waitDrained(t, q)
+
+			attempts := write.get()
+			if len(attempts) != tt.wantAttempts {
+				t.Fatalf("want %d attempts, got %d", tt.wantAttempts, len(attempts))
+			}
+			// the backoff doubles on every attempt
+			for i := 1; i < len(attempts); i++ {
+				if gap, backoff := attempts[i].Sub(attempts[i-1]), 10*time.Millisecond<<i; gap < backoff {
+					t.Errorf("want attempt %d at least %v after the previous one, got %v", i+1, backoff, gap)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryQueueFull(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	q := NewRetryQueue(meter, RetryOptions{MaxQueueSize: 1, InitialBackoff: time.Hour, MaxBackoff: time.Hour, MaxAttempts: 5})
+	write := func(context.Context) error { return nil }
+	if !q.Add("first", write) {
+		t.Fatal("want the first write queued")
+	}
+	if q.Add("second", write) {
+		t.Error("want the second write dropped, the queue is full")
+	}
+}
+
+func TestRetryQueueDepth(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	if err := selfmetrics.Register(meter); err != nil {
+		t.Fatal(err)
+	}
+	q := NewRetryQueue(meter, RetryOptions{MaxQueueSize: 10, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second, MaxAttempts: 2})
+	depth := func() float64 {
+		t.Helper()
+		rows, err := meter.RetrieveData(selfmetrics.RetryQueueDepth.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("want a single series, got %v", rows)
+		}
+		return rows[0].Data.(*view.LastValueData).Value
+	}
+
+	write := func(context.Context) error { return nil }
+	q.Add("first", write)
+	q.Add("second", write)
+	if got := depth(); got != 2 {
+		t.Errorf("want 2 queued writes, got %v", got)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+	waitDrained(t, q)
+	if got := depth(); got != 0 {
+		t.Errorf("want no queued write once retried, got %v", got)
+	}
+}
+
+// flakyBackend fails to record its first failures samples
+type flakyBackend struct {
+	*FakeBackend
+	failures int
+}
+
+func (f *flakyBackend) Record(ctx context.Context, name string, tags map[string]string, value float64) error {
+	f.rw.Lock()
+	failing := f.failures > 0
+	f.failures--
+	f.rw.Unlock()
+	if failing {
+		return errors.New("backend unavailable")
+	}
+	return f.FakeBackend.Record(ctx, name, tags, value)
+}
+
+func TestRecordRetriesFailedWrites(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	retries := NewRetryQueue(meter, RetryOptions{MaxQueueSize: 10, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second, MaxAttempts: 5})
+	backend := &flakyBackend{FakeBackend: NewFakeBackend(), failures: 2}
+	manager := NewManager(meter, retries)
+	manager.UseBackend(backend)
+	index := manager.GetIndex()
+
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{
+				{
+					Name: "status",
+					Type: "counter",
+					By: []v1alpha1.ByStatement{
+						{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: ptr.String("Succeeded")}},
+					},
+				},
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := index.RegisterRunMetric(ctx, recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)); err != nil {
+		t.Fatal(err)
+	}
+	go retries.Run(ctx)
+
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello-world-xpto0",
+			Namespace: "dev",
+			Labels:    map[string]string{"tekton.dev/task": "hello-world"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				},
+			},
+		},
+	}
+	index.Record(ctx, recorder.TaskRunDimensions(taskRun), "counter")
+	waitDrained(t, retries)
+
+	// the sample is written once, by the second retry
+	want := []FakeSample{{Tags: map[string]string{"status": "success"}, Value: 1}}
+	if diff := cmp.Diff(want, backend.Samples("task_hello_status_total")); diff != "" {
+		t.Errorf("samples (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/sink"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AddSink writes every sample recorded from then on to the sink, with a
+// reference to its run
+func (m *MetricManager) AddSink(s *sink.Buffered) {
+	m.Index.rw.Lock()
+	defer m.Index.rw.Unlock()
+	m.Index.sinks = append(m.Index.sinks, s)
+}
+
+// samplingRecorder records to the meter and copies every sample to the sinks
+type samplingRecorder struct {
+	stats.Recorder
+	views []*view.View
+	run   *v1alpha1.RunDimensions
+	sinks []*sink.Buffered
+	// writes collects the samples dropped by full sinks
+	writes *failedWrites
+}
+
+// recorderFor returns the recorder of the metric for the run, the output
+// itself when there are no sinks. Failed writes are collected in writes.
+func (m *MetricIndex) recorderFor(metric RunMetric, run *v1alpha1.RunDimensions, writes *failedWrites) stats.Recorder {
+	if len(m.sinks) == 0 {
+		return m.trackedOutput(writes)
+	}
+	return &samplingRecorder{
+		Recorder: m.trackedOutput(writes),
+		views:    runMetricViews(metric),
+		run:      run,
+		sinks:    m.sinks,
+		writes:   writes,
+	}
+}
+
+func (r *samplingRecorder) Record(tagMap *tag.Map, measurements any, attachments map[string]any) {
+	r.Recorder.Record(tagMap, measurements, attachments)
+	values, ok := measurements.([]stats.Measurement)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	for _, measurement := range values {
+		sample := r.newSample(now, measurement, tagMap)
+		for _, s := range r.sinks {
+			s := s
+			write := func(context.Context) error {
+				if !s.Add(sample) {
+					return recorder.Retryable(fmt.Errorf("sink %s is full", s.Name()))
+				}
+				return nil
+			}
+			if err := write(context.Background()); err != nil {
+				r.writes.add(err, write)
+			}
+		}
+	}
+}
+
+func (r *samplingRecorder) newSample(now time.Time, measurement stats.Measurement, tagMap *tag.Map) sink.Sample {
+	sample := sink.Sample{
+		Time:      now,
+		Metric:    measurement.Measure().Name(),
+		Resource:  r.run.Resource,
+		Namespace: r.run.Namespace,
+		Name:      r.run.Name,
+		Tags:      map[string]string{},
+		Value:     measurement.Value(),
+		Unit:      measurement.Measure().Unit(),
+	}
+	for _, v := range r.views {
+		if v.Measure.Name() != sample.Metric {
+			continue
+		}
+		for key, value := range viewTags(v, tagMap) {
+			sample.Tags[key] = value
+		}
+	}
+	if object, err := meta.Accessor(r.run.Object); err == nil {
+		sample.UID = string(object.GetUID())
+		created := object.GetCreationTimestamp().Time
+		sample.Created = &created
+	}
+	var started, completed *metav1.Time
+	switch run := r.run.Object.(type) {
+	case *pipelinev1beta1.TaskRun:
+		started, completed = run.Status.StartTime, run.Status.CompletionTime
+	case *pipelinev1beta1.PipelineRun:
+		started, completed = run.Status.StartTime, run.Status.CompletionTime
+	}
+	if started != nil {
+		sample.Started = &started.Time
+	}
+	if completed != nil {
+		sample.Completed = &completed.Time
+	}
+	return sample
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// SkippedTasksMonitorName is the monitor name used in the names of the
+// skipped task metrics
+const SkippedTasksMonitorName = "skipped"
+
+var reasonKey = tag.MustNewKey("reason")
+
+// skippedTaskMetrics counts the tasks skipped by done PipelineRuns, making
+// when expressions and conditional execution observable
+type skippedTaskMetrics struct {
+	skipped *stats.Float64Measure
+	views   []*view.View
+}
+
+func newSkippedTaskMetrics() *skippedTaskMetrics {
+	s := &skippedTaskMetrics{}
+	s.skipped = stats.Float64(naming.CounterMetric("pipelinerun", SkippedTasksMonitorName, "tasks"), "tasks skipped by pipelineruns by skipping reason", stats.UnitDimensionless)
+	s.views = []*view.View{
+		{
+			Description: s.skipped.Description(),
+			Measure:     s.skipped,
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{namespaceKey, pipelineKey, reasonKey},
+		},
+	}
+	return s
+}
+
+// EnableSkippedTaskMetrics registers the skipped task metrics, recorded from
+// then on for every done PipelineRun
+func (m *MetricManager) EnableSkippedTaskMetrics() error {
+	skipped := newSkippedTaskMetrics()
+	if err := m.Index.external.Register(skipped.views...); err != nil {
+		return err
+	}
+	m.skippedTasks = skipped
+	return nil
+}
+
+// recordSkippedTasks adds the skipped tasks of the PipelineRun by reason when
+// skipped task metrics are enabled
+func (m *MetricManager) recordSkippedTasks(ctx context.Context, run *v1alpha1.RunDimensions) {
+	pipelineRun, ok := run.Object.(*pipelinev1beta1.PipelineRun)
+	if m.skippedTasks == nil || !ok {
+		return
+	}
+	pipeline, exists := run.Labels["tekton.dev/pipeline"]
+	if !exists {
+		pipeline = "MISSING"
+	}
+	reasons := map[string]int{}
+	for _, skippedTask := range pipelineRun.Status.SkippedTasks {
+		reasons[string(skippedTask.Reason)]++
+	}
+	for reason, count := range reasons {
+		tagCtx, err := tag.New(ctx, tag.Upsert(namespaceKey, run.Namespace), tag.Upsert(pipelineKey, pipeline), tag.Upsert(reasonKey, reason))
+		if err != nil {
+			logging.FromContext(ctx).Errorw("recording skipped task metrics failed", "run", run.GetId(), zap.Error(err))
+			return
+		}
+		m.Index.external.Record(tag.FromContext(tagCtx), []stats.Measurement{m.skippedTasks.skipped.M(float64(count))}, map[string]any{})
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordSkippedTasks(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+
+	pipelineRun := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-1", Namespace: "dev", Labels: map[string]string{"tekton.dev/pipeline": "release"}},
+		Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+			SkippedTasks: []v1beta1.SkippedTask{
+				{Name: "deploy", Reason: v1beta1.WhenExpressionsSkip},
+				{Name: "notify", Reason: v1beta1.WhenExpressionsSkip},
+				{Name: "smoke", Reason: v1beta1.ParentTasksSkip},
+			},
+		}},
+	}
+	ctx := context.Background()
+	// skipped tasks aren't recorded unless enabled
+	manager.recordSkippedTasks(ctx, recorder.PipelineRunDimensions(pipelineRun))
+	if err := manager.EnableSkippedTaskMetrics(); err != nil {
+		t.Fatal(err)
+	}
+	manager.recordSkippedTasks(ctx, recorder.PipelineRunDimensions(pipelineRun))
+
+	rows, err := external.RetrieveData(manager.skippedTasks.skipped.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			switch {
+			case tag.Key == reasonKey:
+				got[tag.Value] = row.Data.(*view.SumData).Value
+			case tag.Key == pipelineKey && tag.Value != "release":
+				t.Errorf("unexpected pipeline %q", tag.Value)
+			}
+		}
+	}
+	want := map[string]float64{
+		string(v1beta1.WhenExpressionsSkip): 2,
+		string(v1beta1.ParentTasksSkip):     1,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("skipped tasks by reason (-want, +got):\n%s", diff)
+	}
+}
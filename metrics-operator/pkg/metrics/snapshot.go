@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"go.opencensus.io/stats/view"
+)
+
+// ViewSnapshot is the in-memory aggregation state of a view
+type ViewSnapshot struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Aggregation string        `json:"aggregation"`
+	TagKeys     []string      `json:"tagKeys"`
+	Rows        []RowSnapshot `json:"rows"`
+}
+
+// RowSnapshot is the aggregated value of a tag set, only the fields of the
+// aggregation of the view are set
+type RowSnapshot struct {
+	Tags      map[string]string `json:"tags"`
+	Count     int64             `json:"count,omitempty"`
+	Sum       float64           `json:"sum,omitempty"`
+	Min       float64           `json:"min,omitempty"`
+	Max       float64           `json:"max,omitempty"`
+	Mean      float64           `json:"mean,omitempty"`
+	LastValue *float64          `json:"lastValue,omitempty"`
+	Buckets   []BucketSnapshot  `json:"buckets,omitempty"`
+}
+
+type BucketSnapshot struct {
+	// UpperBound of the bucket, nil for the last one
+	UpperBound *float64 `json:"upperBound,omitempty"`
+	Count      int64    `json:"count"`
+}
+
+// views returns every view registered by the manager, sorted by name
+func (m *MetricManager) views() []*view.View {
+	views := []*view.View{}
+	// the views of monitor metrics aren't in the meter with a backend
+	if m.Index.backend == nil {
+		for _, runMetric := range m.Index.store.List() {
+			views = append(views, runMetricViews(runMetric)...)
+		}
+	}
+	for _, d := range m.defaults {
+		views = append(views, d.views...)
+	}
+	if m.gates != nil {
+		views = append(views, m.gates.views...)
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Name < views[j].Name
+	})
+	return views
+}
+
+// Snapshot returns the current aggregation state of every view, or of the
+// view with the given name when not empty
+func (m *MetricManager) Snapshot(name string) ([]ViewSnapshot, error) {
+	snapshots := []ViewSnapshot{}
+	for _, v := range m.views() {
+		if name != "" && v.Name != name {
+			continue
+		}
+		rows, err := m.Index.external.RetrieveData(v.Name)
+		if err != nil {
+			return nil, err
+		}
+		snapshot := ViewSnapshot{
+			Name:        v.Name,
+			Description: v.Description,
+			Aggregation: v.Aggregation.Type.String(),
+			TagKeys:     []string{},
+			Rows:        []RowSnapshot{},
+		}
+		for _, key := range v.TagKeys {
+			snapshot.TagKeys = append(snapshot.TagKeys, key.Name())
+		}
+		for _, row := range rows {
+			snapshot.Rows = append(snapshot.Rows, rowSnapshot(v, row))
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func rowSnapshot(v *view.View, row *view.Row) RowSnapshot {
+	snapshot := RowSnapshot{Tags: map[string]string{}}
+	for _, t := range row.Tags {
+		snapshot.Tags[t.Key.Name()] = t.Value
+	}
+	switch data := row.Data.(type) {
+	case *view.CountData:
+		snapshot.Count = data.Value
+	case *view.SumData:
+		snapshot.Sum = data.Value
+	case *view.LastValueData:
+		value := data.Value
+		snapshot.LastValue = &value
+	case *view.DistributionData:
+		snapshot.Count = data.Count
+		snapshot.Sum = data.Sum()
+		snapshot.Min = data.Min
+		snapshot.Max = data.Max
+		snapshot.Mean = data.Mean
+		bounds := v.Aggregation.Buckets
+		for i, count := range data.CountPerBucket {
+			bucket := BucketSnapshot{Count: count}
+			if i < len(bounds) {
+				bound := bounds[i]
+				bucket.UpperBound = &bound
+			}
+			snapshot.Buckets = append(snapshot.Buckets, bucket)
+		}
+	}
+	return snapshot
+}
+
+// SnapshotHandler serves the snapshot as JSON, the metric query parameter
+// restricts it to a single view
+func (m *MetricManager) SnapshotHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshots, err := m.Snapshot(r.URL.Query().Get("metric"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(snapshots)
+	})
+}
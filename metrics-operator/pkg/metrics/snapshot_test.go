@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestSnapshot(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+
+	ctx := context.Background()
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{{
+				Name: "runs",
+				Type: "counter",
+			}, {
+				Name:     "duration",
+				Type:     "histogram",
+				Duration: &v1alpha1.MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"},
+			}},
+		},
+	}
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	histogram := recorder.NewTaskHistogram(&taskMonitor.Spec.Metrics[1], taskMonitor)
+	for _, runMetric := range []RunMetric{counter, histogram} {
+		if err := manager.GetIndex().RegisterRunMetric(ctx, runMetric); err != nil {
+			t.Fatal(err)
+		}
+	}
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world-1", Namespace: "dev", UID: "1"},
+		Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "hello-world"}},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1.Status{Conditions: duckv1.Conditions{
+				{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+			}},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				StartTime:      recorder.MustParseRFC3339("2023-08-16T16:00:00Z"),
+				CompletionTime: recorder.MustParseRFC3339("2023-08-16T16:00:30Z"),
+			},
+		},
+	}
+	if err := manager.RecordTaskRunDone(ctx, taskRun); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := manager.Snapshot("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+	if diff := cmp.Diff([]string{histogram.MetricName(), counter.MetricName()}, names); diff != "" {
+		t.Errorf("views (-want, +got):\n%s", diff)
+	}
+
+	// the handler restricts the snapshot to the metric of the query
+	response := httptest.NewRecorder()
+	manager.SnapshotHandler().ServeHTTP(response, httptest.NewRequest("GET", "/snapshot?metric="+histogram.MetricName(), nil))
+	if contentType := response.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	got := []ViewSnapshot{}
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	// the run of 30s falls in the bucket up to 50s
+	buckets := []BucketSnapshot{}
+	for _, bound := range histogram.View().Aggregation.Buckets {
+		bound := bound
+		bucket := BucketSnapshot{UpperBound: &bound}
+		if bound == 50 {
+			bucket.Count = 1
+		}
+		buckets = append(buckets, bucket)
+	}
+	buckets = append(buckets, BucketSnapshot{})
+	want := []ViewSnapshot{{
+		Name:        histogram.MetricName(),
+		Description: histogram.View().Description,
+		Aggregation: "Distribution",
+		TagKeys:     []string{},
+		Rows: []RowSnapshot{{
+			Tags:    map[string]string{},
+			Count:   1,
+			Sum:     30,
+			Min:     30,
+			Max:     30,
+			Mean:    30,
+			Buckets: buckets,
+		}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("snapshot (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// metricStats counts the samples of a registered metric
+type metricStats struct {
+	recorded     int64
+	skipped      int64
+	errors       int64
+	lastError    string
+	lastRecorded time.Time
+}
+
+// statsStore holds the stats of every registered metric by name
+type statsStore struct {
+	m  map[string]*metricStats
+	rw sync.Mutex
+}
+
+func (s *statsStore) observe(metricName string, err error, skipped bool) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if s.m == nil {
+		s.m = map[string]*metricStats{}
+	}
+	stats, exists := s.m[metricName]
+	if !exists {
+		stats = &metricStats{}
+		s.m[metricName] = stats
+	}
+	switch {
+	case err == nil:
+		stats.recorded++
+		stats.lastRecorded = time.Now()
+	case skipped:
+		stats.skipped++
+	default:
+		stats.skipped++
+		stats.errors++
+		stats.lastError = err.Error()
+	}
+}
+
+func (s *statsStore) get(metricName string) metricStats {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if stats, exists := s.m[metricName]; exists {
+		return *stats
+	}
+	return metricStats{}
+}
+
+func (s *statsStore) reset(metricName string) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	delete(s.m, metricName)
+}
+
+// MonitorStatus returns the stats of every metric of the monitor, sorted by
+// name, counted since the metric was last registered
+func (m *MetricIndex) MonitorStatus(resource, monitor string) []v1alpha1.MetricStatus {
+	statuses := []v1alpha1.MetricStatus{}
+	for _, runMetric := range m.store.ListMonitor(resource, monitor) {
+		metricName := runMetric.MetricName()
+		stats := m.stats.get(metricName)
+		status := v1alpha1.MetricStatus{
+			Name:       runMetric.Metric().Name,
+			Recorded:   stats.recorded,
+			Skipped:    stats.skipped,
+			Errors:     stats.errors,
+			LastError:  stats.lastError,
+			Overflowed: m.guards.overflowed(metricName),
+		}
+		if !stats.lastRecorded.IsZero() {
+			status.LastRecordTime = &metav1.Time{Time: stats.lastRecorded.Truncate(time.Second)}
+		}
+		for _, v := range runMetricViews(runMetric) {
+			status.Views = append(status.Views, viewName(v))
+		}
+		if b, suspended := m.breakers.suspended(metricName); suspended {
+			status.Suspended = true
+			status.ConsecutiveFailures = b.failures
+			status.LastError = b.lastError
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestStatsStoreObserve(t *testing.T) {
+	stats := &statsStore{}
+	stats.observe("duration", nil, false)
+	stats.observe("duration", recorder.Skipped("no completion time"), true)
+	stats.observe("duration", errors.New("error parsing duration"), false)
+
+	got := stats.get("duration")
+	if got.recorded != 1 || got.skipped != 2 || got.errors != 1 {
+		t.Errorf("want 1 recorded, 2 skipped and 1 error, got %+v", got)
+	}
+	if got.lastError != "error parsing duration" {
+		t.Errorf("unexpected last error %q", got.lastError)
+	}
+	if got.lastRecorded.IsZero() {
+		t.Error("want the last record time set")
+	}
+
+	stats.reset("duration")
+	if got := stats.get("duration"); got.recorded != 0 || got.errors != 0 || !got.lastRecorded.IsZero() {
+		t.Errorf("want stats reset, got %+v", got)
+	}
+}
+
+func TestMonitorStatus(t *testing.T) {
+	external := view.NewMeter()
+	external.Start()
+	defer external.Stop()
+	manager := NewManager(external, nil)
+
+	ctx := context.Background()
+	taskMonitor := &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "hello-world",
+			Metrics: []v1alpha1.Metric{{
+				Name: "runs",
+				Type: "counter",
+			}, {
+				Name:     "duration",
+				Type:     "histogram",
+				Duration: &v1alpha1.MetricHistogramDuration{From: ".status.startTime", To: ".status.completionTime"},
+			}},
+		},
+	}
+	counter := recorder.NewTaskCounter(&taskMonitor.Spec.Metrics[0], taskMonitor)
+	histogram := recorder.NewTaskHistogram(&taskMonitor.Spec.Metrics[1], taskMonitor)
+	for _, runMetric := range []RunMetric{counter, histogram} {
+		if err := manager.GetIndex().RegisterRunMetric(ctx, runMetric); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskRun := func(name, task string, startTime *metav1.Time) *v1beta1.TaskRun {
+		return &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "dev",
+				UID:       types.UID(name),
+				Labels:    map[string]string{"tekton.dev/task": task},
+			},
+			Spec: v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: task}},
+			Status: v1beta1.TaskRunStatus{
+				Status: duckv1.Status{Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				}},
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					StartTime:      startTime,
+					CompletionTime: recorder.MustParseRFC3339("2023-08-16T16:00:30Z"),
+				},
+			},
+		}
+	}
+	// the run without a start time is skipped by the histogram, the run of
+	// another task isn't counted at all
+	for _, tr := range []*v1beta1.TaskRun{
+		taskRun("hello-world-1", "hello-world", recorder.MustParseRFC3339("2023-08-16T16:00:00Z")),
+		taskRun("hello-world-2", "hello-world", nil),
+		taskRun("build-1", "build", recorder.MustParseRFC3339("2023-08-16T16:00:00Z")),
+	} {
+		if err := manager.RecordTaskRunDone(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	statuses := manager.GetIndex().MonitorStatus("task", "hello")
+	for i := range statuses {
+		if statuses[i].LastRecordTime == nil {
+			t.Errorf("want the last record time of %s set", statuses[i].Name)
+		}
+		statuses[i].LastRecordTime = nil
+	}
+	want := []v1alpha1.MetricStatus{{
+		Name:     "duration",
+		Recorded: 1,
+		Skipped:  1,
+		Views:    []string{histogram.MetricName()},
+	}, {
+		Name:     "runs",
+		Recorded: 2,
+		Views:    []string{counter.MetricName()},
+	}}
+	if diff := cmp.Diff(want, statuses); diff != "" {
+		t.Errorf("statuses (-want, +got):\n%s", diff)
+	}
+
+	// the stats restart when the metric is registered again
+	if err := manager.GetIndex().UnregisterRunMetricByName(counter.MetricName()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.GetIndex().RegisterRunMetric(ctx, counter); err != nil {
+		t.Fatal(err)
+	}
+	statuses = manager.GetIndex().MonitorStatus("task", "hello")
+	if len(statuses) != 2 || statuses[1].Recorded != 0 {
+		t.Errorf("want the stats of runs reset, got %+v", statuses)
+	}
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// StepsMonitorName is the monitor name used in the names of the step metrics
+const StepsMonitorName = "steps"
+
+// stepMetrics records the number of steps of every done TaskRun, to track the
+// complexity of tasks over time
+type stepMetrics struct {
+	executed *stats.Float64Measure
+	// skipped is nil unless skipped steps are counted
+	skipped *stats.Float64Measure
+	views   []*view.View
+}
+
+func newStepMetrics(countSkipped bool) *stepMetrics {
+	s := &stepMetrics{}
+	buckets := view.Distribution(1, 2, 3, 4, 5, 6, 8, 10, 15, 20, 30, 50)
+	tagKeys := []tag.Key{namespaceKey, taskKey}
+	s.executed = stats.Float64(naming.GaugeMetric("taskrun", StepsMonitorName, "executed"), "steps executed per taskrun", stats.UnitDimensionless)
+	s.views = []*view.View{
+		{
+			Description: s.executed.Description(),
+			Measure:     s.executed,
+			Aggregation: buckets,
+			TagKeys:     tagKeys,
+		},
+	}
+	if countSkipped {
+		s.skipped = stats.Float64(naming.GaugeMetric("taskrun", StepsMonitorName, "skipped"), "steps skipped per taskrun after a failed step", stats.UnitDimensionless)
+		s.views = append(s.views, &view.View{
+			Description: s.skipped.Description(),
+			Measure:     s.skipped,
+			Aggregation: buckets,
+			TagKeys:     tagKeys,
+		})
+	}
+	return s
+}
+
+// countSteps returns the executed and skipped steps of the TaskRun. A failed
+// step skips every following step, unless the step continues on error.
+func countSteps(taskRun *pipelinev1beta1.TaskRun) (executed, skipped int) {
+	onError := map[string]pipelinev1beta1.OnErrorType{}
+	if taskRun.Status.TaskSpec != nil {
+		for _, step := range taskRun.Status.TaskSpec.Steps {
+			onError[step.Name] = step.OnError
+		}
+	}
+	stopped := false
+	for _, step := range taskRun.Status.Steps {
+		if step.Terminated == nil {
+			continue
+		}
+		if stopped {
+			skipped++
+			continue
+		}
+		executed++
+		if step.Terminated.ExitCode != 0 && onError[step.Name] != pipelinev1beta1.Continue {
+			stopped = true
+		}
+	}
+	return executed, skipped
+}
+
+// EnableStepMetrics registers the step metrics, recorded from then on for
+// every done TaskRun
+func (m *MetricManager) EnableStepMetrics(countSkipped bool) error {
+	steps := newStepMetrics(countSkipped)
+	if err := m.Index.external.Register(steps.views...); err != nil {
+		return err
+	}
+	m.steps = steps
+	return nil
+}
+
+// recordSteps records the steps of the TaskRun when step metrics are enabled
+func (m *MetricManager) recordSteps(ctx context.Context, run *v1alpha1.RunDimensions) {
+	taskRun, ok := run.Object.(*pipelinev1beta1.TaskRun)
+	if m.steps == nil || !ok {
+		return
+	}
+	task, exists := run.Labels["tekton.dev/task"]
+	if !exists {
+		task = "MISSING"
+	}
+	tagCtx, err := tag.New(ctx, tag.Upsert(namespaceKey, run.Namespace), tag.Upsert(taskKey, task))
+	if err != nil {
+		logging.FromContext(ctx).Errorw("recording step metrics failed", "run", run.GetId(), zap.Error(err))
+		return
+	}
+	executed, skipped := countSteps(taskRun)
+	measurements := []stats.Measurement{m.steps.executed.M(float64(executed))}
+	if m.steps.skipped != nil {
+		measurements = append(measurements, m.steps.skipped.M(float64(skipped)))
+	}
+	m.Index.external.Record(tag.FromContext(tagCtx), measurements, map[string]any{})
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCountSteps(t *testing.T) {
+	terminated := func(name string, exitCode int32) pipelinev1beta1.StepState {
+		return pipelinev1beta1.StepState{
+			Name:           name,
+			ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+		}
+	}
+	taskRun := &pipelinev1beta1.TaskRun{
+		Status: pipelinev1beta1.TaskRunStatus{
+			TaskRunStatusFields: pipelinev1beta1.TaskRunStatusFields{
+				TaskSpec: &pipelinev1beta1.TaskSpec{
+					Steps: []pipelinev1beta1.Step{{Name: "lint", OnError: pipelinev1beta1.Continue}, {Name: "build"}, {Name: "test"}, {Name: "push"}},
+				},
+				Steps: []pipelinev1beta1.StepState{terminated("lint", 1), terminated("build", 2), terminated("test", 1), terminated("push", 1)},
+			},
+		},
+	}
+
+	executed, skipped := countSteps(taskRun)
+	if executed != 2 || skipped != 2 {
+		t.Errorf("got %d executed and %d skipped steps, want 2 and 2", executed, skipped)
+	}
+}
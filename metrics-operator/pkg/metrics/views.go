@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"reflect"
+	"sort"
+
+	"go.opencensus.io/stats/view"
+)
+
+// diffViews returns the views of old to unregister and the views of new to
+// register. A view is kept when an equal view of the same name exists in both,
+// so its aggregated data survives edits of other views of the metric.
+func diffViews(old, new []*view.View) (removed, added []*view.View) {
+	kept := map[string]bool{}
+	for _, n := range new {
+		for _, o := range old {
+			if viewName(o) == viewName(n) && sameView(o, n) {
+				kept[viewName(n)] = true
+			}
+		}
+	}
+	for _, o := range old {
+		if !kept[viewName(o)] {
+			removed = append(removed, o)
+		}
+	}
+	for _, n := range new {
+		if !kept[viewName(n)] {
+			added = append(added, n)
+		}
+	}
+	return removed, added
+}
+
+// viewName returns the name of the view once registered, the measure name
+// when unset
+func viewName(v *view.View) string {
+	if v.Name == "" {
+		return v.Measure.Name()
+	}
+	return v.Name
+}
+
+// sameView also compares the description and tag keys, opencensus only
+// compares the measure and aggregation and would keep serving the old ones
+func sameView(a, b *view.View) bool {
+	if a.Measure.Name() != b.Measure.Name() || a.Measure.Unit() != b.Measure.Unit() {
+		return false
+	}
+	if viewDescription(a) != viewDescription(b) || !reflect.DeepEqual(tagKeyNames(a), tagKeyNames(b)) {
+		return false
+	}
+	// the aggregation funcs are never equal, distributions are created per view
+	return a.Aggregation.Type == b.Aggregation.Type && reflect.DeepEqual(a.Aggregation.Buckets, b.Aggregation.Buckets)
+}
+
+func viewDescription(v *view.View) string {
+	if v.Description == "" {
+		return v.Measure.Description()
+	}
+	return v.Description
+}
+
+// tagKeyNames returns the sorted tag key names, registration sorts the keys
+func tagKeyNames(v *view.View) []string {
+	names := make([]string, 0, len(v.TagKeys))
+	for _, key := range v.TagKeys {
+		names = append(names, key.Name())
+	}
+	sort.Strings(names)
+	return names
+}
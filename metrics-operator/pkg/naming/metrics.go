@@ -13,6 +13,15 @@ func HistogramMetric(resource, monitorName, metricName string) string {
 	return fmt.Sprintf("%s_%s_%s_seconds", resource, strings.ReplaceAll(monitorName, "-", "_"), metricName)
 }
 
+// DurationMetric is HistogramMetric suffixed with the time unit of the
+// durations, seconds when empty
+func DurationMetric(resource, monitorName, metricName, unit string) string {
+	if unit == "" {
+		unit = "seconds"
+	}
+	return fmt.Sprintf("%s_%s", GaugeMetric(resource, monitorName, metricName), unit)
+}
+
 func GaugeMetric(resource, monitorName, metricName string) string {
 	return fmt.Sprintf("%s_%s_%s", resource, strings.ReplaceAll(monitorName, "-", "_"), metricName)
 }
@@ -20,3 +29,47 @@ func GaugeMetric(resource, monitorName, metricName string) string {
 func MonitorId(resource, monitorName string) string {
 	return fmt.Sprintf("%s/%s", resource, monitorName)
 }
+
+func RatioMetric(resource, monitorName, metricName string) string {
+	return fmt.Sprintf("%s_%s_%s_ratio", resource, strings.ReplaceAll(monitorName, "-", "_"), metricName)
+}
+
+func CountOverMetric(resource, monitorName, metricName string) string {
+	return fmt.Sprintf("%s_%s_%s_over_total", resource, strings.ReplaceAll(monitorName, "-", "_"), metricName)
+}
+
+// ClusterResource is the resource of the cluster scoped variant of a monitor,
+// so its metrics never collide with the ones of a namespaced monitor
+func ClusterResource(resource string) string {
+	return "cluster" + resource
+}
+
+// Prefixed replaces the resource and monitor name starting the metric name
+// with the metric prefix of the monitor, the name is unchanged without prefix
+func Prefixed(prefix, resource, monitorName, metric string) string {
+	base := fmt.Sprintf("%s_%s", resource, strings.ReplaceAll(monitorName, "-", "_"))
+	if prefix == "" || !strings.HasPrefix(metric, base) {
+		return metric
+	}
+	return prefix + strings.TrimPrefix(metric, base)
+}
+
+// RateMetric is the name of the runs completed per minute over a window
+func RateMetric(resource, monitorName, metricName string) string {
+	return fmt.Sprintf("%s_%s_%s_per_minute", resource, strings.ReplaceAll(monitorName, "-", "_"), metricName)
+}
+
+// SLOGoodMetric is the name of the counter of good runs of a slo, next to the
+// counter of every run named like counters
+func SLOGoodMetric(resource, monitorName, metricName string) string {
+	return fmt.Sprintf("%s_%s_%s_good_total", resource, strings.ReplaceAll(monitorName, "-", "_"), metricName)
+}
+
+// Keys of the attachments of histogram samples, exported as the attributes,
+// trace id and span id of exemplars
+const (
+	ExemplarRunKey     = "run"
+	ExemplarRunUIDKey  = "run_uid"
+	ExemplarTraceIDKey = "trace_id"
+	ExemplarSpanIDKey  = "span_id"
+)
@@ -0,0 +1,139 @@
+package clustertaskmonitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	clustertaskmonitorreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/clustertaskmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+)
+
+type Reconciler struct {
+	manager       *metrics.MetricManager
+	taskRunLister pipelinev1beta1listers.TaskRunLister
+	// statusInterval between two refreshes of the metric stats in the status,
+	// zero refreshes them only when the monitor is reconciled
+	statusInterval time.Duration
+}
+
+var (
+	resource                                        = naming.ClusterResource("task")
+	_        clustertaskmonitorreconciler.Interface = (*Reconciler)(nil)
+)
+
+// NewReconciler returns a reconciler registering the metrics of
+// ClusterTaskMonitors in the manager
+func NewReconciler(manager *metrics.MetricManager, taskRunLister pipelinev1beta1listers.TaskRunLister) *Reconciler {
+	return &Reconciler{
+		manager:       manager,
+		taskRunLister: taskRunLister,
+	}
+}
+
+func (r *Reconciler) ReconcileKind(ctx context.Context, clusterTaskMonitor *monitoringv1alpha1.ClusterTaskMonitor) reconciler.Event {
+	logger := logging.FromContext(ctx).With("monitor", clusterTaskMonitor.Name)
+	// monitors created before the webhook was installed aren't validated
+	if err := clusterTaskMonitor.Validate(ctx); err != nil {
+		logger.Errorw("invalid monitor", "error", err)
+		clusterTaskMonitor.Status.ObservedGeneration = clusterTaskMonitor.Generation
+		monitoringv1alpha1.SetReadyCondition(&clusterTaskMonitor.Status.Status, "InvalidSpec", err)
+		return controller.NewPermanentError(err)
+	}
+	latestMetrics := sets.NewString()
+	r.manager.GetIndex().SetMonitorReference(resource, clusterTaskMonitor.Name, metrics.MonitorReference("ClusterTaskMonitor", clusterTaskMonitor))
+	for _, metric := range clusterTaskMonitor.Spec.Metrics {
+		metric = monitoringv1alpha1.ExpandGranularity(monitoringv1alpha1.ExpandPreset(metric))
+		metric.SetDefaults(ctx)
+		if len(metric.Reasons) == 0 {
+			metric.Reasons = clusterTaskMonitor.Spec.Reasons
+		}
+		metric.Tags = monitoringv1alpha1.MergeTags(clusterTaskMonitor.Spec.CommonTags, metric.Tags)
+		var runMetric metrics.RunMetric
+		// TODO: fail if type is invalid
+		switch metric.Type {
+		case "counter":
+			runMetric = recorder.NewClusterTaskCounter(metric.DeepCopy(), clusterTaskMonitor)
+		case "histogram":
+			runMetric = recorder.NewClusterTaskHistogram(metric.DeepCopy(), clusterTaskMonitor)
+		case "gauge":
+			runMetric = recorder.NewClusterTaskGauge(metric.DeepCopy(), clusterTaskMonitor)
+		case "timeoutRatio":
+			runMetric = recorder.NewClusterTaskTimeoutRatio(metric.DeepCopy(), clusterTaskMonitor)
+		case "durationBreakdown":
+			runMetric = recorder.NewClusterTaskDurationBreakdown(metric.DeepCopy(), clusterTaskMonitor)
+		case "lastValue":
+			runMetric = recorder.NewClusterTaskLastValue(metric.DeepCopy(), clusterTaskMonitor)
+		case "rate":
+			runMetric = recorder.NewClusterTaskRate(metric.DeepCopy(), clusterTaskMonitor)
+		case "slo":
+			runMetric = recorder.NewClusterTaskSLO(metric.DeepCopy(), clusterTaskMonitor)
+		default:
+			logger.Errorw("invalid metric type", "metric", metric.Name, "type", metric.Type)
+			return fmt.Errorf("invalid metric type: %q", metric.Type)
+		}
+		if runMetric != nil {
+			latestMetrics = latestMetrics.Insert(runMetric.MetricName())
+			err := r.manager.GetIndex().RegisterRunMetric(ctx, runMetric)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	registeredMetrics := sets.NewString(r.manager.Index.GetAllMetricNamesFromMonitor(resource, clusterTaskMonitor.Name)...)
+	removed := registeredMetrics.Difference(latestMetrics)
+
+	for _, removedMetricName := range removed.List() {
+		err := r.manager.GetIndex().UnregisterRunMetricByName(removedMetricName)
+		if err != nil {
+			return err
+		}
+	}
+
+	clusterTaskMonitor.Status.ObservedGeneration = clusterTaskMonitor.Generation
+	clusterTaskMonitor.Status.Metrics = r.manager.GetIndex().MonitorStatus(resource, clusterTaskMonitor.Name)
+	monitoringv1alpha1.SetReadyCondition(&clusterTaskMonitor.Status.Status, "", nil)
+	monitoringv1alpha1.SetDegradedCondition(&clusterTaskMonitor.Status.Status, clusterTaskMonitor.Status.Metrics)
+	if r.statusInterval > 0 {
+		// stats change with every recorded run, not only with the monitor
+		return controller.NewRequeueAfter(r.statusInterval)
+	}
+	return nil
+}
+
+func (r *Reconciler) FinalizeKind(ctx context.Context, clusterTaskMonitor *monitoringv1alpha1.ClusterTaskMonitor) reconciler.Event {
+	err := r.manager.GetIndex().UnregisterAllMetricsMonitor(resource, clusterTaskMonitor.Name)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+var _ clustertaskmonitorreconciler.ReadOnlyInterface = (*Reconciler)(nil)
+
+// ObserveKind registers the metrics of the ClusterTaskMonitor on the replicas not leading
+// it, so they record runs as soon as they are promoted. The status is only
+// written by the leader.
+func (r *Reconciler) ObserveKind(ctx context.Context, clusterTaskMonitor *monitoringv1alpha1.ClusterTaskMonitor) reconciler.Event {
+	event := r.ReconcileKind(ctx, clusterTaskMonitor.DeepCopy())
+	if requeue, _ := controller.IsRequeueKey(event); requeue {
+		return nil
+	}
+	return event
+}
+
+// ObserveDeletion unregisters the metrics of a deleted ClusterTaskMonitor, only the
+// leader finalizes it
+func (r *Reconciler) ObserveDeletion(ctx context.Context, key types.NamespacedName) error {
+	return r.manager.GetIndex().UnregisterAllMetricsMonitor(resource, key.Name)
+}
@@ -0,0 +1,34 @@
+package clustertaskmonitor
+
+import (
+	"context"
+	"time"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+
+	clustertaskmonitorinformer "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/informers/monitoring/v1alpha1/clustertaskmonitor"
+	clustertaskmonitorreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/clustertaskmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	taskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/taskrun"
+)
+
+// NewController returns the ClusterTaskMonitor controller, the metric stats in
+// the status are refreshed every statusInterval
+func NewController(manager *metrics.MetricManager, statusInterval time.Duration) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		clusterTaskMonitorInformer := clustertaskmonitorinformer.Get(ctx)
+		taskRunInformer := taskruninformer.Get(ctx)
+
+		c := NewReconciler(manager, taskRunInformer.Lister())
+		c.statusInterval = statusInterval
+
+		impl := clustertaskmonitorreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
+			return controller.Options{}
+		})
+		clusterTaskMonitorInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+		manager.Instrument("clustertaskmonitor", impl)
+		return impl
+	}
+}
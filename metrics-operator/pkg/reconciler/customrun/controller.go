@@ -0,0 +1,58 @@
+package customrun
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/informers"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	customruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/customrun"
+	customrunreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1beta1/customrun"
+)
+
+// NewController returns the CustomRun controller recording wait and approval
+// gates, see MetricManager.EnableGateMetrics. When monitors is true,
+// CustomRuns are recorded by the TaskMonitors of kind CustomRun as well.
+func NewController(manager *metrics.MetricManager, monitors bool) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		customRunInformer := customruninformer.Get(ctx)
+
+		c := &Reconciler{
+			manager:  manager,
+			monitors: monitors,
+		}
+		impl := customrunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
+			return controller.Options{
+				SkipStatusUpdates: true,
+			}
+		})
+		customRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: informers.ExcludedNamespaceFilter(ctx),
+			Handler:    controller.HandleAll(impl.Enqueue),
+		})
+		customRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(obj any) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if customRun, ok := obj.(*pipelinev1beta1.CustomRun); ok {
+					manager.ForgetGate(ctx, customRun.Namespace, customRun.Name, string(customRun.UID))
+					if monitors {
+						manager.CleanDeleted(ctx, "customrun", meta.AsPartialObjectMetadata(customRun))
+					}
+				}
+			},
+		})
+		if monitors {
+			manager.AnnotateRunsWith("customrun", annotateCustomRun(ctx))
+		}
+		manager.Instrument("customrun", impl)
+		return impl
+	}
+}
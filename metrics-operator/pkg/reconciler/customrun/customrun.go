@@ -0,0 +1,29 @@
+package customrun
+
+import (
+	"context"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"knative.dev/pkg/reconciler"
+)
+
+type Reconciler struct {
+	manager *metrics.MetricManager
+	// monitors records CustomRuns in the metrics of TaskMonitors of kind
+	// CustomRun, on top of gates
+	monitors bool
+}
+
+func (r *Reconciler) ReconcileKind(ctx context.Context, customRun *pipelinev1beta1.CustomRun) reconciler.Event {
+	if err := r.manager.RecordGate(ctx, customRun); err != nil {
+		return err
+	}
+	if !r.monitors {
+		return nil
+	}
+	if customRun.IsDone() {
+		return r.manager.RecordCustomRunDone(ctx, customRun)
+	}
+	return r.manager.RecordCustomRunRunning(ctx, customRun)
+}
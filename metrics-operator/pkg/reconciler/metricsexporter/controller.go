@@ -0,0 +1,29 @@
+package metricsexporter
+
+import (
+	"context"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+
+	metricsexporterinformer "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/informers/monitoring/v1alpha1/metricsexporter"
+	metricsexporterreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/metricsexporter"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/server"
+)
+
+// NewController returns the MetricsExporter controller, attaching the
+// exporters to the exporter of monitor metrics
+func NewController(exporter *server.ObservedExporter) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		metricsExporterInformer := metricsexporterinformer.Get(ctx)
+
+		c := NewReconciler(exporter)
+
+		impl := metricsexporterreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
+			return controller.Options{}
+		})
+		metricsExporterInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+		return impl
+	}
+}
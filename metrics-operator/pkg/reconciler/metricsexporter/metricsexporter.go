@@ -0,0 +1,111 @@
+package metricsexporter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	metricsexporterreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/metricsexporter"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/server"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+)
+
+type Reconciler struct {
+	exporter *server.ObservedExporter
+}
+
+var _ metricsexporterreconciler.Interface = (*Reconciler)(nil)
+
+// NewReconciler returns a reconciler attaching the exporters of
+// MetricsExporters to the exporter of monitor metrics
+func NewReconciler(exporter *server.ObservedExporter) *Reconciler {
+	return &Reconciler{exporter: exporter}
+}
+
+func (r *Reconciler) ReconcileKind(ctx context.Context, metricsExporter *monitoringv1alpha1.MetricsExporter) reconciler.Event {
+	logger := logging.FromContext(ctx).With("exporter", metricsExporter.Name)
+	metricsExporter.Status.ObservedGeneration = metricsExporter.Generation
+
+	config, err := ObservabilityConfig(&metricsExporter.Spec)
+	if err != nil {
+		monitoringv1alpha1.SetReadyCondition(&metricsExporter.Status.Status, "InvalidSpec", err)
+		return controller.NewPermanentError(err)
+	}
+	var interval time.Duration
+	if metricsExporter.Spec.Interval != nil {
+		interval = metricsExporter.Spec.Interval.Duration
+	}
+	if err := r.exporter.Attach(attachedName(metricsExporter), config, interval); err != nil {
+		logger.Errorw("failed to attach exporter", "type", metricsExporter.Spec.Type, "error", err)
+		monitoringv1alpha1.SetReadyCondition(&metricsExporter.Status.Status, "AttachFailed", err)
+		return err
+	}
+	monitoringv1alpha1.SetReadyCondition(&metricsExporter.Status.Status, "", nil)
+	return nil
+}
+
+func (r *Reconciler) FinalizeKind(ctx context.Context, metricsExporter *monitoringv1alpha1.MetricsExporter) reconciler.Event {
+	r.exporter.Detach(attachedName(metricsExporter))
+	return nil
+}
+
+func attachedName(metricsExporter *monitoringv1alpha1.MetricsExporter) string {
+	return "metricsexporter/" + metricsExporter.Name
+}
+
+// ObservabilityConfig returns the config of the exporter described by the
+// spec
+func ObservabilityConfig(spec *monitoringv1alpha1.MetricsExporterSpec) (*server.ObservabilityConfig, error) {
+	config := server.DefaultObservabilityConfig()
+	switch spec.Type {
+	case monitoringv1alpha1.MetricsExporterTypePrometheus:
+		host, port, err := splitEndpoint(spec.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		config.Backend, config.PrometheusHost, config.PrometheusPort = server.BackendPrometheus, host, port
+	case monitoringv1alpha1.MetricsExporterTypeOTLP:
+		config.Backend, config.OTLPEndpoint = server.BackendOTLP, spec.Endpoint
+		if spec.Protocol != "" {
+			config.OTLPProtocol = spec.Protocol
+		}
+		if spec.TLS != nil {
+			config.OTLPInsecure = spec.TLS.Insecure
+			config.OTLPInsecureSkipVerify = spec.TLS.InsecureSkipVerify
+			config.OTLPCABundle = spec.TLS.CABundle
+			config.OTLPServerName = spec.TLS.ServerName
+		}
+	case monitoringv1alpha1.MetricsExporterTypeStatsD:
+		host, port, err := splitEndpoint(spec.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		config.Backend, config.StatsDHost, config.StatsDPort, config.StatsDPrefix = server.BackendStatsD, host, port, spec.Prefix
+		if spec.Flavor != "" {
+			config.StatsDFlavor = spec.Flavor
+		}
+	default:
+		return nil, fmt.Errorf("unsupported exporter type %q, expected prometheus, otlp or statsd", spec.Type)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func splitEndpoint(endpoint string) (string, int, error) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port of endpoint %q: %w", endpoint, err)
+	}
+	return host, p, nil
+}
@@ -0,0 +1,32 @@
+package metricsexporter
+
+import (
+	"testing"
+
+	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/server"
+)
+
+func TestObservabilityConfig(t *testing.T) {
+	config, err := ObservabilityConfig(&monitoringv1alpha1.MetricsExporterSpec{
+		Type:     monitoringv1alpha1.MetricsExporterTypeStatsD,
+		Endpoint: "statsd.monitoring:9125",
+		Prefix:   "tekton",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Backend != server.BackendStatsD || config.StatsDHost != "statsd.monitoring" || config.StatsDPort != 9125 || config.StatsDPrefix != "tekton" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	for _, spec := range []monitoringv1alpha1.MetricsExporterSpec{
+		{Type: "graphite", Endpoint: "graphite:2003"},
+		{Type: monitoringv1alpha1.MetricsExporterTypePrometheus, Endpoint: "9090"},
+		{Type: monitoringv1alpha1.MetricsExporterTypeOTLP, Endpoint: "collector:4317", Protocol: "thrift"},
+	} {
+		if _, err := ObservabilityConfig(&spec); err == nil {
+			t.Errorf("expected an error for %+v", spec)
+		}
+	}
+}
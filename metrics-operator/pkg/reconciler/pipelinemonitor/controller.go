@@ -2,6 +2,7 @@ package pipelinemonitor
 
 import (
 	"context"
+	"time"
 
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -13,20 +14,21 @@ import (
 	pipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/pipelinerun"
 )
 
-func NewController(manager *metrics.MetricManager) injection.ControllerConstructor {
+// NewController returns the PipelineMonitor controller, the metric stats in the status
+// are refreshed every statusInterval
+func NewController(manager *metrics.MetricManager, statusInterval time.Duration) injection.ControllerConstructor {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		pipelineMonitorInformer := pipelinemonitorinformer.Get(ctx)
 		pipelineRunInformer := pipelineruninformer.Get(ctx)
 
-		c := &Reconciler{
-			manager:           manager,
-			pipelineRunLister: pipelineRunInformer.Lister(),
-		}
+		c := NewReconciler(manager, pipelineRunInformer.Lister())
+		c.statusInterval = statusInterval
 
 		impl := pipelinemonitorreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			return controller.Options{}
 		})
 		pipelineMonitorInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+		manager.Instrument("pipelinemonitor", impl)
 		return impl
 	}
 }
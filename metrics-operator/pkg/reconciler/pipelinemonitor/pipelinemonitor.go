@@ -3,13 +3,16 @@ package pipelinemonitor
 import (
 	"context"
 	"fmt"
+	"time"
 
 	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	pipelinemonitorreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/pipelinemonitor"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 )
@@ -17,6 +20,9 @@ import (
 type Reconciler struct {
 	manager           *metrics.MetricManager
 	pipelineRunLister pipelinev1beta1listers.PipelineRunLister
+	// statusInterval between two refreshes of the metric stats in the status,
+	// zero refreshes them only when the monitor is reconciled
+	statusInterval time.Duration
 }
 
 var (
@@ -24,10 +30,33 @@ var (
 	_        pipelinemonitorreconciler.Interface = (*Reconciler)(nil)
 )
 
+// NewReconciler returns a reconciler registering the metrics of PipelineMonitors in
+// the manager
+func NewReconciler(manager *metrics.MetricManager, pipelineRunLister pipelinev1beta1listers.PipelineRunLister) *Reconciler {
+	return &Reconciler{
+		manager:           manager,
+		pipelineRunLister: pipelineRunLister,
+	}
+}
+
 func (r *Reconciler) ReconcileKind(ctx context.Context, pipelineMonitor *monitoringv1alpha1.PipelineMonitor) reconciler.Event {
 	logger := logging.FromContext(ctx).With("monitor", pipelineMonitor.Name)
+	// monitors created before the webhook was installed aren't validated
+	if err := pipelineMonitor.Validate(ctx); err != nil {
+		logger.Errorw("invalid monitor", "error", err)
+		pipelineMonitor.Status.ObservedGeneration = pipelineMonitor.Generation
+		monitoringv1alpha1.SetReadyCondition(&pipelineMonitor.Status.Status, "InvalidSpec", err)
+		return controller.NewPermanentError(err)
+	}
 	latestMetrics := sets.NewString()
+	r.manager.GetIndex().SetMonitorReference(resource, pipelineMonitor.Name, metrics.MonitorReference("PipelineMonitor", pipelineMonitor))
 	for _, metric := range pipelineMonitor.Spec.Metrics {
+		metric = monitoringv1alpha1.ExpandPreset(metric)
+		metric.SetDefaults(ctx)
+		if len(metric.Reasons) == 0 {
+			metric.Reasons = pipelineMonitor.Spec.Reasons
+		}
+		metric.Tags = monitoringv1alpha1.MergeTags(pipelineMonitor.Spec.CommonTags, metric.Tags)
 		var runMetric metrics.RunMetric
 		// TODO: fail if type is invalid
 		switch metric.Type {
@@ -37,6 +66,20 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pipelineMonitor *monitor
 			runMetric = recorder.NewPipelineHistogram(metric.DeepCopy(), pipelineMonitor)
 		case "gauge":
 			runMetric = recorder.NewPipelineGauge(metric.DeepCopy(), pipelineMonitor)
+		case "timeoutRatio":
+			runMetric = recorder.NewPipelineTimeoutRatio(metric.DeepCopy(), pipelineMonitor)
+		case "durationBreakdown":
+			runMetric = recorder.NewPipelineDurationBreakdown(metric.DeepCopy(), pipelineMonitor)
+		case "lastValue":
+			runMetric = recorder.NewPipelineLastValue(metric.DeepCopy(), pipelineMonitor)
+		case "rate":
+			runMetric = recorder.NewPipelineRate(metric.DeepCopy(), pipelineMonitor)
+		case "slo":
+			runMetric = recorder.NewPipelineSLO(metric.DeepCopy(), pipelineMonitor)
+		case "childStates":
+			runMetric = recorder.NewPipelineChildStates(metric.DeepCopy(), pipelineMonitor)
+		case "childOutcomes":
+			runMetric = recorder.NewPipelineChildOutcomes(metric.DeepCopy(), pipelineMonitor)
 		default:
 			logger.Errorw("invalid metric type", "metric", metric.Name, "type", metric.Type)
 			return fmt.Errorf("invalid metric type: %q", metric.Type)
@@ -60,6 +103,14 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pipelineMonitor *monitor
 		}
 	}
 
+	pipelineMonitor.Status.ObservedGeneration = pipelineMonitor.Generation
+	pipelineMonitor.Status.Metrics = r.manager.GetIndex().MonitorStatus(resource, pipelineMonitor.Name)
+	monitoringv1alpha1.SetReadyCondition(&pipelineMonitor.Status.Status, "", nil)
+	monitoringv1alpha1.SetDegradedCondition(&pipelineMonitor.Status.Status, pipelineMonitor.Status.Metrics)
+	if r.statusInterval > 0 {
+		// stats change with every recorded run, not only with the monitor
+		return controller.NewRequeueAfter(r.statusInterval)
+	}
 	return nil
 }
 
@@ -70,3 +121,22 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, pipelineMonitor *monitori
 	}
 	return nil
 }
+
+var _ pipelinemonitorreconciler.ReadOnlyInterface = (*Reconciler)(nil)
+
+// ObserveKind registers the metrics of the PipelineMonitor on the replicas not leading
+// it, so they record runs as soon as they are promoted. The status is only
+// written by the leader.
+func (r *Reconciler) ObserveKind(ctx context.Context, pipelineMonitor *monitoringv1alpha1.PipelineMonitor) reconciler.Event {
+	event := r.ReconcileKind(ctx, pipelineMonitor.DeepCopy())
+	if requeue, _ := controller.IsRequeueKey(event); requeue {
+		return nil
+	}
+	return event
+}
+
+// ObserveDeletion unregisters the metrics of a deleted PipelineMonitor, only the
+// leader finalizes it
+func (r *Reconciler) ObserveDeletion(ctx context.Context, key types.NamespacedName) error {
+	return r.manager.GetIndex().UnregisterAllMetricsMonitor(resource, key.Name)
+}
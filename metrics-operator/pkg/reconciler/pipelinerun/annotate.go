@@ -0,0 +1,29 @@
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// annotatePipelineRun patches an annotation of a PipelineRun, other
+// annotations are left untouched
+func annotatePipelineRun(ctx context.Context) metrics.AnnotateFunc {
+	client := pipelineclient.Get(ctx)
+	return func(ctx context.Context, object metav1.Object, key, value string) error {
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"annotations": map[string]string{key: value},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = client.TektonV1beta1().PipelineRuns(object.GetNamespace()).Patch(ctx, object.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+		return metrics.RetryableAPIError(err)
+	}
+}
@@ -3,30 +3,101 @@ package pipelinerun
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
+	"knative.dev/pkg/reconciler"
 
+	"github.com/tektoncd/experimental/metrics-operator/pkg/informers"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	pipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/pipelinerun"
+	taskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/taskrun"
 	pipelinerunreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1beta1/pipelinerun"
 )
 
-func NewController(manager *metrics.MetricManager) injection.ControllerConstructor {
+const finalizerName = "pipelinerun.metrics.tekton.dev"
+
+// NewController returns the PipelineRun controller, when finalize is true a
+// finalizer is added to PipelineRuns so their series are always cleaned.
+func NewController(manager *metrics.MetricManager, finalize bool) injection.ControllerConstructor {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		pipelineRunInformer := pipelineruninformer.Get(ctx)
+		taskRunInformer := taskruninformer.Get(ctx)
+
+		children := taskRunConditions(taskRunInformer.Lister())
 
-		c := &Reconciler{
-			manager: manager,
+		var c pipelinerunreconciler.Interface = &Reconciler{
+			manager:  manager,
+			children: children,
+		}
+		if finalize {
+			c = &FinalizingReconciler{Reconciler{manager: manager, children: children}}
+		}
+
+		// the new leader of a bucket releases the finalizers and replays the
+		// runs followers skipped, child outcomes of replayed runs are resolved
+		// from the TaskRun cache
+		promoted := func(bucket reconciler.Bucket) {
+			if !finalize {
+				releaseFinalizers(ctx, manager, pipelineRunInformer.Lister(), bucket.Has, pipelineRunInformer.Informer().HasSynced)
+			}
+			manager.ReplayRuns(recorder.WithChildConditions(ctx, children), "pipelinerun", donePipelineRuns(ctx, pipelineRunInformer.Lister(), bucket.Has),
+				pipelineRunInformer.Informer().HasSynced, taskRunInformer.Informer().HasSynced)
 		}
 
 		impl := pipelinerunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			return controller.Options{
-				FinalizerName:     "pipelinerun.metrics.tekton.dev",
+				FinalizerName:     finalizerName,
 				SkipStatusUpdates: true,
+				PromoteFunc: func(bucket reconciler.Bucket) {
+					go promoted(bucket)
+				},
 			}
 		})
-		pipelineRunInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+		// replicas only release the finalizers of the runs they lead
+		leads := func(key types.NamespacedName) bool {
+			return metrics.Leads(impl, key)
+		}
+		pipelineRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: informers.ExcludedNamespaceFilter(ctx),
+			Handler:    controller.HandleAll(enqueueByPriority(impl.Enqueue, impl.EnqueueSlow)),
+		})
+		if !finalize {
+			pipelineRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(_, obj any) {
+					if object, ok := obj.(metav1.Object); ok && leads(types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}) {
+						releaseFinalizer(ctx, manager, obj)
+					}
+				},
+			})
+		}
+		informers.GetPipelineRunMetadata(ctx).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(obj any) {
+				if object, ok := informers.DeletedObject(obj); ok {
+					manager.CleanDeleted(ctx, "pipelinerun", object)
+				}
+			},
+		})
+		manager.AnnotateRunsWith("pipelinerun", annotatePipelineRun(ctx))
+		manager.Instrument("pipelinerun", impl)
 		return impl
 	}
 }
+
+// enqueueByPriority puts done and deleted PipelineRuns in the fast lane, intermediate
+// status updates go to the slow lane so completions are recorded first under
+// backlog.
+func enqueueByPriority(enqueue, enqueueSlow func(obj any)) func(obj any) {
+	return func(obj any) {
+		if pipelineRun, ok := obj.(*pipelinev1beta1.PipelineRun); ok && !pipelineRun.IsDone() && pipelineRun.DeletionTimestamp == nil {
+			enqueueSlow(obj)
+			return
+		}
+		enqueue(obj)
+	}
+}
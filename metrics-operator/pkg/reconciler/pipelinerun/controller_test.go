@@ -0,0 +1,41 @@
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestEnqueueByPriority(t *testing.T) {
+	running := &pipelinev1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "dev"}}
+	done := running.DeepCopy()
+	done.Name = "done"
+	done.Status.Status = duckv1.Status{Conditions: duckv1.Conditions{
+		{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse},
+	}}
+	deleted := running.DeepCopy()
+	deleted.Name = "deleted"
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	fast, slow := []string{}, []string{}
+	enqueue := enqueueByPriority(func(obj any) {
+		fast = append(fast, obj.(*pipelinev1beta1.PipelineRun).Name)
+	}, func(obj any) {
+		slow = append(slow, obj.(*pipelinev1beta1.PipelineRun).Name)
+	})
+	for _, pipelineRun := range []*pipelinev1beta1.PipelineRun{running, done, deleted} {
+		enqueue(pipelineRun)
+	}
+	if diff := cmp.Diff([]string{"done", "deleted"}, fast); diff != "" {
+		t.Errorf("fast lane (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"running"}, slow); diff != "" {
+		t.Errorf("slow lane (-want, +got):\n%s", diff)
+	}
+}
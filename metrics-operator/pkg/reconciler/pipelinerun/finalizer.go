@@ -0,0 +1,67 @@
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/logging"
+)
+
+// releaseFinalizer cleans and removes the finalizer left by a previous
+// configuration on a deleted PipelineRun when finalizers are disabled, otherwise
+// the deletion would be blocked forever.
+func releaseFinalizer(ctx context.Context, manager *metrics.MetricManager, obj any) {
+	pipelineRun, ok := obj.(*pipelinev1beta1.PipelineRun)
+	if !ok || pipelineRun.DeletionTimestamp == nil {
+		return
+	}
+	finalizers := sets.NewString(pipelineRun.Finalizers...)
+	if !finalizers.Has(finalizerName) {
+		return
+	}
+	manager.GetIndex().Clean(ctx, recorder.PipelineRunDimensions(pipelineRun))
+
+	finalizers.Delete(finalizerName)
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"finalizers":      finalizers.List(),
+			"resourceVersion": pipelineRun.ResourceVersion,
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to build finalizer patch", "error", err)
+		return
+	}
+	_, err = pipelineclient.Get(ctx).TektonV1beta1().PipelineRuns(pipelineRun.Namespace).Patch(ctx, pipelineRun.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to remove finalizer", "pipelineRun", pipelineRun.Name, "error", err)
+	}
+}
+
+// releaseFinalizers releases the finalizers of the cached PipelineRuns led by
+// the replica, deleted while another replica led them
+func releaseFinalizers(ctx context.Context, manager *metrics.MetricManager, lister pipelinev1beta1listers.PipelineRunLister, leads func(types.NamespacedName) bool, synced ...cache.InformerSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return
+	}
+	pipelineRuns, err := lister.List(labels.Everything())
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to list PipelineRuns to release", "error", err)
+		return
+	}
+	for _, pipelineRun := range pipelineRuns {
+		if leads(types.NamespacedName{Namespace: pipelineRun.Namespace, Name: pipelineRun.Name}) {
+			releaseFinalizer(ctx, manager, pipelineRun)
+		}
+	}
+}
@@ -6,21 +6,37 @@ import (
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/reconciler"
 )
 
 type Reconciler struct {
 	manager *metrics.MetricManager
+	// children resolves the conditions of the children of PipelineRuns
+	children recorder.ChildConditionFunc
 }
 
 func (r *Reconciler) ReconcileKind(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) reconciler.Event {
+	if r.children != nil {
+		ctx = recorder.WithChildConditions(ctx, r.children)
+	}
 	if pipelineRun.IsDone() {
 		return r.manager.RecordPipelineRunDone(ctx, pipelineRun)
 	}
 	return r.manager.RecordPipelineRunRunning(ctx, pipelineRun)
 }
 
-func (r *Reconciler) FinalizeKind(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) reconciler.Event {
+// FinalizingReconciler adds a finalizer to PipelineRuns so series are cleaned even
+// when the operator was down while the run was deleted
+type FinalizingReconciler struct {
+	Reconciler
+}
+
+func (r *FinalizingReconciler) FinalizeKind(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) reconciler.Event {
+	if r.children != nil {
+		ctx = recorder.WithChildConditions(ctx, r.children)
+	}
 	run := recorder.PipelineRunDimensions(pipelineRun)
 	if pipelineRun.IsDone() {
 		r.manager.GetIndex().Clean(ctx, run)
@@ -28,3 +44,18 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, pipelineRun *pipelinev1be
 	}
 	return r.manager.RecordPipelineRunRunning(ctx, pipelineRun)
 }
+
+// taskRunConditions resolves the conditions of TaskRun children from the
+// lister, other kinds of children are unknown
+func taskRunConditions(lister listers.TaskRunLister) recorder.ChildConditionFunc {
+	return func(namespace string, child pipelinev1beta1.ChildStatusReference) (*apis.Condition, bool) {
+		if child.Kind != "TaskRun" {
+			return nil, false
+		}
+		taskRun, err := lister.TaskRuns(namespace).Get(child.Name)
+		if err != nil {
+			return nil, false
+		}
+		return taskRun.Status.GetCondition(apis.ConditionSucceeded), true
+	}
+}
@@ -0,0 +1,32 @@
+package pipelinerun
+
+import (
+	"context"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/informers"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// donePipelineRuns lists the cached done PipelineRuns of namespaces not excluded kept by
+// leads, e.g. the runs of a bucket the replica was promoted for
+func donePipelineRuns(ctx context.Context, lister pipelinev1beta1listers.PipelineRunLister, leads func(types.NamespacedName) bool) func() ([]*v1alpha1.RunDimensions, error) {
+	tuning := informers.GetTuning(ctx)
+	return func() ([]*v1alpha1.RunDimensions, error) {
+		pipelineRuns, err := lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		runs := []*v1alpha1.RunDimensions{}
+		for _, pipelineRun := range pipelineRuns {
+			key := types.NamespacedName{Namespace: pipelineRun.Namespace, Name: pipelineRun.Name}
+			if pipelineRun.IsDone() && !tuning.NamespaceExcluded(pipelineRun.Namespace) && leads(key) {
+				runs = append(runs, recorder.PipelineRunDimensions(pipelineRun))
+			}
+		}
+		return runs, nil
+	}
+}
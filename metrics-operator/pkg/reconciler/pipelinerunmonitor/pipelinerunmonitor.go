@@ -3,13 +3,16 @@ package pipelinerunmonitor
 import (
 	"context"
 	"fmt"
+	"time"
 
 	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	pipelinerunmonitorreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/pipelinerunmonitor"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 )
@@ -17,6 +20,9 @@ import (
 type Reconciler struct {
 	manager       *metrics.MetricManager
 	pipelineRunLister pipelinev1beta1listers.PipelineRunLister
+	// statusInterval between two refreshes of the metric stats in the status,
+	// zero refreshes them only when the monitor is reconciled
+	statusInterval time.Duration
 }
 
 var (
@@ -24,10 +30,33 @@ var (
 	_ pipelinerunmonitorreconciler.Interface = (*Reconciler)(nil)
 )
 
+// NewReconciler returns a reconciler registering the metrics of PipelineRunMonitors in
+// the manager
+func NewReconciler(manager *metrics.MetricManager, pipelineRunLister pipelinev1beta1listers.PipelineRunLister) *Reconciler {
+	return &Reconciler{
+		manager:           manager,
+		pipelineRunLister: pipelineRunLister,
+	}
+}
+
 func (r *Reconciler) ReconcileKind(ctx context.Context, pipelineRunMonitor *monitoringv1alpha1.PipelineRunMonitor) reconciler.Event {
 	logger := logging.FromContext(ctx).With("monitor", pipelineRunMonitor.Name)
+	// monitors created before the webhook was installed aren't validated
+	if err := pipelineRunMonitor.Validate(ctx); err != nil {
+		logger.Errorw("invalid monitor", "error", err)
+		pipelineRunMonitor.Status.ObservedGeneration = pipelineRunMonitor.Generation
+		monitoringv1alpha1.SetReadyCondition(&pipelineRunMonitor.Status.Status, "InvalidSpec", err)
+		return controller.NewPermanentError(err)
+	}
 	latestMetrics := sets.NewString()
+	r.manager.GetIndex().SetMonitorReference(resource, pipelineRunMonitor.Name, metrics.MonitorReference("PipelineRunMonitor", pipelineRunMonitor))
 	for _, metric := range pipelineRunMonitor.Spec.Metrics {
+		metric = monitoringv1alpha1.ExpandPreset(metric)
+		metric.SetDefaults(ctx)
+		if len(metric.Reasons) == 0 {
+			metric.Reasons = pipelineRunMonitor.Spec.Reasons
+		}
+		metric.Tags = monitoringv1alpha1.MergeTags(pipelineRunMonitor.Spec.CommonTags, metric.Tags)
 		var runMetric metrics.RunMetric
 		// TODO: fail if type is invalid
 		switch metric.Type {
@@ -37,6 +66,20 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pipelineRunMonitor *moni
 			runMetric = recorder.NewPipelineRunHistogram(metric.DeepCopy(), pipelineRunMonitor)
 		case "gauge":
 			runMetric = recorder.NewPipelineRunGauge(metric.DeepCopy(), pipelineRunMonitor)
+		case "timeoutRatio":
+			runMetric = recorder.NewPipelineRunTimeoutRatio(metric.DeepCopy(), pipelineRunMonitor)
+		case "durationBreakdown":
+			runMetric = recorder.NewPipelineRunDurationBreakdown(metric.DeepCopy(), pipelineRunMonitor)
+		case "lastValue":
+			runMetric = recorder.NewPipelineRunLastValue(metric.DeepCopy(), pipelineRunMonitor)
+		case "rate":
+			runMetric = recorder.NewPipelineRunRate(metric.DeepCopy(), pipelineRunMonitor)
+		case "slo":
+			runMetric = recorder.NewPipelineRunSLO(metric.DeepCopy(), pipelineRunMonitor)
+		case "childStates":
+			runMetric = recorder.NewPipelineRunChildStates(metric.DeepCopy(), pipelineRunMonitor)
+		case "childOutcomes":
+			runMetric = recorder.NewPipelineRunChildOutcomes(metric.DeepCopy(), pipelineRunMonitor)
 		default:
 			logger.Errorw("invalid metric type", "metric", metric.Name, "type", metric.Type)
 			return fmt.Errorf("invalid metric type: %q", metric.Type)
@@ -60,6 +103,14 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pipelineRunMonitor *moni
 		}
 	}
 
+	pipelineRunMonitor.Status.ObservedGeneration = pipelineRunMonitor.Generation
+	pipelineRunMonitor.Status.Metrics = r.manager.GetIndex().MonitorStatus(resource, pipelineRunMonitor.Name)
+	monitoringv1alpha1.SetReadyCondition(&pipelineRunMonitor.Status.Status, "", nil)
+	monitoringv1alpha1.SetDegradedCondition(&pipelineRunMonitor.Status.Status, pipelineRunMonitor.Status.Metrics)
+	if r.statusInterval > 0 {
+		// stats change with every recorded run, not only with the monitor
+		return controller.NewRequeueAfter(r.statusInterval)
+	}
 	return nil
 }
 
@@ -70,3 +121,22 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, pipelineRunMonitor *monit
 	}
 	return nil
 }
+
+var _ pipelinerunmonitorreconciler.ReadOnlyInterface = (*Reconciler)(nil)
+
+// ObserveKind registers the metrics of the PipelineRunMonitor on the replicas not leading
+// it, so they record runs as soon as they are promoted. The status is only
+// written by the leader.
+func (r *Reconciler) ObserveKind(ctx context.Context, pipelineRunMonitor *monitoringv1alpha1.PipelineRunMonitor) reconciler.Event {
+	event := r.ReconcileKind(ctx, pipelineRunMonitor.DeepCopy())
+	if requeue, _ := controller.IsRequeueKey(event); requeue {
+		return nil
+	}
+	return event
+}
+
+// ObserveDeletion unregisters the metrics of a deleted PipelineRunMonitor, only the
+// leader finalizes it
+func (r *Reconciler) ObserveDeletion(ctx context.Context, key types.NamespacedName) error {
+	return r.manager.GetIndex().UnregisterAllMetricsMonitor(resource, key.Name)
+}
@@ -2,6 +2,7 @@ package taskmonitor
 
 import (
 	"context"
+	"time"
 
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -13,20 +14,21 @@ import (
 	taskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/taskrun"
 )
 
-func NewController(manager *metrics.MetricManager) injection.ControllerConstructor {
+// NewController returns the TaskMonitor controller, the metric stats in the status
+// are refreshed every statusInterval
+func NewController(manager *metrics.MetricManager, statusInterval time.Duration) injection.ControllerConstructor {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		taskMonitorInformer := taskmonitorinformer.Get(ctx)
 		taskRunInformer := taskruninformer.Get(ctx)
 
-		c := &Reconciler{
-			manager:       manager,
-			taskRunLister: taskRunInformer.Lister(),
-		}
+		c := NewReconciler(manager, taskRunInformer.Lister())
+		c.statusInterval = statusInterval
 
 		impl := taskmonitorreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			return controller.Options{}
 		})
 		taskMonitorInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+		manager.Instrument("taskmonitor", impl)
 		return impl
 	}
 }
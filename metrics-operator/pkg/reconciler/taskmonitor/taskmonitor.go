@@ -3,13 +3,16 @@ package taskmonitor
 import (
 	"context"
 	"fmt"
+	"time"
 
 	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	taskmonitorreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/taskmonitor"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 )
@@ -17,6 +20,9 @@ import (
 type Reconciler struct {
 	manager       *metrics.MetricManager
 	taskRunLister pipelinev1beta1listers.TaskRunLister
+	// statusInterval between two refreshes of the metric stats in the status,
+	// zero refreshes them only when the monitor is reconciled
+	statusInterval time.Duration
 }
 
 var (
@@ -24,10 +30,33 @@ var (
 	_ taskmonitorreconciler.Interface = (*Reconciler)(nil)
 )
 
+// NewReconciler returns a reconciler registering the metrics of TaskMonitors in
+// the manager
+func NewReconciler(manager *metrics.MetricManager, taskRunLister pipelinev1beta1listers.TaskRunLister) *Reconciler {
+	return &Reconciler{
+		manager:       manager,
+		taskRunLister: taskRunLister,
+	}
+}
+
 func (r *Reconciler) ReconcileKind(ctx context.Context, taskMonitor *monitoringv1alpha1.TaskMonitor) reconciler.Event {
 	logger := logging.FromContext(ctx).With("monitor", taskMonitor.Name)
+	// monitors created before the webhook was installed aren't validated
+	if err := taskMonitor.Validate(ctx); err != nil {
+		logger.Errorw("invalid monitor", "error", err)
+		taskMonitor.Status.ObservedGeneration = taskMonitor.Generation
+		monitoringv1alpha1.SetReadyCondition(&taskMonitor.Status.Status, "InvalidSpec", err)
+		return controller.NewPermanentError(err)
+	}
 	latestMetrics := sets.NewString()
+	r.manager.GetIndex().SetMonitorReference(resource, taskMonitor.Name, metrics.MonitorReference("TaskMonitor", taskMonitor))
 	for _, metric := range taskMonitor.Spec.Metrics {
+		metric = monitoringv1alpha1.ExpandGranularity(monitoringv1alpha1.ExpandPreset(metric))
+		metric.SetDefaults(ctx)
+		if len(metric.Reasons) == 0 {
+			metric.Reasons = taskMonitor.Spec.Reasons
+		}
+		metric.Tags = monitoringv1alpha1.MergeTags(taskMonitor.Spec.CommonTags, metric.Tags)
 		var runMetric metrics.RunMetric
 		// TODO: fail if type is invalid
 		switch metric.Type {
@@ -37,6 +66,16 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, taskMonitor *monitoringv
 			runMetric = recorder.NewTaskHistogram(metric.DeepCopy(), taskMonitor)
 		case "gauge":
 			runMetric = recorder.NewTaskGauge(metric.DeepCopy(), taskMonitor)
+		case "timeoutRatio":
+			runMetric = recorder.NewTaskTimeoutRatio(metric.DeepCopy(), taskMonitor)
+		case "durationBreakdown":
+			runMetric = recorder.NewTaskDurationBreakdown(metric.DeepCopy(), taskMonitor)
+		case "lastValue":
+			runMetric = recorder.NewTaskLastValue(metric.DeepCopy(), taskMonitor)
+		case "rate":
+			runMetric = recorder.NewTaskRate(metric.DeepCopy(), taskMonitor)
+		case "slo":
+			runMetric = recorder.NewTaskSLO(metric.DeepCopy(), taskMonitor)
 		default:
 			logger.Errorw("invalid metric type", "metric", metric.Name, "type", metric.Type)
 			return fmt.Errorf("invalid metric type: %q", metric.Type)
@@ -60,6 +99,14 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, taskMonitor *monitoringv
 		}
 	}
 
+	taskMonitor.Status.ObservedGeneration = taskMonitor.Generation
+	taskMonitor.Status.Metrics = r.manager.GetIndex().MonitorStatus(resource, taskMonitor.Name)
+	monitoringv1alpha1.SetReadyCondition(&taskMonitor.Status.Status, "", nil)
+	monitoringv1alpha1.SetDegradedCondition(&taskMonitor.Status.Status, taskMonitor.Status.Metrics)
+	if r.statusInterval > 0 {
+		// stats change with every recorded run, not only with the monitor
+		return controller.NewRequeueAfter(r.statusInterval)
+	}
 	return nil
 }
 
@@ -70,3 +117,22 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, taskMonitor *monitoringv1
 	}
 	return nil
 }
+
+var _ taskmonitorreconciler.ReadOnlyInterface = (*Reconciler)(nil)
+
+// ObserveKind registers the metrics of the TaskMonitor on the replicas not leading
+// it, so they record runs as soon as they are promoted. The status is only
+// written by the leader.
+func (r *Reconciler) ObserveKind(ctx context.Context, taskMonitor *monitoringv1alpha1.TaskMonitor) reconciler.Event {
+	event := r.ReconcileKind(ctx, taskMonitor.DeepCopy())
+	if requeue, _ := controller.IsRequeueKey(event); requeue {
+		return nil
+	}
+	return event
+}
+
+// ObserveDeletion unregisters the metrics of a deleted TaskMonitor, only the
+// leader finalizes it
+func (r *Reconciler) ObserveDeletion(ctx context.Context, key types.NamespacedName) error {
+	return r.manager.GetIndex().UnregisterAllMetricsMonitor(resource, key.Name)
+}
@@ -3,30 +3,104 @@ package taskrun
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
+	"knative.dev/pkg/reconciler"
 
+	"github.com/tektoncd/experimental/metrics-operator/pkg/informers"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	taskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/taskrun"
 	taskrunreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1beta1/taskrun"
 )
 
-func NewController(manager *metrics.MetricManager) injection.ControllerConstructor {
+const finalizerName = "taskrun.metrics.tekton.dev"
+
+// NewController returns the TaskRun controller, when finalize is true a
+// finalizer is added to TaskRuns so their series are always cleaned. When
+// pods is true, the pods of TaskRuns are cached for the value presets
+// measuring pods and the pod restart counter.
+func NewController(manager *metrics.MetricManager, finalize, pods bool) injection.ControllerConstructor {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		taskRunInformer := taskruninformer.Get(ctx)
 
-		c := &Reconciler{
+		base := Reconciler{
 			manager: manager,
 		}
+		if pods {
+			base.pods = podLister(ctx)
+		}
+		var c taskrunreconciler.Interface = &base
+		if finalize {
+			c = &FinalizingReconciler{base}
+		}
+
+		replayCtx := ctx
+		if base.pods != nil {
+			replayCtx = recorder.WithPodLister(ctx, base.pods)
+		}
+		// the new leader of a bucket releases the finalizers and replays the
+		// runs followers skipped
+		promoted := func(bucket reconciler.Bucket) {
+			if !finalize {
+				releaseFinalizers(ctx, manager, taskRunInformer.Lister(), bucket.Has, taskRunInformer.Informer().HasSynced)
+			}
+			manager.ReplayRuns(replayCtx, "taskrun", doneTaskRuns(ctx, taskRunInformer.Lister(), bucket.Has), taskRunInformer.Informer().HasSynced)
+		}
 
 		impl := taskrunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			return controller.Options{
-				FinalizerName:     "taskrun.metrics.tekton.dev",
+				FinalizerName:     finalizerName,
 				SkipStatusUpdates: true,
+				PromoteFunc: func(bucket reconciler.Bucket) {
+					go promoted(bucket)
+				},
 			}
 		})
-		taskRunInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+		// replicas only release the finalizers of the runs they lead
+		leads := func(key types.NamespacedName) bool {
+			return metrics.Leads(impl, key)
+		}
+		taskRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: informers.ExcludedNamespaceFilter(ctx),
+			Handler:    controller.HandleAll(enqueueByPriority(impl.Enqueue, impl.EnqueueSlow)),
+		})
+		if !finalize {
+			taskRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(_, obj any) {
+					if object, ok := obj.(metav1.Object); ok && leads(types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}) {
+						releaseFinalizer(ctx, manager, obj)
+					}
+				},
+			})
+		}
+		informers.GetTaskRunMetadata(ctx).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(obj any) {
+				if object, ok := informers.DeletedObject(obj); ok {
+					manager.CleanDeleted(ctx, "taskrun", object)
+				}
+			},
+		})
+		manager.AnnotateRunsWith("taskrun", annotateTaskRun(ctx))
+		manager.Instrument("taskrun", impl)
 		return impl
 	}
 }
+
+// enqueueByPriority puts done and deleted TaskRuns in the fast lane, intermediate
+// status updates go to the slow lane so completions are recorded first under
+// backlog.
+func enqueueByPriority(enqueue, enqueueSlow func(obj any)) func(obj any) {
+	return func(obj any) {
+		if taskRun, ok := obj.(*pipelinev1beta1.TaskRun); ok && !taskRun.IsDone() && taskRun.DeletionTimestamp == nil {
+			enqueueSlow(obj)
+			return
+		}
+		enqueue(obj)
+	}
+}
@@ -0,0 +1,30 @@
+package taskrun
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestEnqueueByPriority(t *testing.T) {
+	fast, slow := []string{}, []string{}
+	enqueue := enqueueByPriority(func(obj any) {
+		fast = append(fast, obj.(*pipelinev1beta1.TaskRun).Name)
+	}, func(obj any) {
+		slow = append(slow, obj.(*pipelinev1beta1.TaskRun).Name)
+	})
+	for _, taskRun := range []*pipelinev1beta1.TaskRun{
+		testTaskRun("running", false, false),
+		testTaskRun("done", true, false),
+		testTaskRun("deleted", false, true),
+	} {
+		enqueue(taskRun)
+	}
+	if diff := cmp.Diff([]string{"done", "deleted"}, fast); diff != "" {
+		t.Errorf("fast lane (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"running"}, slow); diff != "" {
+		t.Errorf("slow lane (-want, +got):\n%s", diff)
+	}
+}
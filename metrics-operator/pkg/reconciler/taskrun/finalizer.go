@@ -0,0 +1,67 @@
+package taskrun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/logging"
+)
+
+// releaseFinalizer cleans and removes the finalizer left by a previous
+// configuration on a deleted TaskRun when finalizers are disabled, otherwise
+// the deletion would be blocked forever.
+func releaseFinalizer(ctx context.Context, manager *metrics.MetricManager, obj any) {
+	taskRun, ok := obj.(*pipelinev1beta1.TaskRun)
+	if !ok || taskRun.DeletionTimestamp == nil {
+		return
+	}
+	finalizers := sets.NewString(taskRun.Finalizers...)
+	if !finalizers.Has(finalizerName) {
+		return
+	}
+	manager.GetIndex().Clean(ctx, recorder.TaskRunDimensions(taskRun))
+
+	finalizers.Delete(finalizerName)
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"finalizers":      finalizers.List(),
+			"resourceVersion": taskRun.ResourceVersion,
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to build finalizer patch", "error", err)
+		return
+	}
+	_, err = pipelineclient.Get(ctx).TektonV1beta1().TaskRuns(taskRun.Namespace).Patch(ctx, taskRun.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to remove finalizer", "taskRun", taskRun.Name, "error", err)
+	}
+}
+
+// releaseFinalizers releases the finalizers of the cached TaskRuns led by
+// the replica, deleted while another replica led them
+func releaseFinalizers(ctx context.Context, manager *metrics.MetricManager, lister pipelinev1beta1listers.TaskRunLister, leads func(types.NamespacedName) bool, synced ...cache.InformerSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return
+	}
+	taskRuns, err := lister.List(labels.Everything())
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to list TaskRuns to release", "error", err)
+		return
+	}
+	for _, taskRun := range taskRuns {
+		if leads(types.NamespacedName{Namespace: taskRun.Namespace, Name: taskRun.Name}) {
+			releaseFinalizer(ctx, manager, taskRun)
+		}
+	}
+}
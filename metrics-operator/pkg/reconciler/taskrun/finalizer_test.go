@@ -0,0 +1,122 @@
+package taskrun
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	fakepipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client/fake"
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestReleaseFinalizers(t *testing.T) {
+	taskRuns := []*pipelinev1beta1.TaskRun{
+		testTaskRun("led-deleted", true, true),
+		testTaskRun("led-running", false, false),
+		testTaskRun("other-deleted", true, true),
+	}
+	objects := []runtime.Object{}
+	for _, taskRun := range taskRuns {
+		objects = append(objects, taskRun)
+	}
+	ctx, client := fakepipelineclient.With(context.Background(), objects...)
+	manager := metrics.NewManager(view.NewMeter(), nil)
+
+	synced := func() bool { return true }
+	releaseFinalizers(ctx, manager, testLister(t, taskRuns...), bucketOf("led-deleted", "led-running"), synced)
+
+	patched := []string{}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" {
+			patched = append(patched, action.(clienttesting.PatchAction).GetName())
+		}
+	}
+	sort.Strings(patched)
+	if diff := cmp.Diff([]string{"led-deleted"}, patched); diff != "" {
+		t.Errorf("released finalizers (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReleaseFinalizer(t *testing.T) {
+	deleted := testTaskRun("deleted", true, true)
+	deleted.Finalizers = []string{"other.tekton.dev", finalizerName}
+	deleted.ResourceVersion = "42"
+	running := testTaskRun("running", false, false)
+	released := testTaskRun("released", true, true)
+	released.Finalizers = []string{"other.tekton.dev"}
+	ctx, client := fakepipelineclient.With(context.Background(), deleted, running, released)
+	manager := metrics.NewManager(view.NewMeter(), nil)
+
+	for _, taskRun := range []*pipelinev1beta1.TaskRun{deleted, running, released} {
+		releaseFinalizer(ctx, manager, taskRun)
+	}
+
+	patches := map[string]any{}
+	for _, action := range client.Actions() {
+		if patch, ok := action.(clienttesting.PatchAction); ok {
+			var body map[string]any
+			if err := json.Unmarshal(patch.GetPatch(), &body); err != nil {
+				t.Fatal(err)
+			}
+			patches[patch.GetName()] = body
+		}
+	}
+	// only the finalizer of the operator is removed, on deleted runs holding it
+	want := map[string]any{
+		"deleted": map[string]any{"metadata": map[string]any{
+			"finalizers":      []any{"other.tekton.dev"},
+			"resourceVersion": "42",
+		}},
+	}
+	if diff := cmp.Diff(want, patches); diff != "" {
+		t.Errorf("finalizer patches (-want, +got):\n%s", diff)
+	}
+}
+
+func TestFinalizeKind(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	manager := metrics.NewManager(meter, nil)
+	ctx := context.Background()
+	metric := v1alpha1.ExpandPreset(v1alpha1.Metric{Preset: v1alpha1.PresetRunning})
+	gauge := recorder.NewTaskRunGauge(&metric, &v1alpha1.TaskRunMonitor{ObjectMeta: metav1.ObjectMeta{Name: "builds"}})
+	if err := manager.GetIndex().RegisterRunMetric(ctx, gauge); err != nil {
+		t.Fatal(err)
+	}
+	running := func() float64 {
+		t.Helper()
+		rows, err := meter.RetrieveData(gauge.MetricName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("want a single series, got %v", rows)
+		}
+		return rows[0].Data.(*view.LastValueData).Value
+	}
+
+	r := &FinalizingReconciler{Reconciler{manager: manager}}
+	if err := r.ReconcileKind(ctx, testTaskRun("build", false, false)); err != nil {
+		t.Fatal(err)
+	}
+	if got := running(); got != 1 {
+		t.Fatalf("want 1 running, got %v", got)
+	}
+	// the run is finalized once deleted, even if it never completed
+	if err := r.FinalizeKind(ctx, testTaskRun("build", false, true)); err != nil {
+		t.Fatal(err)
+	}
+	if got := running(); got != 0 {
+		t.Errorf("want the series of the deleted run cleaned, got %v running", got)
+	}
+}
@@ -0,0 +1,37 @@
+package taskrun
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+// podLister returns a lister of the pods of TaskRuns, its informer is started
+// and synced with the controller and only caches the pods labeled by Tekton.
+// Runs reconciled before the pods are cached would be skipped as if their pod
+// was gone.
+func podLister(ctx context.Context) corev1listers.PodLister {
+	options := []kubeinformers.SharedInformerOption{
+		kubeinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = "tekton.dev/taskRun"
+		}),
+	}
+	if injection.HasNamespaceScope(ctx) {
+		options = append(options, kubeinformers.WithNamespace(injection.GetNamespaceScope(ctx)))
+	}
+	factory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeclient.Get(ctx), controller.GetResyncPeriod(ctx), options...)
+	pods := factory.Core().V1().Pods()
+	lister := pods.Lister()
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), pods.Informer().HasSynced) {
+		logging.FromContext(ctx).Warn("pods of TaskRuns not cached, they are skipped until the cache is synced")
+	}
+	return lister
+}
@@ -0,0 +1,32 @@
+package taskrun
+
+import (
+	"context"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/informers"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// doneTaskRuns lists the cached done TaskRuns of namespaces not excluded kept by
+// leads, e.g. the runs of a bucket the replica was promoted for
+func doneTaskRuns(ctx context.Context, lister pipelinev1beta1listers.TaskRunLister, leads func(types.NamespacedName) bool) func() ([]*v1alpha1.RunDimensions, error) {
+	tuning := informers.GetTuning(ctx)
+	return func() ([]*v1alpha1.RunDimensions, error) {
+		taskRuns, err := lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		runs := []*v1alpha1.RunDimensions{}
+		for _, taskRun := range taskRuns {
+			key := types.NamespacedName{Namespace: taskRun.Namespace, Name: taskRun.Name}
+			if taskRun.IsDone() && !tuning.NamespaceExcluded(taskRun.Namespace) && leads(key) {
+				runs = append(runs, recorder.TaskRunDimensions(taskRun))
+			}
+		}
+		return runs, nil
+	}
+}
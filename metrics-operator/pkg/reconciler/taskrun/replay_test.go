@@ -0,0 +1,75 @@
+package taskrun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func testTaskRun(name string, done, deleted bool) *pipelinev1beta1.TaskRun {
+	taskRun := &pipelinev1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev", Finalizers: []string{finalizerName}},
+	}
+	if done {
+		taskRun.Status.Status = duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue},
+		}}
+	}
+	if deleted {
+		taskRun.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	}
+	return taskRun
+}
+
+// bucketOf leads the runs of the given names, like a bucket the replica was
+// promoted for
+func bucketOf(names ...string) func(types.NamespacedName) bool {
+	return func(key types.NamespacedName) bool {
+		for _, name := range names {
+			if key.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func testLister(t *testing.T, taskRuns ...*pipelinev1beta1.TaskRun) pipelinev1beta1listers.TaskRunLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, taskRun := range taskRuns {
+		if err := indexer.Add(taskRun); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pipelinev1beta1listers.NewTaskRunLister(indexer)
+}
+
+func TestDoneTaskRuns(t *testing.T) {
+	lister := testLister(t,
+		testTaskRun("led-done", true, false),
+		testTaskRun("led-running", false, false),
+		testTaskRun("other-done", true, false),
+	)
+	runs, err := doneTaskRuns(context.Background(), lister, bucketOf("led-done", "led-running"))()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, run := range runs {
+		names = append(names, run.Name)
+	}
+	if diff := cmp.Diff([]string{"led-done"}, names); diff != "" {
+		t.Errorf("replayed runs (-want, +got):\n%s", diff)
+	}
+}
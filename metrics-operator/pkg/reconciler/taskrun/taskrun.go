@@ -6,21 +6,36 @@ import (
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/pkg/reconciler"
 )
 
 type Reconciler struct {
 	manager *metrics.MetricManager
+	// pods is nil unless pod metrics are enabled
+	pods corev1listers.PodLister
 }
 
 func (r *Reconciler) ReconcileKind(ctx context.Context, taskRun *pipelinev1beta1.TaskRun) reconciler.Event {
+	if r.pods != nil {
+		ctx = recorder.WithPodLister(ctx, r.pods)
+	}
 	if taskRun.IsDone() {
 		return r.manager.RecordTaskRunDone(ctx, taskRun)
 	}
 	return r.manager.RecordTaskRunRunning(ctx, taskRun)
 }
 
-func (r *Reconciler) FinalizeKind(ctx context.Context, taskRun *pipelinev1beta1.TaskRun) reconciler.Event {
+// FinalizingReconciler adds a finalizer to TaskRuns so series are cleaned even
+// when the operator was down while the run was deleted
+type FinalizingReconciler struct {
+	Reconciler
+}
+
+func (r *FinalizingReconciler) FinalizeKind(ctx context.Context, taskRun *pipelinev1beta1.TaskRun) reconciler.Event {
+	if r.pods != nil {
+		ctx = recorder.WithPodLister(ctx, r.pods)
+	}
 	run := recorder.TaskRunDimensions(taskRun)
 	if taskRun.IsDone() {
 		r.manager.GetIndex().Clean(ctx, run)
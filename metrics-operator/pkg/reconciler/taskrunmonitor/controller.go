@@ -2,6 +2,7 @@ package taskrunmonitor
 
 import (
 	"context"
+	"time"
 
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -13,20 +14,21 @@ import (
 	taskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/taskrun"
 )
 
-func NewController(manager *metrics.MetricManager) injection.ControllerConstructor {
+// NewController returns the TaskRunMonitor controller, the metric stats in the status
+// are refreshed every statusInterval
+func NewController(manager *metrics.MetricManager, statusInterval time.Duration) injection.ControllerConstructor {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		taskRunMonitorInformer := taskrunmonitorinformer.Get(ctx)
 		taskRunInformer := taskruninformer.Get(ctx)
 
-		c := &Reconciler{
-			manager:       manager,
-			taskRunLister: taskRunInformer.Lister(),
-		}
+		c := NewReconciler(manager, taskRunInformer.Lister())
+		c.statusInterval = statusInterval
 
 		impl := taskrunmonitorreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			return controller.Options{}
 		})
 		taskRunMonitorInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+		manager.Instrument("taskrunmonitor", impl)
 		return impl
 	}
 }
@@ -3,13 +3,16 @@ package taskrunmonitor
 import (
 	"context"
 	"fmt"
+	"time"
 
 	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
 	taskrunmonitorreconciler "github.com/tektoncd/experimental/metrics-operator/pkg/client/injection/reconciler/monitoring/v1alpha1/taskrunmonitor"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
 	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
 	pipelinev1beta1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 )
@@ -17,6 +20,9 @@ import (
 type Reconciler struct {
 	manager       *metrics.MetricManager
 	taskRunLister pipelinev1beta1listers.TaskRunLister
+	// statusInterval between two refreshes of the metric stats in the status,
+	// zero refreshes them only when the monitor is reconciled
+	statusInterval time.Duration
 }
 
 var (
@@ -24,10 +30,33 @@ var (
 	_ taskrunmonitorreconciler.Interface = (*Reconciler)(nil)
 )
 
+// NewReconciler returns a reconciler registering the metrics of TaskRunMonitors in
+// the manager
+func NewReconciler(manager *metrics.MetricManager, taskRunLister pipelinev1beta1listers.TaskRunLister) *Reconciler {
+	return &Reconciler{
+		manager:       manager,
+		taskRunLister: taskRunLister,
+	}
+}
+
 func (r *Reconciler) ReconcileKind(ctx context.Context, taskRunMonitor *monitoringv1alpha1.TaskRunMonitor) reconciler.Event {
 	logger := logging.FromContext(ctx).With("monitor", taskRunMonitor.Name)
+	// monitors created before the webhook was installed aren't validated
+	if err := taskRunMonitor.Validate(ctx); err != nil {
+		logger.Errorw("invalid monitor", "error", err)
+		taskRunMonitor.Status.ObservedGeneration = taskRunMonitor.Generation
+		monitoringv1alpha1.SetReadyCondition(&taskRunMonitor.Status.Status, "InvalidSpec", err)
+		return controller.NewPermanentError(err)
+	}
 	latestMetrics := sets.NewString()
+	r.manager.GetIndex().SetMonitorReference(resource, taskRunMonitor.Name, metrics.MonitorReference("TaskRunMonitor", taskRunMonitor))
 	for _, metric := range taskRunMonitor.Spec.Metrics {
+		metric = monitoringv1alpha1.ExpandGranularity(monitoringv1alpha1.ExpandPreset(metric))
+		metric.SetDefaults(ctx)
+		if len(metric.Reasons) == 0 {
+			metric.Reasons = taskRunMonitor.Spec.Reasons
+		}
+		metric.Tags = monitoringv1alpha1.MergeTags(taskRunMonitor.Spec.CommonTags, metric.Tags)
 		var runMetric metrics.RunMetric
 		// TODO: fail if type is invalid
 		switch metric.Type {
@@ -37,6 +66,16 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, taskRunMonitor *monitori
 			runMetric = recorder.NewTaskRunHistogram(metric.DeepCopy(), taskRunMonitor)
 		case "gauge":
 			runMetric = recorder.NewTaskRunGauge(metric.DeepCopy(), taskRunMonitor)
+		case "timeoutRatio":
+			runMetric = recorder.NewTaskRunTimeoutRatio(metric.DeepCopy(), taskRunMonitor)
+		case "durationBreakdown":
+			runMetric = recorder.NewTaskRunDurationBreakdown(metric.DeepCopy(), taskRunMonitor)
+		case "lastValue":
+			runMetric = recorder.NewTaskRunLastValue(metric.DeepCopy(), taskRunMonitor)
+		case "rate":
+			runMetric = recorder.NewTaskRunRate(metric.DeepCopy(), taskRunMonitor)
+		case "slo":
+			runMetric = recorder.NewTaskRunSLO(metric.DeepCopy(), taskRunMonitor)
 		default:
 			logger.Errorw("invalid metric type", "metric", metric.Name, "type", metric.Type)
 			return fmt.Errorf("invalid metric type: %q", metric.Type)
@@ -60,6 +99,14 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, taskRunMonitor *monitori
 		}
 	}
 
+	taskRunMonitor.Status.ObservedGeneration = taskRunMonitor.Generation
+	taskRunMonitor.Status.Metrics = r.manager.GetIndex().MonitorStatus(resource, taskRunMonitor.Name)
+	monitoringv1alpha1.SetReadyCondition(&taskRunMonitor.Status.Status, "", nil)
+	monitoringv1alpha1.SetDegradedCondition(&taskRunMonitor.Status.Status, taskRunMonitor.Status.Metrics)
+	if r.statusInterval > 0 {
+		// stats change with every recorded run, not only with the monitor
+		return controller.NewRequeueAfter(r.statusInterval)
+	}
 	return nil
 }
 
@@ -70,3 +117,22 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, taskRunMonitor *monitorin
 	}
 	return nil
 }
+
+var _ taskrunmonitorreconciler.ReadOnlyInterface = (*Reconciler)(nil)
+
+// ObserveKind registers the metrics of the TaskRunMonitor on the replicas not leading
+// it, so they record runs as soon as they are promoted. The status is only
+// written by the leader.
+func (r *Reconciler) ObserveKind(ctx context.Context, taskRunMonitor *monitoringv1alpha1.TaskRunMonitor) reconciler.Event {
+	event := r.ReconcileKind(ctx, taskRunMonitor.DeepCopy())
+	if requeue, _ := controller.IsRequeueKey(event); requeue {
+		return nil
+	}
+	return event
+}
+
+// ObserveDeletion unregisters the metrics of a deleted TaskRunMonitor, only the
+// leader finalizes it
+func (r *Reconciler) ObserveDeletion(ctx context.Context, key types.NamespacedName) error {
+	return r.manager.GetIndex().UnregisterAllMetricsMonitor(resource, key.Name)
+}
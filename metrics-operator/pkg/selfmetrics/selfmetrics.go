@@ -0,0 +1,132 @@
+package selfmetrics
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// Measures about the operator itself, exported next to the metrics defined by
+// monitors.
+var (
+	RetryQueueDepth   = stats.Int64("metrics_operator_retry_queue_depth", "Number of recordings waiting to be retried", stats.UnitDimensionless)
+	DurationAnomalies = stats.Int64("metrics_operator_duration_anomalies_total", "Number of negative durations measured, e.g. on clock skew", stats.UnitDimensionless)
+	ActiveSeries      = stats.Int64("metrics_operator_active_series", "Number of series of a monitor metric", stats.UnitDimensionless)
+	EvaluationLatency = stats.Float64("metrics_operator_evaluation_latency_seconds", "Time spent evaluating the filters, expressions and tags of a monitor metric", stats.UnitSeconds)
+	RecordingLag      = stats.Float64("metrics_operator_recording_lag_seconds", "Time from the completion of a run to its recording by a monitor", stats.UnitSeconds)
+	ReconcileDuration = stats.Float64("metrics_operator_reconcile_duration_seconds", "Time spent reconciling a key by a controller of the operator", stats.UnitSeconds)
+	QueueDepth        = stats.Int64("metrics_operator_queue_depth", "Number of keys waiting in the work queue of a controller", stats.UnitDimensionless)
+	Recordings        = stats.Int64("metrics_operator_recordings_total", "Number of runs recorded by a monitor metric, by result", stats.UnitDimensionless)
+	RegisteredViews   = stats.Int64("metrics_operator_registered_views", "Number of views registered for the metrics of monitors", stats.UnitDimensionless)
+	RunningElapsed    = stats.Float64("metrics_operator_running_elapsed_seconds", "Time elapsed since the start of the in-flight runs, summed by resource", stats.UnitSeconds)
+	OldestRunningAge  = stats.Float64("metrics_operator_oldest_running_age_seconds", "Time elapsed since the creation of the oldest in-flight run of a resource", stats.UnitSeconds)
+)
+
+var (
+	// MetricKey tags operator measurements with the name of the monitor metric
+	MetricKey = tag.MustNewKey("metric")
+	// MonitorKey tags operator measurements with the id of the monitor
+	MonitorKey = tag.MustNewKey("monitor")
+	// StageKey tags evaluation latencies with the evaluated stage
+	StageKey = tag.MustNewKey("stage")
+	// ReconcilerKey tags controller measurements with the reconciled resource
+	ReconcilerKey = tag.MustNewKey("reconciler")
+	// ResultKey tags reconciles and recordings with their result
+	ResultKey = tag.MustNewKey("result")
+	// ResourceKey tags in-flight run measurements with the run resource
+	ResourceKey = tag.MustNewKey("resource")
+)
+
+// Results of reconciles and recordings
+const (
+	ResultSuccess = "success"
+	ResultSkipped = "skipped"
+	ResultFailure = "failure"
+)
+
+// Views returns the views of every operator measure.
+func Views() []*view.View {
+	return []*view.View{
+		{
+			Description: RetryQueueDepth.Description(),
+			Measure:     RetryQueueDepth,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Description: DurationAnomalies.Description(),
+			Measure:     DurationAnomalies,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{MetricKey},
+		},
+		{
+			Description: ActiveSeries.Description(),
+			Measure:     ActiveSeries,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{MonitorKey, MetricKey},
+		},
+		{
+			Description: EvaluationLatency.Description(),
+			Measure:     EvaluationLatency,
+			Aggregation: view.Distribution(.00001, .00005, .0001, .0005, .001, .005, .01, .05, .1, .5, 1),
+			TagKeys:     []tag.Key{MetricKey, StageKey},
+		},
+		{
+			Description: RecordingLag.Description(),
+			Measure:     RecordingLag,
+			Aggregation: view.Distribution(.1, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600),
+			TagKeys:     []tag.Key{MonitorKey},
+		},
+		{
+			Description: ReconcileDuration.Description(),
+			Measure:     ReconcileDuration,
+			Aggregation: view.Distribution(.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10),
+			TagKeys:     []tag.Key{ReconcilerKey, ResultKey},
+		},
+		{
+			Description: QueueDepth.Description(),
+			Measure:     QueueDepth,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{ReconcilerKey},
+		},
+		{
+			Description: Recordings.Description(),
+			Measure:     Recordings,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{MonitorKey, MetricKey, ResultKey},
+		},
+		{
+			Description: RegisteredViews.Description(),
+			Measure:     RegisteredViews,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Description: RunningElapsed.Description(),
+			Measure:     RunningElapsed,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{ResourceKey},
+		},
+		{
+			Description: OldestRunningAge.Description(),
+			Measure:     OldestRunningAge,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{ResourceKey},
+		},
+	}
+}
+
+// Register registers the operator views on the meter.
+func Register(meter view.Meter) error {
+	return meter.Register(Views()...)
+}
+
+// Record records operator measurements with the given tags.
+func Record(recorder stats.Recorder, mutators []tag.Mutator, measurements ...stats.Measurement) error {
+	ctx, err := tag.New(context.Background(), mutators...)
+	if err != nil {
+		return err
+	}
+	recorder.Record(tag.FromContext(ctx), measurements, map[string]any{})
+	return nil
+}
@@ -0,0 +1,482 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"contrib.go.opencensus.io/exporter/ocagent"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
+)
+
+// Keys of the knative config-observability ConfigMap honored by the exporter
+// of monitor metrics
+const (
+	BackendDestinationKey = "metrics.backend-destination"
+	ReportingPeriodKey    = "metrics.reporting-period-seconds"
+	PrometheusHostKey     = "metrics.prometheus-host"
+	PrometheusPortKey     = "metrics.prometheus-port"
+	OpenCensusAddressKey  = "metrics.opencensus-address"
+	OTLPEndpointKey       = "metrics.otlp-endpoint"
+	OTLPProtocolKey       = "metrics.otlp-protocol"
+	OTLPInsecureKey       = "metrics.otlp-insecure"
+	StatsDHostKey         = "metrics.statsd-host"
+	StatsDPortKey         = "metrics.statsd-port"
+	StatsDPrefixKey       = "metrics.statsd-prefix"
+	StatsDFlavorKey       = "metrics.statsd-flavor"
+
+	StackdriverProjectKey      = "metrics.stackdriver-project-id"
+	StackdriverLocationKey     = "metrics.stackdriver-gcp-location"
+	StackdriverClusterKey      = "metrics.stackdriver-cluster-name"
+	StackdriverResourceTypeKey = "metrics.stackdriver-resource-type"
+)
+
+const (
+	BackendPrometheus  = "prometheus"
+	BackendOpenCensus  = "opencensus"
+	BackendOTLP        = "otlp"
+	BackendStatsD      = "statsd"
+	BackendStackdriver = "stackdriver"
+	BackendNone        = "none"
+)
+
+// ObservabilityConfig describes where monitor metrics are exported
+type ObservabilityConfig struct {
+	Backend           string
+	ReportingPeriod   time.Duration
+	PrometheusHost    string
+	PrometheusPort    int
+	OpenCensusAddress string
+	OTLPEndpoint      string
+	OTLPProtocol      string
+	OTLPInsecure      bool
+	// TLS of the OTLP connection, only set by MetricsExporters
+	OTLPInsecureSkipVerify bool
+	OTLPCABundle           string
+	OTLPServerName         string
+
+	StatsDHost   string
+	StatsDPort   int
+	StatsDPrefix string
+	StatsDFlavor string
+
+	StackdriverProject      string
+	StackdriverLocation     string
+	StackdriverCluster      string
+	StackdriverResourceType string
+
+	// OpenMetrics with the exemplars of histograms on the Prometheus
+	// endpoint, only set by the --exemplars flag
+	PrometheusExemplars bool
+}
+
+// DefaultObservabilityConfig exports monitor metrics with Prometheus on port 2112
+func DefaultObservabilityConfig() *ObservabilityConfig {
+	return &ObservabilityConfig{
+		Backend:        BackendPrometheus,
+		PrometheusHost: "0.0.0.0",
+		PrometheusPort: 2112,
+		OTLPProtocol:   OTLPProtocolGRPC,
+		StatsDHost:     "localhost",
+		StatsDPort:     8125,
+		StatsDFlavor:   StatsDFlavorDogStatsD,
+
+		StackdriverResourceType: StackdriverResourceCluster,
+	}
+}
+
+// Validate checks the backend, the OTLP protocol, the StatsD flavor and the
+// Stackdriver resource type are supported
+func (c *ObservabilityConfig) Validate() error {
+	switch c.Backend {
+	case BackendPrometheus, BackendOpenCensus, BackendOTLP, BackendStatsD, BackendStackdriver, BackendNone:
+	default:
+		return fmt.Errorf("unsupported metrics backend %q", c.Backend)
+	}
+	switch c.OTLPProtocol {
+	case OTLPProtocolGRPC, OTLPProtocolHTTP:
+	default:
+		return fmt.Errorf("unsupported OTLP protocol %q", c.OTLPProtocol)
+	}
+	switch c.StatsDFlavor {
+	case StatsDFlavorStatsD, StatsDFlavorDogStatsD:
+	default:
+		return fmt.Errorf("unsupported StatsD flavor %q", c.StatsDFlavor)
+	}
+	switch c.StackdriverResourceType {
+	case StackdriverResourceCluster, StackdriverResourceTask:
+	default:
+		return fmt.Errorf("unsupported Stackdriver resource type %q", c.StackdriverResourceType)
+	}
+	return nil
+}
+
+// NewObservabilityConfigFromMap reads the config-observability data, missing
+// keys keep their default value
+func NewObservabilityConfigFromMap(data map[string]string) (*ObservabilityConfig, error) {
+	return NewObservabilityConfigFromMapWithDefaults(DefaultObservabilityConfig(), data)
+}
+
+// NewObservabilityConfigFromMapWithDefaults reads the config-observability
+// data, missing keys keep the value of the given defaults
+func NewObservabilityConfigFromMapWithDefaults(defaults *ObservabilityConfig, data map[string]string) (*ObservabilityConfig, error) {
+	config := *defaults
+	if backend, ok := data[BackendDestinationKey]; ok && backend != "" {
+		config.Backend = backend
+	}
+	if protocol, ok := data[OTLPProtocolKey]; ok && protocol != "" {
+		config.OTLPProtocol = protocol
+	}
+	if flavor, ok := data[StatsDFlavorKey]; ok && flavor != "" {
+		config.StatsDFlavor = flavor
+	}
+	if resourceType, ok := data[StackdriverResourceTypeKey]; ok && resourceType != "" {
+		config.StackdriverResourceType = resourceType
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if period, ok := data[ReportingPeriodKey]; ok && period != "" {
+		seconds, err := strconv.Atoi(period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", ReportingPeriodKey, period, err)
+		}
+		config.ReportingPeriod = time.Duration(seconds) * time.Second
+	}
+	if host, ok := data[PrometheusHostKey]; ok && host != "" {
+		config.PrometheusHost = host
+	}
+	if port, ok := data[PrometheusPortKey]; ok && port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", PrometheusPortKey, port, err)
+		}
+		config.PrometheusPort = p
+	}
+	if address, ok := data[OpenCensusAddressKey]; ok && address != "" {
+		config.OpenCensusAddress = address
+	}
+	if endpoint, ok := data[OTLPEndpointKey]; ok && endpoint != "" {
+		config.OTLPEndpoint = endpoint
+	}
+	if insecure, ok := data[OTLPInsecureKey]; ok && insecure != "" {
+		b, err := strconv.ParseBool(insecure)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", OTLPInsecureKey, insecure, err)
+		}
+		config.OTLPInsecure = b
+	}
+	if host, ok := data[StatsDHostKey]; ok && host != "" {
+		config.StatsDHost = host
+	}
+	if port, ok := data[StatsDPortKey]; ok && port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", StatsDPortKey, port, err)
+		}
+		config.StatsDPort = p
+	}
+	if prefix, ok := data[StatsDPrefixKey]; ok && prefix != "" {
+		config.StatsDPrefix = prefix
+	}
+	if project, ok := data[StackdriverProjectKey]; ok && project != "" {
+		config.StackdriverProject = project
+	}
+	if location, ok := data[StackdriverLocationKey]; ok && location != "" {
+		config.StackdriverLocation = location
+	}
+	if cluster, ok := data[StackdriverClusterKey]; ok && cluster != "" {
+		config.StackdriverCluster = cluster
+	}
+	return &config, nil
+}
+
+// shutdownTimeout bounds the time given to in-flight scrapes when the
+// exporter stops
+const shutdownTimeout = 5 * time.Second
+
+// ObservedExporter exports the meter to the backend of the latest applied
+// config, replacing the exporter when the config changes
+type ObservedExporter struct {
+	meter    view.Meter
+	defaults ObservabilityConfig
+	config   ObservabilityConfig
+	exporter view.Exporter
+	stop     func(context.Context)
+	// attached exporters by name, e.g. of MetricsExporters
+	attached map[string]attachedExporter
+	logger   atomic.Pointer[zap.SugaredLogger]
+	mu       sync.Mutex
+}
+
+type attachedExporter struct {
+	config   ObservabilityConfig
+	interval time.Duration
+	exporter view.Exporter
+	stop     func(context.Context)
+}
+
+// NewObservedExporter creates an exporter of the meter, keys missing from the
+// config-observability ConfigMap keep the value of the given defaults
+func NewObservedExporter(meter view.Meter, defaults *ObservabilityConfig) *ObservedExporter {
+	e := &ObservedExporter{meter: meter, defaults: *defaults, attached: map[string]attachedExporter{}}
+	e.logger.Store(zap.NewNop().Sugar())
+	return e
+}
+
+// Run logs the errors of the exporter with the logger of the context and
+// stops it once the context is done, letting in-flight scrapes complete
+func (e *ObservedExporter) Run(ctx context.Context) {
+	e.logger.Store(logging.FromContext(ctx))
+
+	<-ctx.Done()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdown()
+	for name := range e.attached {
+		e.detach(name)
+	}
+}
+
+// Apply switches to the exporter described by the config, a no-op when the
+// config didn't change. The current exporter keeps running when the new one
+// can't be created.
+func (e *ObservedExporter) Apply(config *ObservabilityConfig) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.exporter != nil && e.config == *config {
+		return nil
+	}
+
+	previous, stopped := e.config, false
+	if config.Backend == BackendPrometheus && e.exporter != nil && previous.Backend == BackendPrometheus {
+		// the previous server may hold the port
+		e.shutdown()
+		stopped = true
+	}
+	exporter, stop, err := e.newExporter(config, config.ReportingPeriod)
+	if err != nil {
+		if stopped {
+			if restoreErr := e.start(&previous); restoreErr != nil {
+				e.logger.Load().Errorw("previous exporter could not be restored", zap.Error(restoreErr))
+			}
+		}
+		return err
+	}
+	e.shutdown()
+	e.register(config, exporter, stop)
+	return nil
+}
+
+// start creates and registers the exporter of the config, the lock must be
+// held
+func (e *ObservedExporter) start(config *ObservabilityConfig) error {
+	exporter, stop, err := e.newExporter(config, config.ReportingPeriod)
+	if err != nil {
+		return err
+	}
+	e.register(config, exporter, stop)
+	return nil
+}
+
+// register makes the exporter the current one, the lock must be held
+func (e *ObservedExporter) register(config *ObservabilityConfig, exporter view.Exporter, stop func(context.Context)) {
+	if exporter != nil {
+		e.meter.RegisterExporter(exporter)
+	}
+	if config.ReportingPeriod > 0 {
+		e.meter.SetReportingPeriod(config.ReportingPeriod)
+	}
+	e.exporter, e.stop, e.config = exporter, stop, *config
+}
+
+// Attach adds a named exporter next to the exporter of the ConfigMap,
+// replacing the exporter of the same name when the config changed. The
+// reports of the meter are forwarded at most once per interval.
+func (e *ObservedExporter) Attach(name string, config *ObservabilityConfig, interval time.Duration) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	current, ok := e.attached[name]
+	if ok && current.config == *config && current.interval == interval {
+		return nil
+	}
+
+	if ok && config.Backend == BackendPrometheus {
+		// the previous server may hold the port
+		e.detach(name)
+	}
+	exporter, stop, err := e.newExporter(config, interval)
+	if err != nil {
+		return err
+	}
+	e.detach(name)
+	if exporter == nil {
+		return nil
+	}
+	if interval > 0 {
+		exporter = newThrottledExporter(exporter, interval)
+	}
+	e.meter.RegisterExporter(exporter)
+	e.attached[name] = attachedExporter{config: *config, interval: interval, exporter: exporter, stop: stop}
+	return nil
+}
+
+// Detach stops the named exporter, a no-op when it isn't attached
+func (e *ObservedExporter) Detach(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.detach(name)
+}
+
+// newExporter creates the exporter of the backend, nil for none
+func (e *ObservedExporter) newExporter(config *ObservabilityConfig, interval time.Duration) (view.Exporter, func(context.Context), error) {
+	switch config.Backend {
+	case BackendPrometheus:
+		server, err := NewPrometheusExporter(&MetricConfig{
+			PrometheusHost: config.PrometheusHost,
+			PrometheusPort: config.PrometheusPort,
+			Exemplars:      config.PrometheusExemplars,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := server.Listen(); err != nil {
+			return nil, nil, fmt.Errorf("failed to listen on %s:%d: %w", config.PrometheusHost, config.PrometheusPort, err)
+		}
+		go func() {
+			if err := server.Start(); err != nil {
+				e.logger.Load().Errorw("prometheus endpoint stopped", zap.Error(err))
+			}
+		}()
+		return server.GetExporter(), func(ctx context.Context) { server.Shutdown(ctx) }, nil
+	case BackendOpenCensus:
+		options := []ocagent.ExporterOption{ocagent.WithInsecure(), ocagent.WithServiceName("metrics-operator")}
+		if config.OpenCensusAddress != "" {
+			options = append(options, ocagent.WithAddress(config.OpenCensusAddress))
+		}
+		agent, err := ocagent.NewExporter(options...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return agent, func(context.Context) { agent.Stop() }, nil
+	case BackendOTLP:
+		otlp, err := NewOTLPExporter(context.Background(), &OTLPConfig{
+			Endpoint:           config.OTLPEndpoint,
+			Protocol:           config.OTLPProtocol,
+			Insecure:           config.OTLPInsecure,
+			InsecureSkipVerify: config.OTLPInsecureSkipVerify,
+			CABundle:           config.OTLPCABundle,
+			ServerName:         config.OTLPServerName,
+		}, func(err error) {
+			e.logger.Load().Errorw("failed to push monitor metrics", zap.Error(err))
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return otlp, otlp.Stop, nil
+	case BackendStatsD:
+		statsd, err := NewStatsDExporter(&StatsDConfig{
+			Host:   config.StatsDHost,
+			Port:   config.StatsDPort,
+			Prefix: config.StatsDPrefix,
+			Flavor: config.StatsDFlavor,
+		}, func(err error) {
+			e.logger.Load().Errorw("failed to push monitor metrics", zap.Error(err))
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return statsd, func(context.Context) { statsd.Stop() }, nil
+	case BackendStackdriver:
+		stackdriver, err := NewStackdriverExporter(context.Background(), &StackdriverConfig{
+			Project:         config.StackdriverProject,
+			Location:        config.StackdriverLocation,
+			Cluster:         config.StackdriverCluster,
+			ResourceType:    config.StackdriverResourceType,
+			SystemNamespace: os.Getenv(system.NamespaceEnvKey),
+			Interval:        interval,
+		}, func(err error) {
+			e.logger.Load().Errorw("failed to write monitor metrics", zap.Error(err))
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return stackdriver, stackdriver.Stop, nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+// viewPurger is implemented by exporters keeping series between two reports,
+// e.g. the previous values of counters pushed as deltas
+type viewPurger interface {
+	PurgeViews(names ...string)
+}
+
+// PurgeViews drops the series kept by the current and attached exporters for
+// the given views, called once the views are unregistered
+func (e *ObservedExporter) PurgeViews(names ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	exporters := []view.Exporter{e.exporter}
+	for _, attached := range e.attached {
+		exporters = append(exporters, attached.exporter)
+	}
+	for _, exporter := range exporters {
+		if purger, ok := exporter.(viewPurger); ok {
+			purger.PurgeViews(names...)
+		}
+	}
+}
+
+// shutdown stops the current exporter, the lock must be held
+func (e *ObservedExporter) shutdown() {
+	stopExporter(e.meter, e.exporter, e.stop)
+	e.exporter, e.stop = nil, nil
+}
+
+// detach stops the named exporter, the lock must be held
+func (e *ObservedExporter) detach(name string) {
+	if attached, ok := e.attached[name]; ok {
+		stopExporter(e.meter, attached.exporter, attached.stop)
+		delete(e.attached, name)
+	}
+}
+
+func stopExporter(meter view.Meter, exporter view.Exporter, stop func(context.Context)) {
+	if exporter != nil {
+		meter.UnregisterExporter(exporter)
+	}
+	if stop != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		stop(ctx)
+	}
+}
+
+// Watch returns a ConfigMap watcher applying the config-observability
+func (e *ObservedExporter) Watch(logger *zap.SugaredLogger) func(*corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		config, err := NewObservabilityConfigFromMapWithDefaults(&e.defaults, configMap.Data)
+		if err != nil {
+			logger.Errorw("invalid observability config, keeping the current exporter", zap.Error(err))
+			return
+		}
+		if err := e.Apply(config); err != nil {
+			logger.Errorw("failed to apply observability config", zap.Error(err))
+			return
+		}
+		logger.Infow("observability config applied", zap.String("backend", config.Backend))
+	}
+}
@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestObservabilityConfigDefaults(t *testing.T) {
+	defaults := DefaultObservabilityConfig()
+	defaults.PrometheusPort = 9090
+
+	config, err := NewObservabilityConfigFromMapWithDefaults(defaults, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.PrometheusPort != 9090 {
+		t.Errorf("expected the default port 9090, got %d", config.PrometheusPort)
+	}
+
+	config, err = NewObservabilityConfigFromMapWithDefaults(defaults, map[string]string{PrometheusPortKey: "9091"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.PrometheusPort != 9091 {
+		t.Errorf("expected the configured port 9091, got %d", config.PrometheusPort)
+	}
+	if defaults.PrometheusPort != 9090 {
+		t.Errorf("defaults were modified")
+	}
+
+	if _, err := NewObservabilityConfigFromMapWithDefaults(defaults, map[string]string{BackendDestinationKey: "graphite"}); err == nil {
+		t.Errorf("expected an error for an unsupported backend")
+	}
+}
+
+func TestObservedExporterRun(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+
+	config := DefaultObservabilityConfig()
+	config.PrometheusHost = "127.0.0.1"
+	config.PrometheusPort = 21120
+	exporter := NewObservedExporter(meter, config)
+	if err := exporter.Apply(config); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(ctx)
+		close(done)
+	}()
+
+	response, err := http.Get("http://127.0.0.1:21120/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", response.StatusCode)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("exporter didn't stop with its context")
+	}
+	if _, err := http.Get("http://127.0.0.1:21120/metrics"); err == nil {
+		t.Errorf("expected the endpoint to be closed")
+	}
+}
+
+func TestConvertView(t *testing.T) {
+	key := tag.MustNewKey("status")
+	measure := stats.Float64("duration", "", stats.UnitSeconds)
+	distribution := view.Distribution(1, 10)
+	data := &view.Data{
+		View: &view.View{Name: "duration", Measure: measure, Aggregation: distribution, TagKeys: []tag.Key{key}},
+		Rows: []*view.Row{{
+			Tags: []tag.Tag{{Key: key, Value: "success"}},
+			Data: &view.DistributionData{Count: 2, Min: 0.5, Max: 5, Mean: 2.75, CountPerBucket: []int64{1, 1, 0}},
+		}},
+	}
+
+	metric, ok := convertView(data)
+	if !ok {
+		t.Fatal("expected the distribution to be converted")
+	}
+	histogram, ok := metric.Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("expected a histogram, got %T", metric.Data)
+	}
+	if len(histogram.DataPoints) != 1 {
+		t.Fatalf("expected 1 data point, got %d", len(histogram.DataPoints))
+	}
+	point := histogram.DataPoints[0]
+	if point.Count != 2 || point.Sum != 5.5 {
+		t.Errorf("expected count 2 and sum 5.5, got %d and %f", point.Count, point.Sum)
+	}
+	if value, _ := point.Attributes.Value("status"); value.AsString() != "success" {
+		t.Errorf("expected the status attribute, got %v", point.Attributes)
+	}
+	if metric.Unit != stats.UnitSeconds {
+		t.Errorf("expected unit %s, got %s", stats.UnitSeconds, metric.Unit)
+	}
+}
+
+func TestObservedExporterAttach(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+
+	exporter := NewObservedExporter(meter, DefaultObservabilityConfig())
+	config := DefaultObservabilityConfig()
+	config.PrometheusHost = "127.0.0.1"
+	config.PrometheusPort = 21121
+	if err := exporter.Attach("metricsexporter/scraped", config, 0); err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.Get("http://127.0.0.1:21121/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	// the same port can't be attached twice
+	if err := exporter.Attach("metricsexporter/other", config, 0); err == nil {
+		t.Errorf("expected an error for a port in use")
+	}
+
+	exporter.Detach("metricsexporter/scraped")
+	if _, err := http.Get("http://127.0.0.1:21121/metrics"); err == nil {
+		t.Errorf("expected the endpoint to be closed")
+	}
+}
+
+func TestNewObservabilityConfigFromMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    func(config *ObservabilityConfig)
+		wantErr bool
+	}{{
+		name: "defaults",
+		data: map[string]string{},
+		want: func(*ObservabilityConfig) {},
+	}, {
+		name: "empty values keep the defaults",
+		data: map[string]string{BackendDestinationKey: "", PrometheusPortKey: ""},
+		want: func(*ObservabilityConfig) {},
+	}, {
+		name: "prometheus",
+		data: map[string]string{PrometheusHostKey: "127.0.0.1", PrometheusPortKey: "9090", ReportingPeriodKey: "30"},
+		want: func(config *ObservabilityConfig) {
+			config.PrometheusHost = "127.0.0.1"
+			config.PrometheusPort = 9090
+			config.ReportingPeriod = 30 * time.Second
+		},
+	}, {
+		name: "opencensus",
+		data: map[string]string{BackendDestinationKey: "opencensus", OpenCensusAddressKey: "collector:55678"},
+		want: func(config *ObservabilityConfig) {
+			config.Backend = BackendOpenCensus
+			config.OpenCensusAddress = "collector:55678"
+		},
+	}, {
+		name: "otlp",
+		data: map[string]string{BackendDestinationKey: "otlp", OTLPEndpointKey: "collector:4318", OTLPProtocolKey: "http", OTLPInsecureKey: "true"},
+		want: func(config *ObservabilityConfig) {
+			config.Backend = BackendOTLP
+			config.OTLPEndpoint = "collector:4318"
+			config.OTLPProtocol = OTLPProtocolHTTP
+			config.OTLPInsecure = true
+		},
+	}, {
+		name: "statsd",
+		data: map[string]string{BackendDestinationKey: "statsd", StatsDHostKey: "agent", StatsDPortKey: "9125", StatsDPrefixKey: "tekton", StatsDFlavorKey: "statsd"},
+		want: func(config *ObservabilityConfig) {
+			config.Backend = BackendStatsD
+			config.StatsDHost = "agent"
+			config.StatsDPort = 9125
+			config.StatsDPrefix = "tekton"
+			config.StatsDFlavor = StatsDFlavorStatsD
+		},
+	}, {
+		name: "stackdriver",
+		data: map[string]string{BackendDestinationKey: "stackdriver", StackdriverProjectKey: "project", StackdriverLocationKey: "europe-west1", StackdriverClusterKey: "ci", StackdriverResourceTypeKey: StackdriverResourceTask},
+		want: func(config *ObservabilityConfig) {
+			config.Backend = BackendStackdriver
+			config.StackdriverProject = "project"
+			config.StackdriverLocation = "europe-west1"
+			config.StackdriverCluster = "ci"
+			config.StackdriverResourceType = StackdriverResourceTask
+		},
+	}, {
+		name: "none",
+		data: map[string]string{BackendDestinationKey: "none"},
+		want: func(config *ObservabilityConfig) {
+			config.Backend = BackendNone
+		},
+	}, {
+		name:    "unsupported backend",
+		data:    map[string]string{BackendDestinationKey: "graphite"},
+		wantErr: true,
+	}, {
+		name:    "unsupported otlp protocol",
+		data:    map[string]string{OTLPProtocolKey: "thrift"},
+		wantErr: true,
+	}, {
+		name:    "unsupported statsd flavor",
+		data:    map[string]string{StatsDFlavorKey: "graphite"},
+		wantErr: true,
+	}, {
+		name:    "unsupported stackdriver resource type",
+		data:    map[string]string{StackdriverResourceTypeKey: "gce_instance"},
+		wantErr: true,
+	}, {
+		name:    "invalid reporting period",
+		data:    map[string]string{ReportingPeriodKey: "30s"},
+		wantErr: true,
+	}, {
+		name:    "invalid prometheus port",
+		data:    map[string]string{PrometheusPortKey: "http"},
+		wantErr: true,
+	}, {
+		name:    "invalid otlp insecure",
+		data:    map[string]string{OTLPInsecureKey: "maybe"},
+		wantErr: true,
+	}, {
+		name:    "invalid statsd port",
+		data:    map[string]string{StatsDPortKey: "udp"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewObservabilityConfigFromMap(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := DefaultObservabilityConfig()
+			tt.want(want)
+			if *config != *want {
+				t.Errorf("expected %+v, got %+v", want, config)
+			}
+		})
+	}
+}
+
+func TestObservedExporterApply(t *testing.T) {
+	// a port held by another server
+	taken, err := net.Listen("tcp", "127.0.0.1:21124")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	prometheus := func(port int) *ObservabilityConfig {
+		config := DefaultObservabilityConfig()
+		config.PrometheusHost = "127.0.0.1"
+		config.PrometheusPort = port
+		return config
+	}
+	none := DefaultObservabilityConfig()
+	none.Backend = BackendNone
+	serving := func(port int) bool {
+		response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", port))
+		if err != nil {
+			return false
+		}
+		response.Body.Close()
+		return response.StatusCode == http.StatusOK
+	}
+
+	tests := []struct {
+		name        string
+		apply       *ObservabilityConfig
+		wantErr     bool
+		wantBackend string
+		// wantServing and wantClosed are the ports expected to serve and
+		// to be closed after the apply
+		wantServing []int
+		wantClosed  []int
+	}{{
+		name:        "unchanged config",
+		apply:       prometheus(21122),
+		wantBackend: BackendPrometheus,
+		wantServing: []int{21122},
+	}, {
+		name:        "prometheus port change",
+		apply:       prometheus(21123),
+		wantBackend: BackendPrometheus,
+		wantServing: []int{21123},
+		wantClosed:  []int{21122},
+	}, {
+		name:        "port in use restores the previous endpoint",
+		apply:       prometheus(21124),
+		wantErr:     true,
+		wantBackend: BackendPrometheus,
+		wantServing: []int{21123},
+	}, {
+		name:        "switch to none",
+		apply:       none,
+		wantBackend: BackendNone,
+		wantClosed:  []int{21123},
+	}, {
+		name:        "switch back to prometheus",
+		apply:       prometheus(21122),
+		wantBackend: BackendPrometheus,
+		wantServing: []int{21122},
+	}}
+
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	exporter := NewObservedExporter(meter, DefaultObservabilityConfig())
+	if err := exporter.Apply(prometheus(21122)); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		exporter.mu.Lock()
+		defer exporter.mu.Unlock()
+		exporter.shutdown()
+	}()
+	// the steps depend on the exporter left by the previous ones
+	for _, tt := range tests {
+		err := exporter.Apply(tt.apply)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+		exporter.mu.Lock()
+		backend := exporter.config.Backend
+		exporter.mu.Unlock()
+		if backend != tt.wantBackend {
+			t.Errorf("%s: expected the %s backend, got %s", tt.name, tt.wantBackend, backend)
+		}
+		for _, port := range tt.wantServing {
+			if !serving(port) {
+				t.Errorf("%s: expected port %d to serve metrics", tt.name, port)
+			}
+		}
+		for _, port := range tt.wantClosed {
+			if serving(port) {
+				t.Errorf("%s: expected port %d to be closed", tt.name, port)
+			}
+		}
+	}
+}
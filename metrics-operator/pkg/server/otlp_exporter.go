@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
+// otlpPushTimeout bounds a push, views are reported by the meter goroutine
+const otlpPushTimeout = 10 * time.Second
+
+type OTLPConfig struct {
+	// host:port of the collector, the OTLP default when empty
+	Endpoint string
+
+	// grpc or http
+	Protocol string
+
+	Insecure bool
+
+	// InsecureSkipVerify, CABundle and ServerName configure TLS, the system
+	// roots verify the collector when unset
+	InsecureSkipVerify bool
+	CABundle           string
+	ServerName         string
+}
+
+// tlsConfig returns the TLS config of the connection, nil for the defaults
+func (c *OTLPConfig) tlsConfig() (*tls.Config, error) {
+	if !c.InsecureSkipVerify && c.CABundle == "" && c.ServerName == "" {
+		return nil, nil
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		ServerName:         c.ServerName,
+	}
+	if c.CABundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.CABundle)) {
+			return nil, fmt.Errorf("no certificate found in the CA bundle")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// OTLPExporter pushes the views reported by a meter to an OpenTelemetry
+// collector, converting each view to the matching OTLP data type
+type OTLPExporter struct {
+	exporter sdkmetric.Exporter
+	resource *resource.Resource
+	onError  func(error)
+}
+
+func NewOTLPExporter(ctx context.Context, config *OTLPConfig, onError func(error)) (*OTLPExporter, error) {
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	var exporter sdkmetric.Exporter
+	switch config.Protocol {
+	case OTLPProtocolGRPC, "":
+		options := []otlpmetricgrpc.Option{}
+		if config.Endpoint != "" {
+			options = append(options, otlpmetricgrpc.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		} else if tlsConfig != nil {
+			options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		exporter, err = otlpmetricgrpc.New(ctx, options...)
+	case OTLPProtocolHTTP:
+		options := []otlpmetrichttp.Option{}
+		if config.Endpoint != "" {
+			options = append(options, otlpmetrichttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		} else if tlsConfig != nil {
+			options = append(options, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+		exporter, err = otlpmetrichttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", config.Protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &OTLPExporter{
+		exporter: exporter,
+		resource: resource.NewSchemaless(semconv.ServiceName("metrics-operator")),
+		onError:  onError,
+	}, nil
+}
+
+// ExportView implements view.Exporter
+func (o *OTLPExporter) ExportView(data *view.Data) {
+	metric, ok := convertView(data)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), otlpPushTimeout)
+	defer cancel()
+	err := o.exporter.Export(ctx, &metricdata.ResourceMetrics{
+		Resource: o.resource,
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope:   instrumentation.Scope{Name: "github.com/tektoncd/experimental/metrics-operator"},
+			Metrics: []metricdata.Metrics{metric},
+		}},
+	})
+	if err != nil && o.onError != nil {
+		o.onError(fmt.Errorf("failed to push %s: %w", data.View.Name, err))
+	}
+}
+
+// Stop flushes and closes the connection to the collector
+func (o *OTLPExporter) Stop(ctx context.Context) {
+	o.exporter.Shutdown(ctx)
+}
+
+// convertView maps counts and sums to cumulative sums, last values to gauges
+// and distributions to histograms
+func convertView(data *view.Data) (metricdata.Metrics, bool) {
+	metric := metricdata.Metrics{
+		Name:        data.View.Name,
+		Description: data.View.Description,
+		Unit:        data.View.Measure.Unit(),
+	}
+	switch data.View.Aggregation.Type {
+	case view.AggTypeCount:
+		sum := metricdata.Sum[int64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+		for _, row := range data.Rows {
+			if count, ok := row.Data.(*view.CountData); ok {
+				sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[int64]{
+					Attributes: rowAttributes(row),
+					StartTime:  data.Start,
+					Time:       data.End,
+					Value:      count.Value,
+				})
+			}
+		}
+		metric.Data = sum
+	case view.AggTypeSum:
+		sum := metricdata.Sum[float64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+		for _, row := range data.Rows {
+			if value, ok := row.Data.(*view.SumData); ok {
+				sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: rowAttributes(row),
+					StartTime:  data.Start,
+					Time:       data.End,
+					Value:      value.Value,
+				})
+			}
+		}
+		metric.Data = sum
+	case view.AggTypeLastValue:
+		gauge := metricdata.Gauge[float64]{}
+		for _, row := range data.Rows {
+			if value, ok := row.Data.(*view.LastValueData); ok {
+				gauge.DataPoints = append(gauge.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: rowAttributes(row),
+					Time:       data.End,
+					Value:      value.Value,
+				})
+			}
+		}
+		metric.Data = gauge
+	case view.AggTypeDistribution:
+		histogram := metricdata.Histogram[float64]{Temporality: metricdata.CumulativeTemporality}
+		for _, row := range data.Rows {
+			distribution, ok := row.Data.(*view.DistributionData)
+			if !ok {
+				continue
+			}
+			counts := make([]uint64, len(distribution.CountPerBucket))
+			for i, count := range distribution.CountPerBucket {
+				counts[i] = uint64(count)
+			}
+			point := metricdata.HistogramDataPoint[float64]{
+				Attributes:   rowAttributes(row),
+				StartTime:    data.Start,
+				Time:         data.End,
+				Count:        uint64(distribution.Count),
+				Bounds:       data.View.Aggregation.Buckets,
+				BucketCounts: counts,
+				Sum:          distribution.Mean * float64(distribution.Count),
+			}
+			if distribution.Count > 0 {
+				point.Min = metricdata.NewExtrema(distribution.Min)
+				point.Max = metricdata.NewExtrema(distribution.Max)
+			}
+			histogram.DataPoints = append(histogram.DataPoints, point)
+		}
+		metric.Data = histogram
+	default:
+		return metric, false
+	}
+	return metric, true
+}
+
+func rowAttributes(row *view.Row) attribute.Set {
+	attributes := make([]attribute.KeyValue, 0, len(row.Tags))
+	for _, t := range row.Tags {
+		attributes = append(attributes, attribute.String(t.Key.Name(), t.Value))
+	}
+	return attribute.NewSet(attributes...)
+}
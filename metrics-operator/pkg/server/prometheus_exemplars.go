@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/naming"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricproducer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exemplarLabelOrder is the order attachments are kept in exemplars, the
+// trace first since the labels of an exemplar are limited in length
+var exemplarLabelOrder = []string{naming.ExemplarTraceIDKey, naming.ExemplarSpanIDKey, naming.ExemplarRunKey, naming.ExemplarRunUIDKey}
+
+// exemplarGatherer adds the exemplars kept by the OpenCensus histograms to
+// the buckets gathered by the Prometheus exporter, which drops them
+type exemplarGatherer struct {
+	gatherer  prometheus.Gatherer
+	namespace string
+}
+
+func (g *exemplarGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	if err != nil {
+		return families, err
+	}
+	exemplars := g.bucketExemplars()
+	if len(exemplars) == 0 {
+		return families, nil
+	}
+	for _, family := range families {
+		if family.GetType() != dto.MetricType_HISTOGRAM {
+			continue
+		}
+		for _, metric := range family.Metric {
+			buckets, ok := exemplars[seriesKey(family.GetName(), metric.Label)]
+			if !ok {
+				continue
+			}
+			// the exporter sorts buckets by bound like the distribution, the
+			// overflow bucket is implicit
+			for i, bucket := range metric.GetHistogram().GetBucket() {
+				if i < len(buckets) && buckets[i] != nil {
+					bucket.Exemplar = toPromExemplar(buckets[i])
+				}
+			}
+		}
+	}
+	return families, nil
+}
+
+// bucketExemplars reads the exemplars of every distribution series by series
+// key
+func (g *exemplarGatherer) bucketExemplars() map[string][]*metricdata.Exemplar {
+	exemplars := map[string][]*metricdata.Exemplar{}
+	for _, producer := range metricproducer.GlobalManager().GetAll() {
+		for _, metric := range producer.Read() {
+			if metric.Descriptor.Type != metricdata.TypeCumulativeDistribution {
+				continue
+			}
+			name := sanitizeName(metric.Descriptor.Name)
+			if g.namespace != "" {
+				name = g.namespace + "_" + name
+			}
+			for _, ts := range metric.TimeSeries {
+				if len(ts.Points) == 0 {
+					continue
+				}
+				distribution, ok := ts.Points[len(ts.Points)-1].Value.(*metricdata.Distribution)
+				if !ok {
+					continue
+				}
+				buckets := make([]*metricdata.Exemplar, len(distribution.Buckets))
+				found := false
+				for i, bucket := range distribution.Buckets {
+					buckets[i] = bucket.Exemplar
+					found = found || bucket.Exemplar != nil
+				}
+				if !found {
+					continue
+				}
+				labels := []*dto.LabelPair{}
+				for i, key := range metric.Descriptor.LabelKeys {
+					if i < len(ts.LabelValues) && ts.LabelValues[i].Present {
+						labels = append(labels, &dto.LabelPair{Name: proto.String(sanitizeName(key.Key)), Value: proto.String(ts.LabelValues[i].Value)})
+					}
+				}
+				exemplars[seriesKey(name, labels)] = buckets
+			}
+		}
+	}
+	return exemplars
+}
+
+// seriesKey identifies a series by its name and labels in any order
+func seriesKey(name string, labels []*dto.LabelPair) string {
+	pairs := make([]string, 0, len(labels))
+	for _, label := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", label.GetName(), label.GetValue()))
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// toPromExemplar converts the attachments to exemplar labels, dropping the
+// ones exceeding the length allowed by OpenMetrics
+func toPromExemplar(exemplar *metricdata.Exemplar) *dto.Exemplar {
+	labels := []*dto.LabelPair{}
+	runes := 0
+	for _, key := range exemplarLabelOrder {
+		value, ok := exemplar.Attachments[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+		length := utf8.RuneCountInString(key) + utf8.RuneCountInString(value)
+		if runes+length > prometheus.ExemplarMaxRunes {
+			continue
+		}
+		runes += length
+		labels = append(labels, &dto.LabelPair{Name: proto.String(key), Value: proto.String(value)})
+	}
+	return &dto.Exemplar{
+		Label:     labels,
+		Value:     proto.Float64(exemplar.Value),
+		Timestamp: timestamppb.New(exemplar.Timestamp),
+	}
+}
+
+// sanitizeName escapes a name like the Prometheus exporter of OpenCensus,
+// invalid characters are replaced with underscores
+func sanitizeName(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	if len(s) > 100 {
+		s = s[:100]
+	}
+	var sb strings.Builder
+	if s[0] >= '0' && s[0] <= '9' {
+		sb.WriteByte('_')
+	}
+	for _, c := range s {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' {
+			sb.WriteRune(c)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	s = sb.String()
+	if s[0] == '_' {
+		s = "key" + s
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	prom "contrib.go.opencensus.io/exporter/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestExemplarGatherer(t *testing.T) {
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	key := tag.MustNewKey("task")
+	measure := stats.Float64("task_build_duration_seconds", "duration", stats.UnitSeconds)
+	if err := meter.Register(&view.View{
+		Measure:     measure,
+		Aggregation: view.Distribution(10, 60),
+		TagKeys:     []tag.Key{key},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tagMap, err := tag.New(context.Background(), tag.Upsert(key, "build"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meter.Record(tag.FromContext(tagMap), []stats.Measurement{measure.M(30)}, map[string]any{"run": "build-abc", "trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"})
+	// samples are aggregated by the worker of the meter, retrieving the data
+	// waits for them
+	if _, err := meter.RetrieveData("task_build_duration_seconds"); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	if _, err := prom.NewExporter(prom.Options{Registry: registry}); err != nil {
+		t.Fatal(err)
+	}
+	families, err := (&exemplarGatherer{gatherer: registry}).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var histogram *dto.Histogram
+	for _, family := range families {
+		if family.GetName() == "task_build_duration_seconds" {
+			histogram = family.Metric[0].GetHistogram()
+		}
+	}
+	if histogram == nil {
+		t.Fatalf("histogram not gathered: %v", families)
+	}
+	buckets := histogram.GetBucket()
+	if len(buckets) != 2 || buckets[0].Exemplar != nil || buckets[1].Exemplar == nil {
+		t.Fatalf("want the exemplar on the 60 bucket, got %v", buckets)
+	}
+	exemplar := buckets[1].Exemplar
+	if exemplar.GetValue() != 30 {
+		t.Errorf("want the value 30, got %v", exemplar.GetValue())
+	}
+	labels := map[string]string{}
+	for _, label := range exemplar.Label {
+		labels[label.GetName()] = label.GetValue()
+	}
+	if labels["run"] != "build-abc" || labels["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected exemplar labels %v", labels)
+	}
+}
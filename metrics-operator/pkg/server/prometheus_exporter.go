@@ -1,10 +1,15 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
 	"strconv"
 
 	prom "contrib.go.opencensus.io/exporter/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opencensus.io/stats/view"
 )
 
@@ -15,22 +20,48 @@ type MetricConfig struct {
 	PrometheusHost string
 
 	PrometheusPort int
+
+	// Exemplars serves OpenMetrics with the exemplars of histogram buckets
+	Exemplars bool
 }
 
 type PrometheusServer struct {
 	server   *http.Server
+	listener net.Listener
 	exporter view.Exporter
 }
 
 func (p *PrometheusServer) GetExporter() view.Exporter { return p.exporter }
 
-func (p *PrometheusServer) Start() {
-	p.server.ListenAndServe()
+// Listen binds the address of the server, so a port already in use is
+// reported before serving
+func (p *PrometheusServer) Listen() error {
+	listener, err := net.Listen("tcp", p.server.Addr)
+	if err != nil {
+		return err
+	}
+	p.listener = listener
+	return nil
 }
 
-func (p *PrometheusServer) Restart() {
-	p.server.Close()
-	p.server.ListenAndServe()
+// Start serves /metrics until the server is stopped, binding the address
+// first unless Listen was called
+func (p *PrometheusServer) Start() error {
+	if p.listener == nil {
+		if err := p.Listen(); err != nil {
+			return err
+		}
+	}
+	if err := p.server.Serve(p.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the server once the in-flight scrapes are served or the
+// context is done
+func (p *PrometheusServer) Shutdown(ctx context.Context) error {
+	return p.server.Shutdown(ctx)
 }
 
 func (p *PrometheusServer) Stop() {
@@ -38,14 +69,20 @@ func (p *PrometheusServer) Stop() {
 }
 
 func NewPrometheusExporter(config *MetricConfig) (*PrometheusServer, error) {
-	e, err := prom.NewExporter(prom.Options{Namespace: config.Namespace})
+	registry := prometheus.NewRegistry()
+	e, err := prom.NewExporter(prom.Options{Namespace: config.Namespace, Registry: registry})
 	if err != nil {
 		return nil, err
 	}
 	sm := http.NewServeMux()
-	sm.Handle("/metrics", e)
+	if config.Exemplars {
+		gatherer := &exemplarGatherer{gatherer: registry, namespace: config.Namespace}
+		sm.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+	} else {
+		sm.Handle("/metrics", e)
+	}
 	server := &http.Server{
-		Addr:    config.PrometheusHost + ":" + strconv.Itoa(config.PrometheusPort),
+		Addr:    net.JoinHostPort(config.PrometheusHost, strconv.Itoa(config.PrometheusPort)),
 		Handler: sm,
 	}
 	return &PrometheusServer{
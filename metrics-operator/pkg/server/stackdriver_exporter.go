@@ -0,0 +1,330 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"go.opencensus.io/stats/view"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	StackdriverResourceCluster = "k8s_cluster"
+	StackdriverResourceTask    = "generic_task"
+)
+
+const (
+	stackdriverScope = "https://www.googleapis.com/auth/monitoring.write"
+
+	// Cloud Monitoring rejects more than 200 time series per request
+	stackdriverMaxSeriesPerRequest = 200
+
+	// and more than one point per time series and minute is refused or sampled
+	stackdriverMinInterval = time.Minute
+)
+
+type StackdriverConfig struct {
+	// project, location and cluster default to the GKE metadata server
+	Project  string
+	Location string
+	Cluster  string
+
+	// k8s_cluster, or generic_task with the namespace of the samples
+	ResourceType string
+
+	// SystemNamespace is the namespace of samples without a namespace tag
+	SystemNamespace string
+
+	// Interval between two writes, at least a minute
+	Interval time.Duration
+}
+
+// StackdriverExporter writes the views reported by a meter to Google Cloud
+// Monitoring as custom metrics. Reports are merged until the next write, so
+// every time series gets at most one point per interval however often the
+// meter reports.
+type StackdriverExporter struct {
+	config   StackdriverConfig
+	endpoint string
+	client   *http.Client
+	onError  func(error)
+
+	// latest point by time series, written on the next flush
+	pending map[string]stackdriverTimeSeries
+	mu      sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type stackdriverTimeSeries struct {
+	Metric     stackdriverType    `json:"metric"`
+	Resource   stackdriverType    `json:"resource"`
+	MetricKind string             `json:"metricKind"`
+	ValueType  string             `json:"valueType"`
+	Points     []stackdriverPoint `json:"points"`
+}
+
+type stackdriverType struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type stackdriverPoint struct {
+	Interval stackdriverInterval `json:"interval"`
+	Value    map[string]any      `json:"value"`
+}
+
+type stackdriverInterval struct {
+	StartTime string `json:"startTime,omitempty"`
+	EndTime   string `json:"endTime"`
+}
+
+type stackdriverRequest struct {
+	TimeSeries []stackdriverTimeSeries `json:"timeSeries"`
+}
+
+func NewStackdriverExporter(ctx context.Context, config *StackdriverConfig, onError func(error)) (*StackdriverExporter, error) {
+	c := *config
+	switch c.ResourceType {
+	case StackdriverResourceCluster, StackdriverResourceTask:
+	case "":
+		c.ResourceType = StackdriverResourceCluster
+	default:
+		return nil, fmt.Errorf("unsupported Stackdriver resource type %q", c.ResourceType)
+	}
+	if c.Interval < stackdriverMinInterval {
+		c.Interval = stackdriverMinInterval
+	}
+	if c.Project == "" {
+		project, err := metadata.ProjectID()
+		if err != nil {
+			return nil, fmt.Errorf("no project configured and none found in the metadata server: %w", err)
+		}
+		c.Project = project
+	}
+	if c.Location == "" {
+		c.Location, _ = metadata.InstanceAttributeValue("cluster-location")
+	}
+	if c.Cluster == "" {
+		c.Cluster, _ = metadata.InstanceAttributeValue("cluster-name")
+	}
+	client, err := google.DefaultClient(ctx, stackdriverScope)
+	if err != nil {
+		return nil, fmt.Errorf("no default credentials: %w", err)
+	}
+	s := &StackdriverExporter{
+		config:   c,
+		endpoint: fmt.Sprintf("https://monitoring.googleapis.com/v3/projects/%s/timeSeries", url.PathEscape(c.Project)),
+		client:   client,
+		onError:  onError,
+		pending:  map[string]stackdriverTimeSeries{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// ExportView implements view.Exporter, the points replace the pending
+// points of the same time series
+func (s *StackdriverExporter) ExportView(data *view.Data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range data.Rows {
+		series, ok := s.convertRow(data, row)
+		if !ok {
+			continue
+		}
+		s.pending[stackdriverSeriesKey(&series)] = series
+	}
+}
+
+// PurgeViews drops the pending points of the views, so deleted metrics aren't
+// written on the next flush
+func (s *StackdriverExporter) PurgeViews(names ...string) {
+	types := map[string]bool{}
+	for _, name := range names {
+		types[stackdriverMetricType(name)] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, series := range s.pending {
+		if types[series.Metric.Type] {
+			delete(s.pending, key)
+		}
+	}
+}
+
+func (s *StackdriverExporter) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush(context.Background())
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stop writes the pending points and stops the write loop
+func (s *StackdriverExporter) Stop(ctx context.Context) {
+	close(s.stop)
+	<-s.done
+	s.flush(ctx)
+	s.client.CloseIdleConnections()
+}
+
+// flush writes the pending points in batches of at most 200 time series
+func (s *StackdriverExporter) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := make([]stackdriverTimeSeries, 0, len(s.pending))
+	for _, series := range s.pending {
+		pending = append(pending, series)
+	}
+	s.pending = map[string]stackdriverTimeSeries{}
+	s.mu.Unlock()
+
+	for start := 0; start < len(pending); start += stackdriverMaxSeriesPerRequest {
+		end := start + stackdriverMaxSeriesPerRequest
+		if end > len(pending) {
+			end = len(pending)
+		}
+		if err := s.write(ctx, pending[start:end]); err != nil && s.onError != nil {
+			s.onError(err)
+		}
+	}
+}
+
+func (s *StackdriverExporter) write(ctx context.Context, series []stackdriverTimeSeries) error {
+	body, err := json.Marshal(stackdriverRequest{TimeSeries: series})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("cloud monitoring returned %s for %d time series: %s", response.Status, len(series), bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// convertRow maps counts and sums to cumulative metrics, last values to
+// gauges and distributions to cumulative distributions
+func (s *StackdriverExporter) convertRow(data *view.Data, row *view.Row) (stackdriverTimeSeries, bool) {
+	labels := map[string]string{}
+	namespace := s.config.SystemNamespace
+	for _, t := range row.Tags {
+		if t.Key.Name() == "namespace" {
+			namespace = t.Value
+		}
+		labels[stackdriverLabel(t.Key.Name())] = t.Value
+	}
+	series := stackdriverTimeSeries{
+		Metric:   stackdriverType{Type: stackdriverMetricType(data.View.Name), Labels: labels},
+		Resource: s.resource(namespace),
+	}
+	point := stackdriverPoint{Interval: stackdriverInterval{
+		StartTime: data.Start.UTC().Format(time.RFC3339Nano),
+		EndTime:   data.End.UTC().Format(time.RFC3339Nano),
+	}}
+	switch value := row.Data.(type) {
+	case *view.CountData:
+		series.MetricKind, series.ValueType = "CUMULATIVE", "INT64"
+		point.Value = map[string]any{"int64Value": strconv.FormatInt(value.Value, 10)}
+	case *view.SumData:
+		series.MetricKind, series.ValueType = "CUMULATIVE", "DOUBLE"
+		point.Value = map[string]any{"doubleValue": value.Value}
+	case *view.LastValueData:
+		series.MetricKind, series.ValueType = "GAUGE", "DOUBLE"
+		point.Interval.StartTime = ""
+		point.Value = map[string]any{"doubleValue": value.Value}
+	case *view.DistributionData:
+		series.MetricKind, series.ValueType = "CUMULATIVE", "DISTRIBUTION"
+		counts := make([]string, len(value.CountPerBucket))
+		for i, count := range value.CountPerBucket {
+			counts[i] = strconv.FormatInt(count, 10)
+		}
+		point.Value = map[string]any{"distributionValue": map[string]any{
+			"count":                 strconv.FormatInt(value.Count, 10),
+			"mean":                  value.Mean,
+			"sumOfSquaredDeviation": value.SumOfSquaredDev,
+			"bucketOptions":         map[string]any{"explicitBuckets": map[string]any{"bounds": data.View.Aggregation.Buckets}},
+			"bucketCounts":          counts,
+		}}
+	default:
+		return series, false
+	}
+	series.Points = []stackdriverPoint{point}
+	return series, true
+}
+
+// resource returns the monitored resource of a sample, generic_task carries
+// the namespace of the run
+func (s *StackdriverExporter) resource(namespace string) stackdriverType {
+	if s.config.ResourceType == StackdriverResourceTask {
+		return stackdriverType{Type: StackdriverResourceTask, Labels: map[string]string{
+			"project_id": s.config.Project,
+			"location":   s.config.Location,
+			"namespace":  namespace,
+			"job":        "metrics-operator",
+			"task_id":    s.config.Cluster,
+		}}
+	}
+	return stackdriverType{Type: StackdriverResourceCluster, Labels: map[string]string{
+		"project_id":   s.config.Project,
+		"location":     s.config.Location,
+		"cluster_name": s.config.Cluster,
+	}}
+}
+
+func stackdriverMetricType(viewName string) string {
+	return "custom.googleapis.com/tekton/" + viewName
+}
+
+func stackdriverSeriesKey(series *stackdriverTimeSeries) string {
+	keys := make([]string, 0, len(series.Metric.Labels))
+	for key := range series.Metric.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(series.Metric.Type)
+	for _, key := range keys {
+		b.WriteString("," + key + "=" + series.Metric.Labels[key])
+	}
+	b.WriteString("|" + series.Resource.Labels["namespace"])
+	return b.String()
+}
+
+var stackdriverInvalidLabel = regexp.MustCompile(`[^a-z0-9_]`)
+
+// stackdriverLabel lowercases the key and replaces the characters refused in
+// label keys
+func stackdriverLabel(key string) string {
+	return stackdriverInvalidLabel.ReplaceAllString(strings.ToLower(key), "_")
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestStackdriverExporterBatches(t *testing.T) {
+	var mu sync.Mutex
+	requests := []stackdriverRequest{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := stackdriverRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	exporter := &StackdriverExporter{
+		config:   StackdriverConfig{Project: "p", Location: "l", Cluster: "c", ResourceType: StackdriverResourceTask, SystemNamespace: "tekton-metrics"},
+		endpoint: collector.URL,
+		client:   collector.Client(),
+		pending:  map[string]stackdriverTimeSeries{},
+	}
+
+	key := tag.MustNewKey("namespace")
+	v := &view.View{Name: "runs", Measure: stats.Int64("runs", "", stats.UnitDimensionless), Aggregation: view.Count(), TagKeys: []tag.Key{key}}
+	rows := []*view.Row{}
+	for i := 0; i < 250; i++ {
+		rows = append(rows, &view.Row{Tags: []tag.Tag{{Key: key, Value: "ns-" + strconv.Itoa(i)}}, Data: &view.CountData{Value: 1}})
+	}
+	start := time.Now().Add(-time.Minute)
+	// the second report replaces the points of the first one
+	exporter.ExportView(&view.Data{View: v, Start: start, End: time.Now(), Rows: rows})
+	exporter.ExportView(&view.Data{View: v, Start: start, End: time.Now(), Rows: rows})
+	exporter.flush(context.Background())
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	if len(requests[0].TimeSeries)+len(requests[1].TimeSeries) != 250 {
+		t.Errorf("expected 250 time series, got %d and %d", len(requests[0].TimeSeries), len(requests[1].TimeSeries))
+	}
+	series := requests[0].TimeSeries[0]
+	if series.Resource.Type != StackdriverResourceTask || series.Resource.Labels["namespace"] != series.Metric.Labels["namespace"] {
+		t.Errorf("expected a generic_task resource with the namespace of the sample, got %v", series.Resource)
+	}
+	if series.MetricKind != "CUMULATIVE" || series.ValueType != "INT64" {
+		t.Errorf("expected a cumulative int64, got %s %s", series.MetricKind, series.ValueType)
+	}
+}
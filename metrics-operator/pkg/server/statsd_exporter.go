@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats/view"
+)
+
+const (
+	StatsDFlavorStatsD    = "statsd"
+	StatsDFlavorDogStatsD = "dogstatsd"
+)
+
+// statsDMaxPacketSize keeps datagrams under the usual network MTU
+const statsDMaxPacketSize = 1432
+
+type StatsDConfig struct {
+	// default "localhost"
+	Host string
+
+	// default 8125
+	Port int
+
+	// prepended to metric names with a dot, none when empty
+	Prefix string
+
+	// statsd or dogstatsd, only dogstatsd carries tags
+	Flavor string
+}
+
+// StatsDExporter pushes the views reported by a meter to a StatsD or
+// DogStatsD endpoint over UDP. Views are cumulative, so counters are pushed as
+// the increase since the previous report of the same series.
+type StatsDExporter struct {
+	conn    net.Conn
+	prefix  string
+	tags    bool
+	onError func(error)
+
+	// previous cumulative value by view and series
+	last map[string]map[string]float64
+	mu   sync.Mutex
+}
+
+func NewStatsDExporter(config *StatsDConfig, onError func(error)) (*StatsDExporter, error) {
+	switch config.Flavor {
+	case StatsDFlavorStatsD, StatsDFlavorDogStatsD, "":
+	default:
+		return nil, fmt.Errorf("unsupported StatsD flavor %q", config.Flavor)
+	}
+	conn, err := net.Dial("udp", net.JoinHostPort(config.Host, strconv.Itoa(config.Port)))
+	if err != nil {
+		return nil, err
+	}
+	prefix := config.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	return &StatsDExporter{
+		conn:    conn,
+		prefix:  prefix,
+		tags:    config.Flavor == StatsDFlavorDogStatsD,
+		onError: onError,
+		last:    map[string]map[string]float64{},
+	}, nil
+}
+
+// ExportView implements view.Exporter, distributions are pushed as the
+// count and sum counters of their samples
+func (s *StatsDExporter) ExportView(data *view.Data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, ok := s.last[data.View.Name]
+	if !ok {
+		last = map[string]float64{}
+		s.last[data.View.Name] = last
+	}
+	lines := []string{}
+	for _, row := range data.Rows {
+		name, tags := s.prefix+data.View.Name, s.formatTags(row)
+		if !s.tags {
+			name = s.taggedName(name, row)
+		}
+		switch value := row.Data.(type) {
+		case *view.CountData:
+			lines = appendCounter(lines, last, name, tags, float64(value.Value))
+		case *view.SumData:
+			lines = appendCounter(lines, last, name, tags, value.Value)
+		case *view.LastValueData:
+			lines = append(lines, formatStatsDLine(name, value.Value, "g", tags))
+		case *view.DistributionData:
+			lines = appendCounter(lines, last, name+".count", tags, float64(value.Count))
+			lines = appendCounter(lines, last, name+".sum", tags, value.Mean*float64(value.Count))
+		}
+	}
+	s.send(lines)
+}
+
+// appendCounter appends the increase of a cumulative value, a series seen
+// for the first time or reset pushes its whole value
+func appendCounter(lines []string, last map[string]float64, name, tags string, value float64) []string {
+	series := name + "|" + tags
+	delta := value
+	if previous, ok := last[series]; ok && previous <= value {
+		delta = value - previous
+	}
+	last[series] = value
+	if delta == 0 {
+		return lines
+	}
+	return append(lines, formatStatsDLine(name, delta, "c", tags))
+}
+
+// send writes the lines in as few datagrams as possible
+func (s *StatsDExporter) send(lines []string) {
+	var packet bytes.Buffer
+	flush := func() {
+		if packet.Len() == 0 {
+			return
+		}
+		if _, err := s.conn.Write(packet.Bytes()); err != nil && s.onError != nil {
+			s.onError(fmt.Errorf("failed to push to StatsD: %w", err))
+		}
+		packet.Reset()
+	}
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsDMaxPacketSize {
+			flush()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	flush()
+}
+
+// formatTags returns the DogStatsD tags of the row, empty for plain StatsD
+func (s *StatsDExporter) formatTags(row *view.Row) string {
+	if !s.tags || len(row.Tags) == 0 {
+		return ""
+	}
+	tags := make([]string, 0, len(row.Tags))
+	for _, t := range row.Tags {
+		tags = append(tags, sanitizeStatsD(t.Key.Name())+":"+sanitizeStatsD(t.Value))
+	}
+	return "|#" + strings.Join(tags, ",")
+}
+
+// taggedName appends the tag values to the name, plain StatsD has no tags
+func (s *StatsDExporter) taggedName(name string, row *view.Row) string {
+	for _, t := range row.Tags {
+		if t.Value != "" {
+			name += "." + strings.ReplaceAll(sanitizeStatsD(t.Value), ".", "_")
+		}
+	}
+	return name
+}
+
+// PurgeViews forgets the previous values of the views, a view registered
+// again under the same name pushes its whole value
+func (s *StatsDExporter) PurgeViews(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		delete(s.last, name)
+	}
+}
+
+// Stop closes the connection
+func (s *StatsDExporter) Stop() {
+	s.conn.Close()
+}
+
+func formatStatsDLine(name string, value float64, kind, tags string) string {
+	return sanitizeStatsD(name) + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + kind + tags
+}
+
+// statsDReplacer replaces the separators of the StatsD line protocol
+var statsDReplacer = strings.NewReplacer(":", "_", "|", "_", ",", "_", "#", "_", "\n", "_", " ", "_")
+
+func sanitizeStatsD(s string) string {
+	return statsDReplacer.Replace(s)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestStatsDExporter(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	exporter, err := NewStatsDExporter(&StatsDConfig{
+		Host:   "127.0.0.1",
+		Port:   listener.LocalAddr().(*net.UDPAddr).Port,
+		Prefix: "tekton",
+		Flavor: StatsDFlavorDogStatsD,
+	}, func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.Stop()
+
+	key := tag.MustNewKey("task")
+	v := &view.View{Name: "runs", Measure: stats.Int64("runs", "", stats.UnitDimensionless), Aggregation: view.Count(), TagKeys: []tag.Key{key}}
+	export := func(count int64) string {
+		exporter.ExportView(&view.Data{View: v, Rows: []*view.Row{{
+			Tags: []tag.Tag{{Key: key, Value: "build"}},
+			Data: &view.CountData{Value: count},
+		}}})
+		buffer := make([]byte, statsDMaxPacketSize)
+		listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := listener.ReadFrom(buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buffer[:n])
+	}
+
+	if line := export(3); line != "tekton.runs:3|c|#task:build" {
+		t.Errorf("unexpected first line %q", line)
+	}
+	if line := export(5); line != "tekton.runs:2|c|#task:build" {
+		t.Errorf("expected the increase since the previous report, got %q", line)
+	}
+	exporter.PurgeViews("runs")
+	if line := export(1); line != "tekton.runs:1|c|#task:build" {
+		t.Errorf("expected the whole value of a purged view, got %q", line)
+	}
+}
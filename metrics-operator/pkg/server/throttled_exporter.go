@@ -0,0 +1,49 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats/view"
+)
+
+// throttledExporter forwards the reports of a view at most once per interval,
+// views are cumulative so skipped reports lose no sample
+type throttledExporter struct {
+	exporter view.Exporter
+	interval time.Duration
+
+	// last forwarded report by view
+	last map[string]time.Time
+	mu   sync.Mutex
+}
+
+func newThrottledExporter(exporter view.Exporter, interval time.Duration) *throttledExporter {
+	return &throttledExporter{exporter: exporter, interval: interval, last: map[string]time.Time{}}
+}
+
+// ExportView implements view.Exporter
+func (t *throttledExporter) ExportView(data *view.Data) {
+	t.mu.Lock()
+	last, ok := t.last[data.View.Name]
+	if ok && data.End.Sub(last) < t.interval {
+		t.mu.Unlock()
+		return
+	}
+	t.last[data.View.Name] = data.End
+	t.mu.Unlock()
+	t.exporter.ExportView(data)
+}
+
+// PurgeViews forgets the last reports of the views and purges the wrapped
+// exporter
+func (t *throttledExporter) PurgeViews(names ...string) {
+	t.mu.Lock()
+	for _, name := range names {
+		delete(t.last, name)
+	}
+	t.mu.Unlock()
+	if purger, ok := t.exporter.(viewPurger); ok {
+		purger.PurgeViews(names...)
+	}
+}
@@ -0,0 +1,125 @@
+package simulate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// maxMonitorSize bounds the monitor manifests posted for evaluation
+const maxMonitorSize = 1 << 20
+
+// Evaluate registers the monitor in a throwaway manager and evaluates its
+// metrics against the run, a TaskRun, PipelineRun or CustomRun. Nothing is
+// recorded in the metrics of the operator.
+func Evaluate(ctx context.Context, monitor runtime.Object, run runtime.Object) ([]metrics.Evaluation, error) {
+	var dimensions *monitoringv1alpha1.RunDimensions
+	switch r := run.(type) {
+	case *pipelinev1beta1.TaskRun:
+		dimensions = recorder.TaskRunDimensions(r)
+	case *pipelinev1beta1.PipelineRun:
+		dimensions = recorder.PipelineRunDimensions(r)
+	case *pipelinev1beta1.CustomRun:
+		dimensions = recorder.CustomRunDimensions(r)
+	default:
+		return nil, fmt.Errorf("unsupported run %T", run)
+	}
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	manager := metrics.NewManager(meter, nil)
+	if err := register(ctx, manager, monitor); err != nil {
+		return nil, err
+	}
+	return manager.GetIndex().Evaluate(ctx, dimensions), nil
+}
+
+// Decode returns the single object of a YAML or JSON manifest
+func Decode(r io.Reader) (runtime.Object, error) {
+	raw := runtime.RawExtension{}
+	if err := utilyaml.NewYAMLOrJSONDecoder(r, 4096).Decode(&raw); err != nil {
+		return nil, err
+	}
+	obj, _, err := codecs.UniversalDeserializer().Decode(raw.Raw, nil, nil)
+	return obj, err
+}
+
+// ErrUnsupportedResource is returned by a RunGetter asked for a resource it
+// can't fetch, the handler answers it as a bad request
+var ErrUnsupportedResource = errors.New("unsupported resource")
+
+// RunGetter returns a run of the cluster, the resource is taskrun,
+// pipelinerun or customrun
+type RunGetter func(ctx context.Context, resource, namespace, name string) (runtime.Object, error)
+
+// EvaluateHandler evaluates the monitor posted as YAML or JSON against the
+// run named by the taskRun, pipelineRun or customRun query parameter, in the
+// namespace parameter or the namespace of the monitor. The evaluations are
+// served as JSON, nothing is recorded.
+func EvaluateHandler(getRun RunGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "post the monitor to evaluate", http.StatusMethodNotAllowed)
+			return
+		}
+		monitor, err := Decode(io.LimitReader(r.Body, maxMonitorSize))
+		if err != nil {
+			http.Error(w, "invalid monitor: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := r.URL.Query()
+		namespace := query.Get("namespace")
+		if object, err := meta.Accessor(monitor); err == nil && namespace == "" {
+			namespace = object.GetNamespace()
+		}
+		var resource, name string
+		for _, param := range []struct{ resource, name string }{
+			{"taskrun", query.Get("taskRun")},
+			{"pipelinerun", query.Get("pipelineRun")},
+			{"customrun", query.Get("customRun")},
+		} {
+			if param.name != "" {
+				resource, name = param.resource, param.name
+			}
+		}
+		if name == "" || namespace == "" {
+			http.Error(w, "a taskRun, pipelineRun or customRun and its namespace are required", http.StatusBadRequest)
+			return
+		}
+		run, err := getRun(r.Context(), resource, namespace, name)
+		if errors.Is(err, ErrUnsupportedResource) {
+			http.Error(w, err.Error()+", supported resources are taskrun, pipelinerun and customrun", http.StatusBadRequest)
+			return
+		}
+		if apierrors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		evaluations, err := Evaluate(r.Context(), monitor, run)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(evaluations)
+	})
+}
@@ -0,0 +1,59 @@
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestEvaluateHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		err      error
+		wantCode int
+		wantBody string
+	}{{
+		name:     "missing run",
+		query:    "namespace=dev",
+		wantCode: http.StatusBadRequest,
+		wantBody: "a taskRun, pipelineRun or customRun and its namespace are required",
+	}, {
+		name:     "unsupported resource",
+		query:    "taskRun=build-a",
+		err:      fmt.Errorf("%w %q", ErrUnsupportedResource, "taskrun"),
+		wantCode: http.StatusBadRequest,
+		wantBody: "supported resources are taskrun, pipelinerun and customrun",
+	}, {
+		name:     "run not found",
+		query:    "taskRun=build-a",
+		err:      apierrors.NewNotFound(schema.GroupResource{Group: "tekton.dev", Resource: "taskruns"}, "build-a"),
+		wantCode: http.StatusNotFound,
+		wantBody: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := EvaluateHandler(func(ctx context.Context, resource, namespace, name string) (runtime.Object, error) {
+				if resource != "taskrun" || namespace != "dev" || name != "build-a" {
+					t.Errorf("unexpected run %s %s/%s", resource, namespace, name)
+				}
+				return nil, tt.err
+			})
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/evaluate?"+tt.query, strings.NewReader(monitor)))
+			if recorder.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d", tt.wantCode, recorder.Code)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.wantBody) {
+				t.Errorf("want %q in the response, got %q", tt.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+// Package simulate replays past TaskRuns and PipelineRuns through a set of
+// monitors, so new metric definitions can be validated against real traffic
+// before being applied to a cluster.
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	prom "contrib.go.opencensus.io/exporter/prometheus"
+	monitoringv1alpha1 "github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/clustertaskmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/pipelinemonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/pipelinerunmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/taskmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/taskrunmonitor"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var (
+	scheme = runtime.NewScheme()
+	codecs = serializer.NewCodecFactory(scheme)
+)
+
+func init() {
+	_ = corev1.AddToScheme(scheme)
+	_ = monitoringv1alpha1.AddToScheme(scheme)
+	_ = pipelinev1beta1.AddToScheme(scheme)
+}
+
+// Input holds the monitors and the runs to replay
+type Input struct {
+	TaskMonitors        []*monitoringv1alpha1.TaskMonitor
+	ClusterTaskMonitors []*monitoringv1alpha1.ClusterTaskMonitor
+	TaskRunMonitors     []*monitoringv1alpha1.TaskRunMonitor
+	PipelineMonitors    []*monitoringv1alpha1.PipelineMonitor
+	PipelineRunMonitors []*monitoringv1alpha1.PipelineRunMonitor
+	TaskRuns            []*pipelinev1beta1.TaskRun
+	PipelineRuns        []*pipelinev1beta1.PipelineRun
+}
+
+// Load reads monitors, TaskRuns and PipelineRuns of every YAML or JSON file in
+// the given paths. Objects can be listed one per document or in a List, as
+// exported by kubectl or Tekton Results, other kinds are ignored.
+func (in *Input) Load(paths ...string) error {
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !isManifest(file) {
+				return nil
+			}
+			return in.loadFile(file)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *Input) loadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		raw := runtime.RawExtension{}
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if len(raw.Raw) == 0 {
+			continue
+		}
+		if err := in.add(raw.Raw); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+}
+
+func (in *Input) add(raw []byte) error {
+	obj, _, err := codecs.UniversalDeserializer().Decode(raw, nil, nil)
+	if runtime.IsNotRegisteredError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch o := obj.(type) {
+	case *corev1.List:
+		for _, item := range o.Items {
+			if err := in.add(item.Raw); err != nil {
+				return err
+			}
+		}
+	case *monitoringv1alpha1.TaskMonitor:
+		in.TaskMonitors = append(in.TaskMonitors, o)
+	case *monitoringv1alpha1.ClusterTaskMonitor:
+		in.ClusterTaskMonitors = append(in.ClusterTaskMonitors, o)
+	case *monitoringv1alpha1.TaskRunMonitor:
+		in.TaskRunMonitors = append(in.TaskRunMonitors, o)
+	case *monitoringv1alpha1.PipelineMonitor:
+		in.PipelineMonitors = append(in.PipelineMonitors, o)
+	case *monitoringv1alpha1.PipelineRunMonitor:
+		in.PipelineRunMonitors = append(in.PipelineRunMonitors, o)
+	case *pipelinev1beta1.TaskRun:
+		in.TaskRuns = append(in.TaskRuns, o)
+	case *pipelinev1beta1.PipelineRun:
+		in.PipelineRuns = append(in.PipelineRuns, o)
+	}
+	return nil
+}
+
+// Run registers the monitors, replays the runs in creation order and writes
+// the resulting series in the Prometheus text format.
+func Run(ctx context.Context, in *Input, out io.Writer) error {
+	exporter, err := prom.NewExporter(prom.Options{})
+	if err != nil {
+		return err
+	}
+	meter := view.NewMeter()
+	meter.Start()
+	defer meter.Stop()
+	meter.RegisterExporter(exporter)
+	manager := metrics.NewManager(meter, nil)
+
+	monitors := []runtime.Object{}
+	for _, monitor := range in.TaskMonitors {
+		monitors = append(monitors, monitor)
+	}
+	for _, monitor := range in.ClusterTaskMonitors {
+		monitors = append(monitors, monitor)
+	}
+	for _, monitor := range in.TaskRunMonitors {
+		monitors = append(monitors, monitor)
+	}
+	for _, monitor := range in.PipelineMonitors {
+		monitors = append(monitors, monitor)
+	}
+	for _, monitor := range in.PipelineRunMonitors {
+		monitors = append(monitors, monitor)
+	}
+	for _, monitor := range monitors {
+		if err := register(ctx, manager, monitor); err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(in.TaskRuns, func(i, j int) bool {
+		return in.TaskRuns[i].CreationTimestamp.Before(&in.TaskRuns[j].CreationTimestamp)
+	})
+	for _, taskRun := range in.TaskRuns {
+		if taskRun.IsDone() {
+			err = manager.RecordTaskRunDone(ctx, taskRun)
+		} else {
+			err = manager.RecordTaskRunRunning(ctx, taskRun)
+		}
+		if err != nil {
+			return fmt.Errorf("TaskRun %s: %w", taskRun.Name, err)
+		}
+	}
+	sort.SliceStable(in.PipelineRuns, func(i, j int) bool {
+		return in.PipelineRuns[i].CreationTimestamp.Before(&in.PipelineRuns[j].CreationTimestamp)
+	})
+	for _, pipelineRun := range in.PipelineRuns {
+		if pipelineRun.IsDone() {
+			err = manager.RecordPipelineRunDone(ctx, pipelineRun)
+		} else {
+			err = manager.RecordPipelineRunRunning(ctx, pipelineRun)
+		}
+		if err != nil {
+			return fmt.Errorf("PipelineRun %s: %w", pipelineRun.Name, err)
+		}
+	}
+
+	// measurements are processed asynchronously by the meter, retrieving data
+	// waits until the ones recorded before are processed
+	meter.RetrieveData("")
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	_, err = io.Copy(out, recorder.Body)
+	return err
+}
+
+// register reconciles the monitor into the manager, as the operator would
+func register(ctx context.Context, manager *metrics.MetricManager, monitor runtime.Object) error {
+	var err error
+	switch m := monitor.(type) {
+	case *monitoringv1alpha1.TaskMonitor:
+		if err = taskmonitor.NewReconciler(manager, nil).ReconcileKind(ctx, m); err != nil {
+			err = fmt.Errorf("TaskMonitor %s: %w", m.Name, err)
+		}
+	case *monitoringv1alpha1.ClusterTaskMonitor:
+		if err = clustertaskmonitor.NewReconciler(manager, nil).ReconcileKind(ctx, m); err != nil {
+			err = fmt.Errorf("ClusterTaskMonitor %s: %w", m.Name, err)
+		}
+	case *monitoringv1alpha1.TaskRunMonitor:
+		if err = taskrunmonitor.NewReconciler(manager, nil).ReconcileKind(ctx, m); err != nil {
+			err = fmt.Errorf("TaskRunMonitor %s: %w", m.Name, err)
+		}
+	case *monitoringv1alpha1.PipelineMonitor:
+		if err = pipelinemonitor.NewReconciler(manager, nil).ReconcileKind(ctx, m); err != nil {
+			err = fmt.Errorf("PipelineMonitor %s: %w", m.Name, err)
+		}
+	case *monitoringv1alpha1.PipelineRunMonitor:
+		if err = pipelinerunmonitor.NewReconciler(manager, nil).ReconcileKind(ctx, m); err != nil {
+			err = fmt.Errorf("PipelineRunMonitor %s: %w", m.Name, err)
+		}
+	default:
+		err = fmt.Errorf("unsupported monitor %T", monitor)
+	}
+	return err
+}
+
+func isManifest(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package simulate
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const monitor = `apiVersion: metrics.tekton.dev/v1alpha1
+kind: TaskMonitor
+metadata:
+  name: build
+  namespace: dev
+spec:
+  taskName: build
+  metrics:
+  - name: runs
+    type: counter
+    by:
+    - condition: Succeeded
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+`
+
+const taskRuns = `apiVersion: v1
+kind: List
+items:
+- apiVersion: tekton.dev/v1beta1
+  kind: TaskRun
+  metadata:
+    name: build-a
+    namespace: dev
+    uid: a
+    creationTimestamp: "2023-08-01T10:00:00Z"
+  spec:
+    taskRef:
+      name: build
+  status:
+    conditions:
+    - type: Succeeded
+      status: "True"
+- apiVersion: tekton.dev/v1beta1
+  kind: TaskRun
+  metadata:
+    name: build-b
+    namespace: dev
+    uid: b
+    creationTimestamp: "2023-08-01T10:05:00Z"
+  spec:
+    taskRef:
+      name: build
+  status:
+    conditions:
+    - type: Succeeded
+      status: "False"
+- apiVersion: tekton.dev/v1beta1
+  kind: TaskRun
+  metadata:
+    name: test-a
+    namespace: dev
+    uid: c
+    creationTimestamp: "2023-08-01T10:10:00Z"
+  spec:
+    taskRef:
+      name: test
+  status:
+    conditions:
+    - type: Succeeded
+      status: "True"
+`
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "monitor.yaml", monitor)
+	writeFile(t, dir, "runs.yml", taskRuns)
+	writeFile(t, dir, "README.md", "not a manifest")
+
+	in := &Input{}
+	if err := in.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.TaskMonitors) != 1 || len(in.TaskRuns) != 3 {
+		t.Errorf("want 1 TaskMonitor and 3 TaskRuns, got %d and %d", len(in.TaskMonitors), len(in.TaskRuns))
+	}
+
+	writeFile(t, dir, "invalid.json", "{")
+	if err := (&Input{}).Load(dir); err == nil {
+		t.Error("want an error for an invalid manifest")
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "monitor.yaml", monitor)
+	writeFile(t, dir, "runs.yaml", taskRuns)
+	in := &Input{}
+	if err := in.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := Run(context.Background(), in, out); err != nil {
+		t.Fatal(err)
+	}
+	// the run of the test task isn't matched by the monitor
+	for _, want := range []string{
+		`task_build_runs_total{status="success"} 1`,
+		`task_build_runs_total{status="failed"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in the output, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+const defaultIngestionEndpoint = "https://dc.services.visualstudio.com/"
+
+// AzureMonitor tracks every sample as a custom metric of an Application
+// Insights resource, the tags and the run reference are its dimensions
+type AzureMonitor struct {
+	endpoint           string
+	instrumentationKey string
+	client             *http.Client
+}
+
+type azureEnvelope struct {
+	Name string            `json:"name"`
+	Time string            `json:"time"`
+	IKey string            `json:"iKey"`
+	Tags map[string]string `json:"tags"`
+	Data azureData         `json:"data"`
+}
+
+type azureData struct {
+	BaseType string          `json:"baseType"`
+	BaseData azureMetricData `json:"baseData"`
+}
+
+type azureMetricData struct {
+	Ver        int               `json:"ver"`
+	Metrics    []azureDataPoint  `json:"metrics"`
+	Properties map[string]string `json:"properties"`
+}
+
+type azureDataPoint struct {
+	Name  string  `json:"name"`
+	Kind  int     `json:"kind"`
+	Value float64 `json:"value"`
+	Count int     `json:"count"`
+}
+
+type azureTrackResponse struct {
+	ItemsReceived int `json:"itemsReceived"`
+	ItemsAccepted int `json:"itemsAccepted"`
+	Errors        []struct {
+		Index      int    `json:"index"`
+		StatusCode int    `json:"statusCode"`
+		Message    string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewAzureMonitor sends the samples to the Application Insights resource of
+// the connection string
+func NewAzureMonitor(connectionString string) (*AzureMonitor, error) {
+	settings := map[string]string{}
+	for _, setting := range strings.Split(connectionString, ";") {
+		if key, value, found := strings.Cut(strings.TrimSpace(setting), "="); found {
+			settings[strings.ToLower(key)] = value
+		}
+	}
+	instrumentationKey := settings["instrumentationkey"]
+	if instrumentationKey == "" {
+		return nil, fmt.Errorf("connection string has no InstrumentationKey")
+	}
+	endpoint := settings["ingestionendpoint"]
+	if endpoint == "" {
+		endpoint = defaultIngestionEndpoint
+	}
+	return &AzureMonitor{
+		endpoint:           strings.TrimSuffix(endpoint, "/") + "/v2/track",
+		instrumentationKey: instrumentationKey,
+		client:             &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (a *AzureMonitor) envelope(sample Sample) azureEnvelope {
+	properties := map[string]string{
+		"resource":     sample.Resource,
+		"runNamespace": sample.Namespace,
+		"runName":      sample.Name,
+		"runUid":       sample.UID,
+	}
+	for tag, value := range sample.Tags {
+		properties[tag] = value
+	}
+	return azureEnvelope{
+		Name: "Microsoft.ApplicationInsights.Metric",
+		Time: sample.Time.UTC().Format(time.RFC3339Nano),
+		IKey: a.instrumentationKey,
+		Tags: map[string]string{"ai.cloud.role": "tekton-metrics-operator"},
+		Data: azureData{
+			BaseType: "MetricData",
+			BaseData: azureMetricData{
+				Ver:        2,
+				Metrics:    []azureDataPoint{{Name: sample.Metric, Value: sample.Value, Count: 1}},
+				Properties: properties,
+			},
+		},
+	}
+}
+
+func (a *AzureMonitor) Write(ctx context.Context, samples []Sample) error {
+	envelopes := make([]azureEnvelope, 0, len(samples))
+	for _, sample := range samples {
+		envelopes = append(envelopes, a.envelope(sample))
+	}
+	body, err := json.Marshal(envelopes)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := a.client.Do(request)
+	if err != nil {
+		return recorder.Retryable(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusPartialContent {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return retryable(response.StatusCode, fmt.Errorf("azure monitor returned %s: %s", response.Status, bytes.TrimSpace(message)))
+	}
+	trackResponse := azureTrackResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&trackResponse); err != nil {
+		return err
+	}
+	if len(trackResponse.Errors) > 0 {
+		first := trackResponse.Errors[0]
+		return fmt.Errorf("%d of %d samples rejected, sample %d: %s", trackResponse.ItemsReceived-trackResponse.ItemsAccepted, len(samples), first.Index, first.Message)
+	}
+	return nil
+}
+
+func (a *AzureMonitor) Close() error {
+	a.client.CloseIdleConnections()
+	return nil
+}
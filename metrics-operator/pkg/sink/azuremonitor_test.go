@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+func TestNewAzureMonitor(t *testing.T) {
+	tests := []struct {
+		connectionString string
+		wantEndpoint     string
+		wantErr          bool
+	}{
+		{connectionString: "InstrumentationKey=abc", wantEndpoint: "https://dc.services.visualstudio.com/v2/track"},
+		{connectionString: "InstrumentationKey=abc; IngestionEndpoint=https://westeurope-5.in.applicationinsights.azure.com/", wantEndpoint: "https://westeurope-5.in.applicationinsights.azure.com/v2/track"},
+		{connectionString: "IngestionEndpoint=https://westeurope-5.in.applicationinsights.azure.com/", wantErr: true},
+	}
+	for _, tt := range tests {
+		a, err := NewAzureMonitor(tt.connectionString)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: want an error", tt.connectionString)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.endpoint != tt.wantEndpoint || a.instrumentationKey != "abc" {
+			t.Errorf("%q: want endpoint %s, got %s with key %q", tt.connectionString, tt.wantEndpoint, a.endpoint, a.instrumentationKey)
+		}
+	}
+}
+
+func TestAzureMonitorWrite(t *testing.T) {
+	var envelopes []azureEnvelope
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&envelopes); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"itemsReceived": 1, "itemsAccepted": 1, "errors": []}`))
+	}))
+	defer server.Close()
+
+	a := &AzureMonitor{endpoint: server.URL, instrumentationKey: "abc", client: server.Client()}
+	sample := Sample{
+		Time:      time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC),
+		Metric:    "task_build_runs_total",
+		Resource:  "taskrun",
+		Namespace: "dev",
+		Name:      "build-x1",
+		UID:       "1234",
+		Tags:      map[string]string{"status": "success"},
+		Value:     1,
+	}
+	if err := a.Write(context.Background(), []Sample{sample}); err != nil {
+		t.Fatal(err)
+	}
+	want := []azureEnvelope{{
+		Name: "Microsoft.ApplicationInsights.Metric",
+		Time: "2023-08-01T10:05:00Z",
+		IKey: "abc",
+		Tags: map[string]string{"ai.cloud.role": "tekton-metrics-operator"},
+		Data: azureData{
+			BaseType: "MetricData",
+			BaseData: azureMetricData{
+				Ver:     2,
+				Metrics: []azureDataPoint{{Name: "task_build_runs_total", Value: 1, Count: 1}},
+				Properties: map[string]string{
+					"resource":     "taskrun",
+					"runNamespace": "dev",
+					"runName":      "build-x1",
+					"runUid":       "1234",
+					"status":       "success",
+				},
+			},
+		},
+	}}
+	if diff := cmp.Diff(want, envelopes); diff != "" {
+		t.Errorf("envelopes (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAzureMonitorWriteErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		wantRetryable bool
+	}{
+		{name: "throttled", statusCode: http.StatusTooManyRequests, wantRetryable: true},
+		{name: "bad request", statusCode: http.StatusBadRequest},
+		{name: "partially rejected", statusCode: http.StatusPartialContent, body: `{"itemsReceived": 2, "itemsAccepted": 1, "errors": [{"index": 1, "statusCode": 400, "message": "invalid"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			a := &AzureMonitor{endpoint: server.URL, instrumentationKey: "abc", client: server.Client()}
+			err := a.Write(context.Background(), []Sample{{Metric: "a"}, {Metric: "b"}})
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if recorder.IsRetryable(err) != tt.wantRetryable {
+				t.Errorf("want retryable %v, got %v", tt.wantRetryable, err)
+			}
+		})
+	}
+}
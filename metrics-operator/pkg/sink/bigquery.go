@@ -0,0 +1,158 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const bigQueryScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+
+// BigQueryConfig locates the table receiving the samples
+type BigQueryConfig struct {
+	Project string
+	Dataset string
+	Table   string
+	// Credentials is a service account key in JSON, the application default
+	// credentials are used when empty, e.g. with workload identity
+	Credentials []byte
+}
+
+// BigQuery streams one row per sample into a table with the insertAll API
+type BigQuery struct {
+	endpoint string
+	client   *http.Client
+}
+
+type bigQueryRow struct {
+	InsertID string         `json:"insertId"`
+	JSON     map[string]any `json:"json"`
+}
+
+type bigQueryInsertRequest struct {
+	Rows []bigQueryRow `json:"rows"`
+}
+
+type bigQueryInsertResponse struct {
+	InsertErrors []struct {
+		Index  int `json:"index"`
+		Errors []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"insertErrors"`
+}
+
+func NewBigQuery(ctx context.Context, config BigQueryConfig) (*BigQuery, error) {
+	if config.Project == "" || config.Dataset == "" || config.Table == "" {
+		return nil, fmt.Errorf("project, dataset and table are required")
+	}
+	var client *http.Client
+	if len(config.Credentials) > 0 {
+		credentials, err := google.CredentialsFromJSON(ctx, config.Credentials, bigQueryScope)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials: %w", err)
+		}
+		client = oauth2.NewClient(ctx, credentials.TokenSource)
+	} else {
+		var err error
+		client, err = google.DefaultClient(ctx, bigQueryScope)
+		if err != nil {
+			return nil, fmt.Errorf("no default credentials: %w", err)
+		}
+	}
+	return &BigQuery{
+		endpoint: fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+			url.PathEscape(config.Project), url.PathEscape(config.Dataset), url.PathEscape(config.Table)),
+		client: client,
+	}, nil
+}
+
+// Write inserts the samples in a single request, the insert ids let
+// BigQuery drop the rows of a request sent again
+func (b *BigQuery) Write(ctx context.Context, samples []Sample) error {
+	request := bigQueryInsertRequest{Rows: make([]bigQueryRow, 0, len(samples))}
+	for _, sample := range samples {
+		tags, err := json.Marshal(sample.Tags)
+		if err != nil {
+			return err
+		}
+		request.Rows = append(request.Rows, bigQueryRow{
+			InsertID: bigQueryInsertID(sample, tags),
+			JSON: map[string]any{
+				"time":      formatTime(&sample.Time),
+				"metric":    sample.Metric,
+				"resource":  sample.Resource,
+				"namespace": sample.Namespace,
+				"name":      sample.Name,
+				"uid":       sample.UID,
+				"tags":      string(tags),
+				"value":     sample.Value,
+				"created":   formatTime(sample.Created),
+				"started":   formatTime(sample.Started),
+				"completed": formatTime(sample.Completed),
+			},
+		})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	response, err := b.client.Do(httpRequest)
+	if err != nil {
+		return recorder.Retryable(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return retryable(response.StatusCode, fmt.Errorf("bigquery returned %s: %s", response.Status, bytes.TrimSpace(message)))
+	}
+	insertResponse := bigQueryInsertResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&insertResponse); err != nil {
+		return err
+	}
+	if len(insertResponse.InsertErrors) > 0 {
+		first := insertResponse.InsertErrors[0]
+		message := "unknown error"
+		if len(first.Errors) > 0 {
+			message = first.Errors[0].Message
+		}
+		return fmt.Errorf("%d of %d rows rejected, row %d: %s", len(insertResponse.InsertErrors), len(samples), first.Index, message)
+	}
+	return nil
+}
+
+// bigQueryInsertID identifies the sample by its run, metric, time and tags,
+// the samples of a run differing only by their tags get distinct rows. The
+// tags are marshalled with sorted keys and everything is hashed to stay under
+// the 128 characters BigQuery accepts.
+func bigQueryInsertID(sample Sample, tags []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{sample.UID, sample.Metric, strconv.FormatInt(sample.Time.UnixNano(), 10)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(tags)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (b *BigQuery) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,95 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBigQueryWrite(t *testing.T) {
+	var request bigQueryInsertRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	b := &BigQuery{endpoint: server.URL, client: server.Client()}
+	sample := Sample{
+		Time:      time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC),
+		Metric:    "task_build_runs_total",
+		Resource:  "taskrun",
+		Namespace: "dev",
+		Name:      "build-x1",
+		UID:       "1234",
+		Tags:      map[string]string{"status": "success"},
+		Value:     1,
+	}
+	if err := b.Write(context.Background(), []Sample{sample}); err != nil {
+		t.Fatal(err)
+	}
+	// the insert id is stable so the row of a retried request is dropped
+	want := bigQueryInsertRequest{Rows: []bigQueryRow{{
+		InsertID: "c40557ea83ef34c41bdf723230b2eda31dd9a75979f7748f6e83579fa7172c0c",
+		JSON: map[string]any{
+			"time":      "2023-08-01T10:05:00Z",
+			"metric":    "task_build_runs_total",
+			"resource":  "taskrun",
+			"namespace": "dev",
+			"name":      "build-x1",
+			"uid":       "1234",
+			"tags":      `{"status":"success"}`,
+			"value":     1.0,
+			"created":   nil,
+			"started":   nil,
+			"completed": nil,
+		},
+	}}}
+	if diff := cmp.Diff(want, request); diff != "" {
+		t.Errorf("insert request (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBigQueryInsertID(t *testing.T) {
+	sample := func(tags map[string]string) Sample {
+		return Sample{Time: time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC), Metric: "task_build_steps_total", UID: "1234", Tags: tags}
+	}
+	insertID := func(sample Sample) string {
+		tags, err := json.Marshal(sample.Tags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bigQueryInsertID(sample, tags)
+	}
+	build := insertID(sample(map[string]string{"step": "build", "status": "success"}))
+	if got := insertID(sample(map[string]string{"status": "success", "step": "build"})); got != build {
+		t.Errorf("want the same insert id for the same tags, got %s and %s", build, got)
+	}
+	// the samples of every step of a run are recorded at the same time
+	if got := insertID(sample(map[string]string{"step": "test", "status": "success"})); got == build {
+		t.Errorf("want distinct insert ids for distinct tags, got %s twice", got)
+	}
+	if len(build) > 128 {
+		t.Errorf("want an insert id of at most 128 characters, got %d", len(build))
+	}
+}
+
+func TestBigQueryWriteInsertErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"insertErrors": [{"index": 1, "errors": [{"reason": "invalid", "message": "no such field"}]}]}`))
+	}))
+	defer server.Close()
+
+	b := &BigQuery{endpoint: server.URL, client: server.Client()}
+	err := b.Write(context.Background(), []Sample{{Metric: "a"}, {Metric: "b"}})
+	if want := "1 of 2 rows rejected, row 1: no such field"; err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,92 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+func TestClickHouseWrite(t *testing.T) {
+	var query, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		if database := r.URL.Query().Get("database"); database != "tekton" {
+			t.Errorf("want the database of the endpoint kept, got %q", database)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	clickHouse, err := NewClickHouse(server.URL+"/?database=tekton", "samples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	samples := []Sample{{
+		Time:      time.Date(2023, 8, 1, 10, 5, 0, 500, time.UTC),
+		Metric:    "task_build_duration_seconds",
+		Resource:  "taskrun",
+		Namespace: "dev",
+		Name:      "build-x1",
+		UID:       "1234",
+		Tags:      map[string]string{"status": "success"},
+		Value:     300,
+		Unit:      "s",
+		Started:   &started,
+	}, {
+		Time:   time.Date(2023, 8, 1, 10, 6, 0, 0, time.FixedZone("CEST", 2*60*60)),
+		Metric: "task_build_runs_total",
+		Tags:   map[string]string{},
+		Value:  1,
+	}}
+	if err := clickHouse.Write(context.Background(), samples); err != nil {
+		t.Fatal(err)
+	}
+
+	if query != "INSERT INTO samples FORMAT JSONEachRow" {
+		t.Errorf("unexpected query %q", query)
+	}
+	// one JSON object per line, timestamps in UTC
+	want := []string{
+		`{"time":"2023-08-01T10:05:00.0000005Z","metric":"task_build_duration_seconds","resource":"taskrun","namespace":"dev","name":"build-x1","uid":"1234","tags":{"status":"success"},"value":300,"unit":"s","created":null,"started":"2023-08-01T10:00:00Z","completed":null}`,
+		`{"time":"2023-08-01T08:06:00Z","metric":"task_build_runs_total","resource":"","namespace":"","name":"","uid":"","tags":{},"value":1,"unit":"","created":null,"started":null,"completed":null}`,
+	}
+	if diff := cmp.Diff(want, strings.Split(strings.TrimSpace(body), "\n")); diff != "" {
+		t.Errorf("rows (-want, +got):\n%s", diff)
+	}
+}
+
+func TestClickHouseWriteErrors(t *testing.T) {
+	tests := []struct {
+		statusCode    int
+		wantRetryable bool
+	}{
+		{statusCode: http.StatusBadRequest},
+		{statusCode: http.StatusServiceUnavailable, wantRetryable: true},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "failed", tt.statusCode)
+		}))
+		clickHouse, err := NewClickHouse(server.URL, "samples")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = clickHouse.Write(context.Background(), []Sample{{Metric: "a"}})
+		server.Close()
+		if err == nil {
+			t.Fatalf("status %d: want an error", tt.statusCode)
+		}
+		if recorder.IsRetryable(err) != tt.wantRetryable {
+			t.Errorf("status %d: want retryable %v, got %v", tt.statusCode, tt.wantRetryable, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// CloudWatchConfig locates the log group receiving the samples and maps
+// their tags to dimensions
+type CloudWatchConfig struct {
+	LogGroup  string
+	LogStream string
+	// Namespace of the metrics extracted by CloudWatch
+	Namespace string
+	// Dimensions maps the tags reported as dimensions to their dimension
+	// names, other tags are only properties of the log events
+	Dimensions map[string]string
+}
+
+// CloudWatch writes every sample as a log event in the Embedded Metric
+// Format, CloudWatch extracts the metrics from the log group. Credentials
+// come from the default chain, e.g. IAM roles for service accounts.
+type CloudWatch struct {
+	config CloudWatchConfig
+	client *cloudwatchlogs.Client
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit,omitempty"`
+}
+
+type emfRun struct {
+	Resource  string  `json:"resource"`
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	UID       string  `json:"uid"`
+	Created   *string `json:"created,omitempty"`
+	Started   *string `json:"started,omitempty"`
+	Completed *string `json:"completed,omitempty"`
+}
+
+// NewCloudWatch creates the log stream of the sink in the log group, which
+// must exist
+func NewCloudWatch(ctx context.Context, sinkConfig CloudWatchConfig) (*CloudWatch, error) {
+	if sinkConfig.LogGroup == "" || sinkConfig.LogStream == "" {
+		return nil, errors.New("log group and log stream are required")
+	}
+	awsConfig, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := cloudwatchlogs.NewFromConfig(awsConfig)
+	_, err = client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(sinkConfig.LogGroup),
+		LogStreamName: aws.String(sinkConfig.LogStream),
+	})
+	var exists *types.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return nil, err
+	}
+	return &CloudWatch{config: sinkConfig, client: client}, nil
+}
+
+// event renders the sample in the Embedded Metric Format, the value is a
+// property named after the metric
+func (c *CloudWatch) event(sample Sample) (string, error) {
+	dimensions := []string{}
+	event := map[string]any{}
+	for tag, value := range sample.Tags {
+		if dimension, found := c.config.Dimensions[tag]; found {
+			dimensions = append(dimensions, dimension)
+			event[dimension] = value
+			continue
+		}
+		event[tag] = value
+	}
+	sort.Strings(dimensions)
+	event[sample.Metric] = sample.Value
+	event["run"] = emfRun{
+		Resource:  sample.Resource,
+		Namespace: sample.Namespace,
+		Name:      sample.Name,
+		UID:       sample.UID,
+		Created:   formatTime(sample.Created),
+		Started:   formatTime(sample.Started),
+		Completed: formatTime(sample.Completed),
+	}
+	event["_aws"] = emfMetadata{
+		Timestamp: sample.Time.UnixMilli(),
+		CloudWatchMetrics: []emfDirective{{
+			Namespace:  c.config.Namespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    []emfMetric{{Name: sample.Metric, Unit: cloudWatchUnit(sample.Unit)}},
+		}},
+	}
+	message, err := json.Marshal(event)
+	return string(message), err
+}
+
+func (c *CloudWatch) Write(ctx context.Context, samples []Sample) error {
+	events := make([]types.InputLogEvent, 0, len(samples))
+	for _, sample := range samples {
+		message, err := c.event(sample)
+		if err != nil {
+			return err
+		}
+		events = append(events, types.InputLogEvent{
+			Message:   aws.String(message),
+			Timestamp: aws.Int64(sample.Time.UnixMilli()),
+		})
+	}
+	// the events of a batch must be in chronological order
+	sort.SliceStable(events, func(i, j int) bool {
+		return *events[i].Timestamp < *events[j].Timestamp
+	})
+	_, err := c.client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(c.config.LogGroup),
+		LogStreamName: aws.String(c.config.LogStream),
+		LogEvents:     events,
+	})
+	return err
+}
+
+func (c *CloudWatch) Close() error {
+	return nil
+}
+
+// cloudWatchUnit translates the units of OpenCensus measures
+func cloudWatchUnit(unit string) string {
+	switch unit {
+	case "s":
+		return "Seconds"
+	case "ms":
+		return "Milliseconds"
+	case "By":
+		return "Bytes"
+	default:
+		return "None"
+	}
+}
@@ -0,0 +1,84 @@
+package sink
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCloudWatchEvent(t *testing.T) {
+	c := &CloudWatch{config: CloudWatchConfig{
+		Namespace:  "Tekton",
+		Dimensions: map[string]string{"task": "Task", "namespace": "Namespace"},
+	}}
+	started := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	message, err := c.event(Sample{
+		Time:      time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC),
+		Metric:    "task_build_duration_seconds",
+		Resource:  "taskrun",
+		Namespace: "dev",
+		Name:      "build-x1",
+		UID:       "1234",
+		Tags:      map[string]string{"task": "build", "namespace": "dev", "status": "success"},
+		Value:     300,
+		Unit:      "s",
+		Started:   &started,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]any{}
+	if err := json.Unmarshal([]byte(message), &got); err != nil {
+		t.Fatal(err)
+	}
+	// dimensions are renamed and sorted, other tags are plain properties
+	want := map[string]any{
+		"Task":                        "build",
+		"Namespace":                   "dev",
+		"status":                      "success",
+		"task_build_duration_seconds": 300.0,
+		"run": map[string]any{
+			"resource":  "taskrun",
+			"namespace": "dev",
+			"name":      "build-x1",
+			"uid":       "1234",
+			"started":   "2023-08-01T10:00:00Z",
+		},
+		"_aws": map[string]any{
+			"Timestamp": float64(time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC).UnixMilli()),
+			"CloudWatchMetrics": []any{map[string]any{
+				"Namespace":  "Tekton",
+				"Dimensions": []any{[]any{"Namespace", "Task"}},
+				"Metrics":    []any{map[string]any{"Name": "task_build_duration_seconds", "Unit": "Seconds"}},
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("event (-want, +got):\n%s", diff)
+	}
+}
+
+func TestCloudWatchEventWithoutDimensions(t *testing.T) {
+	c := &CloudWatch{config: CloudWatchConfig{Namespace: "Tekton"}}
+	message, err := c.event(Sample{Metric: "task_build_runs_total", Tags: map[string]string{"task": "build"}, Value: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := struct {
+		AWS emfMetadata `json:"_aws"`
+	}{}
+	if err := json.Unmarshal([]byte(message), &got); err != nil {
+		t.Fatal(err)
+	}
+	// CloudWatch expects a dimension set, even an empty one
+	want := []emfDirective{{
+		Namespace:  "Tekton",
+		Dimensions: [][]string{{}},
+		Metrics:    []emfMetric{{Name: "task_build_runs_total", Unit: "None"}},
+	}}
+	if diff := cmp.Diff(want, got.AWS.CloudWatchMetrics); diff != "" {
+		t.Errorf("metric directives (-want, +got):\n%s", diff)
+	}
+}
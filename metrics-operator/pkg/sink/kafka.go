@@ -0,0 +1,99 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+// Kafka produces every sample as a JSON message on a topic, for stream
+// processing downstream
+type Kafka struct {
+	writer  kafkaWriter
+	keyTags []string
+}
+
+// kafkaWriter is the kafka.Writer producing the messages
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// NewKafka produces to the topic of the brokers, messages are keyed by the
+// values of the key tags so samples of the same series land on the same
+// partition, by the run uid when there are none
+func NewKafka(brokers []string, topic string, keyTags []string) *Kafka {
+	return &Kafka{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: false,
+		},
+		keyTags: keyTags,
+	}
+}
+
+func (k *Kafka) key(sample Sample) []byte {
+	if len(k.keyTags) == 0 {
+		return []byte(sample.UID)
+	}
+	values := make([]string, 0, len(k.keyTags))
+	for _, tag := range k.keyTags {
+		values = append(values, sample.Tags[tag])
+	}
+	return []byte(strings.Join(values, "/"))
+}
+
+func (k *Kafka) Write(ctx context.Context, samples []Sample) error {
+	messages := make([]kafka.Message, 0, len(samples))
+	for _, sample := range samples {
+		value, err := json.Marshal(sample)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, kafka.Message{
+			Key:   k.key(sample),
+			Value: value,
+			Time:  sample.Time,
+		})
+	}
+	if err := k.writer.WriteMessages(ctx, messages...); err != nil {
+		if temporary(err) {
+			return recorder.Retryable(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// temporary returns true when the write may succeed later, the brokers
+// reported a temporary error, like a leader election, or the network timed
+// out. Partial writes are temporary when every failed message is.
+func temporary(err error) bool {
+	var writeErrors kafka.WriteErrors
+	if errors.As(err, &writeErrors) {
+		for _, err := range writeErrors {
+			if err != nil && !temporary(err) {
+				return false
+			}
+		}
+		return writeErrors.Count() > 0
+	}
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr.Temporary()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,121 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/segmentio/kafka-go"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+// fakeKafkaWriter keeps the produced messages, or fails with err
+type fakeKafkaWriter struct {
+	messages []kafka.Message
+	err      error
+}
+
+func (f *fakeKafkaWriter) WriteMessages(_ context.Context, messages ...kafka.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.messages = append(f.messages, messages...)
+	return nil
+}
+
+func (f *fakeKafkaWriter) Close() error {
+	return nil
+}
+
+func TestKafkaKey(t *testing.T) {
+	sample := Sample{UID: "1234", Tags: map[string]string{"namespace": "dev", "task": "build", "status": "success"}}
+	tests := []struct {
+		name    string
+		keyTags []string
+		want    string
+	}{
+		{name: "run uid without key tags", want: "1234"},
+		{name: "single tag", keyTags: []string{"task"}, want: "build"},
+		{name: "tags in order", keyTags: []string{"task", "namespace"}, want: "build/dev"},
+		{name: "missing tag", keyTags: []string{"namespace", "pipeline"}, want: "dev/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := NewKafka([]string{"localhost:9092"}, "samples", tt.keyTags)
+			if got := string(k.key(sample)); got != tt.want {
+				t.Errorf("want key %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKafkaWrite(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	k := &Kafka{writer: writer, keyTags: []string{"task"}}
+	at := time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC)
+	sample := Sample{
+		Time:      at,
+		Metric:    "task_build_duration_seconds",
+		Resource:  "taskrun",
+		Namespace: "dev",
+		Name:      "build-x1",
+		UID:       "1234",
+		Tags:      map[string]string{"task": "build"},
+		Value:     300,
+		Unit:      "s",
+	}
+	if err := k.Write(context.Background(), []Sample{sample}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(writer.messages) != 1 {
+		t.Fatalf("want a message per sample, got %d", len(writer.messages))
+	}
+	message := writer.messages[0]
+	if string(message.Key) != "build" || !message.Time.Equal(at) {
+		t.Errorf("want the key build at %v, got %q at %v", at, message.Key, message.Time)
+	}
+	want := `{"time":"2023-08-01T10:05:00Z","metric":"task_build_duration_seconds","resource":"taskrun","namespace":"dev","name":"build-x1","uid":"1234","tags":{"task":"build"},"value":300,"unit":"s"}`
+	if string(message.Value) != want {
+		t.Errorf("want the message %s, got %s", want, message.Value)
+	}
+	var decoded Sample
+	if err := json.Unmarshal(message.Value, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(sample, decoded); diff != "" {
+		t.Errorf("decoded sample (-want, +got):\n%s", diff)
+	}
+}
+
+func TestKafkaWriteErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+	}{
+		{name: "leader election", err: kafka.LeaderNotAvailable, wantRetryable: true},
+		{name: "message too large", err: kafka.MessageSizeTooLarge},
+		{name: "network timeout", err: &net.DNSError{Err: "i/o timeout", Name: "kafka", IsTimeout: true}, wantRetryable: true},
+		{name: "deadline", err: context.DeadlineExceeded, wantRetryable: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+		{name: "temporary partial write", err: kafka.WriteErrors{nil, kafka.LeaderNotAvailable}, wantRetryable: true},
+		{name: "partial write", err: kafka.WriteErrors{kafka.LeaderNotAvailable, kafka.MessageSizeTooLarge}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &Kafka{writer: &fakeKafkaWriter{err: tt.err}}
+			err := k.Write(context.Background(), []Sample{{Metric: "a"}})
+			if err == nil || err.Error() != tt.err.Error() {
+				t.Fatalf("want the write error, got %v", err)
+			}
+			if recorder.IsRetryable(err) != tt.wantRetryable {
+				t.Errorf("want retryable %v, got %v", tt.wantRetryable, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Package sink writes every recorded sample with a reference to its run to
+// external stores, for ad-hoc analyses that pre-aggregated series can't
+// answer.
+package sink
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"go.uber.org/zap"
+)
+
+// Sample is a single value recorded for a run
+type Sample struct {
+	Time      time.Time         `json:"time"`
+	Metric    string            `json:"metric"`
+	Resource  string            `json:"resource"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	UID       string            `json:"uid"`
+	Tags      map[string]string `json:"tags"`
+	Value     float64           `json:"value"`
+	Unit      string            `json:"unit"`
+	Created   *time.Time        `json:"created,omitempty"`
+	Started   *time.Time        `json:"started,omitempty"`
+	Completed *time.Time        `json:"completed,omitempty"`
+}
+
+// Sink writes batches of samples to a store
+type Sink interface {
+	Write(ctx context.Context, samples []Sample) error
+	Close() error
+}
+
+type BufferOptions struct {
+	// Size is the number of samples waiting to be written before new ones
+	// are dropped
+	Size int
+	// BatchSize is the maximum number of samples per write
+	BatchSize int
+	// FlushInterval between two writes of the pending samples
+	FlushInterval time.Duration
+	// Retry schedules a failed write to be retried with backoff, it returns
+	// false when the write is dropped. Failed writes are dropped when unset.
+	Retry func(id string, write func(ctx context.Context) error) bool
+}
+
+// Buffered writes samples to the sink in batches, asynchronously so slow
+// stores don't delay recording
+type Buffered struct {
+	name    string
+	sink    Sink
+	options BufferOptions
+	samples chan Sample
+	logger  *zap.SugaredLogger
+	dropped int64
+	mu      sync.Mutex
+}
+
+func NewBuffered(name string, sink Sink, options BufferOptions, logger *zap.SugaredLogger) *Buffered {
+	if options.Size <= 0 {
+		options.Size = 10000
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 500
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = 10 * time.Second
+	}
+	return &Buffered{
+		name:    name,
+		sink:    sink,
+		options: options,
+		samples: make(chan Sample, options.Size),
+		logger:  logger.With(zap.String("sink", name)),
+	}
+}
+
+// Name of the sink in logs
+func (b *Buffered) Name() string {
+	return b.name
+}
+
+// Add queues the sample, false when the buffer is full and it's dropped
+func (b *Buffered) Add(sample Sample) bool {
+	select {
+	case b.samples <- sample:
+		return true
+	default:
+		b.mu.Lock()
+		b.dropped++
+		b.mu.Unlock()
+		return false
+	}
+}
+
+// Run writes the queued samples until the context is done, then flushes the
+// remaining ones and closes the sink
+func (b *Buffered) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.options.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]Sample, 0, b.options.BatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.sink.Write(ctx, batch); err != nil {
+			b.retry(batch, err)
+		}
+		batch = batch[:0]
+		b.mu.Lock()
+		if b.dropped > 0 {
+			b.logger.Warnw("samples dropped, the buffer was full", zap.Int64("samples", b.dropped))
+			b.dropped = 0
+		}
+		b.mu.Unlock()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+		drain:
+			for {
+				select {
+				case sample := <-b.samples:
+					batch = append(batch, sample)
+					if len(batch) >= b.options.BatchSize {
+						flush(context.Background())
+					}
+				default:
+					break drain
+				}
+			}
+			flush(context.Background())
+			if err := b.sink.Close(); err != nil {
+				b.logger.Errorw("failed to close sink", zap.Error(err))
+			}
+			return
+		case sample := <-b.samples:
+			batch = append(batch, sample)
+			if len(batch) >= b.options.BatchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
+
+// retry hands the failed batch to the retry queue when the failure is
+// transient, e.g. the store is unreachable or throttling
+func (b *Buffered) retry(batch []Sample, err error) {
+	if b.options.Retry != nil && recorder.IsRetryable(err) {
+		samples := append([]Sample(nil), batch...)
+		write := func(ctx context.Context) error {
+			return b.sink.Write(ctx, samples)
+		}
+		if b.options.Retry("sink/"+b.name, write) {
+			b.logger.Warnw("failed to write samples, retrying", zap.Int("samples", len(batch)), zap.Error(err))
+			return
+		}
+	}
+	b.logger.Errorw("failed to write samples", zap.Int("samples", len(batch)), zap.Error(err))
+}
+
+// retryable marks the failures of requests that may succeed later, the
+// request didn't reach the store, was throttled or failed on its side
+func retryable(statusCode int, err error) error {
+	if statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError {
+		return recorder.Retryable(err)
+	}
+	return err
+}
@@ -0,0 +1,195 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+	"go.uber.org/zap"
+)
+
+// fakeSink keeps the written batches, failing with err while set
+type fakeSink struct {
+	batches [][]Sample
+	err     error
+	closed  bool
+	mu      sync.Mutex
+}
+
+func (f *fakeSink) Write(_ context.Context, samples []Sample) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.batches = append(f.batches, append([]Sample{}, samples...))
+	return nil
+}
+
+func (f *fakeSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeSink) get() [][]Sample {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]Sample{}, f.batches...)
+}
+
+func (f *fakeSink) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func TestBufferedRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantRetry bool
+	}{
+		{name: "retryable", err: recorder.Retryable(errors.New("store unreachable")), wantRetry: true},
+		{name: "not retryable", err: errors.New("invalid sample")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &fakeSink{err: tt.err}
+			var retried []func(ctx context.Context) error
+			retry := func(id string, write func(ctx context.Context) error) bool {
+				if id != "sink/fake" {
+					t.Errorf("want the write named after the sink, got %q", id)
+				}
+				retried = append(retried, write)
+				return true
+			}
+			buffered := NewBuffered("fake", s, BufferOptions{BatchSize: 2, FlushInterval: time.Hour, Retry: retry}, zap.NewNop().Sugar())
+			buffered.Add(Sample{Metric: "a"})
+			buffered.Add(Sample{Metric: "b"})
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			buffered.Run(ctx)
+
+			if !tt.wantRetry {
+				if len(retried) > 0 {
+					t.Errorf("want no retry, got %d", len(retried))
+				}
+				return
+			}
+			if len(retried) != 1 {
+				t.Fatalf("want the failed batch retried once, got %d", len(retried))
+			}
+			s.fail(nil)
+			if err := retried[0](context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			batches := s.get()
+			if len(batches) != 1 || len(batches[0]) != 2 || batches[0][0].Metric != "a" || batches[0][1].Metric != "b" {
+				t.Errorf("want the failed batch written by the retry, got %v", batches)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       bool
+	}{
+		{statusCode: 400},
+		{statusCode: 404},
+		{statusCode: 429, want: true},
+		{statusCode: 500, want: true},
+		{statusCode: 503, want: true},
+	}
+	for _, tt := range tests {
+		if got := recorder.IsRetryable(retryable(tt.statusCode, errors.New("failed"))); got != tt.want {
+			t.Errorf("status %d: want retryable %v, got %v", tt.statusCode, tt.want, got)
+		}
+	}
+}
+
+func TestBufferedBatches(t *testing.T) {
+	s := &fakeSink{}
+	buffered := NewBuffered("fake", s, BufferOptions{BatchSize: 2, FlushInterval: time.Hour}, zap.NewNop().Sugar())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		buffered.Run(ctx)
+		close(done)
+	}()
+	for _, metric := range []string{"a", "b", "c"} {
+		if !buffered.Add(Sample{Metric: metric}) {
+			t.Fatalf("want sample %s queued", metric)
+		}
+	}
+	// a full batch is written without waiting for the flush interval
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.get()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if batches := s.get(); len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("want a single batch of 2 samples before the flush, got %v", batches)
+	}
+
+	// the remaining samples are flushed when the context is done
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("buffer didn't stop with its context")
+	}
+	batches := s.get()
+	if len(batches) != 2 || len(batches[1]) != 1 || batches[1][0].Metric != "c" {
+		t.Errorf("want the last sample flushed on shutdown, got %v", batches)
+	}
+	if !s.closed {
+		t.Error("want the sink closed on shutdown")
+	}
+}
+
+func TestBufferedFlushInterval(t *testing.T) {
+	s := &fakeSink{}
+	buffered := NewBuffered("fake", s, BufferOptions{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, zap.NewNop().Sugar())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go buffered.Run(ctx)
+	buffered.Add(Sample{Metric: "a"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.get()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if batches := s.get(); len(batches) != 1 || len(batches[0]) != 1 {
+		t.Errorf("want the partial batch written on the flush interval, got %v", batches)
+	}
+}
+
+func TestBufferedFull(t *testing.T) {
+	s := &fakeSink{}
+	buffered := NewBuffered("fake", s, BufferOptions{Size: 2, BatchSize: 10, FlushInterval: time.Hour}, zap.NewNop().Sugar())
+	// nothing writes the samples while the buffer isn't running
+	for i, want := range []bool{true, true, false} {
+		if got := buffered.Add(Sample{Metric: "a"}); got != want {
+			t.Errorf("sample %d: want queued %v, got %v", i+1, want, got)
+		}
+	}
+	if buffered.dropped != 1 {
+		t.Errorf("want 1 dropped sample counted, got %d", buffered.dropped)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buffered.Run(ctx)
+	if batches := s.get(); len(batches) != 1 || len(batches[0]) != 2 {
+		t.Errorf("want the queued samples written, got %v", batches)
+	}
+	if buffered.dropped != 0 {
+		t.Errorf("want the dropped samples reported on flush, got %d", buffered.dropped)
+	}
+}
@@ -0,0 +1,103 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+var sampleColumns = []string{"time", "metric", "resource", "namespace", "name", "uid", "tags", "value", "unit", "created", "started", "completed"}
+
+// numberedPlaceholders formats the placeholders of the drivers not using the
+// ? of MySQL, SQLite or ClickHouse
+var numberedPlaceholders = map[string]string{
+	"postgres":  "$%d",
+	"pgx":       "$%d",
+	"sqlserver": "@p%d",
+	"oracle":    ":%d",
+	"godror":    ":%d",
+}
+
+// SQL writes one row per sample to a table of a database/sql database, the
+// driver must be registered by the binary
+type SQL struct {
+	db     *sql.DB
+	insert string
+}
+
+// NewSQL opens the database, rows are inserted in the table with the
+// columns listed in the README
+func NewSQL(driver, dsn, table string) (*SQL, error) {
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &SQL{
+		db:     db,
+		insert: insertStatement(driver, table),
+	}, nil
+}
+
+// insertStatement inserts a sample in the table, with the placeholders of the
+// driver
+func insertStatement(driver, table string) string {
+	placeholders := make([]string, len(sampleColumns))
+	for i := range sampleColumns {
+		placeholders[i] = "?"
+		if format, numbered := numberedPlaceholders[driver]; numbered {
+			placeholders[i] = fmt.Sprintf(format, i+1)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(sampleColumns, ", "), strings.Join(placeholders, ", "))
+}
+
+// Write inserts the samples in a single transaction
+func (s *SQL) Write(ctx context.Context, samples []Sample) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		// the database is unreachable
+		return recorder.Retryable(err)
+	}
+	statement, err := tx.PrepareContext(ctx, s.insert)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer statement.Close()
+	for _, sample := range samples {
+		tags, err := json.Marshal(sample.Tags)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = statement.ExecContext(ctx, sample.Time, sample.Metric, sample.Resource, sample.Namespace, sample.Name, sample.UID, string(tags), sample.Value, sample.Unit,
+			nullTime(sample.Created), nullTime(sample.Started), nullTime(sample.Completed))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQL) Close() error {
+	return s.db.Close()
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -0,0 +1,204 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics/recorder"
+)
+
+func init() {
+	sql.Register("fakesql", fakeDriver{})
+}
+
+// fakeDatabases are the databases of the fake driver by DSN
+var fakeDatabases sync.Map
+
+// fakeDatabase keeps the statements prepared and the rows committed
+type fakeDatabase struct {
+	// openErr fails connections, like an unreachable database
+	openErr error
+	// execErr fails inserts, like a missing column
+	execErr    error
+	statements []string
+	rows       [][]driver.Value
+	mu         sync.Mutex
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	db, ok := fakeDatabases.Load(dsn)
+	if !ok {
+		return nil, errors.New("unknown database")
+	}
+	if err := db.(*fakeDatabase).openErr; err != nil {
+		return nil, err
+	}
+	return &fakeConn{db: db.(*fakeDatabase)}, nil
+}
+
+// fakeConn is its own transaction, rows are kept until they're committed
+type fakeConn struct {
+	db      *fakeDatabase
+	pending [][]driver.Value
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.statements = append(c.db.statements, query)
+	return &fakeStmt{conn: c}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending = nil
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.rows = append(c.db.rows, c.pending...)
+	c.pending = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+type fakeStmt struct {
+	conn *fakeConn
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return len(sampleColumns)
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.conn.db.execErr; err != nil {
+		return nil, err
+	}
+	s.conn.pending = append(s.conn.pending, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries aren't supported")
+}
+
+func TestInsertStatement(t *testing.T) {
+	columns := "INSERT INTO tekton.samples (time, metric, resource, namespace, name, uid, tags, value, unit, created, started, completed) "
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{driver: "postgres", want: columns + "VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)"},
+		{driver: "sqlserver", want: columns + "VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8, @p9, @p10, @p11, @p12)"},
+		{driver: "mysql", want: columns + "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"},
+	}
+	for _, tt := range tests {
+		if got := insertStatement(tt.driver, "tekton.samples"); got != tt.want {
+			t.Errorf("driver %s: want %q, got %q", tt.driver, tt.want, got)
+		}
+	}
+}
+
+func TestNewSQLTableName(t *testing.T) {
+	for _, table := range []string{"", "samples; DROP TABLE runs", "1samples", "a.b.c", "tekton-samples"} {
+		if _, err := NewSQL("postgres", "", table); err == nil {
+			t.Errorf("want an error for table %q", table)
+		}
+	}
+}
+
+func TestSQLWrite(t *testing.T) {
+	db := &fakeDatabase{}
+	fakeDatabases.Store("write", db)
+	s, err := NewSQL("fakesql", "write", "samples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	at := time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC)
+	started := at.Add(-5 * time.Minute)
+	samples := []Sample{{
+		Time:      at,
+		Metric:    "task_build_duration_seconds",
+		Resource:  "taskrun",
+		Namespace: "dev",
+		Name:      "build-x1",
+		UID:       "1234",
+		Tags:      map[string]string{"status": "success"},
+		Value:     300,
+		Unit:      "s",
+		Started:   &started,
+	}, {
+		Time:   at,
+		Metric: "task_build_runs_total",
+		Value:  1,
+	}}
+	if err := s.Write(context.Background(), samples); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{insertStatement("fakesql", "samples")}, db.statements); diff != "" {
+		t.Errorf("statements (-want, +got):\n%s", diff)
+	}
+	// missing timestamps are NULL
+	want := [][]driver.Value{
+		{at, "task_build_duration_seconds", "taskrun", "dev", "build-x1", "1234", `{"status":"success"}`, 300.0, "s", nil, started, nil},
+		{at, "task_build_runs_total", "", "", "", "", "null", 1.0, "", nil, nil, nil},
+	}
+	if diff := cmp.Diff(want, db.rows); diff != "" {
+		t.Errorf("rows (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSQLWriteErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		db            *fakeDatabase
+		wantRetryable bool
+	}{
+		{name: "unreachable", db: &fakeDatabase{openErr: errors.New("connection refused")}, wantRetryable: true},
+		{name: "rejected", db: &fakeDatabase{execErr: errors.New(`column "unit" does not exist`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeDatabases.Store(tt.name, tt.db)
+			s, err := NewSQL("fakesql", tt.name, "samples")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			err = s.Write(context.Background(), []Sample{{Metric: "a"}})
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if recorder.IsRetryable(err) != tt.wantRetryable {
+				t.Errorf("want retryable %v, got %v", tt.wantRetryable, err)
+			}
+			if len(tt.db.rows) != 0 {
+				t.Errorf("want the transaction rolled back, got %v", tt.db.rows)
+			}
+		})
+	}
+}
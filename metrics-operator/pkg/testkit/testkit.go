@@ -0,0 +1,196 @@
+// Package testkit runs monitors against an envtest API server, so changes to
+// monitors can be checked in CI before being applied to a cluster.
+//
+// The API server binaries are located with the KUBEBUILDER_ASSETS environment
+// variable, see sigs.k8s.io/controller-runtime/pkg/envtest.
+package testkit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	prom "contrib.go.opencensus.io/exporter/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/client/clientset/versioned"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/metrics"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/pipelinemonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/pipelinerunmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/taskmonitor"
+	"github.com/tektoncd/experimental/metrics-operator/pkg/reconciler/taskrunmonitor"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"go.opencensus.io/stats/view"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// Kit holds an API server with the monitoring CRDs and a manager recording
+// the metrics of the monitors applied to it.
+type Kit struct {
+	Client  versioned.Interface
+	Manager *metrics.MetricManager
+
+	env      *envtest.Environment
+	meter    view.Meter
+	exporter *prom.Exporter
+}
+
+// Start starts an API server with the CRDs found in the given paths, usually
+// the config directory of the operator.
+func Start(crdPaths ...string) (*Kit, error) {
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     crdPaths,
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		return nil, fmt.Errorf("error starting envtest: %w", err)
+	}
+	client, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		env.Stop()
+		return nil, err
+	}
+	exporter, err := prom.NewExporter(prom.Options{})
+	if err != nil {
+		env.Stop()
+		return nil, err
+	}
+	meter := view.NewMeter()
+	meter.Start()
+	meter.RegisterExporter(exporter)
+
+	return &Kit{
+		Client:   client,
+		Manager:  metrics.NewManager(meter, nil),
+		env:      env,
+		meter:    meter,
+		exporter: exporter,
+	}, nil
+}
+
+// Stop stops the meter and the API server.
+func (k *Kit) Stop() error {
+	k.meter.Stop()
+	return k.env.Stop()
+}
+
+// ApplyTaskMonitor creates or updates the TaskMonitor and registers its metrics.
+func (k *Kit) ApplyTaskMonitor(ctx context.Context, monitor *v1alpha1.TaskMonitor) error {
+	monitors := k.Client.MetricsV1alpha1().TaskMonitors(monitor.Namespace)
+	applied, err := monitors.Create(ctx, monitor, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		var existing *v1alpha1.TaskMonitor
+		existing, err = monitors.Get(ctx, monitor.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = monitor.Spec
+		applied, err = monitors.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return taskmonitor.NewReconciler(k.Manager, nil).ReconcileKind(ctx, applied)
+}
+
+// ApplyTaskRunMonitor creates or updates the TaskRunMonitor and registers its
+// metrics.
+func (k *Kit) ApplyTaskRunMonitor(ctx context.Context, monitor *v1alpha1.TaskRunMonitor) error {
+	monitors := k.Client.MetricsV1alpha1().TaskRunMonitors(monitor.Namespace)
+	applied, err := monitors.Create(ctx, monitor, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		var existing *v1alpha1.TaskRunMonitor
+		existing, err = monitors.Get(ctx, monitor.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = monitor.Spec
+		applied, err = monitors.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return taskrunmonitor.NewReconciler(k.Manager, nil).ReconcileKind(ctx, applied)
+}
+
+// ApplyPipelineMonitor creates or updates the PipelineMonitor and registers
+// its metrics.
+func (k *Kit) ApplyPipelineMonitor(ctx context.Context, monitor *v1alpha1.PipelineMonitor) error {
+	monitors := k.Client.MetricsV1alpha1().PipelineMonitors(monitor.Namespace)
+	applied, err := monitors.Create(ctx, monitor, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		var existing *v1alpha1.PipelineMonitor
+		existing, err = monitors.Get(ctx, monitor.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = monitor.Spec
+		applied, err = monitors.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return pipelinemonitor.NewReconciler(k.Manager, nil).ReconcileKind(ctx, applied)
+}
+
+// ApplyPipelineRunMonitor creates or updates the PipelineRunMonitor and
+// registers its metrics.
+func (k *Kit) ApplyPipelineRunMonitor(ctx context.Context, monitor *v1alpha1.PipelineRunMonitor) error {
+	monitors := k.Client.MetricsV1alpha1().PipelineRunMonitors(monitor.Namespace)
+	applied, err := monitors.Create(ctx, monitor, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		var existing *v1alpha1.PipelineRunMonitor
+		existing, err = monitors.Get(ctx, monitor.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Spec = monitor.Spec
+		applied, err = monitors.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return pipelinerunmonitor.NewReconciler(k.Manager, nil).ReconcileKind(ctx, applied)
+}
+
+// FeedTaskRun records the TaskRun as the operator would when observing it.
+func (k *Kit) FeedTaskRun(ctx context.Context, taskRun *pipelinev1beta1.TaskRun) error {
+	if taskRun.IsDone() {
+		return k.Manager.RecordTaskRunDone(ctx, taskRun)
+	}
+	return k.Manager.RecordTaskRunRunning(ctx, taskRun)
+}
+
+// FeedPipelineRun records the PipelineRun as the operator would when
+// observing it.
+func (k *Kit) FeedPipelineRun(ctx context.Context, pipelineRun *pipelinev1beta1.PipelineRun) error {
+	if pipelineRun.IsDone() {
+		return k.Manager.RecordPipelineRunDone(ctx, pipelineRun)
+	}
+	return k.Manager.RecordPipelineRunRunning(ctx, pipelineRun)
+}
+
+// Series returns the series exported for the metric, nil when the metric
+// isn't exported.
+func (k *Kit) Series(name string) ([]*dto.Metric, error) {
+	// measurements are processed asynchronously by the meter, retrieving data
+	// waits until the ones recorded before are processed
+	k.meter.RetrieveData("")
+	recorder := httptest.NewRecorder()
+	k.exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(recorder.Body)
+	if err != nil {
+		return nil, err
+	}
+	family, ok := families[name]
+	if !ok {
+		return nil, nil
+	}
+	return family.Metric, nil
+}
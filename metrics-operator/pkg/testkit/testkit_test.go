@@ -0,0 +1,79 @@
+package testkit
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/tektoncd/experimental/metrics-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+)
+
+func TestKit(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set")
+	}
+	kit, err := Start("../../config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kit.Stop()
+
+	ctx := context.Background()
+	err = kit.ApplyTaskMonitor(ctx, &v1alpha1.TaskMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kit",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.TaskMonitorSpec{
+			TaskName: "build",
+			Metrics: []v1alpha1.Metric{
+				{
+					Name: "status",
+					Type: "counter",
+					By: []v1alpha1.ByStatement{
+						{MetricDimensionRef: v1alpha1.MetricDimensionRef{Condition: ptr.String("Succeeded")}},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = kit.FeedTaskRun(ctx, &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "build-xpto0",
+			Namespace: "default",
+			UID:       "build-xpto0",
+			Labels:    map[string]string{"tekton.dev/task": "build"},
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "build"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series, err := kit.Series("task_kit_status_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 || series[0].GetCounter().GetValue() != 1 {
+		t.Errorf("expected a single series counting 1 run, got %v", series)
+	}
+}
@@ -0,0 +1,71 @@
+#!/usr/bin/env bash
+
+# Copyright 2023 The Tekton Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# This script runs the presubmit tests; it is started by prow for each PR.
+# For convenience, it can also be executed manually.
+# Running the script without parameters, or with the --all-tests
+# flag, causes all tests to be executed, in the right order.
+# Use the flags --build-tests, --unit-tests and --integration-tests
+# to run a specific set of tests.
+
+# Markdown linting failures don't show up properly in Gubernator resulting
+# in a net-negative contributor experience.
+export DISABLE_MD_LINTING=1
+export TEST_FOLDER=$(pwd)
+export GO111MODULE=on
+
+source $(dirname $0)/../../vendor/github.com/tektoncd/plumbing/scripts/presubmit-tests.sh
+
+function pre_build_tests() {
+    pushd ${TEST_FOLDER}
+}
+
+function pre_unit_tests() {
+    pushd ${TEST_FOLDER}
+}
+
+function pre_integration_tests() {
+    pushd ${TEST_FOLDER}
+}
+
+function post_build_tests() {
+    popd
+}
+
+function post_unit_tests() {
+    popd
+}
+
+function post_integration_tests() {
+    popd
+}
+
+# The default build runner only builds the tip of the PR, every commit of the
+# PR is also built and vetted so the history stays bisectable.
+function build_tests() {
+  local failed=0
+  default_build_test_runner || failed=1
+  subheader "Checking go code with go vet"
+  go vet ./... || failed=1
+  if (( IS_PRESUBMIT )); then
+    subheader "Checking that every commit builds and vets"
+    ./hack/verify-commits.sh ${PULL_BASE_SHA} ${PULL_PULL_SHA} || failed=1
+  fi
+  return ${failed}
+}
+
+# We use the default unit and integration test runners.
+main $@